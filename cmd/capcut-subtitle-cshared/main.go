@@ -0,0 +1,147 @@
+// Command capcut-subtitle-cshared builds the converter as a C shared
+// library for applications not written in Go:
+//
+//	go build -buildmode=c-shared -o libcapcut.so ./cmd/capcut-subtitle-cshared
+//
+// The generated libcapcut.h declares
+//
+//	char *capcut_convert(char *input_path, char *output_path, char *options_json);
+//	void capcut_free(char *message);
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"unsafe"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+func main() {}
+
+// jsonOptions is the options_json object accepted by capcut_convert. Names
+// follow the command-line flags; omitted fields keep their defaults.
+type jsonOptions struct {
+	Format      string  `json:"format"`
+	Granularity string  `json:"granularity"`
+	Tracks      []int   `json:"tracks"`
+	Offset      string  `json:"offset"`
+	NoClean     bool    `json:"no_clean"`
+	Brackets    string  `json:"brackets"`
+	Dedup       bool    `json:"dedup"`
+	MaxChars    int     `json:"max_chars"`
+	LineShape   string  `json:"line_shape"`
+	FPS         float64 `json:"fps"`
+	Rounding    string  `json:"rounding"`
+	Negative    string  `json:"negative"`
+}
+
+// capcut_convert converts the draft at input_path and writes the result to
+// output_path. options_json may be NULL or empty. It returns NULL on
+// success, or an error message the caller must release with capcut_free.
+//
+//export capcut_convert
+func capcut_convert(inputPath, outputPath, optionsJSON *C.char) *C.char {
+	if err := convertFile(C.GoString(inputPath), C.GoString(outputPath), C.GoString(optionsJSON)); err != nil {
+		return C.CString(err.Error())
+	}
+	return nil
+}
+
+// capcut_free releases a message returned by capcut_convert.
+//
+//export capcut_free
+func capcut_free(message *C.char) {
+	C.free(unsafe.Pointer(message))
+}
+
+func convertFile(inputPath, outputPath, optionsJSON string) error {
+	opts, err := parseOptions(optionsJSON)
+	if err != nil {
+		return err
+	}
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer input.Close()
+
+	output, err := os.Create(outputPath)
+	if err != nil {
+		return &writers.WriteError{Path: outputPath, Err: err}
+	}
+	_, err = convert.Convert(input, output, opts)
+	if closeErr := output.Close(); err == nil && closeErr != nil {
+		err = &writers.WriteError{Path: outputPath, Err: closeErr}
+	}
+	if err != nil {
+		// Don't leave a truncated file behind for the caller.
+		os.Remove(outputPath)
+		return err
+	}
+	return nil
+}
+
+func parseOptions(s string) (convert.Options, error) {
+	var o jsonOptions
+	if strings.TrimSpace(s) != "" {
+		if err := json.Unmarshal([]byte(s), &o); err != nil {
+			return convert.Options{}, fmt.Errorf("failed to parse options: %w", err)
+		}
+	}
+
+	opts := convert.Options{
+		Format:   o.Format,
+		Tracks:   o.Tracks,
+		NoClean:  o.NoClean,
+		Dedup:    o.Dedup,
+		MaxChars: o.MaxChars,
+		FPS:      o.FPS,
+	}
+	var err error
+	if o.Offset != "" {
+		offset, err := time.ParseDuration(o.Offset)
+		if err != nil {
+			return convert.Options{}, fmt.Errorf("invalid offset: %w", err)
+		}
+		opts.Offset = offset.Microseconds()
+	}
+	if o.Granularity != "" {
+		if opts.Granularity, err = capcut.ParseGranularity(o.Granularity); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	if o.Brackets != "" {
+		if opts.Brackets, err = subtitle.ParseBracketMode(o.Brackets); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	if o.LineShape != "" {
+		if opts.LineShape, err = subtitle.ParseLineShape(o.LineShape); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	if o.Rounding != "" {
+		if opts.Rounding, err = subtitle.ParseRounding(o.Rounding); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	if o.Negative != "" {
+		if opts.Negative, err = subtitle.ParseNegativePolicy(o.Negative); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	return opts, nil
+}
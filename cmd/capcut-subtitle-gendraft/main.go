@@ -0,0 +1,37 @@
+// Command capcut-subtitle-gendraft writes a synthetic draft_content.json for
+// measuring the converter on drafts of any size:
+//
+//	go run ./cmd/capcut-subtitle-gendraft -tracks 4 -words 250000 > draft_content.json
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func main() {
+	tracks := flag.Int("tracks", 1, "number of text tracks")
+	words := flag.Int("words", 10000, "number of words per track")
+	seed := flag.Int64("seed", 1, "seed for the generated text and timings; the same seed gives the same draft")
+	flag.Parse()
+
+	if *tracks < 1 || *words < 1 {
+		fmt.Fprintln(os.Stderr, "Error: -tracks and -words must be positive")
+		os.Exit(2)
+	}
+
+	output := bufio.NewWriter(os.Stdout)
+	if err := json.NewEncoder(output).Encode(capcuttest.Generate(*tracks, *words, *seed)); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+	if err := output.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
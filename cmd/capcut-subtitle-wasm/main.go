@@ -0,0 +1,81 @@
+//go:build js && wasm
+
+// Command capcut-subtitle-wasm runs the converter in a web page. Built for
+// js/wasm, it registers a global capcutConvert(json, options) function; see
+// web/index.html for a page that uses it.
+package main
+
+import (
+	"strings"
+	"syscall/js"
+
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func main() {
+	js.Global().Set("capcutConvert", js.FuncOf(convertJS))
+	select {}
+}
+
+// convertJS converts the draft_content.json text in args[0] with the
+// options object in args[1], if any. It returns an object with the
+// rendered "output", the number of "cues", a "warnings" array and, on
+// failure, an "error" message.
+func convertJS(this js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return map[string]any{"error": "capcutConvert needs the draft JSON as a string"}
+	}
+
+	var opts convert.Options
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		var err error
+		if opts, err = optionsFromJS(args[1]); err != nil {
+			return map[string]any{"error": err.Error()}
+		}
+	}
+
+	var output strings.Builder
+	report, err := convert.Convert(strings.NewReader(args[0].String()), &output, opts)
+	if err != nil {
+		return map[string]any{"error": err.Error()}
+	}
+
+	warnings := make([]any, len(report.Warnings))
+	for i, w := range report.Warnings {
+		warnings[i] = w.String()
+	}
+	return map[string]any{"output": output.String(), "cues": report.Cues, "warnings": warnings}
+}
+
+// optionsFromJS reads the options the page exposes, named like the
+// command-line flags: format, noClean, brackets, dedup, maxChars and
+// lineShape.
+func optionsFromJS(v js.Value) (convert.Options, error) {
+	var opts convert.Options
+	if f := v.Get("format"); f.Type() == js.TypeString {
+		opts.Format = f.String()
+	}
+	if f := v.Get("noClean"); f.Type() == js.TypeBoolean {
+		opts.NoClean = f.Bool()
+	}
+	if f := v.Get("dedup"); f.Type() == js.TypeBoolean {
+		opts.Dedup = f.Bool()
+	}
+	if f := v.Get("maxChars"); f.Type() == js.TypeNumber {
+		opts.MaxChars = f.Int()
+	}
+
+	var err error
+	if f := v.Get("brackets"); f.Type() == js.TypeString {
+		if opts.Brackets, err = subtitle.ParseBracketMode(f.String()); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	if f := v.Get("lineShape"); f.Type() == js.TypeString {
+		if opts.LineShape, err = subtitle.ParseLineShape(f.String()); err != nil {
+			return convert.Options{}, err
+		}
+	}
+	return opts, nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// runApply writes the text of edited subtitles back into the text
+// materials of the draft they were converted from, so fixes made in a
+// subtitle editor show up in CapCut. The subtitles must hold the cues the
+// draft converts to with the same flags, in the same order; their times
+// are left as the draft has them.
+func runApply(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle apply [flags] <edited.srt> [draft]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		fs.Usage()
+		return fmt.Errorf("apply needs the edited subtitles and at most one draft")
+	}
+	edited, format, err := loadCues(ctx, expandPath(fs.Arg(0)), opts.Options)
+	if err != nil {
+		return err
+	}
+	if format == "capcut" {
+		return fmt.Errorf("%s is a draft; apply takes the edited subtitles first", fs.Arg(0))
+	}
+	path, err := draftArgument(fs.Args()[1:])
+	if err != nil {
+		return err
+	}
+	cues, err := transformedCues(ctx, path, opts)
+	if err != nil {
+		return err
+	}
+	if len(edited) != len(cues) {
+		return fmt.Errorf("%s has %d cues but the draft converts to %d; apply needs the same cues in the same order, so convert with the flags the subtitles were made with", fs.Arg(0), len(edited), len(cues))
+	}
+
+	texts, err := editedTexts(cues, edited)
+	if err != nil {
+		return err
+	}
+	retimed := 0
+	for i, c := range cues {
+		if edited[i].Start != c.Start || edited[i].End != c.End {
+			retimed++
+		}
+	}
+	if retimed > 0 {
+		printWarning(fmt.Sprintf("%d cues have new times; apply writes text only, so retime them in CapCut", retimed))
+	}
+	changed, err := setDraftTexts(path, texts, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Applied %d edited cues; changed %d text materials of %s\n", len(texts), changed, path)
+	return nil
+}
+
+// editedTexts maps the material of every cue whose text edited changes
+// to the new text. A material whose text is spread over several cues, as
+// with word timings, cannot take an edit of one of them.
+func editedTexts(cues, edited []subtitle.Cue) (map[string]string, error) {
+	first := make(map[string]int)
+	shared := make(map[string]bool)
+	for i, c := range cues {
+		if _, ok := first[c.Source.MaterialID]; ok {
+			shared[c.Source.MaterialID] = true
+		} else {
+			first[c.Source.MaterialID] = i
+		}
+	}
+	texts := make(map[string]string)
+	for i, c := range cues {
+		if edited[i].Text == c.Text {
+			continue
+		}
+		if shared[c.Source.MaterialID] {
+			return nil, fmt.Errorf("cue %d comes from material %s with cue %d; convert with --granularity segments to apply edits to it", i+1, c.Source.MaterialID, first[c.Source.MaterialID]+1)
+		}
+		texts[c.Source.MaterialID] = edited[i].Text
+	}
+	return texts, nil
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+)
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	draft := filepath.Join(dir, "draft_content.json")
+	content := `{"materials": {"texts": [{"id": "m1", "content": "Hello"}, {"id": "m2", "content": "<font id=\"\" path=\"Sans.ttf\"><color=(1,1,0,1)><size=8>[wrold]</size></color></font>"}]},
+ "tracks": [{"type": "text", "segments": [
+  {"material_id": "m1", "target_timerange": {"start": 0, "duration": 1000000}},
+  {"material_id": "m2", "target_timerange": {"start": 1000000, "duration": 1500000}}]}]}`
+	if err := os.WriteFile(draft, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	edited := filepath.Join(dir, "edited.srt")
+	write := func(srt string) {
+		t.Helper()
+		if err := os.WriteFile(edited, []byte(srt), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+
+	write("1\n00:00:00,000 --> 00:00:01,000\nHello\n\n")
+	if err := runApply(ctx, []string{edited, dir}); err == nil || !strings.Contains(err.Error(), "has 1 cues but the draft converts to 2") {
+		t.Errorf("runApply() with a cue missing = %v", err)
+	}
+
+	// Only the text of the second cue changed, keeping its style; its new
+	// times are not applied.
+	write("1\n00:00:00,000 --> 00:00:01,000\nHello\n\n2\n00:00:01,200 --> 00:00:02,500\nworld\n\n")
+	if err := runApply(ctx, []string{edited, dir}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := capcut.ReadDraft(draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := got.Materials.Texts; texts[0].Content != "Hello" || texts[1].Content != `<font id="" path="Sans.ttf"><color=(1,1,0,1)><size=8>[world]</size></color></font>` {
+		t.Errorf("draft texts after apply = %+v", texts)
+	}
+	if start := got.Tracks[0].Segments[1].TargetTimerange.Start; start != 1000000 {
+		t.Errorf("second segment starts at %d after apply, want 1000000", start)
+	}
+	if backup, err := os.ReadFile(draft + ".bak"); err != nil || string(backup) != content {
+		t.Errorf("backup = %q, %v; want the draft as it was", backup, err)
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// batchResult is the outcome of converting one draft listed in
+// file-path.txt.
+type batchResult struct {
+	input    string
+	cues     int
+	warnings int64
+	outputs  []string
+	err      error
+}
+
+// status names the outcome for the summary table.
+func (r batchResult) status() string {
+	switch {
+	case errors.Is(r.err, errUpToDate):
+		return "up to date"
+	case errors.Is(r.err, errSkipped):
+		return "skipped"
+	case errors.Is(r.err, errDoneEarlier):
+		return "done earlier"
+	case errors.Is(r.err, context.Canceled):
+		return "interrupted"
+	case r.err != nil:
+		return "failed"
+	}
+	return "converted"
+}
+
+// color is the color of the status.
+func (r batchResult) color() string {
+	switch r.status() {
+	case "failed":
+		return colorRed
+	case "interrupted":
+		return colorYellow
+	case "converted":
+		return colorGreen
+	}
+	return ""
+}
+
+// convertBatch converts every draft in paths, one after another, and
+// prints a summary table once all are done. A failed draft does not stop
+// the others; the run fails if any did. An interrupt stops the batch after
+// the draft being converted, whose outputs are not written, and the table
+// covers the drafts done so far. With job.continueNumbering, the cues are
+// numbered across the outputs in file-path.txt order; a failed draft adds
+// no numbers. With job.resumePath, drafts an earlier run of the batch
+// finished are left out.
+func convertBatch(ctx context.Context, paths []string, job convertJob) error {
+	var state *progress
+	if job.resumePath != "" {
+		var err error
+		if state, err = openProgress(job.resumePath, job.opts); err != nil {
+			return err
+		}
+	}
+	var results []batchResult
+	// writtenBy maps each output to the draft that wrote it, as projects
+	// of the same name share their default output name.
+	writtenBy := make(map[string]string)
+	next := 1
+	for _, path := range paths {
+		if ctx.Err() != nil {
+			break
+		}
+		result, done := state.result(path)
+		if done {
+			fmt.Printf("Skipping %s, done earlier\n", path)
+		} else {
+			fmt.Printf("Converting %s\n", path)
+			if job.continueNumbering {
+				first := next
+				job.opts.Writer = writers.WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+					return writers.WriteSRTFrom(w, *subs, first)
+				})
+			}
+			result = job.convert(ctx, path)
+			if err := state.record(result); err != nil {
+				return err
+			}
+		}
+		if result.err == nil || done {
+			next += result.cues
+		}
+		for _, output := range result.outputs {
+			if previous, ok := writtenBy[output]; ok {
+				printWarning(fmt.Sprintf("%s replaced %s, written from %s", path, output, previous))
+			}
+			writtenBy[output] = path
+		}
+		results = append(results, result)
+	}
+
+	return state.finish(finishBatch(ctx, results, len(paths)))
+}
+
+// finishBatch prints the summary table of a batch of total drafts that
+// got results, and fails it if it was interrupted or any draft failed.
+func finishBatch(ctx context.Context, results []batchResult, total int) error {
+	fmt.Println()
+	if err := writeBatchSummary(os.Stdout, results); err != nil {
+		return err
+	}
+	if ctx.Err() != nil {
+		fmt.Printf("%d of %d drafts not converted\n", total-len(results)+countStatus(results, "interrupted"), total)
+		return ctx.Err()
+	}
+	if failed := countStatus(results, "failed"); failed > 0 {
+		return fmt.Errorf("%d of %d drafts failed", failed, len(results))
+	}
+	return nil
+}
+
+func countStatus(results []batchResult, status string) int {
+	n := 0
+	for _, r := range results {
+		if r.status() == status {
+			n++
+		}
+	}
+	return n
+}
+
+// writeBatchSummary writes a table of results with a line of totals,
+// followed by the error of each failed draft.
+func writeBatchSummary(w io.Writer, results []batchResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "INPUT\tCUES\tWARNINGS\tOUTPUT\tSTATUS")
+	counts := make(map[string]int)
+	cues, warnings := 0, int64(0)
+	for _, r := range results {
+		output := strings.Join(r.outputs, ", ")
+		if output == "" {
+			output = "-"
+		}
+		// The status is the last column, so its color codes do not upset
+		// the alignment.
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", r.input, r.cues, r.warnings, output, colorize(r.color(), r.status()))
+		counts[r.status()]++
+		cues += r.cues
+		warnings += r.warnings
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	skipped := ""
+	if counts["skipped"] > 0 {
+		skipped = fmt.Sprintf(", %d skipped", counts["skipped"])
+	}
+	if counts["done earlier"] > 0 {
+		skipped += fmt.Sprintf(", %d done earlier", counts["done earlier"])
+	}
+	fmt.Fprintf(w, "\n%d drafts: %d converted, %d up to date, %d failed%s; %d cues, %d warnings\n",
+		len(results), counts["converted"], counts["up to date"], counts["failed"], skipped, cues, warnings)
+	for _, r := range results {
+		if r.status() == "failed" {
+			fmt.Fprintf(w, "%s: %v\n", r.input, r.err)
+		}
+	}
+	return nil
+}
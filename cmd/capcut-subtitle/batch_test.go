@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func TestWriteBatchSummary(t *testing.T) {
+	results := []batchResult{
+		{input: "Trip/draft_content.json", cues: 120, warnings: 2, outputs: []string{"Trip/Trip.srt"}},
+		{input: "Vlog/draft_content.json", outputs: []string{"Vlog/Vlog.srt"}, err: errUpToDate},
+		{input: "Old/draft_content.json", err: fmt.Errorf("reading draft: %w", errors.New("not a CapCut draft"))},
+	}
+	var b strings.Builder
+	if err := writeBatchSummary(&b, results); err != nil {
+		t.Fatal(err)
+	}
+	want := `INPUT                    CUES  WARNINGS  OUTPUT         STATUS
+Trip/draft_content.json  120   2         Trip/Trip.srt  converted
+Vlog/draft_content.json  0     0         Vlog/Vlog.srt  up to date
+Old/draft_content.json   0     0         -              failed
+
+3 drafts: 1 converted, 1 up to date, 1 failed; 120 cues, 2 warnings
+Old/draft_content.json: reading draft: not a CapCut draft
+`
+	if got := b.String(); got != want {
+		t.Errorf("summary:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestConvertBatchInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	dir := t.TempDir()
+	err := convertBatch(ctx, []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, convertJob{})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("convertBatch() = %v, want context.Canceled", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("interrupted batch wrote %d files", len(entries))
+	}
+}
+
+func TestConvertBatchContinueNumbering(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for i, name := range []string{"Part 1", "Part 2"} {
+		project := filepath.Join(dir, name)
+		draft, err := json.Marshal(capcuttest.Generate(1, 8, int64(i+1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Mkdir(project, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(project, "draft_content.json"), draft, 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, project)
+	}
+	opts, err := parseOptions(flag.NewFlagSet("capcut-subtitle", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := convertBatch(context.Background(), paths, convertJob{continueNumbering: true, opts: opts}); err != nil {
+		t.Fatal(err)
+	}
+
+	var numbers []int
+	for _, name := range []string{"Part 1", "Part 2"} {
+		cues, err := os.ReadFile(filepath.Join(dir, name, name+".srt"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range strings.Split(strings.TrimSpace(string(cues)), "\n\n") {
+			n, err := strconv.Atoi(strings.SplitN(block, "\n", 2)[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			numbers = append(numbers, n)
+		}
+	}
+	for i, n := range numbers {
+		if n != i+1 {
+			t.Fatalf("cue numbers %v, want 1 to %d", numbers, len(numbers))
+		}
+	}
+}
+
+func TestConvertContinueNumberingTemplate(t *testing.T) {
+	template := filepath.Join(t.TempDir(), "cues.srt.tmpl")
+	if err := os.WriteFile(template, []byte("{{range .Cues}}{{.Number}} {{.Text}}\n{{end}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err := runConvert(context.Background(), []string{"--continue-numbering", "--template", template})
+	if err == nil || !strings.Contains(err.Error(), "--continue-numbering cannot be combined with") || !strings.Contains(err.Error(), "--template") {
+		t.Errorf("runConvert() with --continue-numbering and --template = %v, want an error", err)
+	}
+}
+
+func TestConvertBatchResume(t *testing.T) {
+	dir := t.TempDir()
+	writeDraft := func(name string) string {
+		project := filepath.Join(dir, name)
+		draft, err := json.Marshal(capcuttest.Generate(1, 8, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.MkdirAll(project, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(project, "draft_content.json"), draft, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return project
+	}
+	paths := []string{writeDraft("Part 1"), filepath.Join(dir, "Part 2")}
+	opts, err := parseOptions(flag.NewFlagSet("capcut-subtitle", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := convertJob{resumePath: filepath.Join(dir, "progress.json"), opts: opts}
+
+	// Part 2 is missing, so the first run fails and records its progress.
+	if err := convertBatch(context.Background(), paths, job); err == nil {
+		t.Fatal("convertBatch() succeeded with a missing draft")
+	}
+	first := filepath.Join(dir, "Part 1", "Part 1.srt")
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+	writeDraft("Part 2")
+	if err := convertBatch(context.Background(), paths, job); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(first); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("resumed batch converted Part 1 again")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "Part 2", "Part 2.srt")); err != nil {
+		t.Errorf("resumed batch did not retry Part 2: %v", err)
+	}
+	if _, err := os.Stat(job.resumePath); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("finished batch kept its progress file")
+	}
+}
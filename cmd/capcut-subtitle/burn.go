@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// runBurn renders the subtitles into a copy of an exported video with
+// ffmpeg's subtitles filter, producing a hardsubbed video.
+func runBurn(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("burn", flag.ExitOnError)
+	video := fs.String("video", "", "exported video to burn the subtitles into")
+	output := fs.String("o", "", "output video (default: the video name with .subtitled before the extension)")
+	ffmpeg := fs.String("ffmpeg", "ffmpeg", "ffmpeg executable")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle burn --video <video> [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *video == "" {
+		fs.Usage()
+		return fmt.Errorf("burn needs --video")
+	}
+	if opts.Format != "srt" && opts.Format != "vtt" && opts.Format != "ass" {
+		return fmt.Errorf("burn needs srt, vtt or ass subtitles, not %s", opts.Format)
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	dir, subtitles, err := writeTempSubtitles(cues, opts)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	name := *output
+	if name == "" {
+		name = outputVideoName(*video, "subtitled")
+	}
+	if err := runFFmpegOutput(ctx, *ffmpeg, name,
+		"-i", toolPath(*video),
+		"-vf", "subtitles="+escapeFilterPath(subtitles),
+		"-c:a", "copy",
+	); err != nil {
+		return err
+	}
+
+	fmt.Printf("Burned %d cues into %s\n", len(cues), name)
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+// ANSI colors of the lines that should stand out in long logs.
+const (
+	colorRed    = "31"
+	colorGreen  = "32"
+	colorYellow = "33"
+)
+
+// noColor is set by --no-color.
+var noColor atomic.Bool
+
+// terminalColor reports whether standard output is a terminal that shows
+// colors and the user did not opt out through NO_COLOR (see no-color.org).
+var terminalColor = sync.OnceValue(func() bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return enableColor()
+})
+
+// colorize returns text in color when standard output shows colors, and
+// text itself otherwise, so redirected output stays plain.
+func colorize(color, text string) string {
+	if color == "" || noColor.Load() || !terminalColor() {
+		return text
+	}
+	return "\x1b[" + color + "m" + text + "\x1b[0m"
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// enableColor reports whether the terminal can show colors; outside
+// Windows terminals understand ANSI escape sequences.
+func enableColor() bool {
+	return true
+}
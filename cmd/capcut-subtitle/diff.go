@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+func runDiff(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle diff <old> <new>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("diff needs exactly two inputs")
+	}
+
+	a, _, err := loadCues(ctx, fs.Arg(0), convert.Options{})
+	if err != nil {
+		return err
+	}
+	b, _, err := loadCues(ctx, fs.Arg(1), convert.Options{})
+	if err != nil {
+		return err
+	}
+
+	writeDiffReport(os.Stdout, subtitle.Diff(a, b), len(a), len(b))
+	return nil
+}
+
+func writeDiffReport(w io.Writer, changes []subtitle.Change, oldCount, newCount int) {
+	var timing, text, removed, added int
+	for _, c := range changes {
+		switch c.Kind {
+		case subtitle.ChangeTiming:
+			timing++
+			fmt.Fprintf(w, "~ #%d -> #%d timing: %s => %s (start %s, end %s)\n",
+				c.OldIndex, c.NewIndex, formatRange(c.Old), formatRange(c.New),
+				formatShift(c.New.Start-c.Old.Start), formatShift(c.New.End-c.Old.End))
+		case subtitle.ChangeText:
+			text++
+			fmt.Fprintf(w, "~ #%d -> #%d text: %q => %q", c.OldIndex, c.NewIndex, c.Old.Text, c.New.Text)
+			if c.Old.Start != c.New.Start || c.Old.End != c.New.End {
+				fmt.Fprintf(w, " (%s => %s)", formatRange(c.Old), formatRange(c.New))
+			}
+			fmt.Fprintln(w)
+		case subtitle.ChangeRemoved:
+			removed++
+			fmt.Fprintf(w, "- #%d %s %q\n", c.OldIndex, formatRange(c.Old), c.Old.Text)
+		case subtitle.ChangeAdded:
+			added++
+			fmt.Fprintf(w, "+ #%d %s %q\n", c.NewIndex, formatRange(c.New), c.New.Text)
+		}
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintf(w, "No differences (%d cues)\n", oldCount)
+		return
+	}
+	fmt.Fprintf(w, "%d -> %d cues: %d timing changes, %d text changes, %d removed, %d added\n",
+		oldCount, newCount, timing, text, removed, added)
+}
+
+func formatRange(c subtitle.Cue) string {
+	return writers.FormatTime(c.Start) + " --> " + writers.FormatTime(c.End)
+}
+
+func formatShift(microseconds int64) string {
+	d := time.Duration(microseconds) * time.Microsecond
+	if d > 0 {
+		return "+" + d.String()
+	}
+	return d.String()
+}
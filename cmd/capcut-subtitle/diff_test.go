@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestWriteDiffReport(t *testing.T) {
+	changes := []subtitle.Change{
+		{Kind: subtitle.ChangeTiming, OldIndex: 1, NewIndex: 1, Old: subtitle.Cue{Start: 0, End: 1000000, Text: "Hi"}, New: subtitle.Cue{Start: 200000, End: 1000000, Text: "Hi"}},
+		{Kind: subtitle.ChangeAdded, NewIndex: 2, New: subtitle.Cue{Start: 1000000, End: 2000000, Text: "New"}},
+	}
+
+	var buf bytes.Buffer
+	writeDiffReport(&buf, changes, 1, 2)
+
+	want := `~ #1 -> #1 timing: 00:00:00,000 --> 00:00:01,000 => 00:00:00,200 --> 00:00:01,000 (start +200ms, end 0s)
++ #2 00:00:01,000 --> 00:00:02,000 "New"
+1 -> 2 cues: 1 timing changes, 0 text changes, 0 removed, 1 added
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeDiffReport() = \n%v\nwant\n%v", got, want)
+	}
+}
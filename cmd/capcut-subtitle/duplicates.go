@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// runDuplicates reports cues whose text repeats elsewhere in the file,
+// exactly or nearly, which usually means a template line was pasted and
+// never edited.
+func runDuplicates(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("duplicates", flag.ExitOnError)
+	similarity := fs.Float64("similarity", 0.9, "how alike two texts must be to count as near duplicates, from 0 to 1; 1 reports exact repeats only")
+	minLength := fs.Int("min-length", 10, "skip cues with fewer letters and digits than this")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle duplicates [--similarity 0.9] [--min-length 10] [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *similarity <= 0 || *similarity > 1 {
+		return fmt.Errorf("--similarity must be above 0 and at most 1")
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	writeDuplicateReport(os.Stdout, cues, subtitle.Duplicates(cues, *similarity, *minLength))
+	return nil
+}
+
+func writeDuplicateReport(w io.Writer, cues []subtitle.Cue, groups []subtitle.DuplicateGroup) {
+	if len(groups) == 0 {
+		fmt.Fprintf(w, "No repeated cues (%d cues)\n", len(cues))
+		return
+	}
+	repeated := 0
+	for _, g := range groups {
+		kind := "Repeated"
+		if !g.Exact {
+			kind = "Similar"
+		}
+		fmt.Fprintf(w, "%s text in %d cues:\n", kind, len(g.Cues))
+		for _, n := range g.Cues {
+			c := cues[n-1]
+			fmt.Fprintf(w, "  #%d %s %q\n", n, writers.FormatTime(c.Start), c.Text)
+		}
+		repeated += len(g.Cues)
+	}
+	fmt.Fprintf(w, "%d groups, %d of %d cues\n", len(groups), repeated, len(cues))
+}
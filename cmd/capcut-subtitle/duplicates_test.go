@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestWriteDuplicateReport(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, Text: "Like and subscribe"},
+		{Start: 2000000, Text: "Hello"},
+		{Start: 61500000, Text: "Like and subcribe!"},
+	}
+
+	var buf bytes.Buffer
+	writeDuplicateReport(&buf, cues, subtitle.Duplicates(cues, 0.9, 10))
+
+	want := `Similar text in 2 cues:
+  #1 00:00:01,000 "Like and subscribe"
+  #3 00:01:01,500 "Like and subcribe!"
+1 groups, 2 of 3 cues
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeDuplicateReport() = \n%v\nwant\n%v", got, want)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// editHelp lists the commands of edit.
+const editHelp = `Commands:
+  list [N [M]]          list cues N to M, or all of them
+  start N ±DURATION     move the start of cue N, e.g. start 3 -200ms
+  end N ±DURATION       move the end of cue N
+  move N ±DURATION      move cue N as a whole
+  text N TEXT           replace the text of cue N; | starts a new line
+  merge N               merge cue N with the one after it
+  split N [WORD]        split cue N before word WORD, or at its middle
+  undo                  undo the last change
+  save [FILE]           write the cues in the output format
+  quit                  leave; quit! leaves without saving
+  help                  show this list
+`
+
+// runEdit opens the cues of the input, or the draft in file-path.txt, in
+// an interactive editor on the terminal for the small fixes that should
+// not need a subtitle editor: nudging times, fixing text, and merging or
+// splitting cues. It reads one command a line, so it also takes commands
+// from a script on standard input.
+func runEdit(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ExitOnError)
+	output := fs.String("o", "", "file save writes to (default: the input name, or the project's, with the output format's extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle edit [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	name := *output
+	if name == "" {
+		if name, err = namedAfterInput(fs.Args(), opts.Format); err != nil {
+			return err
+		}
+	}
+	e := &editor{cues: cues, output: name, saved: true, save: func(name string, cues []subtitle.Cue) ([]string, error) {
+		return writeResult(ctx, name, cues, opts)
+	}}
+	return e.run(os.Stdin, os.Stdout)
+}
+
+// editor is a session of edit.
+type editor struct {
+	cues []subtitle.Cue
+	// history holds the cues before each change, for undo.
+	history [][]subtitle.Cue
+	// output is where save writes without a file name.
+	output string
+	saved  bool
+	save   func(name string, cues []subtitle.Cue) ([]string, error)
+}
+
+// run reads commands from r until quit or the end of input, printing to
+// w. Mistakes in a command are printed and the session goes on.
+func (e *editor) run(r io.Reader, w io.Writer) error {
+	fmt.Fprintf(w, "%d cues; type help for the commands.\n", len(e.cues))
+	scanner := bufio.NewScanner(r)
+	for {
+		fmt.Fprint(w, "edit> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(w)
+			if !e.saved {
+				printWarning("input ended with unsaved changes")
+			}
+			return scanner.Err()
+		}
+		quit, err := e.exec(scanner.Text(), w)
+		if err != nil {
+			fmt.Fprintln(w, colorize(colorRed, "Error:"), err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// exec runs one command line, reporting whether it ends the session.
+func (e *editor) exec(line string, w io.Writer) (bool, error) {
+	command, rest, _ := strings.Cut(strings.TrimSpace(line), " ")
+	rest = strings.TrimSpace(rest)
+	switch command {
+	case "":
+		return false, nil
+	case "help", "h", "?":
+		fmt.Fprint(w, editHelp)
+		return false, nil
+	case "list", "l":
+		return false, e.list(rest, w)
+	case "undo", "u":
+		if len(e.history) == 0 {
+			return false, fmt.Errorf("nothing to undo")
+		}
+		e.cues, e.history = e.history[len(e.history)-1], e.history[:len(e.history)-1]
+		e.saved = false
+		fmt.Fprintf(w, "Undone; %d cues.\n", len(e.cues))
+		return false, nil
+	case "save", "w":
+		name := e.output
+		if rest != "" {
+			name = expandPath(rest)
+		}
+		written, err := e.save(name, e.cues)
+		if err != nil {
+			return false, err
+		}
+		e.saved = true
+		fmt.Fprintf(w, "Wrote %s\n", strings.Join(written, ", "))
+		return false, nil
+	case "quit", "q":
+		if !e.saved {
+			return false, fmt.Errorf("unsaved changes; save them, or use quit! to leave without saving")
+		}
+		return true, nil
+	case "quit!", "q!":
+		return true, nil
+	}
+
+	edit, known := editCommands[command]
+	if !known {
+		return false, fmt.Errorf("unknown command %q; type help for the commands", command)
+	}
+	arg, rest, _ := strings.Cut(rest, " ")
+	rest = strings.TrimSpace(rest)
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 1 || n > len(e.cues) {
+		return false, fmt.Errorf("%s needs a cue number from 1 to %d", command, len(e.cues))
+	}
+	i := n - 1
+	cues := slices.Clone(e.cues)
+	switch edit {
+	case editStart, editEnd, editMove:
+		d, err := time.ParseDuration(rest)
+		if err != nil {
+			return false, fmt.Errorf("%s needs a duration such as +200ms or -1.5s", command)
+		}
+		delta := d.Microseconds()
+		c := &cues[i]
+		switch edit {
+		case editStart:
+			c.Start += delta
+		case editEnd:
+			c.End += delta
+		case editMove:
+			c.Start, c.End = c.Start+delta, c.End+delta
+		}
+		if c.Start < 0 || c.End <= c.Start {
+			return false, fmt.Errorf("cue %d would run from %s to %s", n, writers.FormatTime(c.Start), writers.FormatTime(c.End))
+		}
+	case editText:
+		if rest == "" {
+			return false, fmt.Errorf("text needs the new text; | starts a new line")
+		}
+		cues[i].Text = strings.TrimSpace(strings.ReplaceAll(rest, "|", "\n"))
+	case editMerge:
+		if n == len(cues) {
+			return false, fmt.Errorf("cue %d is the last; there is none after it to merge with", n)
+		}
+		next := cues[i+1]
+		cues[i].Start, cues[i].End = min(cues[i].Start, next.Start), max(cues[i].End, next.End)
+		cues[i].Text = strings.TrimSpace(cues[i].Text) + " " + strings.TrimSpace(next.Text)
+		cues = slices.Delete(cues, i+1, i+2)
+	case editSplit:
+		words := strings.Fields(cues[i].Text)
+		if len(words) < 2 {
+			return false, fmt.Errorf("cue %d has a single word; there is nothing to split", n)
+		}
+		at := middleWord(words)
+		if rest != "" {
+			if at, err = strconv.Atoi(rest); err != nil || at < 2 || at > len(words) {
+				return false, fmt.Errorf("split %d takes a word number from 2 to %d to split before", n, len(words))
+			}
+			at--
+		}
+		cues = slices.Replace(cues, i, i+1, splitCue(cues[i], words, at)...)
+	}
+	e.history = append(e.history, e.cues)
+	e.cues = cues
+	e.saved = false
+	last := i + 1
+	if edit == editSplit {
+		last++
+	}
+	writeEditCues(w, e.cues, i, min(last, len(e.cues)))
+	return false, nil
+}
+
+// The commands of edit that change a cue.
+const (
+	editStart = iota
+	editEnd
+	editMove
+	editText
+	editMerge
+	editSplit
+)
+
+// editCommands maps the names of the commands that change a cue to what
+// they do.
+var editCommands = map[string]int{
+	"start": editStart,
+	"end":   editEnd,
+	"move":  editMove,
+	"text":  editText,
+	"merge": editMerge,
+	"split": editSplit,
+}
+
+// list prints the cues numbered from and to in args, or all of them.
+func (e *editor) list(args string, w io.Writer) error {
+	from, to := 1, len(e.cues)
+	fields := strings.Fields(args)
+	var err error
+	if len(fields) > 0 {
+		if from, err = strconv.Atoi(fields[0]); err != nil || from < 1 {
+			return fmt.Errorf("list takes cue numbers")
+		}
+		to = from
+	}
+	if len(fields) > 1 {
+		if to, err = strconv.Atoi(fields[1]); err != nil || to < from {
+			return fmt.Errorf("list takes cue numbers")
+		}
+	}
+	writeEditCues(w, e.cues, min(from, len(e.cues)+1)-1, min(to, len(e.cues)))
+	return nil
+}
+
+// writeEditCues prints cues[from:to], numbered from 1 with their times,
+// their lines separated by |.
+func writeEditCues(w io.Writer, cues []subtitle.Cue, from, to int) {
+	for i := from; i < to; i++ {
+		c := cues[i]
+		fmt.Fprintf(w, "%4d  %s --> %s  %s\n", i+1, writers.FormatTime(c.Start), writers.FormatTime(c.End), strings.ReplaceAll(c.Text, "\n", " | "))
+	}
+}
+
+// middleWord returns the index of the word after the break between words
+// nearest their middle in characters, which a split puts first in the
+// second part.
+func middleWord(words []string) int {
+	total := 0
+	for _, word := range words {
+		total += utf8.RuneCountInString(word)
+	}
+	best, bestOff, done := 1, total, 0
+	for i := 1; i < len(words); i++ {
+		done += utf8.RuneCountInString(words[i-1])
+		if off := max(2*done-total, total-2*done); off < bestOff {
+			best, bestOff = i, off
+		}
+	}
+	return best
+}
+
+// splitCue splits c before words[at], dividing its time between the parts
+// in proportion to their length, as SplitLongCues does.
+func splitCue(c subtitle.Cue, words []string, at int) []subtitle.Cue {
+	first, second := c, c
+	first.Text, second.Text = strings.Join(words[:at], " "), strings.Join(words[at:], " ")
+	a, b := utf8.RuneCountInString(first.Text), utf8.RuneCountInString(second.Text)
+	first.End = c.Start + (c.End-c.Start)*int64(a)/int64(a+b)
+	second.Start = first.End
+	return []subtitle.Cue{first, second}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestEditor(t *testing.T) {
+	var saved []subtitle.Cue
+	var savedTo string
+	e := &editor{
+		cues: []subtitle.Cue{
+			{Start: 0, End: 2_000_000, Text: "Hello there"},
+			{Start: 2_000_000, End: 3_000_000, Text: "wrold"},
+			{Start: 3_000_000, End: 6_000_000, Text: "one two three four"},
+		},
+		output: "out.srt",
+		saved:  true,
+		save: func(name string, cues []subtitle.Cue) ([]string, error) {
+			saved, savedTo = cues, name
+			return []string{name}, nil
+		},
+	}
+	script := strings.Join([]string{
+		"start 1 +250ms",
+		"end 2 -2s", // would end before it starts
+		"text 2 world|again",
+		"bogus 1",
+		"split 3",
+		"merge 1",
+		"undo",
+		"quit", // refused: unsaved changes
+		"save fixed.srt",
+		"quit",
+	}, "\n")
+	var out strings.Builder
+	if err := e.run(strings.NewReader(script), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []subtitle.Cue{
+		{Start: 250_000, End: 2_000_000, Text: "Hello there"},
+		{Start: 2_000_000, End: 3_000_000, Text: "world\nagain"},
+		{Start: 3_000_000, End: 4_235_294, Text: "one two"},
+		{Start: 4_235_294, End: 6_000_000, Text: "three four"},
+	}
+	if !reflect.DeepEqual(saved, want) || savedTo != "fixed.srt" {
+		t.Errorf("saved %+v to %s, want %+v to fixed.srt", saved, savedTo, want)
+	}
+	for _, message := range []string{"cue 2 would run from", `unknown command "bogus"`, "unsaved changes", "Wrote fixed.srt"} {
+		if !strings.Contains(out.String(), message) {
+			t.Errorf("output has no %q:\n%s", message, out.String())
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/convert"
+)
+
+// errSkipped reports a project export-all leaves out, as it has no text
+// track to take subtitles from.
+var errSkipped = errors.New("no text tracks")
+
+// runExportAll converts every project in the CapCut drafts folder into a
+// folder of its own under the output directory.
+func runExportAll(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export-all", flag.ExitOnError)
+	root := fs.String("root", defaultDraftsRoot(), "CapCut drafts folder holding one folder per project")
+	outputDir := fs.String("output-dir", "subtitles", "directory to write the subtitles of each project into, in a folder named after the project")
+	cachePath := fs.String("cache", "", "state file remembering converted drafts; skips projects whose draft and options did not change")
+	resumePath := fs.String("resume", "", "state file recording the progress of the export; running it again skips the projects it finished and retries the rest")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle export-all [--root folder] [--output-dir subtitles] [flags]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return fmt.Errorf("export-all takes no inputs; use --root")
+	}
+	*outputDir, *cachePath, *resumePath = expandPath(*outputDir), expandPath(*cachePath), expandPath(*resumePath)
+	if *root, err = draftsRoot(*root); err != nil {
+		return err
+	}
+
+	drafts, err := draftProjects(*root)
+	if err != nil {
+		return err
+	}
+	if len(drafts) == 0 {
+		return fmt.Errorf("no CapCut projects in %s", *root)
+	}
+	var state *progress
+	if *resumePath != "" {
+		if state, err = openProgress(*resumePath, opts); err != nil {
+			return err
+		}
+	}
+	var results []batchResult
+	// folders holds the output folders taken, as projects may share a name.
+	folders := make(map[string]bool)
+	for _, draft := range drafts {
+		if ctx.Err() != nil {
+			break
+		}
+		folder := fileName(capcut.ProjectName(draft), "subtitles")
+		if folders[strings.ToLower(folder)] {
+			folder += " " + fileName(filepath.Base(filepath.Dir(draft)), "project")
+		}
+		folders[strings.ToLower(folder)] = true
+
+		if result, done := state.result(draft); done {
+			fmt.Printf("Skipping %s, done earlier\n", draft)
+			results = append(results, result)
+			continue
+		}
+		fmt.Printf("Exporting %s\n", draft)
+		result := exportProject(ctx, draft, filepath.Join(*outputDir, folder), *cachePath, opts)
+		if err := state.record(result); err != nil {
+			return err
+		}
+		results = append(results, result)
+	}
+	return state.finish(finishBatch(ctx, results, len(drafts)))
+}
+
+// exportProject converts the draft at path into dir under its default
+// name.
+func exportProject(ctx context.Context, path, dir, cachePath string, opts options) batchResult {
+	result := batchResult{input: path}
+	since := warningCount.Load()
+	name, err := defaultOutput(path, dir, opts.Format)
+	if err == nil {
+		var report convert.Report
+		report, result.outputs, err = convertDraft(ctx, path, name, cachePath, opts)
+		result.cues = report.Cues
+	}
+	result.warnings = warningCount.Load() - since
+	if errors.Is(err, capcut.ErrNoTextTracks) {
+		// defaultOutput created the folder before the draft was read.
+		os.Remove(dir)
+		err = errSkipped
+	}
+	if err == nil {
+		err = strictFailure(since)
+	}
+	result.err = err
+	return result
+}
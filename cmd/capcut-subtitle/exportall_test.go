@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func TestExportAll(t *testing.T) {
+	root, out := t.TempDir(), t.TempDir()
+	captions, err := json.Marshal(capcuttest.Generate(1, 8, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	projects := []struct {
+		folder, name string
+		draft        []byte
+	}{
+		{"0A1B", "Holiday vlog", captions},
+		{"2C3D", "Holiday vlog", captions},
+		{"4E5F", "B-roll", []byte(`{"tracks": [{"id": "v", "type": "video", "segments": []}]}`)},
+	}
+	for _, p := range projects {
+		dir := filepath.Join(root, p.folder)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "draft_content.json"), p.draft, 0644); err != nil {
+			t.Fatal(err)
+		}
+		meta := `{"draft_name": "` + p.name + `"}`
+		if err := os.WriteFile(filepath.Join(dir, "draft_meta_info.json"), []byte(meta), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// CapCut's own state next to the projects.
+	if err := os.WriteFile(filepath.Join(root, "root_meta_info.json"), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, ".recycle_bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runExportAll(context.Background(), []string{"--root", root, "--output-dir", out}); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Holiday vlog/Holiday vlog.srt", "Holiday vlog 2C3D/Holiday vlog.srt"} {
+		if _, err := os.Stat(filepath.Join(out, name)); err != nil {
+			t.Error(err)
+		}
+	}
+	if entries, _ := os.ReadDir(out); len(entries) != 2 {
+		t.Errorf("export-all wrote %d folders, want 2 with the project without text tracks skipped", len(entries))
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// runFFmpeg runs ffmpeg with args, passing its terminal through so its
+// progress and overwrite prompts reach the user.
+func runFFmpeg(ctx context.Context, ffmpeg string, args ...string) error {
+	return runTool(ctx, "ffmpeg", ffmpeg, args...)
+}
+
+// runFFmpegOutput runs ffmpeg with args followed by output, the file it
+// writes. If ffmpeg fails or is interrupted, the partly written output is
+// removed, unless it was there before the run: ffmpeg then asked before
+// overwriting it.
+func runFFmpegOutput(ctx context.Context, ffmpeg, output string, args ...string) error {
+	_, statErr := os.Stat(output)
+	err := runFFmpeg(ctx, ffmpeg, append(args, toolPath(output))...)
+	if err != nil && errors.Is(statErr, fs.ErrNotExist) {
+		os.Remove(output)
+	}
+	return err
+}
+
+// runTool runs an external program, named tool in errors, from path.
+func runTool(ctx context.Context, tool, path string, args ...string) error {
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return &toolError{Tool: tool, Err: err}
+	}
+	return nil
+}
+
+// toolError reports a failed external program. Tool is also the name of
+// the flag that sets the program's location.
+type toolError struct {
+	Tool string
+	Err  error
+}
+
+func (e *toolError) Error() string {
+	return fmt.Sprintf("running %s: %v", e.Tool, e.Err)
+}
+
+func (e *toolError) Unwrap() error {
+	return e.Err
+}
+
+// inputCues converts the draft or subtitle file named in args, or the draft
+// in file-path.txt without one.
+func inputCues(ctx context.Context, args []string, opts options) ([]subtitle.Cue, error) {
+	input := ""
+	switch len(args) {
+	case 0:
+		path, err := draftPath()
+		if err != nil {
+			return nil, err
+		}
+		input = path
+	case 1:
+		input = args[0]
+	default:
+		return nil, fmt.Errorf("expected at most one input, got %d", len(args))
+	}
+	return transformedCues(ctx, input, opts)
+}
+
+// writeTempSubtitles writes cues to a subtitle file in a new temporary
+// directory for ffmpeg to read. The caller removes the directory.
+func writeTempSubtitles(cues []subtitle.Cue, opts options) (dir, name string, err error) {
+	w, err := opts.writer(opts.Format)
+	if err != nil {
+		return "", "", err
+	}
+	if dir, err = os.MkdirTemp("", "capcut-subtitle-*"); err != nil {
+		return "", "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	name = filepath.Join(dir, "subtitles."+opts.Format)
+	if err := writers.WriteFileWith(name, w, cues); err != nil {
+		os.RemoveAll(dir)
+		return "", "", err
+	}
+	return dir, name, nil
+}
+
+// outputVideoName names the video written next to the input, e.g.
+// final.subtitled.mp4 for final.mp4.
+func outputVideoName(video, suffix string) string {
+	ext := filepath.Ext(video)
+	return strings.TrimSuffix(video, ext) + "." + suffix + ext
+}
+
+// escapeFilterPath escapes a file name for use as a filter option value in
+// an ffmpeg filter graph (-vf). The value is parsed twice, once as a filter
+// option, where \, ' and : are special, and once as part of the graph,
+// where \, ', [, ], , and ; are. Backslashes in Windows paths become
+// slashes, which ffmpeg accepts, so only the drive colon needs escaping.
+func escapeFilterPath(path string) string {
+	path = strings.ReplaceAll(filepath.ToSlash(path), `\`, "/")
+	option := escapeChars(path, `\':`)
+	return escapeChars(option, `\'[],;`)
+}
+
+func escapeChars(s, special string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(special, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
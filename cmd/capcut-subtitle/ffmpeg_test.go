@@ -0,0 +1,48 @@
+package main
+
+import "testing"
+
+func TestEscapeFilterPath(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{input: "/tmp/subtitles.srt", want: "/tmp/subtitles.srt"},
+		{input: "C:/Users/me/subtitles.srt", want: `C\\:/Users/me/subtitles.srt`},
+		{input: `C:\Users\me\subtitles.srt`, want: `C\\:/Users/me/subtitles.srt`},
+		{input: "/tmp/it's [final], v2.srt", want: `/tmp/it\\\'s \[final\]\, v2.srt`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeFilterPath(tt.input); got != tt.want {
+			t.Errorf("escapeFilterPath(%q) = %s, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestOutputVideoName(t *testing.T) {
+	if got := outputVideoName("renders/final.mp4", "subtitled"); got != "renders/final.subtitled.mp4" {
+		t.Errorf("outputVideoName() = %v", got)
+	}
+}
+
+func TestSubtitleCodec(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "final.mp4", want: "mov_text"},
+		{input: "final.MOV", want: "mov_text"},
+		{input: "final.mkv", want: "srt"},
+		{input: "final.webm", want: "webvtt"},
+		{input: "final.avi", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := subtitleCodec(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("subtitleCodec(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
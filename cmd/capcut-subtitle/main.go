@@ -0,0 +1,1402 @@
+// Command capcut-subtitle extracts subtitles from CapCut desktop project
+// drafts into .srt files.
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"capcut-subtitle/pkg/cache"
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/punctuate"
+	"capcut-subtitle/pkg/retry"
+	"capcut-subtitle/pkg/spell"
+	"capcut-subtitle/pkg/storage"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/transform"
+	"capcut-subtitle/pkg/writers"
+	"capcut-subtitle/pkg/youtube"
+)
+
+type options struct {
+	convert.Options
+	// splitEvery is the chunk length in microseconds, or 0 for a single file.
+	splitEvery int64
+	romanizer  *subtitle.Romanizer
+	// styleGuide, if set, is checked against every written file.
+	styleGuide *subtitle.StyleGuide
+	// verify parses every written file back and fails if it differs from
+	// the cues it was written from.
+	verify bool
+	// backup moves outputs about to be replaced to <name>.bak, or into
+	// backupDir if it is set.
+	backup    bool
+	backupDir string
+	// lang is the declared language of the subtitles, checked against the
+	// language detected in each track and recorded in formats that can.
+	lang string
+	// splitTracks writes each text track to its own file named with its
+	// language: trackLangs[number], or else the detected one.
+	splitTracks bool
+	trackLangs  map[int]string
+	// vttNotes adds a NOTE block to WebVTT output naming source, the file
+	// converted, with the converter's version and the time of writing.
+	vttNotes bool
+	source   string
+	// vttStyle adds a STYLE block to WebVTT output giving the cues
+	// cueStyle, the look of the draft's text.
+	vttStyle bool
+	cueStyle writers.VTTStyle
+	// speakerColors colors each speaker's cues differently in WebVTT
+	// output; ASS output gets it through its style.
+	speakerColors bool
+	// debugCues annotates SRT and WebVTT cues with where in the draft they
+	// came from; ASS output gets it through its style.
+	debugCues bool
+	// httpClient sends the requests to external APIs, paced and retried
+	// as --rate-limit and --retries ask.
+	httpClient *http.Client
+	// spellChecker, if set, reports unknown words in every written file.
+	spellChecker *spell.Checker
+	// chapterTrack is the text track number holding chapter markers, or 0.
+	chapterTrack int
+	// skippedReport writes the segments left out of the subtitles to a
+	// JSON file alongside them.
+	skippedReport bool
+	// appended holds the cues of the --append file, which the draft's
+	// cues follow, shifted by appendOffset microseconds.
+	appended     []subtitle.Cue
+	appendOffset int64
+	// args and configFiles record the settings the output depends on, for
+	// the conversion cache.
+	args        []string
+	configFiles []string
+}
+
+// commands holds the subcommands selected by the first argument. Without
+// one, the tool converts the draft named in file-path.txt. The context is
+// cancelled on interrupt or termination.
+var commands = map[string]func(ctx context.Context, args []string) error{
+	"apply":      runApply,
+	"burn":       runBurn,
+	"mux":        runMux,
+	"merge":      runMerge,
+	"preview":    runPreview,
+	"qc":         runQC,
+	"realign":    runRealign,
+	"review":     runReview,
+	"search":     runSearch,
+	"serve":      runServe,
+	"stats":      runStats,
+	"tmx":        runTMX,
+	"xliff":      runXLIFF,
+	"diff":       runDiff,
+	"duplicates": runDuplicates,
+	"edit":       runEdit,
+	"export-all": runExportAll,
+	"transform":  runTransform,
+	"upload":     runUpload,
+	"watch":      runWatch,
+	"words":      runWords,
+}
+
+// exitInterrupted is the exit status of a run stopped by a signal, the
+// 128 + SIGINT shells report, so scripts can tell it from a failure.
+const exitInterrupted = 130
+
+func main() {
+	name, command := "", runConvert
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if c, ok := commands[args[0]]; ok {
+			name, command, args = args[0], c, args[1:]
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	err := command(ctx, args)
+	// stop cancels ctx too, so whether a signal came must be read first.
+	interrupted := ctx.Err() != nil
+	stop()
+	if interrupted {
+		if err != nil && !errors.Is(err, context.Canceled) {
+			fmt.Println(colorize(colorRed, "Error:"), err)
+		}
+		fmt.Println(colorize(colorYellow, "Interrupted"))
+		os.Exit(exitInterrupted)
+	}
+	if err == nil {
+		err = strictError()
+	}
+	if err != nil {
+		fmt.Println(colorize(colorRed, "Error:"), err)
+		if hint := errorHint(err, name); hint != "" {
+			fmt.Println(hint)
+		}
+		os.Exit(1)
+	}
+}
+
+// errorHint suggests a fix for the failures users commonly run into. The
+// command is the subcommand name, or "" for the default conversion.
+func errorHint(err error, command string) string {
+	var writeErr *writers.WriteError
+	var youtubeErr *youtube.Error
+	var punctuateErr *punctuate.Error
+	var toolErr *toolError
+	switch {
+	case errors.Is(err, capcut.ErrUnsupportedVersion):
+		return "This CapCut version encrypts its drafts. Export the captions from CapCut as SRT instead."
+	case errors.Is(err, capcut.ErrNoTextTracks):
+		return "The project has no captions. Add them in CapCut (for example with auto captions) and save the project first."
+	case errors.Is(err, capcut.ErrNotADraft) && command == "":
+		return "Check that file-path.txt points to the project's draft_content.json."
+	case errors.Is(err, capcut.ErrNotADraft):
+		return "Check that the input is a project's draft_content.json or a supported subtitle file."
+	case errors.As(err, &toolErr) && errors.Is(err, exec.ErrNotFound):
+		return fmt.Sprintf("Install %s and add it to PATH, or pass its location with --%s.", toolErr.Tool, toolErr.Tool)
+	case errors.As(err, &youtubeErr) && youtubeErr.StatusCode == http.StatusUnauthorized:
+		return "The access token is missing or expired. Get a new one with the youtube.force-ssl scope."
+	case errors.As(err, &youtubeErr) && youtubeErr.StatusCode == http.StatusForbidden:
+		return "Check that the token belongs to the channel that owns the video."
+	case errors.As(err, &punctuateErr) && punctuateErr.StatusCode == http.StatusUnauthorized:
+		return "Set $PUNCTUATE_API_KEY or $OPENAI_API_KEY to a valid key for the --punctuate-url API."
+	case errors.As(err, &writeErr):
+		return fmt.Sprintf("Check that %s is writable.", filepath.Dir(writeErr.Path))
+	}
+	return ""
+}
+
+func runConvert(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("capcut-subtitle", flag.ExitOnError)
+	cachePath := fs.String("cache", "", "state file remembering converted drafts; skips the conversion when neither the draft nor the options changed")
+	resumePath := fs.String("resume", "", "state file recording the progress of a file-path.txt batch; running the batch again skips the drafts it finished and retries the rest")
+	maxMemory := fs.String("max-memory", "", "keep at most this much cue data in memory (e.g. 512MB), spilling the rest to temporary files")
+	output := fs.String("o", "", "output file, or an s3://, gs:// or azure:// URL to upload to (default: the project name with the output format's extension, in the draft's folder)")
+	outputDir := fs.String("output-dir", "", "directory the output is written to under its default name, instead of the draft's folder")
+	webhook := fs.String("webhook", "", "URL to POST a JSON report to when the conversion finishes or fails")
+	chapterTrack := fs.Int("chapters-track", 0, "text track number holding chapter markers, written to chapters.txt as a YouTube chapter list instead of the subtitles")
+	skippedReport := fs.Bool("skipped-report", false, "write every segment left out of the subtitles, with the reason, to a .skipped.json file next to them")
+	appendPath := fs.String("append", "", "existing subtitle file to add the draft's cues to, continuing its numbering; the result replaces it unless -o is given")
+	continueNumbering := fs.Bool("continue-numbering", false, "number the srt cues of each draft in file-path.txt on from the last cue of the draft before it")
+	project := fs.String("project", "", "convert the CapCut project of this name, as shown in CapCut, from the drafts folder instead of the draft in file-path.txt")
+	root := fs.String("root", defaultDraftsRoot(), "CapCut drafts folder searched by --project")
+	appendOffset := fs.String("append-offset", "", "shift the cues added by --append by this much time, e.g. 12m30s (default: the end of the file's last cue)")
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *chapterTrack < 0 {
+		return fmt.Errorf("--chapters-track must be a text track number")
+	}
+	opts.chapterTrack = *chapterTrack
+	opts.skippedReport = *skippedReport
+	if opts.chapterTrack > 0 && len(opts.Tracks) > 0 && !slices.Contains(opts.Tracks, opts.chapterTrack) {
+		opts.Tracks = append(opts.Tracks, opts.chapterTrack)
+	}
+	if opts.MaxMemory, err = parseByteSize(*maxMemory); err != nil {
+		return err
+	}
+	if opts.MaxMemory > 0 && opts.Writer != nil {
+		return fmt.Errorf("--max-memory cannot be combined with --template or ass output")
+	}
+	if opts.MaxMemory > 0 && (opts.splitEvery > 0 || opts.romanizer != nil || opts.chapterTrack > 0 || opts.styleGuide != nil || opts.verify || opts.spellChecker != nil || opts.lang != "" || opts.splitTracks || opts.vttNotes || opts.vttStyle || opts.speakerColors || opts.debugCues || opts.Sentences || opts.Punctuator != nil || opts.skippedReport) {
+		return fmt.Errorf("--max-memory cannot be combined with --split-every, --romanize, --chapters-track, --style-guide, --verify, --spellcheck, --lang, --split-tracks, --vtt-notes, --vtt-style, --speaker-colors, --debug-cues, --sentences, --punctuate or --skipped-report")
+	}
+
+	*output, *outputDir, *cachePath, *resumePath = expandPath(*output), expandPath(*outputDir), expandPath(*cachePath), expandPath(*resumePath)
+	if *output != "" && *outputDir != "" {
+		return fmt.Errorf("-o and --output-dir cannot be combined")
+	}
+	if *cachePath != "" && storage.IsURL(*output) {
+		return fmt.Errorf("--cache needs a local output file")
+	}
+	if *appendPath != "" {
+		if *output, err = appendTarget(ctx, expandPath(*appendPath), *appendOffset, *output, &opts); err != nil {
+			return err
+		}
+		if opts.MaxMemory > 0 || *cachePath != "" || *outputDir != "" {
+			return fmt.Errorf("--append cannot be combined with --max-memory, --cache or --output-dir")
+		}
+	} else if *appendOffset != "" {
+		return fmt.Errorf("--append-offset needs --append")
+	}
+	if *continueNumbering {
+		if opts.Format != "srt" {
+			return fmt.Errorf("--continue-numbering needs srt output, not %s", opts.Format)
+		}
+		if opts.MaxMemory > 0 || *cachePath != "" || opts.splitEvery > 0 || opts.Writer != nil {
+			return fmt.Errorf("--continue-numbering cannot be combined with --max-memory, --cache, --split-every or --template")
+		}
+	}
+
+	job := convertJob{output: *output, outputDir: *outputDir, cachePath: *cachePath, resumePath: *resumePath, webhook: *webhook, continueNumbering: *continueNumbering, opts: opts}
+	var paths []string
+	if *project != "" {
+		var draft string
+		if *root, err = draftsRoot(*root); err == nil {
+			draft, err = findProject(*root, *project)
+		}
+		paths = []string{draft}
+	} else {
+		paths, err = draftPaths()
+	}
+	if err != nil {
+		return job.report(ctx, "", convert.Report{}, nil, err)
+	}
+	if len(paths) > 1 {
+		if *appendPath != "" {
+			return fmt.Errorf("--append adds one draft but file-path.txt lists %d", len(paths))
+		}
+		if *output != "" {
+			return fmt.Errorf("-o names one output but file-path.txt lists %d drafts; use --output-dir", len(paths))
+		}
+	}
+	if len(paths) > 1 || job.resumePath != "" {
+		return convertBatch(ctx, paths, job)
+	}
+
+	result := job.convert(ctx, paths[0])
+	switch {
+	case errors.Is(result.err, errUpToDate):
+		fmt.Println(colorize(colorGreen, "Subtitles are up to date"))
+	case result.err != nil:
+		return result.err
+	default:
+		fmt.Println(colorize(colorGreen, "Subtitles created successfully"))
+	}
+	return nil
+}
+
+// appendTarget reads the subtitle file the draft's cues are appended to
+// into opts, with the offset they are shifted by: offset, or the end of
+// the file's last cue if offset is "". It returns the output, which is
+// the file itself unless output names another.
+func appendTarget(ctx context.Context, path, offset, output string, opts *options) (string, error) {
+	cues, format, err := loadCues(ctx, path, convert.Options{})
+	if err != nil {
+		return "", err
+	}
+	if format == "capcut" {
+		return "", fmt.Errorf("--append needs a subtitle file, not a draft")
+	}
+	if offset == "" {
+		for _, c := range cues {
+			opts.appendOffset = max(opts.appendOffset, c.End)
+		}
+	} else {
+		d, err := time.ParseDuration(offset)
+		if err != nil {
+			return "", fmt.Errorf("invalid --append-offset: %w", err)
+		}
+		opts.appendOffset = d.Microseconds()
+	}
+	opts.appended = cues
+	if output != "" {
+		return output, nil
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); !strings.EqualFold(ext, opts.Format) {
+		return "", fmt.Errorf("--append %s would be rewritten as %s; pass --format %s or -o", path, opts.Format, strings.ToLower(ext))
+	}
+	return path, nil
+}
+
+// convertJob holds the runConvert flags applied to each draft.
+type convertJob struct {
+	output, outputDir, cachePath, webhook string
+	// resumePath is the --resume state file of a batch, or "".
+	resumePath string
+	// continueNumbering numbers each draft's cues on from the drafts
+	// converted before it.
+	continueNumbering bool
+	opts              options
+}
+
+// convert converts the draft listed at path in file-path.txt and posts
+// the webhook report.
+func (j convertJob) convert(ctx context.Context, path string) batchResult {
+	result := batchResult{input: path}
+	since := warningCount.Load()
+	draft, err := resolveDraft(path)
+	var report convert.Report
+	if err == nil {
+		name := j.output
+		if name == "" {
+			name, err = defaultOutput(draft, j.outputDir, j.opts.Format)
+		}
+		if err == nil {
+			report, result.outputs, err = convertDraft(ctx, draft, name, j.cachePath, j.opts)
+		}
+	}
+	result.cues, result.warnings = report.Cues, warningCount.Load()-since
+	if err == nil {
+		// Fail before the webhook so it reports the failure.
+		err = strictFailure(since)
+	}
+	result.err = j.report(ctx, draft, report, result.outputs, err)
+	return result
+}
+
+// report posts the webhook report of a conversion that ended with err,
+// if a webhook is set, and returns err or, if only the webhook failed,
+// its error.
+func (j convertJob) report(ctx context.Context, draft string, report convert.Report, outputs []string, err error) error {
+	if j.webhook == "" {
+		return err
+	}
+	if hookErr := postWebhook(ctx, j.webhook, newRunReport(draft, report, outputs, err)); hookErr != nil {
+		if err == nil {
+			return hookErr
+		}
+		printWarning(hookErr)
+	}
+	return err
+}
+
+// errUpToDate reports a conversion skipped because the cache holds its
+// unchanged result.
+var errUpToDate = errors.New("subtitles are up to date")
+
+// convertDraft converts the draft at path into name, consulting and
+// updating the conversion cache at cachePath if one is given.
+func convertDraft(ctx context.Context, path, name, cachePath string, opts options) (convert.Report, []string, error) {
+	since := warningCount.Load()
+	var state *cache.Cache
+	var hash string
+	var err error
+	if cachePath != "" {
+		if state, err = cache.Open(cachePath); err != nil {
+			return convert.Report{}, nil, err
+		}
+		if hash, err = cache.Hash(append([]string{path}, opts.configFiles...), opts.args...); err != nil {
+			return convert.Report{}, nil, err
+		}
+		if state.Fresh(path, hash) {
+			return convert.Report{}, nil, errUpToDate
+		}
+	}
+
+	var report convert.Report
+	var outputs []string
+	if opts.MaxMemory > 0 {
+		report, outputs, err = convertSpilled(ctx, path, name, opts)
+	} else {
+		report, outputs, err = convertInMemory(ctx, path, name, opts)
+	}
+	if err != nil {
+		return report, nil, err
+	}
+	// A run failed by --strict is not cached, so the next one fails again.
+	if state != nil && strictFailure(since) == nil {
+		if err := state.Store(path, hash, outputs); err != nil {
+			return report, outputs, err
+		}
+		if err := state.Save(); err != nil {
+			return report, outputs, err
+		}
+	}
+	return report, outputs, nil
+}
+
+// draftPaths returns the drafts listed in file-path.txt, one per line and
+// each expanded by expandPath. Blank lines and lines starting with # are
+// skipped.
+func draftPaths() ([]string, error) {
+	text, err := readTextFile("file-path.txt")
+	if err != nil {
+		return nil, fmt.Errorf("reading file path: %w", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(text, "\n") {
+		if path := expandPath(line); path != "" && !strings.HasPrefix(path, "#") {
+			paths = append(paths, path)
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("empty file path")
+	}
+	return paths, nil
+}
+
+// draftPath returns the draft named in file-path.txt, resolved by
+// resolveDraft, for commands converting a single draft.
+func draftPath() (string, error) {
+	paths, err := draftPaths()
+	if err != nil {
+		return "", err
+	}
+	if len(paths) > 1 {
+		return "", fmt.Errorf("file-path.txt lists %d drafts; name the input to use", len(paths))
+	}
+	return resolveDraft(paths[0])
+}
+
+// draftArgument returns the draft named in args, a draft file or project
+// folder, or the one in file-path.txt if args is empty.
+func draftArgument(args []string) (string, error) {
+	if len(args) == 1 {
+		return resolveDraft(expandPath(args[0]))
+	}
+	return draftPath()
+}
+
+// defaultOutput returns where the subtitles of the draft at draftPath go
+// without -o: under the project's name in dir, created if needed, or next
+// to the draft, so a double-clicked binary does not write wherever it was
+// started from.
+func defaultOutput(draftPath, dir, format string) (string, error) {
+	if dir == "" {
+		dir = filepath.Dir(draftPath)
+	} else if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return filepath.Join(dir, defaultOutputName(draftPath, format)), nil
+}
+
+// defaultOutputName names the output after the draft's project, so
+// converting the same project again gives the same file.
+func defaultOutputName(draftPath, format string) string {
+	return fileName(capcut.ProjectName(draftPath), "subtitles") + "." + format
+}
+
+// fileName makes name safe to use as a file name on every platform,
+// replacing characters Windows rejects, or returns fallback if nothing
+// usable is left.
+func fileName(name, fallback string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < ' ' || strings.ContainsRune(`<>:"/\|?*`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	// Windows drops trailing dots and spaces.
+	name = strings.TrimRight(strings.TrimSpace(name), ". ")
+	if name == "" || strings.Trim(name, "_") == "" {
+		return fallback
+	}
+	base, _, _ := strings.Cut(name, ".")
+	switch strings.ToUpper(base) {
+	case "CON", "PRN", "AUX", "NUL",
+		"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+		"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9":
+		return "_" + name
+	}
+	return name
+}
+
+// convertInMemory converts the draft at path with every cue held in memory,
+// which allows splitting and romanized copies.
+func convertInMemory(ctx context.Context, path, name string, opts options) (convert.Report, []string, error) {
+	draft, err := capcut.ReadDraft(path)
+	if err != nil {
+		return convert.Report{}, nil, fmt.Errorf("reading draft: %w", err)
+	}
+
+	var skipped []convert.Skip
+	if opts.skippedReport {
+		opts.Skipped = func(s convert.Skip) { skipped = append(skipped, s) }
+	}
+	cues, warnings, err := convert.CuesContext(ctx, draft, opts.Options)
+	if err != nil {
+		return convert.Report{}, nil, err
+	}
+	printWarnings(warnings)
+
+	var markers []subtitle.Cue
+	if opts.chapterTrack > 0 {
+		cues, markers = splitChapterTrack(cues, opts.chapterTrack)
+	}
+	report := convert.Report{Cues: len(cues), Warnings: warnings}
+	opts.source = sourceName(path)
+	if opts.vttStyle {
+		opts.cueStyle = draftCueStyle(draft)
+	}
+	if opts.appended != nil || opts.appendOffset != 0 {
+		cues = subtitle.Merge(opts.appended, 0, cues, opts.appendOffset)
+	}
+	if opts.chapterTrack > 0 {
+		if err := writeChapters(chaptersPath(name), markers, opts); err != nil {
+			return report, nil, err
+		}
+	}
+	written, err := writeResult(ctx, name, cues, opts)
+	if err != nil {
+		return report, nil, err
+	}
+	if opts.chapterTrack > 0 {
+		written = append(written, chaptersPath(name))
+	}
+	if opts.skippedReport {
+		if err := writeSkipped(skippedPath(name), skipped, opts); err != nil {
+			return report, written, err
+		}
+		written = append(written, skippedPath(name))
+	}
+	return report, written, nil
+}
+
+// skippedSegment is a segment left out of the subtitles as the
+// --skipped-report file lists it, counting segments from 1 as warnings do.
+type skippedSegment struct {
+	Track    int    `json:"track"`
+	Segment  int    `json:"segment"`
+	Material string `json:"material"`
+	Text     string `json:"text"`
+	Reason   string `json:"reason"`
+}
+
+// skippedPath is the --skipped-report file written alongside the subtitles
+// at name: movie.skipped.json for movie.srt, in the working directory when
+// they are uploaded.
+func skippedPath(name string) string {
+	if storage.IsURL(name) {
+		name = path.Base(name)
+	}
+	return strings.TrimSuffix(name, filepath.Ext(name)) + ".skipped.json"
+}
+
+// writeSkipped writes the skipped segments to name in track and segment
+// order.
+func writeSkipped(name string, skipped []convert.Skip, opts options) error {
+	segments := make([]skippedSegment, len(skipped))
+	for i, s := range skipped {
+		segments[i] = skippedSegment{
+			Track:    s.Source.TrackNumber,
+			Segment:  s.Source.Segment + 1,
+			Material: s.Source.MaterialID,
+			Text:     s.Text,
+			Reason:   string(s.Reason),
+		}
+	}
+	slices.SortStableFunc(segments, func(a, b skippedSegment) int {
+		return cmp.Or(cmp.Compare(a.Track, b.Track), cmp.Compare(a.Segment, b.Segment))
+	})
+	data, err := json.MarshalIndent(segments, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := backupOutput(name, opts); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, append(data, '\n'), 0644); err != nil {
+		return &writers.WriteError{Path: name, Err: err}
+	}
+	return nil
+}
+
+// chaptersPath is the chapter list written alongside the subtitles at
+// name: next to them, or in the working directory when they are uploaded.
+func chaptersPath(name string) string {
+	if storage.IsURL(name) {
+		return "chapters.txt"
+	}
+	return filepath.Join(filepath.Dir(name), "chapters.txt")
+}
+
+// splitChapterTrack separates the cues of the chapter marker track from the
+// subtitles.
+func splitChapterTrack(cues []subtitle.Cue, track int) (subtitles, markers []subtitle.Cue) {
+	for _, c := range cues {
+		if c.Source.TrackNumber == track {
+			markers = append(markers, c)
+		} else {
+			subtitles = append(subtitles, c)
+		}
+	}
+	return subtitles, markers
+}
+
+// writeChapters writes the markers to name as a YouTube chapter list,
+// warning about anything that would stop YouTube from showing it.
+func writeChapters(name string, markers []subtitle.Cue, opts options) error {
+	chapters := subtitle.Chapters(markers)
+	if len(chapters) == 0 {
+		return fmt.Errorf("text track %d has no chapter markers", opts.chapterTrack)
+	}
+	for _, problem := range subtitle.CheckYouTubeChapters(chapters) {
+		printWarning(problem)
+	}
+
+	var buf bytes.Buffer
+	if err := writers.WriteYouTubeChapters(&buf, chapters); err != nil {
+		return err
+	}
+	if err := backupOutput(name, opts); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return &writers.WriteError{Path: name, Err: err}
+	}
+	return nil
+}
+
+// convertSpilled converts the draft at path straight into name, spilling
+// cues to temporary files beyond opts.MaxMemory. A partly written output is
+// removed on failure. Outputs for object stores are written to a temporary
+// file and uploaded from there.
+func convertSpilled(ctx context.Context, path, name string, opts options) (convert.Report, []string, error) {
+	input, err := os.Open(path)
+	if err != nil {
+		return convert.Report{}, nil, fmt.Errorf("reading draft: %w", err)
+	}
+	defer input.Close()
+
+	var output *os.File
+	if storage.IsURL(name) {
+		output, err = os.CreateTemp("", "capcut-subtitle-*."+opts.Format)
+	} else if err = backupOutput(name, opts); err == nil {
+		output, err = os.Create(name)
+	}
+	if err != nil {
+		return convert.Report{}, nil, &writers.WriteError{Path: name, Err: err}
+	}
+	report, err := convert.ConvertContext(ctx, input, output, opts.Options)
+	if err == nil && storage.IsURL(name) {
+		err = (&storage.Client{HTTPClient: opts.httpClient}).Put(ctx, name, output)
+	}
+	if closeErr := output.Close(); err == nil && closeErr != nil {
+		err = &writers.WriteError{Path: name, Err: closeErr}
+	}
+	if err != nil || storage.IsURL(name) {
+		os.Remove(output.Name())
+	}
+	if err != nil {
+		return report, nil, err
+	}
+	printWarnings(report.Warnings)
+	return report, []string{name}, nil
+}
+
+func parseOptions(fs *flag.FlagSet, args []string) (options, error) {
+	glossaryPath := fs.String("glossary", "", "CSV file of term,replacement[,case-sensitive] rules applied to cue text")
+	noClean := fs.Bool("no-clean", false, "write material text as-is without stripping tags, brackets or entities")
+	speakersPath := fs.String("prefix-speaker", "", "CSV file of material ID, track ID or text track number to speaker name; prefixes cues with NAME:")
+	dedup := fs.Bool("dedup", false, "merge cues that repeat the same text over overlapping time ranges")
+	splitEvery := fs.Duration("split-every", 0, "split output into movie.part01.srt, movie.part02.srt, ... for -o movie.srt, covering this much time each (e.g. 10m)")
+	romanize := fs.Bool("romanize", false, "also write a romanized copy (Thai, Japanese kana), e.g. subtitles.romanized.srt")
+	romanizeTable := fs.String("romanize-table", "", "CSV file of character,romanization pairs for other scripts, e.g. hanzi to pinyin; implies --romanize")
+	maxChars := fs.Int("max-chars", 0, "wrap cue text to lines of at most this many characters (0 disables wrapping)")
+	maxLines := fs.Int("max-lines", 0, "split cues that need more than this many lines of --max-chars into consecutive cues, dividing their time (0 allows any number)")
+	shape := fs.String("line-shape", "bottom-heavy", "preferred shape of two-line cues: bottom-heavy, top-heavy or balanced")
+	snapFrames := fs.Bool("snap-frames", false, "snap cue boundaries to frame boundaries (see --fps and --rounding)")
+	fps := fs.Float64("fps", 30, "frame rate used by --snap-frames, e.g. 25 or 29.97")
+	roundingMode := fs.String("rounding", "round", "how --snap-frames rounds to a frame: floor, round or ceil")
+	negative := fs.String("negative", "clamp", "cues before 00:00:00: clamp (write as zero), error (abort) or offset (shift the timeline)")
+	brackets := fs.String("brackets", "strip", "bracket handling: strip (drop [ ] only), remove (drop bracketed text) or keep")
+	emoji := fs.String("emoji", "keep", "emoji in cue text: keep, unicode (turn shortcodes such as :smile: into emoji) or shortcodes (the reverse, for players that cannot draw emoji)")
+	charWidth := fs.String("char-width", "keep", "width of Latin letters, digits and punctuation in cue text: keep, half (turn full-width forms such as ＡＢＣ１２３ into ASCII) or full (the reverse)")
+	granularity := fs.String("granularity", "words", "cue granularity for materials with word timings: words or segments")
+	offset := fs.Duration("offset", 0, "shift every cue by this much time (may be negative)")
+	format := fs.String("format", "srt", "output format: "+strings.Join(writers.Formats(), ", "))
+	pipelinePath := fs.String("pipeline", "", "CSV file of transform stages to run instead of the ones selected by flags")
+	styleGuide := fs.String("style-guide", "", "check the output against a style guide and report violations: netflix (gaps use --fps)")
+	backup := fs.Bool("backup", false, "move each output that would be replaced to <name>.bak first")
+	backupDir := fs.String("backup-dir", "", "move outputs that would be replaced into a timestamped folder in this directory instead; implies --backup")
+	strictFlag := fs.Bool("strict", false, "exit with an error after finishing if any warning was printed")
+	noColorFlag := fs.Bool("no-color", false, "print warnings, errors and results without colors (also set by the NO_COLOR environment variable)")
+	lang := fs.String("lang", "", "declared language of the subtitles, e.g. th or en-US; warns about tracks that look like another language")
+	spellcheck := fs.String("spellcheck", "", "comma-separated dictionary languages, e.g. th,en; reports unknown words in the output")
+	dictPath := fs.String("dict-path", "", "directories holding Hunspell .dic and .aff files, separated like PATH, searched before $DICPATH, /usr/share/hunspell and /usr/share/myspell")
+	verify := fs.Bool("verify", false, "parse each written srt, vtt or json file back and fail if it differs from the converted cues")
+	tracks := fs.String("tracks", "", "comma-separated text track numbers to convert, counted from 1 (default all)")
+	vttNotes := fs.Bool("vtt-notes", false, "start vtt output with a NOTE naming the source project, the converter version and the time of conversion")
+	vttStyle := fs.Bool("vtt-style", false, "add a STYLE block to vtt output approximating the text color, background, outline and font of the draft's captions")
+	speakerColors := fs.Bool("speaker-colors", false, "give each speaker a color of their own in ass and vtt output: the speakers of --prefix-speaker, or else each text track")
+	debugCues := fs.Bool("debug-cues", false, "annotate srt, vtt and ass cues with their text track, segment, material ID and text before cleaning")
+	onlyAutoCaptions := fs.Bool("only-auto-captions", false, "convert only auto captions, leaving out titles, lower thirds and other text added by hand")
+	excludeTitles := fs.Bool("exclude-titles", false, "leave out titles, lower thirds and other text added by hand (materials of type text)")
+	trackName := fs.String("track-name", "", "convert only text tracks whose CapCut name matches this regular expression")
+	excludeTrackName := fs.String("exclude-track-name", "", "leave out text tracks whose CapCut name matches this regular expression")
+	materialType := fs.String("material-type", "", "convert only segments whose material type (subtitle, lyrics or text) matches this regular expression")
+	removeFillers := fs.Bool("remove-fillers", false, "remove filler words such as um, uh and เอ่อ, dropping cues left empty")
+	fillersPath := fs.String("fillers", "", "file of filler words or phrases to remove instead of the default ones, one per line; implies --remove-fillers")
+	sentences := fs.String("sentences", "", "group word cues into a cue for each sentence, for drafts with word timings but no sentence segments, by the rules of this language (e.g. th or en) or of each track's script (auto)")
+	sentenceGap := fs.Duration("sentence-gap", 0, "longest pause within a sentence for --sentences (default: the language's, 700ms or 500ms for Thai, Lao, Khmer and Burmese)")
+	capitalize := fs.Bool("capitalize", false, "capitalize the first letter of each sentence and of cues starting one, without a language model")
+	punctuateFlag := fs.Bool("punctuate", false, "restore punctuation and sentence case with a language model, keeping each cue's words and timing; the API key is read from $PUNCTUATE_API_KEY or $OPENAI_API_KEY")
+	punctuateURL := fs.String("punctuate-url", punctuate.DefaultBaseURL, "base URL of the chat completions API --punctuate calls, e.g. http://localhost:11434/v1 for Ollama")
+	punctuateModel := fs.String("punctuate-model", punctuate.DefaultModel, "model --punctuate asks")
+	punctuateBatch := fs.Int("punctuate-batch", punctuate.DefaultBatchSize, "cues --punctuate sends per request")
+	punctuateCache := fs.String("punctuate-cache", "", "JSON file remembering punctuated batches, so unchanged captions are not sent again")
+	retries := fs.Int("retries", 3, "times a request to --punctuate, YouTube or an object store is sent again after a 429, a 5xx or a network error, with exponential backoff")
+	rateLimit := fs.Float64("rate-limit", 0, "most requests per second sent to --punctuate, YouTube or an object store (default no limit)")
+	grep := fs.String("grep", "", "export only cues whose text matches this regular expression, e.g. (?i)sponsor")
+	excludeMaterialType := fs.String("exclude-material-type", "", "leave out segments whose material type (subtitle, lyrics or text) matches this regular expression")
+	splitTracks := fs.Bool("split-tracks", false, "write each text track to its own file named with its language, e.g. movie.th.srt and movie.en.srt")
+	trackLangs := fs.String("track-langs", "", "languages of the text tracks for --split-tracks, e.g. 1=th,2=en (default: detected from the text)")
+	templatePath := fs.String("template", "", "Go text/template file to render the cues with instead of a built-in format; --format then names the output's extension (default: from the template name, e.g. script.txt.tmpl gives txt)")
+	assPreset := fs.String("ass-preset", "classic", "style of ass output: classic (bottom captions for landscape video) or word-pop (one big bold word at a time, centered, for vertical video)")
+	assFont := fs.String("ass-font", "", "font of ass output (default: the preset's, Arial)")
+	assSize := fs.Int("ass-size", 0, "font size of ass output in pixels of the preset's 1080p frame (default: the preset's)")
+	assColor := fs.String("ass-color", "", "text color of ass output as #RRGGBB (default: the preset's, white)")
+	assOutlineColor := fs.String("ass-outline-color", "", "outline color of ass output as #RRGGBB (default: the preset's, black)")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	if *maxLines < 0 {
+		return options{}, fmt.Errorf("--max-lines must not be negative")
+	}
+	if *maxLines > 0 && *maxChars <= 0 {
+		return options{}, fmt.Errorf("--max-lines needs --max-chars")
+	}
+	if *splitEvery < 0 {
+		return options{}, fmt.Errorf("--split-every must not be negative")
+	}
+	opts := options{splitEvery: splitEvery.Microseconds(), args: args}
+	for _, path := range []string{*glossaryPath, *speakersPath, *pipelinePath, *romanizeTable, *templatePath, *fillersPath} {
+		if path != "" {
+			opts.configFiles = append(opts.configFiles, path)
+		}
+	}
+	opts.NoClean = *noClean
+	opts.Dedup = *dedup
+	if *sentences != "" {
+		opts.Sentences, opts.SentenceLang = true, *sentences
+		if *sentences == "auto" {
+			opts.SentenceLang = ""
+		}
+	}
+	if *sentenceGap < 0 {
+		return options{}, fmt.Errorf("--sentence-gap must not be negative")
+	}
+	if *sentenceGap > 0 && *sentences == "" {
+		return options{}, fmt.Errorf("--sentence-gap needs --sentences")
+	}
+	opts.SentenceGap = sentenceGap.Microseconds()
+	opts.Capitalize = *capitalize
+	opts.MaxChars = *maxChars
+	opts.MaxLines = *maxLines
+	opts.Offset = offset.Microseconds()
+	opts.Format = *format
+	opts.verify = *verify
+	opts.lang = *lang
+	opts.splitTracks = *splitTracks
+	opts.vttNotes = *vttNotes
+	opts.vttStyle = *vttStyle
+	opts.backup = *backup || *backupDir != ""
+	if *backupDir != "" {
+		// One folder per run, so a run's outputs are restored together.
+		opts.backupDir = filepath.Join(*backupDir, time.Now().Format("20060102-150405"))
+	}
+	if *noColorFlag {
+		noColor.Store(true)
+	}
+	if *strictFlag {
+		strict.Store(true)
+	}
+
+	var err error
+	if *templatePath != "" {
+		formatSet := false
+		fs.Visit(func(f *flag.Flag) { formatSet = formatSet || f.Name == "format" })
+		if !formatSet {
+			opts.Format = templateExtension(*templatePath)
+		}
+		if !extension.MatchString(opts.Format) {
+			return options{}, fmt.Errorf("invalid --format %q for --template (want a file extension such as txt)", opts.Format)
+		}
+		data, err := os.ReadFile(*templatePath)
+		if err != nil {
+			return options{}, fmt.Errorf("reading template: %w", err)
+		}
+		if opts.Writer, err = writers.NewTemplate(filepath.Base(*templatePath), string(data)); err != nil {
+			return options{}, err
+		}
+	} else if _, err = writers.Lookup(opts.Format); err != nil {
+		return options{}, err
+	}
+	if opts.Format == "ass" && *templatePath == "" {
+		style, ok := writers.ASSPresets[*assPreset]
+		if !ok {
+			return options{}, fmt.Errorf("unknown --ass-preset %q (want classic or word-pop)", *assPreset)
+		}
+		style.Font = cmp.Or(*assFont, style.Font)
+		style.Size = cmp.Or(*assSize, style.Size)
+		style.Color = cmp.Or(*assColor, style.Color)
+		style.OutlineColor = cmp.Or(*assOutlineColor, style.OutlineColor)
+		style.SpeakerColors = *speakerColors
+		style.DebugCues = *debugCues
+		if err := style.Validate(); err != nil {
+			return options{}, err
+		}
+		opts.Writer = writers.WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return writers.WriteASS(w, *subs, style)
+		})
+	}
+	switch {
+	case *speakerColors && opts.Format == "vtt":
+		opts.speakerColors = true
+	case *speakerColors && (opts.Format != "ass" || *templatePath != ""):
+		return options{}, fmt.Errorf("--speaker-colors needs ass or vtt output, not %s", opts.Format)
+	}
+	switch {
+	case *debugCues && (opts.Format == "srt" || opts.Format == "vtt"):
+		opts.debugCues = true
+	case *debugCues && (opts.Format != "ass" || *templatePath != ""):
+		return options{}, fmt.Errorf("--debug-cues needs srt, vtt or ass output, not %s", opts.Format)
+	}
+	if *debugCues {
+		if opts.verify {
+			return options{}, fmt.Errorf("--debug-cues cannot be combined with --verify")
+		}
+		opts.KeepOriginal = true
+	}
+	if (opts.vttNotes || opts.vttStyle) && opts.Format != "vtt" {
+		return options{}, fmt.Errorf("--vtt-notes and --vtt-style need vtt output, not %s", opts.Format)
+	}
+	if opts.verify && !writers.Verifiable(opts.Format) {
+		return options{}, fmt.Errorf("--verify needs srt, vtt or json output, not %s", opts.Format)
+	}
+	if opts.Granularity, err = capcut.ParseGranularity(*granularity); err != nil {
+		return options{}, err
+	}
+	if opts.Tracks, err = parseTrackList(*tracks); err != nil {
+		return options{}, err
+	}
+	if opts.Filter, err = parseFilter(*trackName, *excludeTrackName, *materialType, *excludeMaterialType); err != nil {
+		return options{}, err
+	}
+	if *grep != "" {
+		if opts.Grep, err = regexp.Compile(*grep); err != nil {
+			return options{}, fmt.Errorf("invalid --grep: %w", err)
+		}
+	}
+	if *onlyAutoCaptions {
+		if opts.Filter.IncludeMaterials != nil {
+			return options{}, fmt.Errorf("--only-auto-captions cannot be combined with --material-type")
+		}
+		opts.Filter.IncludeMaterials = regexp.MustCompile(`^subtitle$`)
+	}
+	if *excludeTitles {
+		if opts.Filter.ExcludeMaterials != nil {
+			return options{}, fmt.Errorf("--exclude-titles cannot be combined with --exclude-material-type")
+		}
+		opts.Filter.ExcludeMaterials = regexp.MustCompile(`^text$`)
+	}
+	if opts.lang != "" && !languageTag.MatchString(opts.lang) {
+		return options{}, fmt.Errorf("invalid --lang %q (want a language tag such as th or en-US)", opts.lang)
+	}
+	if opts.trackLangs, err = parseTrackLangs(*trackLangs); err != nil {
+		return options{}, err
+	}
+	switch {
+	case opts.trackLangs != nil && !opts.splitTracks:
+		return options{}, fmt.Errorf("--track-langs needs --split-tracks")
+	case opts.splitTracks && opts.lang != "":
+		return options{}, fmt.Errorf("--split-tracks cannot be combined with --lang; use --track-langs")
+	case opts.splitTracks && opts.splitEvery > 0:
+		return options{}, fmt.Errorf("--split-tracks cannot be combined with --split-every")
+	}
+	if opts.Brackets, err = subtitle.ParseBracketMode(*brackets); err != nil {
+		return options{}, err
+	}
+	if opts.Emoji, err = subtitle.ParseEmojiMode(*emoji); err != nil {
+		return options{}, err
+	}
+	if opts.Width, err = subtitle.ParseWidthMode(*charWidth); err != nil {
+		return options{}, err
+	}
+	if opts.LineShape, err = subtitle.ParseLineShape(*shape); err != nil {
+		return options{}, err
+	}
+	if opts.Negative, err = subtitle.ParseNegativePolicy(*negative); err != nil {
+		return options{}, err
+	}
+	if *snapFrames {
+		if *fps <= 0 {
+			return options{}, fmt.Errorf("--fps must be positive")
+		}
+		opts.FPS = *fps
+		if opts.Rounding, err = subtitle.ParseRounding(*roundingMode); err != nil {
+			return options{}, err
+		}
+	}
+	if *styleGuide != "" {
+		if *fps <= 0 {
+			return options{}, fmt.Errorf("--fps must be positive")
+		}
+		guide, err := subtitle.ParseStyleGuide(*styleGuide, *fps)
+		if err != nil {
+			return options{}, err
+		}
+		opts.styleGuide = &guide
+	}
+	if *spellcheck != "" {
+		if opts.spellChecker, err = openSpellChecker(*spellcheck, *dictPath); err != nil {
+			return options{}, err
+		}
+	}
+	if *pipelinePath != "" {
+		var stageFiles []string
+		if opts.Pipeline, stageFiles, err = transform.ReadPipelineFiles(*pipelinePath); err != nil {
+			return options{}, fmt.Errorf("reading pipeline: %w", err)
+		}
+		opts.configFiles = append(opts.configFiles, stageFiles...)
+	}
+	if *glossaryPath != "" {
+		if opts.Glossary, err = subtitle.ReadGlossary(*glossaryPath); err != nil {
+			return options{}, fmt.Errorf("reading glossary: %w", err)
+		}
+	}
+	if *retries < 0 || *rateLimit < 0 {
+		return options{}, fmt.Errorf("--retries and --rate-limit cannot be negative")
+	}
+	opts.httpClient = (&retry.Transport{Retries: *retries, Rate: *rateLimit}).Client()
+	if *punctuateFlag {
+		if *punctuateBatch <= 0 {
+			return options{}, fmt.Errorf("--punctuate-batch must be positive")
+		}
+		client := &punctuate.Client{
+			BaseURL: *punctuateURL, Model: *punctuateModel, BatchSize: *punctuateBatch,
+			Token:      cmp.Or(os.Getenv("PUNCTUATE_API_KEY"), os.Getenv("OPENAI_API_KEY")),
+			HTTPClient: opts.httpClient,
+		}
+		if client.Token == "" && client.BaseURL == punctuate.DefaultBaseURL {
+			return options{}, fmt.Errorf("--punctuate needs an API key in $PUNCTUATE_API_KEY or $OPENAI_API_KEY")
+		}
+		if *punctuateCache != "" {
+			if client.Cache, err = punctuate.OpenCache(*punctuateCache); err != nil {
+				return options{}, err
+			}
+		}
+		opts.Punctuator = client
+	}
+	switch {
+	case *fillersPath != "":
+		if opts.Fillers, err = subtitle.ReadFillers(*fillersPath); err != nil {
+			return options{}, err
+		}
+	case *removeFillers:
+		opts.Fillers = subtitle.NewFillers(subtitle.DefaultFillers)
+	}
+	if *speakersPath != "" {
+		if opts.Speakers, err = subtitle.ReadSpeakers(*speakersPath); err != nil {
+			return options{}, fmt.Errorf("reading speaker map: %w", err)
+		}
+	}
+	if *romanize || *romanizeTable != "" {
+		opts.romanizer = &subtitle.Romanizer{}
+		if *romanizeTable != "" {
+			if opts.romanizer.Table, err = subtitle.ReadRomanizeTable(*romanizeTable); err != nil {
+				return options{}, fmt.Errorf("reading romanization table: %w", err)
+			}
+		}
+	}
+	return opts, nil
+}
+
+// printViolations reports the style guide violations found in a file.
+func printViolations(name, guide string, violations []subtitle.Violation) {
+	if len(violations) == 0 {
+		fmt.Printf("Style guide %s: no violations in %s\n", guide, name)
+		return
+	}
+	fmt.Printf("Style guide %s: %d violations in %s\n", guide, len(violations), name)
+	for _, v := range violations {
+		fmt.Println("  " + v.String())
+	}
+}
+
+func printMisspellings(name string, misspellings []spell.Misspelling) {
+	if len(misspellings) == 0 {
+		fmt.Printf("Spell check: no unknown words in %s\n", name)
+		return
+	}
+	fmt.Printf("Spell check: %d unknown words in %s\n", len(misspellings), name)
+	for _, m := range misspellings {
+		fmt.Println("  " + m.String())
+	}
+}
+
+// openSpellChecker loads the dictionaries for a comma-separated list of
+// languages from dictPath, $DICPATH or Hunspell's usual locations.
+func openSpellChecker(languages, dictPath string) (*spell.Checker, error) {
+	dirs := append(filepath.SplitList(dictPath), filepath.SplitList(os.Getenv("DICPATH"))...)
+	dirs = append(dirs, "/usr/share/hunspell", "/usr/share/myspell")
+
+	var dictionaries []*spell.Dictionary
+	for _, lang := range strings.Split(languages, ",") {
+		d, err := spell.Open(dirs, strings.TrimSpace(lang))
+		if err != nil {
+			return nil, err
+		}
+		dictionaries = append(dictionaries, d)
+	}
+	return spell.NewChecker(dictionaries...), nil
+}
+
+// warningCount counts the warnings printed so far, which make the run fail
+// once the command finishes if strict is set by --strict.
+var (
+	warningCount atomic.Int64
+	strict       atomic.Bool
+)
+
+func printWarning(w any) {
+	warningCount.Add(1)
+	fmt.Println(colorize(colorYellow, "Warning:"), w)
+}
+
+// strictError fails the run under --strict if warnings were printed.
+func strictError() error {
+	return strictFailure(0)
+}
+
+// strictFailure fails under --strict if warnings were printed since the
+// warning count was since.
+func strictFailure(since int64) error {
+	if n := warningCount.Load() - since; strict.Load() && n > 0 {
+		return fmt.Errorf("%d warnings with --strict", n)
+	}
+	return nil
+}
+
+func printWarnings(warnings []convert.Warning) {
+	for _, w := range warnings {
+		printWarning(w)
+	}
+}
+
+// parseTrackList parses a comma-separated list of text track numbers.
+func parseTrackList(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var numbers []int
+	for _, field := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("invalid track number %q in --tracks", field)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// languageTag matches BCP 47 language tags such as th, en-US or zh-Hant.
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,8}(-[A-Za-z0-9]{1,8})*$`)
+
+// extension matches the file extensions --template output may be given.
+var extension = regexp.MustCompile(`^[A-Za-z0-9]+$`)
+
+// templateExtension is the extension of the output of the template at
+// path: the one before .tmpl, .tpl or .gotmpl, or else txt.
+func templateExtension(path string) string {
+	name := filepath.Base(path)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".tmpl", ".tpl", ".gotmpl":
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return cmp.Or(strings.TrimPrefix(filepath.Ext(name), "."), "txt")
+}
+
+// parseFilter compiles the track name and material type patterns of the
+// filter flags; an empty pattern is left nil.
+func parseFilter(trackName, excludeTrackName, materialType, excludeMaterialType string) (capcut.Filter, error) {
+	var f capcut.Filter
+	for _, p := range []struct {
+		flag, pattern string
+		re            **regexp.Regexp
+	}{
+		{"track-name", trackName, &f.IncludeTracks},
+		{"exclude-track-name", excludeTrackName, &f.ExcludeTracks},
+		{"material-type", materialType, &f.IncludeMaterials},
+		{"exclude-material-type", excludeMaterialType, &f.ExcludeMaterials},
+	} {
+		if p.pattern == "" {
+			continue
+		}
+		re, err := regexp.Compile(p.pattern)
+		if err != nil {
+			return capcut.Filter{}, fmt.Errorf("invalid --%s: %w", p.flag, err)
+		}
+		*p.re = re
+	}
+	return f, nil
+}
+
+// parseTrackLangs parses a --track-langs list of track=language pairs.
+func parseTrackLangs(s string) (map[int]string, error) {
+	if s == "" {
+		return nil, nil
+	}
+	langs := make(map[int]string)
+	for _, field := range strings.Split(s, ",") {
+		number, lang, _ := strings.Cut(strings.TrimSpace(field), "=")
+		n, err := strconv.Atoi(number)
+		if err != nil || n < 1 || !languageTag.MatchString(lang) {
+			return nil, fmt.Errorf("invalid track language %q in --track-langs (want track=language, e.g. 1=th)", field)
+		}
+		langs[n] = lang
+	}
+	return langs, nil
+}
+
+// parseByteSize parses a size such as 512MB, 2GB or a plain number of
+// bytes. KB, MB and GB are powers of 1024.
+func parseByteSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	number, multiplier := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for i, unit := range []string{"KB", "MB", "GB"} {
+		if trimmed, ok := strings.CutSuffix(number, unit); ok {
+			number, multiplier = strings.TrimSpace(trimmed), int64(1)<<(10*(i+1))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(strings.TrimSuffix(number, "B"), 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q in --max-memory (want e.g. 512MB)", s)
+	}
+	return n * multiplier, nil
+}
+
+// writeResult writes cues to name, or to files named after it with
+// .part01, .part02, ... before the extension when splitting is enabled,
+// and returns the names of the files written. Tracks mixing scripts or not
+// in opts.lang are warned about first.
+func writeResult(ctx context.Context, name string, cues []subtitle.Cue, opts options) ([]string, error) {
+	if opts.splitTracks {
+		return writeTracks(ctx, name, cues, opts)
+	}
+	for _, problem := range subtitle.CheckLanguage(cues, opts.lang) {
+		printWarning(problem)
+	}
+	if opts.splitEvery == 0 {
+		return writeOutput(ctx, name, cues, opts)
+	}
+
+	var written []string
+	for _, part := range subtitle.Split(cues, opts.splitEvery) {
+		names, err := writeOutput(ctx, partName(name, part.Number), part.Cues, opts)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, names...)
+	}
+	return written, nil
+}
+
+// writeTracks writes each text track of cues to its own file, named after
+// name with the track's language before the extension, such as
+// movie.th.srt, and records the language in formats that can. A second
+// track of the same language also gets its number: movie.th.track3.srt.
+func writeTracks(ctx context.Context, name string, cues []subtitle.Cue, opts options) ([]string, error) {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	used := make(map[string]bool)
+	var written []string
+	for _, track := range subtitle.Subtitles(cues).Tracks() {
+		trackOpts := opts
+		trackOpts.splitTracks = false
+		trackOpts.lang = cmp.Or(opts.trackLangs[track.Number], subtitle.DetectLanguage(track.Cues).Code, "und")
+		trackName := base + "." + trackOpts.lang + ext
+		if used[trackOpts.lang] {
+			trackName = fmt.Sprintf("%s.%s.track%d%s", base, trackOpts.lang, track.Number, ext)
+		}
+		used[trackOpts.lang] = true
+		names, err := writeResult(ctx, trackName, track.Cues, trackOpts)
+		if err != nil {
+			return nil, err
+		}
+		written = append(written, names...)
+	}
+	return written, nil
+}
+
+// writeOutput writes cues to name and, when romanization is enabled, a
+// romanized copy next to it.
+func writeOutput(ctx context.Context, name string, cues []subtitle.Cue, opts options) ([]string, error) {
+	if err := writeFile(ctx, name, opts.Format, cues, opts); err != nil {
+		return nil, err
+	}
+	if opts.styleGuide != nil {
+		printViolations(name, opts.styleGuide.Name, opts.styleGuide.Check(cues))
+	}
+	if opts.spellChecker != nil {
+		printMisspellings(name, opts.spellChecker.Check(cues))
+	}
+	if opts.romanizer == nil {
+		return []string{name}, nil
+	}
+
+	romanized := make([]subtitle.Cue, len(cues))
+	for i, c := range cues {
+		c.Text = opts.romanizer.Romanize(c.Text)
+		romanized[i] = c
+	}
+	if err := writeFile(ctx, romanizedName(name), opts.Format, romanized, opts); err != nil {
+		return nil, err
+	}
+	return []string{name, romanizedName(name)}, nil
+}
+
+// writeFile writes cues to the file name, or uploads them when name is an
+// object store URL. With opts.verify, the file is read back from disk, or
+// the upload checked before it is sent, with writers.Verify.
+func writeFile(ctx context.Context, name, format string, cues []subtitle.Cue, opts options) error {
+	if !storage.IsURL(name) {
+		if err := backupOutput(name, opts); err != nil {
+			return err
+		}
+		w, err := opts.writer(format)
+		if err != nil {
+			return err
+		}
+		if err := writers.WriteFileWith(name, w, cues); err != nil {
+			return err
+		}
+		if opts.verify {
+			return verifyFile(name, format, cues)
+		}
+		return nil
+	}
+	w, err := opts.writer(format)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	subs := subtitle.Subtitles(cues)
+	if err := w.Write(&buf, &subs); err != nil {
+		return err
+	}
+	if opts.verify {
+		if err := writers.Verify(bytes.NewReader(buf.Bytes()), format, cues); err != nil {
+			return fmt.Errorf("%s failed verification: %w", name, err)
+		}
+	}
+	return (&storage.Client{HTTPClient: opts.httpClient}).Put(ctx, name, bytes.NewReader(buf.Bytes()))
+}
+
+// writer is the writer for format: opts.Writer for the output format, if
+// the flags configured one, or else the registered writer, recording
+// opts.lang, opts.cueStyle and, with opts.vttNotes, where the file came
+// from.
+func (opts options) writer(format string) (writers.Writer, error) {
+	if opts.Writer != nil && format == opts.Format {
+		return opts.Writer, nil
+	}
+	meta := writers.Metadata{Language: opts.lang, Style: opts.cueStyle, SpeakerColors: opts.speakerColors, DebugCues: opts.debugCues}
+	if opts.vttNotes {
+		meta.Source = opts.source
+		meta.Generator = "capcut-subtitle " + converterVersion()
+		meta.Generated = time.Now()
+	}
+	return writers.LookupMetadata(format, meta)
+}
+
+// draftCueStyle is the WebVTT look of the style most of the draft's text
+// segments are drawn in. Text CapCut draws without a background gets a
+// transparent one rather than the player's usual black box.
+func draftCueStyle(draft capcut.DraftContent) writers.VTTStyle {
+	style, ok := capcut.MainStyle(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts))
+	if !ok {
+		return writers.VTTStyle{}
+	}
+	cueStyle := writers.VTTStyle{
+		Color: style.Color, Background: style.Background, BackgroundAlpha: style.BackgroundAlpha,
+		Outline: style.Border, Font: style.Font,
+	}
+	if cueStyle.Background == "" {
+		cueStyle.Background, cueStyle.BackgroundAlpha = "#000000", 0
+	}
+	return cueStyle
+}
+
+// sourceName names the input at path for --vtt-notes: the project of a
+// draft, or else the file name.
+func sourceName(path string) string {
+	if filepath.Base(path) == "draft_content.json" {
+		return capcut.ProjectName(path)
+	}
+	return filepath.Base(path)
+}
+
+// converterVersion is the module version the binary was built from, or
+// its VCS revision for a build from a checkout.
+func converterVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(unknown)"
+	}
+	if info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" && len(setting.Value) >= 12 {
+			return "(devel) " + setting.Value[:12]
+		}
+	}
+	return "(devel)"
+}
+
+// backupOutput moves an existing output out of the way before it is
+// replaced, if opts asks for backups: to name.bak, or into opts.backupDir.
+func backupOutput(name string, opts options) error {
+	if !opts.backup {
+		return nil
+	}
+	if _, err := os.Stat(name); err != nil {
+		// Nothing to keep; other problems surface when writing.
+		return nil
+	}
+	backup := name + ".bak"
+	if opts.backupDir != "" {
+		if err := os.MkdirAll(opts.backupDir, 0755); err != nil {
+			return fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		backup = filepath.Join(opts.backupDir, filepath.Base(name))
+	}
+	if err := os.Rename(name, backup); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", name, err)
+	}
+	return nil
+}
+
+func verifyFile(name, format string, cues []subtitle.Cue) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+	if err := writers.Verify(file, format, cues); err != nil {
+		return fmt.Errorf("%s failed verification: %w", name, err)
+	}
+	return nil
+}
+
+// partName names the nth part of the output name for --split-every:
+// movie.part01.srt for movie.srt.
+func partName(name string, n int) string {
+	ext := filepath.Ext(name)
+	return fmt.Sprintf("%s.part%02d%s", strings.TrimSuffix(name, ext), n, ext)
+}
+
+func romanizedName(name string) string {
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + ".romanized" + ext
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestRomanizedName(t *testing.T) {
+	if got := romanizedName("part01.srt"); got != "part01.romanized.srt" {
+		t.Errorf("romanizedName() = %v", got)
+	}
+}
+
+func TestPartName(t *testing.T) {
+	tests := map[string]string{
+		filepath.Join("out", "movie.srt"): filepath.Join("out", "movie.part03.srt"),
+		"s3://bucket/subs/ep.1.vtt":       "s3://bucket/subs/ep.1.part03.vtt",
+	}
+	for name, want := range tests {
+		if got := partName(name, 3); got != want {
+			t.Errorf("partName(%q, 3) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestSkippedPath(t *testing.T) {
+	tests := map[string]string{
+		filepath.Join("out", "movie.srt"): filepath.Join("out", "movie.skipped.json"),
+		"s3://bucket/subs/movie.vtt":      "movie.skipped.json",
+	}
+	for name, want := range tests {
+		if got := skippedPath(name); got != want {
+			t.Errorf("skippedPath(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestFileName(t *testing.T) {
+	tests := map[string]string{
+		"My Vlog": "My Vlog",
+		"ทริปเชียงใหม่":     "ทริปเชียงใหม่",
+		`Q&A: "live"/2024?`: "Q&A_ _live__2024_",
+		"trailing dots... ": "trailing dots",
+		"con":               "_con",
+		"aux.final":         "_aux.final",
+		"???":               "subtitles",
+		"":                  "subtitles",
+	}
+	for input, want := range tests {
+		if got := fileName(input, "subtitles"); got != want {
+			t.Errorf("fileName(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDefaultOutput(t *testing.T) {
+	project := filepath.Join(t.TempDir(), "Trip")
+	draft := filepath.Join(project, "draft_content.json")
+	if got, err := defaultOutput(draft, "", "srt"); err != nil || got != filepath.Join(project, "Trip.srt") {
+		t.Errorf("defaultOutput without a directory = %q, %v", got, err)
+	}
+	dir := filepath.Join(t.TempDir(), "exports")
+	if got, err := defaultOutput(draft, dir, "vtt"); err != nil || got != filepath.Join(dir, "Trip.vtt") {
+		t.Errorf("defaultOutput with a directory = %q, %v", got, err)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("output directory not created: %v", err)
+	}
+}
+
+func TestAppendTarget(t *testing.T) {
+	show := filepath.Join(t.TempDir(), "show.srt")
+	os.WriteFile(show, []byte("1\n00:00:01,000 --> 00:00:02,000\nOne\n\n2\n00:00:03,000 --> 00:00:04,500\nTwo\n\n"), 0644)
+
+	tests := []struct {
+		name, offset, output, format string
+		wantOutput                   string
+		wantOffset                   int64
+		wantErr                      bool
+	}{
+		{name: "after the last cue", format: "srt", wantOutput: show, wantOffset: 4500000},
+		{name: "given offset", offset: "10m", format: "srt", wantOutput: show, wantOffset: 600000000},
+		{name: "other output", format: "vtt", output: "all.vtt", wantOutput: "all.vtt", wantOffset: 4500000},
+		{name: "other format", format: "vtt", wantErr: true},
+		{name: "invalid offset", offset: "soon", format: "srt", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := options{}
+			opts.Format = tt.format
+			output, err := appendTarget(context.Background(), show, tt.offset, tt.output, &opts)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("appendTarget() expected error")
+				}
+				return
+			}
+			if err != nil || output != tt.wantOutput || opts.appendOffset != tt.wantOffset || len(opts.appended) != 2 {
+				t.Errorf("appendTarget() = %q, %v with offset %d and %d cues, want %q with offset %d and 2 cues",
+					output, err, opts.appendOffset, len(opts.appended), tt.wantOutput, tt.wantOffset)
+			}
+		})
+	}
+}
+
+func TestWriteTracks(t *testing.T) {
+	dir := t.TempDir()
+	cues := []subtitle.Cue{
+		{Start: 0, End: 1000000, Text: "สวัสดีครับ ยินดีต้อนรับ", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 0, End: 1000000, Text: "Hello and welcome to the show", Source: subtitle.Source{TrackNumber: 2}},
+		{Start: 0, End: 1000000, Text: "Welcome, this is the commentary", Source: subtitle.Source{TrackNumber: 3}},
+		{Start: 0, End: 1000000, Text: "Bonjour", Source: subtitle.Source{TrackNumber: 4}},
+	}
+	opts := options{splitTracks: true, trackLangs: map[int]string{4: "fr-CA"}}
+	opts.Format = "vtt"
+	written, err := writeTracks(context.Background(), filepath.Join(dir, "movie.vtt"), cues, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, name := range written {
+		names = append(names, filepath.Base(name))
+	}
+	if want := []string{"movie.th.vtt", "movie.en.vtt", "movie.en.track3.vtt", "movie.fr-CA.vtt"}; !slices.Equal(names, want) {
+		t.Errorf("writeTracks() wrote %v, want %v", names, want)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "movie.fr-CA.vtt")); !strings.HasPrefix(string(data), "WEBVTT\nLanguage: fr-CA\n\n") {
+		t.Errorf("movie.fr-CA.vtt = %q, want a Language header", data)
+	}
+}
+
+func TestTemplateExtension(t *testing.T) {
+	tests := []struct{ path, want string }{
+		{"templates/inhouse.xml.tmpl", "xml"},
+		{"prompter.txt.TPL", "txt"},
+		{"script.gotmpl", "txt"},
+		{"notes.md", "md"},
+		{"template", "txt"},
+	}
+	for _, tt := range tests {
+		if got := templateExtension(tt.path); got != tt.want {
+			t.Errorf("templateExtension(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestBackupOutput(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "subtitles.srt")
+	if err := backupOutput(name, options{backup: true}); err != nil {
+		t.Fatalf("backupOutput() without an existing output error = %v", err)
+	}
+
+	os.WriteFile(name, []byte("fixed by hand"), 0644)
+	if err := backupOutput(name, options{backup: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(name + ".bak"); err != nil || string(data) != "fixed by hand" {
+		t.Errorf("backup = %q, %v", data, err)
+	}
+
+	os.WriteFile(name, []byte("second"), 0644)
+	backupDir := filepath.Join(dir, "backups", "run")
+	if err := backupOutput(name, options{backup: true, backupDir: backupDir}); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(filepath.Join(backupDir, "subtitles.srt")); err != nil || string(data) != "second" {
+		t.Errorf("backup in directory = %q, %v", data, err)
+	}
+	if _, err := os.Stat(name); err == nil {
+		t.Error("backupOutput() left the output in place")
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "", want: 0},
+		{input: "4096", want: 4096},
+		{input: "64KB", want: 64 << 10},
+		{input: "512mb", want: 512 << 20},
+		{input: "2 GB", want: 2 << 30},
+		{input: "0", wantErr: true},
+		{input: "lots", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseByteSize(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("parseByteSize(%q) = %v, %v, want %v", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestErrorHint(t *testing.T) {
+	err := fmt.Errorf("reading draft: %w", capcut.ErrNotADraft)
+	if hint := errorHint(err, ""); !strings.Contains(hint, "file-path.txt") {
+		t.Errorf("errorHint() = %q, want a file-path.txt hint", hint)
+	}
+	if hint := errorHint(err, "merge"); strings.Contains(hint, "file-path.txt") {
+		t.Errorf("errorHint() for merge = %q, which does not read file-path.txt", hint)
+	}
+}
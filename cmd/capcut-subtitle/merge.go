@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/readers"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func runMerge(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	output := fs.String("o", "merged.srt", "output file")
+	offsetA := fs.Duration("offset-a", 0, "time offset applied to the first input (may be negative)")
+	offsetB := fs.Duration("offset-b", 0, "time offset applied to the second input (may be negative)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle merge [flags] <input> <input>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("merge needs exactly two inputs")
+	}
+
+	a, _, err := loadCues(ctx, fs.Arg(0), convert.Options{})
+	if err != nil {
+		return err
+	}
+	b, _, err := loadCues(ctx, fs.Arg(1), convert.Options{})
+	if err != nil {
+		return err
+	}
+
+	merged := subtitle.Merge(a, offsetA.Microseconds(), b, offsetB.Microseconds())
+	if err := writeFile(ctx, *output, "srt", merged, options{}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Merged %d + %d cues into %s\n", len(a), len(b), *output)
+	return nil
+}
+
+// loadCues reads cues from any input the readers package can detect and
+// returns the detected format. Drafts go through the conversion passes
+// selected in opts; other formats are taken as they are.
+func loadCues(ctx context.Context, filename string, opts convert.Options) ([]subtitle.Cue, string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	format, input, err := readers.Sniff(file)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if format == "capcut" {
+		draft, err := capcut.Decode(input)
+		if err != nil {
+			return nil, "", err
+		}
+		cues, warnings, err := convert.CuesContext(ctx, draft, opts)
+		if err != nil {
+			return nil, "", err
+		}
+		printWarnings(warnings)
+		return cues, format, nil
+	}
+	reader, err := readers.Lookup(format)
+	if err != nil {
+		return nil, "", err
+	}
+	cues, err := reader.Read(input)
+	return cues, format, err
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// subtitleCodecs maps video containers to the subtitle codec ffmpeg stores
+// in them.
+var subtitleCodecs = map[string]string{
+	".mp4":  "mov_text",
+	".m4v":  "mov_text",
+	".mov":  "mov_text",
+	".mkv":  "srt",
+	".webm": "webvtt",
+}
+
+// subtitleCodec returns the subtitle codec for the container of the video
+// named name.
+func subtitleCodec(name string) (string, error) {
+	codec, ok := subtitleCodecs[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		return "", fmt.Errorf("cannot add a subtitle stream to %s (want an .mp4, .m4v, .mov, .mkv or .webm output)", name)
+	}
+	return codec, nil
+}
+
+// runMux adds the subtitles to a copy of an exported video as a subtitle
+// stream players can switch on and off. Video and audio are copied as-is.
+func runMux(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("mux", flag.ExitOnError)
+	video := fs.String("video", "", "exported video to add the subtitles to")
+	output := fs.String("o", "", "output video (default: the video name with .captioned before the extension)")
+	language := fs.String("language", "und", "ISO 639-2 language code of the subtitle stream, e.g. eng or tha")
+	title := fs.String("title", "", "name of the subtitle stream shown by players")
+	ffmpeg := fs.String("ffmpeg", "ffmpeg", "ffmpeg executable")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle mux --video <video> [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *video == "" {
+		fs.Usage()
+		return fmt.Errorf("mux needs --video")
+	}
+	if opts.Format != "srt" && opts.Format != "vtt" {
+		return fmt.Errorf("mux needs srt or vtt subtitles, not %s", opts.Format)
+	}
+
+	name := *output
+	if name == "" {
+		name = outputVideoName(*video, "captioned")
+	}
+	codec, err := subtitleCodec(name)
+	if err != nil {
+		return err
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	dir, subtitles, err := writeTempSubtitles(cues, opts)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	ffmpegArgs := []string{
+		"-i", toolPath(*video),
+		"-i", subtitles,
+		"-map", "0:v", "-map", "0:a?", "-map", "1:0",
+		"-c", "copy", "-c:s", codec,
+		"-metadata:s:s:0", "language=" + *language,
+	}
+	if *title != "" {
+		ffmpegArgs = append(ffmpegArgs, "-metadata:s:s:0", "title="+*title)
+	}
+	if err := runFFmpegOutput(ctx, *ffmpeg, name, ffmpegArgs...); err != nil {
+		return err
+	}
+
+	fmt.Printf("Added %d cues to %s\n", len(cues), name)
+	return nil
+}
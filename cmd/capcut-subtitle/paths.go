@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// envReference matches $NAME, ${NAME} and the %NAME% of Windows.
+var envReference = regexp.MustCompile(`\$\{(\w+)\}|\$(\w+)|%(\w+)%`)
+
+// readTextFile reads a small text file as Notepad may have saved it: UTF-8
+// with or without a byte order mark, or UTF-16 in either byte order with
+// or without one.
+func readTextFile(name string) (string, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", err
+	}
+	// Without a byte order mark, UTF-16 shows in text that is mostly ASCII,
+	// like a path, as a zero byte in every other position.
+	if len(data) >= 2 && len(data)%2 == 0 && !bytes.HasPrefix(data, []byte{0xff, 0xfe}) && !bytes.HasPrefix(data, []byte{0xfe, 0xff}) {
+		switch {
+		case data[1] == 0 && data[0] != 0:
+			data = append([]byte{0xff, 0xfe}, data...)
+		case data[0] == 0 && data[1] != 0:
+			data = append([]byte{0xfe, 0xff}, data...)
+		}
+	}
+	text, err := io.ReadAll(subtitle.NewTextReader(bytes.NewReader(data)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(string(text), "\ufeff"), nil
+}
+
+// expandPath cleans up a path typed or pasted by the user: surrounding
+// quotes, including the stray trailing quote cmd.exe leaves after a
+// quoted folder ending in a backslash, are dropped, a leading ~ becomes
+// the home directory and environment variables are expanded. References
+// to unset variables are kept as written, since $ and % are valid in
+// file names.
+func expandPath(path string) string {
+	path = strings.TrimSpace(path)
+	if len(path) >= 2 && (path[0] == '"' || path[0] == '\'') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	}
+	path = strings.TrimSpace(strings.Trim(path, `"`))
+
+	path = envReference.ReplaceAllStringFunc(path, func(ref string) string {
+		m := envReference.FindStringSubmatch(ref)
+		if value, ok := os.LookupEnv(m[1] + m[2] + m[3]); ok {
+			return value
+		}
+		return ref
+	})
+
+	if path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, `~\`) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
+
+// resolveDraft returns the draft file at path: path itself, or the
+// draft_content.json inside it if path is a project folder. Paths that cannot
+// be read are reported in words rather than as raw os errors.
+func resolveDraft(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err == nil && info.IsDir() {
+		draft := filepath.Join(path, "draft_content.json")
+		if _, err := os.Stat(draft); err != nil {
+			return "", fmt.Errorf("%s is a directory without a draft_content.json, not a CapCut project", path)
+		}
+		path = draft
+	}
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		return "", fmt.Errorf("draft %s does not exist", path)
+	case errors.Is(err, fs.ErrPermission):
+		return "", fmt.Errorf("no permission to read draft %s", path)
+	case err != nil:
+		return "", fmt.Errorf("failed to open draft: %w", err)
+	}
+	file, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrPermission):
+		return "", fmt.Errorf("no permission to read draft %s", path)
+	case err != nil:
+		return "", fmt.Errorf("failed to open draft: %w", err)
+	}
+	return path, file.Close()
+}
+
+// draftProjects lists the drafts of the project folders in root, by folder
+// name. Entries without a draft_content.json, such as CapCut's own state
+// files, are left out.
+func draftProjects(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drafts folder: %w", err)
+	}
+	var drafts []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		draft := filepath.Join(root, entry.Name(), "draft_content.json")
+		if _, err := os.Stat(draft); err == nil {
+			drafts = append(drafts, draft)
+		}
+	}
+	return drafts, nil
+}
+
+// defaultDraftsRoot is where CapCut desktop keeps its projects, or "" on
+// systems it does not run on.
+func defaultDraftsRoot() string {
+	switch runtime.GOOS {
+	case "windows":
+		if local := os.Getenv("LOCALAPPDATA"); local != "" {
+			return filepath.Join(local, "CapCut", "User Data", "Projects", "com.lveditor.draft")
+		}
+	case "darwin":
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, "Movies", "CapCut", "User Data", "Projects", "com.lveditor.draft")
+		}
+	}
+	return ""
+}
+
+// draftsRoot returns the drafts folder given by --root, expanded by
+// expandPath.
+func draftsRoot(root string) (string, error) {
+	if root = expandPath(root); root == "" {
+		return "", fmt.Errorf("no default CapCut drafts folder on this system; pass --root")
+	}
+	return root, nil
+}
+
+// findProject returns the draft of the project in root named name, as
+// CapCut shows it, or in a folder of that name. Names are matched without
+// regard to case when no project has the exact name.
+func findProject(root, name string) (string, error) {
+	drafts, err := draftProjects(root)
+	if err != nil {
+		return "", err
+	}
+	var exact, folded []string
+	for _, draft := range drafts {
+		project, folder := capcut.ProjectName(draft), filepath.Base(filepath.Dir(draft))
+		switch {
+		case project == name || folder == name:
+			exact = append(exact, draft)
+		case strings.EqualFold(project, name) || strings.EqualFold(folder, name):
+			folded = append(folded, draft)
+		}
+	}
+	matches := exact
+	if len(matches) == 0 {
+		matches = folded
+	}
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no CapCut project named %q in %s", name, root)
+	case 1:
+		return matches[0], nil
+	}
+	folders := make([]string, len(matches))
+	for i, draft := range matches {
+		folders[i] = filepath.Base(filepath.Dir(draft))
+	}
+	return "", fmt.Errorf("%d CapCut projects are named %q (in folders %s); pass the folder name instead", len(matches), name, strings.Join(folders, ", "))
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package main
+
+// toolPath returns path as an external program should get it; only
+// Windows limits the length of paths.
+func toolPath(path string) string {
+	return path
+}
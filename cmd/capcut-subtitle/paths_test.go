@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf16"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func TestReadTextFile(t *testing.T) {
+	const path = `C:\Users\นก\Projects\ทริป\draft_content.json` + "\r\n"
+	utf16 := func(text string, bigEndian, bom bool) []byte {
+		units := utf16.Encode([]rune(text))
+		if bom {
+			units = append([]uint16{0xfeff}, units...)
+		}
+		var b []byte
+		for _, u := range units {
+			if bigEndian {
+				b = append(b, byte(u>>8), byte(u))
+			} else {
+				b = append(b, byte(u), byte(u>>8))
+			}
+		}
+		return b
+	}
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"utf-8", []byte(path)},
+		{"utf-8 with bom", append([]byte("\ufeff"), path...)},
+		{"utf-16le with bom", utf16(path, false, true)},
+		{"utf-16be with bom", utf16(path, true, true)},
+		{"utf-16le", utf16(path, false, false)},
+		{"utf-16be", utf16(path, true, false)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "file-path.txt")
+			if err := os.WriteFile(name, tt.data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := readTextFile(name); err != nil || got != path {
+				t.Errorf("readTextFile() = %q, %v, want %q", got, err, path)
+			}
+		})
+	}
+}
+
+func TestExpandPath(t *testing.T) {
+	t.Setenv("HOME", "/home/nok")
+	t.Setenv("PROJECTS", "/data/capcut")
+	os.Unsetenv("CAPCUT_UNSET")
+
+	tests := []struct {
+		name, path, want string
+	}{
+		{"plain", "/tmp/draft_content.json", "/tmp/draft_content.json"},
+		{"double quotes", `"/tmp/My Project/draft_content.json"`, "/tmp/My Project/draft_content.json"},
+		{"single quotes", `'/tmp/My Project/draft_content.json'`, "/tmp/My Project/draft_content.json"},
+		{"trailing quote", `C:\Projects\draft_content.json"`, `C:\Projects\draft_content.json`},
+		{"spaces", "  /tmp/draft_content.json \r\n", "/tmp/draft_content.json"},
+		{"home", "~/CapCut/draft_content.json", "/home/nok/CapCut/draft_content.json"},
+		{"tilde in name", "/tmp/~draft.json", "/tmp/~draft.json"},
+		{"dollar", "$PROJECTS/a/draft_content.json", "/data/capcut/a/draft_content.json"},
+		{"braces", "${PROJECTS}/a/draft_content.json", "/data/capcut/a/draft_content.json"},
+		{"percent", `%PROJECTS%\a\draft_content.json`, `/data/capcut\a\draft_content.json`},
+		{"unset", "/tmp/$CAPCUT_UNSET/%CAPCUT_UNSET%", "/tmp/$CAPCUT_UNSET/%CAPCUT_UNSET%"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expandPath(tt.path); got != tt.want {
+				t.Errorf("expandPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveDraft(t *testing.T) {
+	dir := t.TempDir()
+	project := filepath.Join(dir, "project")
+	draft := filepath.Join(project, "draft_content.json")
+	if err := os.Mkdir(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(draft, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, path, want, wantErr string
+	}{
+		{"file", draft, draft, ""},
+		{"project folder", project, draft, ""},
+		{"missing", filepath.Join(dir, "missing.json"), "", "does not exist"},
+		{"directory", dir, "", "without a draft_content.json"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveDraft(tt.path)
+			if tt.wantErr == "" {
+				if err != nil || got != tt.want {
+					t.Errorf("resolveDraft() = %q, %v, want %q", got, err, tt.want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("resolveDraft() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFindProject(t *testing.T) {
+	root := t.TempDir()
+	for folder, name := range map[string]string{"0A1B": "Holiday vlog", "2C3D": "Recipe", "4E5F": "Recipe", "6A7B": "recipe"} {
+		dir := filepath.Join(root, folder)
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "draft_content.json"), []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "draft_meta_info.json"), []byte(`{"draft_name": "`+name+`"}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name, project, wantFolder, wantErr string
+	}{
+		{"by name", "Holiday vlog", "0A1B", ""},
+		{"any case", "HOLIDAY VLOG", "0A1B", ""},
+		{"exact case first", "recipe", "6A7B", ""},
+		{"by folder", "4E5F", "4E5F", ""},
+		{"ambiguous", "Recipe", "", "in folders 2C3D, 4E5F"},
+		{"missing", "Wedding", "", `no CapCut project named "Wedding"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := findProject(root, tt.project)
+			if tt.wantErr == "" {
+				if want := filepath.Join(root, tt.wantFolder, "draft_content.json"); err != nil || got != want {
+					t.Errorf("findProject() = %q, %v, want %q", got, err, want)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("findProject() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConvertDeepUnicodePath(t *testing.T) {
+	// Past MAX_PATH, in folders named in Thai and Chinese, as CapCut
+	// projects under a long user profile end up.
+	project := t.TempDir()
+	for i := 0; i < 6; i++ {
+		project = filepath.Join(project, "โปรเจกต์ทริปเชียงใหม่ 旅行视频项目")
+	}
+	if err := os.MkdirAll(project, 0755); err != nil {
+		t.Fatal(err)
+	}
+	draft, err := json.Marshal(capcuttest.Generate(1, 8, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, "draft_content.json"), draft, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := resolveDraft(project)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path) < 260 {
+		t.Fatalf("path is only %d bytes long", len(path))
+	}
+	opts, err := parseOptions(flag.NewFlagSet("capcut-subtitle", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	name, err := defaultOutput(path, "", opts.Format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := convertInMemory(context.Background(), path, name, opts); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(project, "โปรเจกต์ทริปเชียงใหม่ 旅行视频项目.srt"); name != want {
+		t.Errorf("output %q, want %q", name, want)
+	}
+	if _, err := os.Stat(name); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// maxShortPath is the longest path Windows programs that are not long-path
+// aware can open: MAX_PATH less room for an 8.3 file name.
+const maxShortPath = 248
+
+// toolPath returns path as an external program should get it. The os
+// package handles long paths itself, but ffmpeg or whisper builds that
+// are not long-path aware fail on project folders nested past MAX_PATH,
+// so long paths are passed absolute with the \\?\ prefix that lifts the
+// limit.
+func toolPath(path string) string {
+	if strings.HasPrefix(path, `\\?\`) || strings.HasPrefix(path, `\\.\`) {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil || len(abs) < maxShortPath {
+		return path
+	}
+	if rest, ok := strings.CutPrefix(abs, `\\`); ok {
+		return `\\?\UNC\` + rest
+	}
+	return `\\?\` + abs
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToolPath(t *testing.T) {
+	deep := `C:\Users\นก\AppData\Local\CapCut\User Data\Projects\com.lveditor.draft\` + strings.Repeat(`โปรเจกต์ทริปเชียงใหม่\`, 8) + "final.mp4"
+	share := `\\nas\videos\` + strings.Repeat(`ทริป\`, 30) + "final.mp4"
+	tests := []struct {
+		name, path, want string
+	}{
+		{"short", `C:\Videos\final.mp4`, `C:\Videos\final.mp4`},
+		{"long", deep, `\\?\` + deep},
+		{"long share", share, `\\?\UNC\` + share[2:]},
+		{"prefixed", `\\?\` + deep, `\\?\` + deep},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := toolPath(tt.path); got != tt.want {
+				t.Errorf("toolPath(%q) = %q, want %q", tt.path, got, tt.want)
+			}
+		})
+	}
+}
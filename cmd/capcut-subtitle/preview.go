@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// previewScale is how many pixels of the preview's timeline a second
+// takes.
+const previewScale = 40
+
+// previewPage draws the cues on a timeline, one lane for each cue that
+// overlaps an earlier one, over the video if there is one, and lists them.
+// With a video, the playhead follows it and clicking a cue seeks to it.
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}} – preview</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 1.5em; color: #222; }
+video { display: block; max-width: 100%; max-height: 60vh; background: #000; }
+.scroll { overflow-x: auto; border: 1px solid #ccc; margin: 1em 0; }
+.timeline { position: relative; height: {{.Height}}px; }
+.tick { position: absolute; top: 0; bottom: 0; border-left: 1px solid #eee; font-size: 11px; color: #999; padding-left: 2px; }
+.cue { position: absolute; height: 34px; box-sizing: border-box; overflow: hidden; white-space: nowrap; text-overflow: ellipsis;
+  font-size: 12px; padding: 2px 4px; background: #dbe9ff; border: 1px solid #7aa7f0; border-radius: 3px; cursor: pointer; }
+.cue.active, tr.active { background: #ffe08a; }
+.playhead { position: absolute; top: 0; bottom: 0; width: 0; border-left: 2px solid #d33; }
+table { border-collapse: collapse; width: 100%; font-size: 14px; }
+td, th { text-align: left; padding: 3px 8px; border-bottom: 1px solid #eee; vertical-align: top; }
+td.time { font-family: ui-monospace, monospace; white-space: nowrap; }
+td.text { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<p>{{len .Cues}} cues, {{.Duration}}</p>
+{{if .Video}}<video controls preload="metadata" src="/video"><track kind="subtitles" label="Preview" src="/subtitles.vtt" default></video>{{end}}
+<div class="scroll"><div class="timeline" style="width: {{.Width}}px">
+{{range .Ticks}}<div class="tick" style="left: {{.Left}}px">{{.Label}}</div>
+{{end}}{{range .Cues}}<div class="cue" data-start="{{.StartSeconds}}" data-end="{{.EndSeconds}}" style="left: {{.Left}}px; width: {{.Width}}px; top: {{.Top}}px" title="{{.Number}}: {{.Text}}">{{.Text}}</div>
+{{end}}<div class="playhead" id="playhead"></div>
+</div></div>
+<table>
+<tr><th>#</th><th>Start</th><th>End</th><th>Text</th></tr>
+{{range .Cues}}<tr data-start="{{.StartSeconds}}" data-end="{{.EndSeconds}}"><td>{{.Number}}</td><td class="time">{{.Start}}</td><td class="time">{{.End}}</td><td class="text">{{.Text}}</td></tr>
+{{end}}</table>
+<script>
+const scale = {{.Scale}};
+const video = document.querySelector("video");
+const playhead = document.getElementById("playhead");
+const cues = document.querySelectorAll("[data-start]");
+for (const cue of cues) {
+  cue.addEventListener("click", () => {
+    if (video) { video.currentTime = Number(cue.dataset.start); video.play(); }
+  });
+}
+if (video) {
+  video.addEventListener("timeupdate", () => {
+    const t = video.currentTime;
+    playhead.style.left = (t * scale) + "px";
+    for (const cue of cues) {
+      cue.classList.toggle("active", t >= Number(cue.dataset.start) && t < Number(cue.dataset.end));
+    }
+  });
+}
+</script>
+</body>
+</html>
+`))
+
+// previewData is what previewPage renders.
+type previewData struct {
+	Title, Duration string
+	Video           bool
+	Scale           int
+	Width, Height   int
+	Ticks           []previewTick
+	Cues            []previewCue
+}
+
+// previewTick marks a time on the timeline.
+type previewTick struct {
+	Left  int
+	Label string
+}
+
+// previewCue is a cue as the preview draws it.
+type previewCue struct {
+	Number           int
+	Start, End, Text string
+	StartSeconds     float64
+	EndSeconds       float64
+	Left, Width, Top int
+}
+
+// runPreview serves a temporary web page showing the cues of the input,
+// or the draft in file-path.txt, on a timeline, and over a video if one is
+// given, to look the result over before delivery. It runs until
+// interrupted.
+func runPreview(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("preview", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:0", "address to listen on (default: a free port on this machine)")
+	video := fs.String("video", "", "video to play the cues over")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle preview [--video final.mp4] [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	if *video != "" {
+		*video = expandPath(*video)
+		if _, err := os.Stat(*video); err != nil {
+			return fmt.Errorf("reading video: %w", err)
+		}
+	}
+	title := "subtitles"
+	if fs.NArg() == 1 {
+		title = filepath.Base(fs.Arg(0))
+	} else if path, err := draftPath(); err == nil {
+		title = fileName(capcut.ProjectName(path), title)
+	}
+	handler, err := previewHandler(title, cues, *video)
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	server := &http.Server{Handler: handler}
+	errs := make(chan error, 1)
+	go func() {
+		errs <- server.Serve(listener)
+	}()
+	fmt.Printf("Previewing %d cues of %s on http://%s; press Ctrl+C to stop\n", len(cues), title, listener.Addr())
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	server.Shutdown(shutdown)
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// previewHandler serves the preview of cues:
+//
+//	GET /               the page
+//	GET /subtitles.vtt  the cues as WebVTT, for the video's track
+//	GET /video          the video, if there is one
+func previewHandler(title string, cues []subtitle.Cue, video string) (http.Handler, error) {
+	var page, vtt bytes.Buffer
+	if err := previewPage.Execute(&page, newPreviewData(title, cues, video != "")); err != nil {
+		return nil, fmt.Errorf("failed to render preview: %w", err)
+	}
+	if err := writers.WriteVTT(&vtt, cues); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(page.Bytes())
+	})
+	mux.HandleFunc("GET /subtitles.vtt", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/vtt; charset=utf-8")
+		w.Write(vtt.Bytes())
+	})
+	if video != "" {
+		mux.HandleFunc("GET /video", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, video)
+		})
+	}
+	return mux, nil
+}
+
+// newPreviewData lays cues out on the timeline, putting each in the
+// first lane free when it starts.
+func newPreviewData(title string, cues []subtitle.Cue, video bool) previewData {
+	const laneHeight = 40
+	data := previewData{Title: title, Video: video, Scale: previewScale, Cues: make([]previewCue, len(cues))}
+	var laneEnds []int64
+	var end int64
+	for i, c := range cues {
+		lane := 0
+		for lane < len(laneEnds) && laneEnds[lane] > c.Start {
+			lane++
+		}
+		if lane == len(laneEnds) {
+			laneEnds = append(laneEnds, 0)
+		}
+		laneEnds[lane] = c.End
+		end = max(end, c.End)
+		data.Cues[i] = previewCue{
+			Number: i + 1, Start: writers.FormatTime(c.Start), End: writers.FormatTime(c.End),
+			Text:         c.Text,
+			StartSeconds: float64(c.Start) / 1e6, EndSeconds: float64(c.End) / 1e6,
+			Left: pixels(c.Start), Width: max(pixels(c.End-c.Start), 2), Top: 20 + lane*laneHeight,
+		}
+	}
+	data.Duration = (time.Duration(end) * time.Microsecond).Round(time.Second).String()
+	data.Width = pixels(end) + 100
+	data.Height = 20 + max(len(laneEnds), 1)*laneHeight
+	for s := int64(0); s <= end/1e6; s += 10 {
+		data.Ticks = append(data.Ticks, previewTick{Left: int(s) * previewScale, Label: strings.TrimSuffix(writers.FormatTime(s*1e6), ",000")})
+	}
+	return data
+}
+
+// pixels is the width on the timeline of microseconds.
+func pixels(microseconds int64) int {
+	return int(microseconds * previewScale / 1e6)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 0, End: 2_000_000, Text: "Tom & <Jerry>"},
+		{Start: 1_000_000, End: 3_000_000, Text: "Overlapping"},
+		{Start: 2_500_000, End: 4_000_000, Text: "Back in the first lane"},
+	}
+	data := newPreviewData("clip.srt", cues, false)
+	if tops := []int{data.Cues[0].Top, data.Cues[1].Top, data.Cues[2].Top}; tops[0] != tops[2] || tops[1] == tops[0] {
+		t.Errorf("cue tops = %v, want the overlapping cue alone in the second lane", tops)
+	}
+	if data.Cues[1].Left != 40 || data.Cues[1].Width != 80 {
+		t.Errorf("second cue at %dpx, %dpx wide; want 40px, 80px", data.Cues[1].Left, data.Cues[1].Width)
+	}
+
+	video := filepath.Join(t.TempDir(), "final.mp4")
+	if err := os.WriteFile(video, []byte("not really a video"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		video, path string
+		status      int
+		contains    string
+	}{
+		{path: "/", status: http.StatusOK, contains: "Tom &amp; &lt;Jerry&gt;"},
+		{path: "/subtitles.vtt", status: http.StatusOK, contains: "WEBVTT"},
+		{path: "/video", status: http.StatusNotFound},
+		{video: video, path: "/", status: http.StatusOK, contains: `<video controls`},
+		{video: video, path: "/video", status: http.StatusOK, contains: "not really a video"},
+	}
+	for _, tt := range tests {
+		handler, err := previewHandler("clip.srt", cues, tt.video)
+		if err != nil {
+			t.Fatal(err)
+		}
+		server := httptest.NewServer(handler)
+		resp, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		server.Close()
+		if resp.StatusCode != tt.status || !strings.Contains(string(body), tt.contains) {
+			t.Errorf("GET %s (video %q) = %d %q, want %d containing %q", tt.path, tt.video, resp.StatusCode, body, tt.status, tt.contains)
+		}
+	}
+}
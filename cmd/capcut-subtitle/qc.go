@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// qcOrders are the --sort keys of the qc command, each putting the cues
+// most likely to need attention first.
+var qcOrders = map[string]func(a, b subtitle.CueQuality) bool{
+	"cue":      func(a, b subtitle.CueQuality) bool { return a.Cue < b.Cue },
+	"cps":      func(a, b subtitle.CueQuality) bool { return a.CPS > b.CPS },
+	"wpm":      func(a, b subtitle.CueQuality) bool { return a.WPM > b.WPM },
+	"duration": func(a, b subtitle.CueQuality) bool { return a.Duration < b.Duration },
+	"line":     func(a, b subtitle.CueQuality) bool { return a.LongestLine > b.LongestLine },
+	"gap": func(a, b subtitle.CueQuality) bool {
+		if a.Gap == nil || b.Gap == nil {
+			return b.Gap == nil && a.Gap != nil
+		}
+		return *a.Gap < *b.Gap
+	},
+}
+
+// runQC prints readability metrics for each cue and for the whole file,
+// so reviewers can find the problem cues before publishing.
+func runQC(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("qc", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the full report as JSON")
+	order := fs.String("sort", "cps", "order of the cue table: cue, cps, wpm, duration, line or gap")
+	top := fs.Int("top", 20, "number of cues in the table, 0 for all")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle qc [--json] [--sort cps] [--top 20] [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	less, ok := qcOrders[*order]
+	if !ok {
+		return fmt.Errorf("unknown --sort %q (want cue, cps, wpm, duration, line or gap)", *order)
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	report := subtitle.Quality(cues)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+	return writeQCReport(os.Stdout, report, less, *top)
+}
+
+func writeQCReport(w io.Writer, report subtitle.QualityReport, less func(a, b subtitle.CueQuality) bool, top int) error {
+	sum := report.Summary
+	fmt.Fprintf(w, "%d cues, %s on screen\n", sum.Cues, qcDuration(sum.Duration))
+	if sum.Cues == 0 {
+		return nil
+	}
+	fmt.Fprintf(w, "Reading speed: mean %.1f CPS, max %.1f CPS; mean %.0f WPM, max %.0f WPM\n", sum.MeanCPS, sum.MaxCPS, sum.MeanWPM, sum.MaxWPM)
+	fmt.Fprintf(w, "Durations: mean %s, min %s, max %s\n", qcDuration(sum.MeanDuration), qcDuration(sum.MinDuration), qcDuration(sum.MaxDuration))
+	fmt.Fprintf(w, "Lines: up to %d, longest %d characters\n", sum.MaxLines, sum.LongestLine)
+	minGap := "none"
+	if sum.MinGap != nil {
+		minGap = qcDuration(*sum.MinGap)
+	}
+	fmt.Fprintf(w, "Gaps: shortest %s, %d overlaps\n", minGap, sum.Overlaps)
+
+	cues := append([]subtitle.CueQuality(nil), report.Cues...)
+	sort.SliceStable(cues, func(i, j int) bool { return less(cues[i], cues[j]) })
+	if top > 0 && top < len(cues) {
+		cues = cues[:top]
+	}
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "cue\tstart\tduration\tCPS\tWPM\tlines\tlongest\tgap\t")
+	for _, q := range cues {
+		gap := "-"
+		if q.Gap != nil {
+			gap = qcDuration(*q.Gap)
+			if q.Overlaps() {
+				gap = "overlap " + qcDuration(-*q.Gap)
+			}
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.1f\t%.0f\t%d\t%d\t%s\t\n",
+			q.Cue, writers.FormatTime(q.Start), qcDuration(q.Duration),
+			q.CPS, q.WPM, q.Lines, q.LongestLine, gap)
+	}
+	return tw.Flush()
+}
+
+// qcDuration formats microseconds to the millisecond.
+func qcDuration(microseconds int64) string {
+	return (time.Duration(microseconds) * time.Microsecond).Round(time.Millisecond).String()
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestWriteQCReport(t *testing.T) {
+	report := subtitle.Quality([]subtitle.Cue{
+		{Start: 0, End: 2000000, Text: "Hello there"},
+		{Start: 1500000, End: 2000000, Text: "Fast"},
+	})
+
+	var buf bytes.Buffer
+	if err := writeQCReport(&buf, report, qcOrders["cps"], 1); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `2 cues, 2.5s on screen
+Reading speed: mean 6.0 CPS, max 8.0 CPS; mean 72 WPM, max 120 WPM
+Durations: mean 1.25s, min 500ms, max 2s
+Lines: up to 1, longest 11 characters
+Gaps: shortest none, 1 overlaps
+
+  cue         start  duration  CPS  WPM  lines  longest  gap
+    2  00:00:01,500     500ms  8.0  120      1        4    -
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeQCReport() = \n%v\nwant\n%v", got, want)
+	}
+}
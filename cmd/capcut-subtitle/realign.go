@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// runRealign corrects cue timings that drifted from the final video by
+// matching the cues against a transcript of it, produced on the spot by a
+// local whisper.cpp or given as a file.
+func runRealign(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("realign", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the input name, or subtitles, with .realigned and the output format's extension)")
+	transcript := fs.String("transcript", "", "transcript of the final video (Whisper JSON, SRT or WebVTT) to align to instead of running whisper")
+	media := fs.String("media", "", "final video or audio to transcribe with whisper.cpp")
+	model := fs.String("model", "", "whisper.cpp model file, e.g. models/ggml-base.bin")
+	whisper := fs.String("whisper", "whisper-cli", "whisper.cpp executable")
+	ffmpeg := fs.String("ffmpeg", "ffmpeg", "ffmpeg executable, used to extract the audio for whisper")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle realign (--media <video> --model <model> | --transcript <file>) [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *transcript == "" && (*media == "" || *model == "") {
+		fs.Usage()
+		return fmt.Errorf("realign needs --media and --model, or --transcript")
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+
+	referencePath := *transcript
+	if referencePath == "" {
+		dir, err := os.MkdirTemp("", "capcut-subtitle-*")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %w", err)
+		}
+		defer os.RemoveAll(dir)
+
+		audio := filepath.Join(dir, "audio.wav")
+		if err := runFFmpeg(ctx, *ffmpeg, "-i", toolPath(*media), "-vn", "-ar", "16000", "-ac", "1", "-c:a", "pcm_s16le", audio); err != nil {
+			return err
+		}
+		base := filepath.Join(dir, "transcript")
+		if err := runTool(ctx, "whisper", *whisper, "-m", toolPath(*model), "-f", audio, "-oj", "-of", base); err != nil {
+			return err
+		}
+		referencePath = base + ".json"
+	}
+	reference, _, err := loadCues(ctx, referencePath, opts.Options)
+	if err != nil {
+		return err
+	}
+
+	realigned, matched := subtitle.Realign(cues, reference)
+	if matched == 0 {
+		return fmt.Errorf("no cue matches the transcript; check that it is of the same video and language")
+	}
+
+	name := *output
+	if name == "" {
+		input := "subtitles"
+		if fs.NArg() > 0 {
+			input = strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0)))
+		}
+		name = input + ".realigned." + opts.Format
+	}
+	if fs.NArg() > 0 {
+		opts.source = sourceName(fs.Arg(0))
+	}
+	written, err := writeResult(ctx, name, realigned, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Realigned %d of %d cues to the transcript, wrote %s\n", matched, len(cues), strings.Join(written, ", "))
+	return nil
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"capcut-subtitle/pkg/cache"
+)
+
+// errDoneEarlier reports an input a resumed batch leaves out, as the run
+// it resumes finished it.
+var errDoneEarlier = errors.New("done in an earlier run")
+
+// progress is the --resume state file of a batch, recording the outcome
+// of each input as the batch goes so a run stopped by a crash or an
+// interrupt can be picked up where it left off.
+type progress struct {
+	path string
+	// Settings hashes the options of the batch and the files they name;
+	// progress recorded with other settings is discarded.
+	Settings string                   `json:"settings"`
+	Inputs   map[string]inputProgress `json:"inputs"`
+}
+
+// inputProgress is the outcome of one input, with what continuing the
+// batch needs of it.
+type inputProgress struct {
+	Status  string   `json:"status"`
+	Cues    int      `json:"cues"`
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// openProgress loads the state file at path of a batch run with opts. A
+// missing file, or one recorded with other settings, starts afresh.
+func openProgress(path string, opts options) (*progress, error) {
+	settings, err := cache.Hash(opts.configFiles, opts.args...)
+	if err != nil {
+		return nil, err
+	}
+	p := &progress{path: path, Settings: settings, Inputs: make(map[string]inputProgress)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read progress: %w", err)
+	}
+	var saved progress
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("failed to parse progress %s: %w", path, err)
+	}
+	if saved.Settings != settings {
+		printWarning(fmt.Sprintf("%s was recorded with other options; starting the batch over", path))
+		return p, nil
+	}
+	if saved.Inputs != nil {
+		p.Inputs = saved.Inputs
+	}
+	return p, nil
+}
+
+// result returns the result of input recorded by an earlier run, with
+// errDoneEarlier, and whether that run finished it. A nil progress has
+// no results.
+func (p *progress) result(input string) (batchResult, bool) {
+	if p == nil {
+		return batchResult{}, false
+	}
+	recorded, ok := p.Inputs[input]
+	switch {
+	case !ok:
+		return batchResult{}, false
+	case recorded.Status == "converted", recorded.Status == "up to date", recorded.Status == "skipped":
+		return batchResult{input: input, cues: recorded.Cues, outputs: recorded.Outputs, err: errDoneEarlier}, true
+	}
+	return batchResult{}, false
+}
+
+// record stores the result of an input and saves the state file. A nil
+// progress records nothing.
+func (p *progress) record(r batchResult) error {
+	if p == nil {
+		return nil
+	}
+	p.Inputs[r.input] = inputProgress{Status: r.status(), Cues: r.cues, Outputs: r.outputs}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	temp, err := os.CreateTemp(filepath.Dir(p.path), ".progress-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	if err := os.Rename(temp.Name(), p.path); err != nil {
+		return fmt.Errorf("failed to write progress: %w", err)
+	}
+	return nil
+}
+
+// finish ends a batch that finished with err: once every input is done,
+// the state file is removed, so the next run of the batch starts over.
+func (p *progress) finish(err error) error {
+	if p == nil || err != nil {
+		return err
+	}
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove progress: %w", err)
+	}
+	return nil
+}
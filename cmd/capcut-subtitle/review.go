@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// reviewColumns are the columns of a review sheet. Reviewers fill in or
+// correct target; import finds the columns by name, so they may be
+// reordered.
+var reviewColumns = []string{"number", "start", "end", "source", "target", "material"}
+
+// utf8BOM starts review sheets so spreadsheet applications read them as
+// UTF-8.
+const utf8BOM = "\ufeff"
+
+// runReview round-trips subtitles through a spreadsheet for human review:
+// export writes a sheet of source and target text with their times, and
+// import merges the corrected target column back into subtitles or the
+// draft.
+func runReview(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runReviewExport(ctx, args[1:])
+		case "import":
+			return runReviewImport(ctx, args[1:])
+		}
+	}
+	return fmt.Errorf("usage: capcut-subtitle review export [flags] [input], or review import [flags] <review.csv> [draft]")
+}
+
+// runReviewExport writes a review sheet of the cues of the input, or the
+// draft in file-path.txt, with the target column empty or holding the cue
+// of a translation each overlaps longest.
+func runReviewExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("review export", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the input name, or the project's, with the .review.csv extension)")
+	targetPath := fs.String("target", "", "subtitles or draft to fill the target column from, such as a translation (default: leave it empty)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle review export [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	targets := make([]string, len(cues))
+	if *targetPath != "" {
+		target, err := transformedCues(ctx, expandPath(*targetPath), opts)
+		if err != nil {
+			return err
+		}
+		for i, p := range subtitle.Pairs(cues, target) {
+			targets[i] = p.Target.Text
+		}
+	}
+
+	name := *output
+	if name == "" {
+		if name, err = namedAfterInput(fs.Args(), "review.csv"); err != nil {
+			return err
+		}
+	}
+	var buf bytes.Buffer
+	if err := writeReviewSheet(&buf, cues, targets); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return &writers.WriteError{Path: name, Err: err}
+	}
+	fmt.Printf("Wrote %d cues for review to %s\n", len(cues), name)
+	return nil
+}
+
+// writeReviewSheet writes cues as rows of a review sheet, with targets
+// in the target column.
+func writeReviewSheet(w io.Writer, cues []subtitle.Cue, targets []string) error {
+	io.WriteString(w, utf8BOM)
+	cw := csv.NewWriter(w)
+	cw.Write(reviewColumns)
+	for i, c := range cues {
+		cw.Write([]string{strconv.Itoa(i + 1), writers.FormatTime(c.Start), writers.FormatTime(c.End), c.Text, targets[i], c.Source.MaterialID})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// reviewRow is a row of a review sheet.
+type reviewRow struct {
+	line             int
+	cue              subtitle.Cue
+	target, material string
+}
+
+// readReviewSheet reads the rows of a review sheet, which needs the start,
+// end, source and target columns; material may be missing.
+func readReviewSheet(r io.Reader) ([]reviewRow, error) {
+	reader := bufio.NewReader(r)
+	if head, err := reader.Peek(len(utf8BOM)); err == nil && string(head) == utf8BOM {
+		reader.Discard(len(utf8BOM))
+	}
+	cr := csv.NewReader(reader)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read review sheet: %w", err)
+	}
+	column := make(map[string]int)
+	for i, name := range header {
+		column[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"start", "end", "source", "target"} {
+		if _, ok := column[name]; !ok {
+			return nil, fmt.Errorf("review sheet has no %s column", name)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := column[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var rows []reviewRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read review sheet: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		row := reviewRow{line: line, target: field(record, "target"), material: field(record, "material")}
+		row.cue.Text = field(record, "source")
+		if row.cue.Start, err = subtitle.ParseTimestamp(strings.TrimSpace(field(record, "start"))); err != nil {
+			return nil, fmt.Errorf("line %d of the review sheet: %w", line, err)
+		}
+		if row.cue.End, err = subtitle.ParseTimestamp(strings.TrimSpace(field(record, "end"))); err != nil {
+			return nil, fmt.Errorf("line %d of the review sheet: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// runReviewImport merges the target column of a review sheet back: into
+// subtitles at the times of its rows, keeping the source text of rows
+// without a target, or with --into-draft into the text materials of the
+// draft the sheet was exported from.
+func runReviewImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("review import", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the sheet's name with .reviewed and the output format's extension)")
+	intoDraft := fs.Bool("into-draft", false, "write the targets into the text materials of the draft, given after the sheet or in file-path.txt, keeping a backup, instead of writing subtitles")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle review import [flags] <review.csv> [draft]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() < 1 || fs.NArg() > 2 || (fs.NArg() == 2 && !*intoDraft) {
+		fs.Usage()
+		return fmt.Errorf("review import needs a review sheet, and a draft only with --into-draft")
+	}
+	file, err := os.Open(expandPath(fs.Arg(0)))
+	if err != nil {
+		return fmt.Errorf("reading review sheet: %w", err)
+	}
+	rows, err := readReviewSheet(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	if *intoDraft {
+		return importReviewIntoDraft(rows, fs.Args()[1:], opts)
+	}
+	cues := make([]subtitle.Cue, len(rows))
+	corrected := 0
+	for i, row := range rows {
+		cues[i] = row.cue
+		if row.target != "" {
+			cues[i].Text = row.target
+			corrected++
+		}
+	}
+	name := *output
+	if name == "" {
+		base := strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0)))
+		name = strings.TrimSuffix(base, ".review") + ".reviewed." + opts.Format
+	}
+	written, err := writeResult(ctx, name, cues, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d of %d reviewed cues; wrote %s\n", corrected, len(cues), strings.Join(written, ", "))
+	return nil
+}
+
+// importReviewIntoDraft sets the text of the material of every row with
+// a target to it, in the draft in args or file-path.txt.
+func importReviewIntoDraft(rows []reviewRow, args []string, opts options) error {
+	texts := make(map[string]string)
+	lines := make(map[string]int)
+	for _, row := range rows {
+		if row.target == "" {
+			continue
+		}
+		if row.material == "" {
+			return fmt.Errorf("line %d of the review sheet has no material to write its target into; export the sheet from the draft", row.line)
+		}
+		if line, ok := lines[row.material]; ok {
+			return fmt.Errorf("lines %d and %d of the review sheet are both of material %s; export the sheet with --granularity segments to merge it into the draft", line, row.line, row.material)
+		}
+		texts[row.material], lines[row.material] = row.target, row.line
+	}
+
+	path, err := draftArgument(args)
+	if err != nil {
+		return err
+	}
+	changed, err := setDraftTexts(path, texts, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Merged %d reviewed cues; changed %d text materials of %s\n", len(texts), changed, path)
+	return nil
+}
+
+// setDraftTexts replaces the text of the materials of the draft at path
+// in texts, keyed by material ID, moving the draft as it was to
+// <draft>.bak, or into the --backup-dir, first. The draft is untouched
+// when no text changes.
+func setDraftTexts(path string, texts map[string]string, opts options) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open draft: %w", err)
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open draft: %w", err)
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), ".draft-*.tmp")
+	if err != nil {
+		return 0, &writers.WriteError{Path: path, Err: err}
+	}
+	defer os.Remove(temp.Name())
+	w := bufio.NewWriter(temp)
+	changed, err := capcut.SetTexts(file, w, texts)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	file.Close()
+	if changed == 0 {
+		return 0, nil
+	}
+
+	if err := os.Chmod(temp.Name(), info.Mode().Perm()); err != nil {
+		return 0, &writers.WriteError{Path: path, Err: err}
+	}
+	opts.backup = true
+	if err := backupOutput(path, opts); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return 0, &writers.WriteError{Path: path, Err: err}
+	}
+	return changed, nil
+}
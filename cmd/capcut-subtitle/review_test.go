@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+)
+
+func TestReviewRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	draft := filepath.Join(dir, "draft_content.json")
+	content := `{"materials": {"texts": [{"id": "m1", "content": "Helo"}, {"id": "m2", "content": "world, \"again\""}]},
+ "tracks": [{"type": "text", "segments": [
+  {"material_id": "m1", "target_timerange": {"start": 0, "duration": 1000000}},
+  {"material_id": "m2", "target_timerange": {"start": 1000000, "duration": 1500000}}]}]}`
+	if err := os.WriteFile(draft, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sheet := filepath.Join(dir, "clip.review.csv")
+	ctx := context.Background()
+	if err := runReviewExport(ctx, []string{"-o", sheet, draft}); err != nil {
+		t.Fatal(err)
+	}
+	exported, err := os.ReadFile(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := utf8BOM + "number,start,end,source,target,material\n1,\"00:00:00,000\",\"00:00:01,000\",Helo,,m1\n2,\"00:00:01,000\",\"00:00:02,500\",\"world, \"\"again\"\"\",,m2\n"
+	if string(exported) != want {
+		t.Fatalf("exported:\n%q\nwant:\n%q", exported, want)
+	}
+
+	// The reviewer corrects the first cue only.
+	reviewed := strings.Replace(string(exported), "Helo,,m1", "Helo,Hello,m1", 1)
+	if err := os.WriteFile(sheet, []byte(reviewed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runReviewImport(ctx, []string{sheet}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "clip.reviewed.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "1\n00:00:00,000 --> 00:00:01,000\nHello\n\n2\n00:00:01,000 --> 00:00:02,500\nworld, \"again\"\n\n"; !strings.Contains(string(got), want) {
+		t.Errorf("imported:\n%s\nwant:\n%s", got, want)
+	}
+
+	if err := runReviewImport(ctx, []string{"--into-draft", sheet, dir}); err != nil {
+		t.Fatal(err)
+	}
+	merged, err := capcut.ReadDraft(draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if texts := merged.Materials.Texts; texts[0].Content != "Hello" || texts[1].Content != `world, "again"` {
+		t.Errorf("draft texts after import = %+v", texts)
+	}
+	if backup, err := os.ReadFile(draft + ".bak"); err != nil || string(backup) != content {
+		t.Errorf("backup = %q, %v; want the draft as it was", backup, err)
+	}
+}
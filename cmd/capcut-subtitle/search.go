@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// runSearch prints the cues matching a regular expression, with their
+// times and the project they are in, across any number of drafts and
+// subtitle files.
+func runSearch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	ignoreCase := fs.Bool("i", false, "match without regard to case")
+	all := fs.Bool("all", false, "search every project in the CapCut drafts folder")
+	root := fs.String("root", defaultDraftsRoot(), "CapCut drafts folder searched by --all")
+	since := fs.Duration("since", 0, "with --all, search only projects changed within this long, e.g. 720h for the last 30 days")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle search [-i] [--all [--since 720h]] [flags] <pattern> [input ...]")
+		fs.PrintDefaults()
+	}
+	// Whole segments rather than single words, so phrases can match.
+	opts, err := parseOptions(fs, append([]string{"-granularity", "segments"}, args...))
+	if err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("search needs a pattern")
+	}
+	pattern := fs.Arg(0)
+	if *ignoreCase {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern: %w", err)
+	}
+	if *since != 0 && !*all {
+		return fmt.Errorf("--since needs --all")
+	}
+
+	inputs := fs.Args()[1:]
+	switch {
+	case *all && len(inputs) > 0:
+		return fmt.Errorf("--all cannot be combined with inputs")
+	case *all:
+		if inputs, err = recentProjects(*root, *since); err != nil {
+			return err
+		}
+	case len(inputs) == 0:
+		if inputs, err = draftPaths(); err != nil {
+			return err
+		}
+	}
+
+	matches, matched := 0, 0
+	for _, input := range inputs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		path, err := resolveDraft(expandPath(input))
+		var cues []subtitle.Cue
+		if err == nil {
+			cues, err = transformedCues(ctx, path, opts)
+		}
+		if err != nil {
+			printWarning(fmt.Sprintf("%s: %v", input, err))
+			continue
+		}
+		if n := writeMatches(os.Stdout, sourceName(path), cues, re); n > 0 {
+			matches += n
+			matched++
+		}
+	}
+	fmt.Printf("%d matching cues in %d of %d inputs\n", matches, matched, len(inputs))
+	return nil
+}
+
+// recentProjects lists the drafts in the drafts folder root changed
+// within since, or all of them if since is 0.
+func recentProjects(root string, since time.Duration) ([]string, error) {
+	root, err := draftsRoot(root)
+	if err != nil {
+		return nil, err
+	}
+	drafts, err := draftProjects(root)
+	if err != nil {
+		return nil, err
+	}
+	if since <= 0 {
+		return drafts, nil
+	}
+	var recent []string
+	for _, draft := range drafts {
+		if info, err := os.Stat(draft); err == nil && time.Since(info.ModTime()) <= since {
+			recent = append(recent, draft)
+		}
+	}
+	return recent, nil
+}
+
+// writeMatches writes the cues whose text matches re, one per line after
+// the name of their source, and returns how many there were. Line breaks
+// are matched and written as spaces.
+func writeMatches(w io.Writer, source string, cues []subtitle.Cue, re *regexp.Regexp) int {
+	n := 0
+	for _, c := range cues {
+		text := strings.Join(strings.Fields(c.Text), " ")
+		if !re.MatchString(text) {
+			continue
+		}
+		text = re.ReplaceAllStringFunc(text, func(match string) string { return colorize(colorYellow, match) })
+		fmt.Fprintf(w, "%s  %s --> %s  %s\n", source, writers.FormatTime(c.Start), writers.FormatTime(c.End), text)
+		n++
+	}
+	return n
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestWriteMatches(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 0, End: 1500000, Text: "Subscribe to\nthe channel"},
+		{Start: 2000000, End: 3000000, Text: "Thanks for watching"},
+		{Start: 61000000, End: 62250000, Text: "New CHANNEL trailer"},
+	}
+
+	tests := []struct {
+		name    string
+		pattern string
+		want    string
+		count   int
+	}{
+		{
+			name:    "case sensitive",
+			pattern: "channel",
+			want:    "vlog  00:00:00,000 --> 00:00:01,500  Subscribe to the channel\n",
+			count:   1,
+		},
+		{
+			name:    "case insensitive",
+			pattern: "(?i)channel",
+			want: "vlog  00:00:00,000 --> 00:00:01,500  Subscribe to the channel\n" +
+				"vlog  00:01:01,000 --> 00:01:02,250  New CHANNEL trailer\n",
+			count: 2,
+		},
+		{
+			name:    "across line breaks",
+			pattern: "to the",
+			want:    "vlog  00:00:00,000 --> 00:00:01,500  Subscribe to the channel\n",
+			count:   1,
+		},
+		{
+			name:    "no match",
+			pattern: "giveaway",
+			want:    "",
+			count:   0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			count := writeMatches(&buf, "vlog", cues, regexp.MustCompile(tt.pattern))
+			if got := buf.String(); got != tt.want || count != tt.count {
+				t.Errorf("writeMatches() = %d, %q, want %d, %q", count, got, tt.count, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/jobs"
+	"capcut-subtitle/pkg/metrics"
+)
+
+// jobOptions are the conversion flags a job may set as query parameters.
+// Flags naming files are left out, since those would be read on the
+// server, and so are the ones writing more than one output.
+var jobOptions = []string{
+	"brackets", "capitalize", "char-width", "dedup", "emoji", "exclude-material-type", "exclude-titles", "exclude-track-name",
+	"format", "fps", "granularity", "grep", "line-shape", "material-type", "max-chars", "max-lines",
+	"negative", "no-clean", "offset", "only-auto-captions", "remove-fillers", "rounding", "sentence-gap",
+	"sentences", "snap-frames", "track-name", "tracks",
+}
+
+// runServe runs an HTTP API that queues conversions and converts them in
+// the background, one at a time.
+func runServe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	dir := fs.String("dir", "jobs", "directory storing the queued jobs and their results")
+	maxUpload := fs.String("max-upload", "1GB", "largest draft accepted")
+	webhook := fs.String("webhook", "", "URL to POST a JSON report to after each job")
+	metricsAddr := fs.String("metrics-addr", "", "address to serve Prometheus metrics at /metrics and pprof profiles under /debug/pprof/ on, e.g. localhost:9090 (default off)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	limit, err := parseByteSize(*maxUpload)
+	if err != nil {
+		return err
+	}
+
+	queue, err := jobs.Open(*dir)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Addr: *addr, Handler: jobHandler(queue, limit)}
+
+	var conversions metrics.Metrics
+	errs := make(chan error, 3)
+	serveMetrics(ctx, *metricsAddr, &conversions, errs)
+	go func() {
+		errs <- queue.Run(ctx, reportedJobs(&conversions, *webhook))
+	}()
+	go func() {
+		errs <- server.ListenAndServe()
+	}()
+	fmt.Printf("Serving jobs from %s on http://%s\n", *dir, *addr)
+
+	select {
+	case err = <-errs:
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	server.Shutdown(shutdown)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// serveMetrics serves m and the runtime profiles on addr, if set, in the
+// background until ctx is done, sending the error stopping it early to
+// errs.
+func serveMetrics(ctx context.Context, addr string, m *metrics.Metrics, errs chan<- error) {
+	if addr == "" {
+		return
+	}
+	go func() {
+		if err := metrics.ListenAndServe(ctx, addr, m); err != nil {
+			errs <- err
+		}
+	}()
+	fmt.Printf("Serving metrics on http://%s/metrics\n", addr)
+}
+
+// jobHandler serves the job API:
+//
+//	POST /jobs               queue the draft in the body, with options as query parameters
+//	GET  /jobs/{id}          the job's status
+//	GET  /jobs/{id}/result   the subtitles of a succeeded job
+func jobHandler(queue *jobs.Queue, maxUpload int64) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		args, err := jobArgs(r.URL.Query())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		job, err := queue.Submit(http.MaxBytesReader(w, r.Body, maxUpload), args)
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			http.Error(w, fmt.Sprintf("draft is larger than %d bytes", tooLarge.Limit), http.StatusRequestEntityTooLarge)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Location", "/jobs/"+job.ID)
+		writeJSON(w, http.StatusAccepted, job)
+	})
+	mux.HandleFunc("GET /jobs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Get(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+	mux.HandleFunc("GET /jobs/{id}/result", func(w http.ResponseWriter, r *http.Request) {
+		job, err := queue.Get(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if job.Status != jobs.Succeeded {
+			http.Error(w, fmt.Sprintf("job is %s", job.Status), http.StatusConflict)
+			return
+		}
+		result, err := queue.Result(job.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer result.Close()
+		opts, _ := jobOptionsFor(job)
+		w.Header().Set("Content-Type", contentTypes[opts.Format])
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="subtitles.%s"`, opts.Format))
+		io.Copy(w, result)
+	})
+	return mux
+}
+
+// contentTypes maps output formats to the media type results are served as.
+var contentTypes = map[string]string{
+	"srt":  "application/x-subrip; charset=utf-8",
+	"vtt":  "text/vtt; charset=utf-8",
+	"json": "application/json",
+	"csv":  "text/csv; charset=utf-8",
+	"html": "text/html; charset=utf-8",
+	"smi":  "application/x-sami; charset=utf-8",
+	"itt":  "application/ttml+xml",
+	"ass":  "text/x-ssa; charset=utf-8",
+}
+
+// jobArgs turns query parameters into conversion flags, checking that they
+// parse.
+func jobArgs(query url.Values) ([]string, error) {
+	var args []string
+	for name, values := range query {
+		if !slices.Contains(jobOptions, name) {
+			return nil, fmt.Errorf("unsupported option %q (want one of %s)", name, strings.Join(jobOptions, ", "))
+		}
+		for _, value := range values {
+			args = append(args, "--"+name+"="+value)
+		}
+	}
+	slices.Sort(args)
+	if _, err := jobOptionsFor(jobs.Job{Args: args}); err != nil {
+		return nil, err
+	}
+	return args, nil
+}
+
+// jobOptionsFor parses the conversion flags stored with a job.
+func jobOptionsFor(job jobs.Job) (options, error) {
+	fs := flag.NewFlagSet("job", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return parseOptions(fs, job.Args)
+}
+
+// runJob converts a queued draft.
+func runJob(ctx context.Context, job jobs.Job, input io.Reader, output io.Writer) (jobs.Result, error) {
+	opts, err := jobOptionsFor(job)
+	if err != nil {
+		return jobs.Result{}, err
+	}
+	report, err := convert.ConvertContext(ctx, input, output, opts.Options)
+	result := jobs.Result{Cues: report.Cues}
+	for _, w := range report.Warnings {
+		result.Warnings = append(result.Warnings, w.String())
+	}
+	return result, err
+}
+
+// reportedJobs converts queued drafts with runJob, recording each
+// conversion in m and posting its report to webhook, if set.
+func reportedJobs(m *metrics.Metrics, webhook string) jobs.WorkFunc {
+	return func(ctx context.Context, job jobs.Job, input io.Reader, output io.Writer) (jobs.Result, error) {
+		start := time.Now()
+		result, err := runJob(ctx, job, input, output)
+		if ctx.Err() != nil {
+			return result, err
+		}
+		m.Observe(time.Since(start), err)
+		if webhook != "" {
+			if hookErr := postWebhook(ctx, webhook, jobReport(job, result, err)); hookErr != nil {
+				printWarning(hookErr)
+			}
+		}
+		return result, err
+	}
+}
+
+// jobReport is the --webhook report of a finished job, naming the job and
+// its result by their paths on the server.
+func jobReport(job jobs.Job, result jobs.Result, err error) runReport {
+	var outputs []string
+	if err == nil {
+		outputs = []string{"/jobs/" + job.ID + "/result"}
+	}
+	r := newRunReport("/jobs/"+job.ID, convert.Report{Cues: result.Cues}, outputs, err)
+	if result.Warnings != nil {
+		r.Warnings = result.Warnings
+	}
+	return r
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+	"capcut-subtitle/pkg/jobs"
+	"capcut-subtitle/pkg/metrics"
+)
+
+func TestJobHandler(t *testing.T) {
+	queue, err := jobs.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reports := make(chan runReport, 1)
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report runReport
+		json.NewDecoder(r.Body).Decode(&report)
+		reports <- report
+	}))
+	defer hook.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var conversions metrics.Metrics
+	go queue.Run(ctx, reportedJobs(&conversions, hook.URL))
+	server := httptest.NewServer(jobHandler(queue, 1<<20))
+	defer server.Close()
+
+	draft, err := json.Marshal(capcuttest.Generate(1, 16, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.Post(server.URL+"/jobs?format=vtt&granularity=segments", "application/json", bytes.NewReader(draft))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var job jobs.Job
+	json.NewDecoder(resp.Body).Decode(&job)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusAccepted || resp.Header.Get("Location") != "/jobs/"+job.ID {
+		t.Fatalf("POST /jobs = %s, Location %q", resp.Status, resp.Header.Get("Location"))
+	}
+
+	for !job.Done() {
+		time.Sleep(2 * time.Millisecond)
+		resp, err := http.Get(server.URL + "/jobs/" + job.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(&job)
+		resp.Body.Close()
+	}
+	if job.Status != jobs.Succeeded || job.Cues != 2 {
+		t.Fatalf("job = %+v, want 2 cues converted", job)
+	}
+	if report := <-reports; report.Status != "succeeded" || report.Draft != "/jobs/"+job.ID || report.Cues != 2 {
+		t.Errorf("webhook report = %+v", report)
+	}
+
+	resp, err = http.Get(server.URL + "/jobs/" + job.ID + "/result")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.HasPrefix(string(body), "WEBVTT") || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/vtt") {
+		t.Errorf("result = %s %q", resp.Header.Get("Content-Type"), body)
+	}
+
+	for _, tt := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodPost, "/jobs?glossary=/etc/passwd", http.StatusBadRequest},
+		{http.MethodPost, "/jobs?max-chars=many", http.StatusBadRequest},
+		{http.MethodGet, "/jobs/missing", http.StatusNotFound},
+		{http.MethodGet, "/jobs/missing/result", http.StatusNotFound},
+	} {
+		req, _ := http.NewRequest(tt.method, server.URL+tt.path, strings.NewReader("{}"))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, resp.StatusCode, tt.want)
+		}
+	}
+
+	resp, err = http.Post(server.URL+"/jobs", "application/json", bytes.NewReader(make([]byte, 2<<20)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Errorf("POST of an oversized draft = %d, want 413", resp.StatusCode)
+	}
+}
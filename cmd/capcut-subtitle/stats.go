@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// runStats prints what a transcript says and when: speaking time, word
+// counts, words per minute over time, the longest silences and the most
+// frequent words and phrases.
+func runStats(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	window := fs.Duration("window", time.Minute, "length of the periods words per minute are counted over")
+	top := fs.Int("top", 10, "number of silences, words and phrases listed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle stats [--json] [--window 1m] [--top 10] [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *window <= 0 {
+		return fmt.Errorf("--window must be positive")
+	}
+	if *top < 0 {
+		return fmt.Errorf("--top must not be negative")
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	stats := subtitle.Stats(cues, window.Microseconds(), *top)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	return writeStats(os.Stdout, stats)
+}
+
+func writeStats(w io.Writer, stats subtitle.TranscriptStats) error {
+	fmt.Fprintf(w, "%d cues over %s\n", stats.Cues, qcDuration(stats.Duration))
+	if stats.Cues == 0 {
+		return nil
+	}
+	share := 0.0
+	if stats.Duration > 0 {
+		share = 100 * float64(stats.SpeakingTime) / float64(stats.Duration)
+	}
+	fmt.Fprintf(w, "Speaking time: %s (%.0f%%)\n", qcDuration(stats.SpeakingTime), share)
+	fmt.Fprintf(w, "Words: %d, %.0f per minute of speech\n", stats.Words, stats.WPM)
+
+	maxWPM := 0.0
+	for _, window := range stats.Windows {
+		maxWPM = max(maxWPM, window.WPM)
+	}
+	fmt.Fprintln(w, "\nWords per minute:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, window := range stats.Windows {
+		bar := 0
+		if maxWPM > 0 {
+			bar = int(40 * window.WPM / maxWPM)
+		}
+		fmt.Fprintf(tw, "  %s\t%d words\t%.0f WPM\t%s\n", writers.FormatTime(window.Start), window.Words, window.WPM, strings.Repeat("#", bar))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(stats.Silences) > 0 {
+		fmt.Fprintln(w, "\nLongest silences:")
+		for _, s := range stats.Silences {
+			fmt.Fprintf(w, "  %s  %s --> %s\n", qcDuration(s.Duration), writers.FormatTime(s.Start), writers.FormatTime(s.End))
+		}
+	}
+	for _, list := range []struct {
+		title string
+		terms []subtitle.TermCount
+	}{{"Top words", stats.TopWords}, {"Top phrases", stats.TopPhrases}} {
+		if len(list.terms) == 0 {
+			continue
+		}
+		terms := make([]string, len(list.terms))
+		for i, t := range list.terms {
+			terms[i] = fmt.Sprintf("%s (%d)", t.Term, t.Count)
+		}
+		fmt.Fprintf(w, "\n%s: %s\n", list.title, strings.Join(terms, ", "))
+	}
+	return nil
+}
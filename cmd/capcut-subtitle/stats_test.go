@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestWriteStats(t *testing.T) {
+	stats := subtitle.Stats([]subtitle.Cue{
+		{Start: 0, End: 2000000, Text: "Hello there, hello"},
+		{Start: 62000000, End: 63000000, Text: "Hello there"},
+	}, 60000000, 2)
+
+	var buf bytes.Buffer
+	if err := writeStats(&buf, stats); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `2 cues over 1m3s
+Speaking time: 3s (5%)
+Words: 5, 100 per minute of speech
+
+Words per minute:
+  00:00:00,000  3 words  3 WPM  ########################################
+  00:01:00,000  2 words  2 WPM  ##########################
+
+Longest silences:
+  1m0s  00:00:02,000 --> 00:01:02,000
+
+Top words: hello (3), there (2)
+
+Top phrases: hello there (2)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("writeStats() = \n%v\nwant\n%v", got, want)
+	}
+}
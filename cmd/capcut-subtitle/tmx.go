@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/tmx"
+	"capcut-subtitle/pkg/writers"
+)
+
+// runTMX exchanges cue texts with translation tools as TMX translation
+// memories: export builds one from subtitles in two languages, and import
+// pre-translates subtitles from one.
+func runTMX(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runTMXExport(ctx, args[1:])
+		case "import":
+			return runTMXImport(ctx, args[1:])
+		}
+	}
+	return fmt.Errorf("usage: capcut-subtitle tmx export [flags] <source> <target>, or tmx import --memory <file.tmx> --target-lang <lang> [flags] [input]")
+}
+
+// runTMXExport writes a translation memory pairing each cue of the source
+// subtitles with the cue of their translation it overlaps longest.
+func runTMXExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tmx export", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the source name with the .tmx extension)")
+	sourceLang := fs.String("source-lang", "", "language of the source subtitles, e.g. th (default: detected from their script)")
+	targetLang := fs.String("target-lang", "", "language of the translation, e.g. en (default: detected from its script)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle tmx export [flags] <source> <target>")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return fmt.Errorf("tmx export needs a source and a target input")
+	}
+	source, err := transformedCues(ctx, fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	target, err := transformedCues(ctx, fs.Arg(1), opts)
+	if err != nil {
+		return err
+	}
+
+	memory := tmx.Memory{
+		SourceLang: cmp.Or(*sourceLang, subtitle.DetectLanguage(source).Code),
+		TargetLang: cmp.Or(*targetLang, subtitle.DetectLanguage(target).Code),
+	}
+	if memory.SourceLang == "" || memory.TargetLang == "" {
+		return fmt.Errorf("cannot tell the languages of the subtitles; name them with --source-lang and --target-lang")
+	}
+	seen := make(map[tmx.Unit]bool)
+	for _, p := range subtitle.Pairs(source, target) {
+		u := tmx.Unit{Source: subtitle.OneLine(p.Source.Text), Target: subtitle.OneLine(p.Target.Text)}
+		if u.Source == "" || u.Target == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		memory.Units = append(memory.Units, u)
+	}
+
+	name := *output
+	if name == "" {
+		name = strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0))) + ".tmx"
+	}
+	var buf bytes.Buffer
+	if err := tmx.Write(&buf, memory, "capcut-subtitle"); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return &writers.WriteError{Path: name, Err: err}
+	}
+	fmt.Printf("Wrote %d translation units (%s to %s) to %s\n", len(memory.Units), memory.SourceLang, memory.TargetLang, name)
+	return nil
+}
+
+// runTMXImport replaces the text of every cue the translation memory has
+// a translation of, leaving the others for a translator.
+func runTMXImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("tmx import", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the input name, or the project's, with the target language and the output format's extension)")
+	memoryPath := fs.String("memory", "", "TMX translation memory to pre-translate from")
+	sourceLang := fs.String("source-lang", "", "language of the subtitles in the memory (default: the memory's source language)")
+	targetLang := fs.String("target-lang", "", "language to pre-translate into, e.g. en")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle tmx import --memory <file.tmx> --target-lang <lang> [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *memoryPath == "" || *targetLang == "" {
+		fs.Usage()
+		return fmt.Errorf("tmx import needs --memory and --target-lang")
+	}
+	file, err := os.Open(expandPath(*memoryPath))
+	if err != nil {
+		return fmt.Errorf("reading translation memory: %w", err)
+	}
+	memory, err := tmx.Read(file, *sourceLang, *targetLang)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	name := *output
+	if name == "" {
+		if name, err = namedAfterInput(fs.Args(), *targetLang+"."+opts.Format); err != nil {
+			return err
+		}
+	}
+	translations := memory.Translations()
+	translated := 0
+	for i, c := range cues {
+		if text, ok := translations[subtitle.OneLine(c.Text)]; ok {
+			cues[i].Text = text
+			translated++
+		}
+	}
+	written, err := writeResult(ctx, name, cues, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Pre-translated %d of %d cues into %s; wrote %s\n", translated, len(cues), memory.TargetLang, strings.Join(written, ", "))
+	return nil
+}
+
+// namedAfterInput names an output after the input in args, or the draft
+// in file-path.txt without one, with ext in place of the input's
+// extension.
+func namedAfterInput(args []string, ext string) (string, error) {
+	if len(args) == 1 {
+		return strings.TrimSuffix(args[0], filepath.Ext(args[0])) + "." + ext, nil
+	}
+	path, err := draftPath()
+	if err != nil {
+		return "", err
+	}
+	return defaultOutput(path, "", ext)
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTMXRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"th.srt":  "1\n00:00:00,000 --> 00:00:02,000\nสวัสดีครับ\n\n2\n00:00:02,000 --> 00:00:04,000\nไปกันเถอะ\n\n",
+		"en.srt":  "1\n00:00:00,100 --> 00:00:01,900\nHello\n\n2\n00:00:02,000 --> 00:00:04,000\nLet's go\n\n",
+		"new.srt": "1\n00:00:00,000 --> 00:00:01,000\nไปกันเถอะ\n\n2\n00:00:01,000 --> 00:00:02,000\nใหม่\n\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	memory := filepath.Join(dir, "memory.tmx")
+	ctx := context.Background()
+	if err := runTMXExport(ctx, []string{"-o", memory, "--target-lang", "en", filepath.Join(dir, "th.srt"), filepath.Join(dir, "en.srt")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := runTMXImport(ctx, []string{"--memory", memory, "--target-lang", "en", filepath.Join(dir, "new.srt")}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "new.en.srt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The cue the memory has no translation of is left for a translator.
+	if want := "1\n00:00:00,000 --> 00:00:01,000\nLet's go\n\n2\n00:00:01,000 --> 00:00:02,000\nใหม่\n\n"; !strings.Contains(string(got), want) {
+		t.Errorf("pre-translated:\n%s\nwant:\n%s", got, want)
+	}
+}
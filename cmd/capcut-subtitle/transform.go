@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// runTransform applies the conversion flags to an existing subtitle file,
+// or converts a draft given by path instead of through file-path.txt.
+func runTransform(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("transform", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the input name with .transformed and the output format's extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle transform [flags] <input>")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("transform needs exactly one input")
+	}
+
+	input := fs.Arg(0)
+	opts.source = sourceName(input)
+	cues, err := transformedCues(ctx, input, opts)
+	if err != nil {
+		return err
+	}
+
+	name := *output
+	if name == "" {
+		name = strings.TrimSuffix(input, filepath.Ext(input)) + ".transformed." + opts.Format
+	}
+	written, err := writeResult(ctx, name, cues, opts)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Wrote %d cues to %s\n", len(cues), strings.Join(written, ", "))
+	return nil
+}
+
+// transformedCues reads a draft or subtitle file and applies the passes
+// selected in opts.
+func transformedCues(ctx context.Context, input string, opts options) ([]subtitle.Cue, error) {
+	cues, format, err := loadCues(ctx, input, opts.Options)
+	if err != nil {
+		return nil, err
+	}
+	if format != "capcut" {
+		subs := subtitle.Subtitles(cues)
+		warnings, err := convert.Apply(ctx, &subs, opts.Options)
+		if err != nil {
+			return nil, err
+		}
+		printWarnings(warnings)
+		cues = subs
+	}
+	return cues, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+	"capcut-subtitle/pkg/youtube"
+)
+
+// runUpload publishes the subtitles to a video hosting service. YouTube is
+// the only one supported.
+func runUpload(ctx context.Context, args []string) error {
+	if len(args) == 0 || args[0] != "youtube" {
+		return fmt.Errorf("usage: capcut-subtitle upload youtube --video-id <id> [flags] [input]")
+	}
+	return runUploadYouTube(ctx, args[1:])
+}
+
+// runUploadYouTube adds the subtitles to a YouTube video as a caption
+// track, or replaces the track of the same language and name.
+func runUploadYouTube(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("upload youtube", flag.ExitOnError)
+	videoID := fs.String("video-id", "", "ID of the YouTube video, as in youtube.com/watch?v=<id>")
+	language := fs.String("language", "en", "BCP-47 language of the captions, e.g. en or th")
+	name := fs.String("name", "", "name of the caption track shown to viewers")
+	replace := fs.Bool("replace", false, "replace the video's track with the same language and name instead of adding another")
+	draft := fs.Bool("draft", false, "upload the track as a draft, hidden from viewers until published in YouTube Studio")
+	token := fs.String("token", os.Getenv("YOUTUBE_ACCESS_TOKEN"), "OAuth 2.0 access token with the youtube.force-ssl scope (default $YOUTUBE_ACCESS_TOKEN)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle upload youtube --video-id <id> [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *videoID == "" {
+		fs.Usage()
+		return fmt.Errorf("upload youtube needs --video-id")
+	}
+	if *token == "" {
+		return fmt.Errorf("upload youtube needs an access token in --token or YOUTUBE_ACCESS_TOKEN")
+	}
+	if opts.Format != "srt" && opts.Format != "vtt" {
+		return fmt.Errorf("YouTube needs srt or vtt captions, not %s", opts.Format)
+	}
+
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	w, err := writers.Lookup(opts.Format)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	subs := subtitle.Subtitles(cues)
+	if err := w.Write(&body, &subs); err != nil {
+		return err
+	}
+
+	client := &youtube.Client{Token: *token, HTTPClient: opts.httpClient}
+	caption := youtube.Caption{VideoID: *videoID, Language: *language, Name: *name, Draft: *draft}
+	if *replace {
+		existing, err := client.List(ctx, *videoID)
+		if err != nil {
+			return err
+		}
+		for _, c := range existing {
+			if c.Language == caption.Language && c.Name == caption.Name {
+				caption.ID = c.ID
+				break
+			}
+		}
+	}
+
+	if caption.ID != "" {
+		if _, err := client.Update(ctx, caption, body.Bytes()); err != nil {
+			return err
+		}
+		fmt.Printf("Replaced captions %s of video %s with %d cues\n", caption.ID, *videoID, len(cues))
+		return nil
+	}
+	added, err := client.Insert(ctx, caption, body.Bytes())
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Added captions %s to video %s with %d cues\n", added.ID, *videoID, len(cues))
+	return nil
+}
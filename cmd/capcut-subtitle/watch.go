@@ -0,0 +1,253 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/metrics"
+)
+
+// runWatch converts every draft dropped into an inbox directory, the
+// watch-folder workflow of editing teams.
+func runWatch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("watch", flag.ExitOnError)
+	folder := hotFolder{}
+	fs.StringVar(&folder.inbox, "inbox", "inbox", "directory to watch for drafts (.json) and zipped projects (.zip)")
+	fs.StringVar(&folder.outbox, "outbox", "outbox", "directory the subtitles are written to")
+	fs.StringVar(&folder.failed, "error", "error", "directory failed inputs are moved to, each with a .error.txt file giving the reason")
+	fs.StringVar(&folder.processed, "processed", "processed", "directory converted inputs are moved to")
+	fs.StringVar(&folder.webhook, "webhook", "", "URL to POST a JSON report to after each conversion")
+	interval := fs.Duration("interval", 2*time.Second, "how often the inbox is checked")
+	metricsAddr := fs.String("metrics-addr", "", "address to serve Prometheus metrics at /metrics and pprof profiles under /debug/pprof/ on, e.g. localhost:9090 (default off)")
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+	folder.opts = opts
+	for _, dir := range []string{folder.inbox, folder.outbox, folder.failed, folder.processed} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", dir, err)
+		}
+	}
+
+	errs := make(chan error, 1)
+	serveMetrics(ctx, *metricsAddr, &folder.conversions, errs)
+	fmt.Printf("Watching %s for drafts\n", folder.inbox)
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		if err := folder.poll(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			return err
+		case <-ticker.C:
+		}
+	}
+}
+
+// hotFolder holds the directories of the watch command and the files seen
+// in the inbox.
+type hotFolder struct {
+	inbox, outbox, failed, processed string
+	webhook                          string
+	opts                             options
+	// conversions counts the conversions for --metrics-addr.
+	conversions metrics.Metrics
+	// seen records each inbox file's size and modification time at the
+	// last poll. A file is converted once they stop changing, so a copy
+	// still in progress is left alone.
+	seen map[string]fileState
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// poll converts the inbox files that did not change since the last poll.
+// Errors converting a file move it to the error directory; only failures
+// of the directories themselves are returned.
+func (h *hotFolder) poll(ctx context.Context) error {
+	entries, err := os.ReadDir(h.inbox)
+	if err != nil {
+		return fmt.Errorf("failed to read inbox: %w", err)
+	}
+
+	seen := make(map[string]fileState)
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+		if previous, ok := h.seen[entry.Name()]; !ok || previous != state {
+			seen[entry.Name()] = state
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil
+		}
+		if err := h.convert(ctx, entry.Name()); err != nil {
+			return err
+		}
+	}
+	h.seen = seen
+	return nil
+}
+
+// convert converts one inbox file and moves it out of the inbox.
+func (h *hotFolder) convert(ctx context.Context, name string) error {
+	input := filepath.Join(h.inbox, name)
+	ext := filepath.Ext(name)
+	base := h.outputBase(strings.TrimSuffix(name, ext), ext)
+	start := time.Now()
+	report, outputs, err := h.convertFile(ctx, input, base)
+	if ctx.Err() != nil {
+		// Interrupted: leave the file for the next run.
+		return nil
+	}
+	h.conversions.Observe(time.Since(start), err)
+	if h.webhook != "" {
+		if hookErr := postWebhook(ctx, h.webhook, newRunReport(input, report, outputs, err)); hookErr != nil {
+			printWarning(hookErr)
+		}
+	}
+
+	if err != nil {
+		fmt.Printf("%s %s: %v\n", colorize(colorRed, "Failed to convert"), name, err)
+		moved, moveErr := moveInto(input, h.failed)
+		if moveErr != nil {
+			return moveErr
+		}
+		reason := moved + ".error.txt"
+		if err := os.WriteFile(reason, []byte(err.Error()+"\n"), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", reason, err)
+		}
+		return nil
+	}
+	fmt.Printf("%s %s to %s\n", colorize(colorGreen, "Converted"), name, strings.Join(outputs, ", "))
+	if err := os.Rename(input, filepath.Join(h.processed, base+ext)); err != nil {
+		return fmt.Errorf("failed to move %s: %w", input, err)
+	}
+	return nil
+}
+
+// outputBase returns the name, without extension, that an inbox file named
+// stem+ext is moved to the processed directory under and its outputs are
+// named after: stem, or stem.2, stem.3, ... if the processed directory
+// already has a file of that name or the outbox outputs named after it, so
+// a second draft_content.json dropped never overwrites the subtitles of
+// the first.
+func (h *hotFolder) outputBase(stem, ext string) string {
+	entries, _ := os.ReadDir(h.outbox)
+	taken := func(base string) bool {
+		if _, err := os.Lstat(filepath.Join(h.processed, base+ext)); !os.IsNotExist(err) {
+			return true
+		}
+		return slices.ContainsFunc(entries, func(entry os.DirEntry) bool {
+			return strings.HasPrefix(entry.Name(), base+".")
+		})
+	}
+	base := stem
+	for i := 2; taken(base); i++ {
+		base = fmt.Sprintf("%s.%d", stem, i)
+	}
+	return base
+}
+
+// convertFile converts the inbox file input into outputs in the outbox
+// named after base.
+func (h *hotFolder) convertFile(ctx context.Context, input, base string) (convert.Report, []string, error) {
+	var draft capcut.DraftContent
+	var err error
+	switch strings.ToLower(filepath.Ext(input)) {
+	case ".json":
+		draft, err = capcut.ReadDraft(input)
+	case ".zip":
+		draft, err = readZippedDraft(input)
+	default:
+		return convert.Report{}, nil, fmt.Errorf("unsupported file type; drop a draft_content.json or a zipped project")
+	}
+	if err != nil {
+		return convert.Report{}, nil, fmt.Errorf("reading draft: %w", err)
+	}
+
+	cues, warnings, err := convert.CuesContext(ctx, draft, h.opts.Options)
+	report := convert.Report{Cues: len(cues), Warnings: warnings}
+	if err != nil {
+		return report, nil, err
+	}
+	printWarnings(warnings)
+
+	opts := h.opts
+	opts.source = filepath.Base(input)
+	if opts.vttStyle {
+		opts.cueStyle = draftCueStyle(draft)
+	}
+	outputs, err := writeResult(ctx, filepath.Join(h.outbox, base+"."+opts.Format), cues, opts)
+	return report, outputs, err
+}
+
+// readZippedDraft reads the draft_content.json of a zipped project folder.
+// When the archive holds several, the one nearest its root is used.
+func readZippedDraft(name string) (capcut.DraftContent, error) {
+	archive, err := zip.OpenReader(name)
+	if err != nil {
+		return capcut.DraftContent{}, err
+	}
+	defer archive.Close()
+
+	var draft *zip.File
+	for _, f := range archive.File {
+		if path.Base(f.Name) == "draft_content.json" && (draft == nil || strings.Count(f.Name, "/") < strings.Count(draft.Name, "/")) {
+			draft = f
+		}
+	}
+	if draft == nil {
+		return capcut.DraftContent{}, fmt.Errorf("no draft_content.json in %s", filepath.Base(name))
+	}
+	r, err := draft.Open()
+	if err != nil {
+		return capcut.DraftContent{}, err
+	}
+	defer r.Close()
+	return capcut.Decode(r)
+}
+
+// moveInto moves the file at name into dir, adding a number to its name if
+// dir already has a file of that name, and returns the new path.
+func moveInto(name, dir string) (string, error) {
+	base := filepath.Base(name)
+	ext := filepath.Ext(base)
+	target := filepath.Join(dir, base)
+	for i := 2; ; i++ {
+		if _, err := os.Lstat(target); os.IsNotExist(err) {
+			break
+		}
+		target = filepath.Join(dir, fmt.Sprintf("%s.%d%s", strings.TrimSuffix(base, ext), i, ext))
+	}
+	if err := os.Rename(name, target); err != nil {
+		return "", fmt.Errorf("failed to move %s: %w", name, err)
+	}
+	return target, nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func TestHotFolder(t *testing.T) {
+	opts, err := parseOptions(flag.NewFlagSet("watch", flag.ContinueOnError), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := t.TempDir()
+	folder := hotFolder{
+		inbox:     filepath.Join(root, "inbox"),
+		outbox:    filepath.Join(root, "outbox"),
+		failed:    filepath.Join(root, "error"),
+		processed: filepath.Join(root, "processed"),
+		opts:      opts,
+	}
+	for _, dir := range []string{folder.inbox, folder.outbox, folder.failed, folder.processed} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	draft, err := json.Marshal(capcuttest.Generate(1, 8, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	write := func(name string, data []byte) {
+		if err := os.WriteFile(filepath.Join(folder.inbox, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("episode1.json", draft)
+	write("notes.txt", []byte("not a draft"))
+	zipped, err := os.Create(filepath.Join(folder.inbox, "episode2.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	archive := zip.NewWriter(zipped)
+	w, _ := archive.Create("episode2/draft_content.json")
+	w.Write(draft)
+	archive.Close()
+	zipped.Close()
+
+	ctx := context.Background()
+	// The first poll only notes the files, which might still be copying.
+	if err := folder.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(folder.outbox, "episode1.srt")); err == nil {
+		t.Fatalf("converted a file on first sight")
+	}
+	if err := folder.poll(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"outbox/episode1.srt", "outbox/episode2.srt", "processed/episode1.json", "processed/episode2.zip", "error/notes.txt"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+	reason, err := os.ReadFile(filepath.Join(folder.failed, "notes.txt.error.txt"))
+	if err != nil || !strings.Contains(string(reason), "unsupported file type") {
+		t.Errorf("reason = %q, %v", reason, err)
+	}
+	if entries, _ := os.ReadDir(folder.inbox); len(entries) != 0 {
+		t.Errorf("inbox still holds %d files", len(entries))
+	}
+
+	// A second file of the same name does not overwrite the first.
+	write("notes.txt", []byte("again"))
+	folder.poll(ctx)
+	folder.poll(ctx)
+	if _, err := os.Stat(filepath.Join(folder.failed, "notes.2.txt.error.txt")); err != nil {
+		t.Errorf("second failure: %v", err)
+	}
+
+	// Nor does a second draft_content.json overwrite the subtitles of the
+	// first.
+	for range 2 {
+		write("draft_content.json", draft)
+		folder.poll(ctx)
+		folder.poll(ctx)
+	}
+	for _, name := range []string{"outbox/draft_content.srt", "outbox/draft_content.2.srt", "processed/draft_content.json", "processed/draft_content.2.json"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err != nil {
+			t.Errorf("missing %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"capcut-subtitle/pkg/convert"
+)
+
+// webhookTimeout bounds how long a webhook may take to accept a report.
+const webhookTimeout = 30 * time.Second
+
+// runReport is the JSON document posted to --webhook when a conversion
+// finishes or fails. Status is succeeded, failed, or skipped when the cache
+// found the outputs up to date.
+type runReport struct {
+	Draft    string    `json:"draft"`
+	Status   string    `json:"status"`
+	Error    string    `json:"error,omitempty"`
+	Outputs  []string  `json:"outputs"`
+	Cues     int       `json:"cues"`
+	Warnings []string  `json:"warnings"`
+	Finished time.Time `json:"finished"`
+}
+
+func newRunReport(draft string, report convert.Report, outputs []string, err error) runReport {
+	r := runReport{
+		Draft:    draft,
+		Status:   "succeeded",
+		Outputs:  outputs,
+		Cues:     report.Cues,
+		Warnings: make([]string, len(report.Warnings)),
+		Finished: time.Now().UTC(),
+	}
+	if r.Outputs == nil {
+		r.Outputs = []string{}
+	}
+	for i, w := range report.Warnings {
+		r.Warnings[i] = w.String()
+	}
+	switch {
+	case errors.Is(err, errUpToDate):
+		r.Status = "skipped"
+	case err != nil:
+		r.Status, r.Error = "failed", err.Error()
+	}
+	return r
+}
+
+// postWebhook sends the report to url. It is sent even after an interrupt
+// cancelled ctx, so receivers learn about the failed run.
+func postWebhook(ctx context.Context, url string, report runReport) error {
+	body, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to notify webhook: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to notify webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("failed to notify webhook: %s answered %s", url, resp.Status)
+	}
+	return nil
+}
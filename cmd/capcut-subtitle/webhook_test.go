@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"capcut-subtitle/pkg/convert"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestPostWebhook(t *testing.T) {
+	var got runReport
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s %s", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.Status == "failed" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	report := convert.Report{Cues: 2, Warnings: []convert.Warning{{Source: subtitle.Source{TrackNumber: 1}, Message: "missing material"}}}
+	sent := newRunReport("draft_content.json", report, []string{"subtitles.srt"}, nil)
+	if err := postWebhook(context.Background(), server.URL, sent); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"text track 1, segment 1: missing material"}; got.Status != "succeeded" || got.Cues != 2 || !reflect.DeepEqual(got.Warnings, want) || !reflect.DeepEqual(got.Outputs, sent.Outputs) {
+		t.Errorf("posted %+v", got)
+	}
+
+	// A cancelled run still reports, and a rejected report is an error.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	failed := newRunReport("draft_content.json", convert.Report{}, nil, errors.New("interrupted"))
+	if err := postWebhook(ctx, server.URL, failed); err == nil {
+		t.Errorf("postWebhook() succeeded although the webhook answered 500")
+	}
+	if got.Status != "failed" || got.Error != "interrupted" || got.Outputs == nil {
+		t.Errorf("posted %+v", got)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"capcut-subtitle/pkg/capcut"
+)
+
+// runWords writes the word timings of a draft's auto captions as JSON, for
+// caption editors that work on words rather than cues.
+func runWords(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("words", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: <project>.words.json in the draft's folder)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle words [-o words.json] [draft]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var path string
+	var err error
+	switch fs.NArg() {
+	case 0:
+		path, err = draftPath()
+	case 1:
+		path, err = resolveDraft(expandPath(fs.Arg(0)))
+	default:
+		return fmt.Errorf("expected at most one draft, got %d", fs.NArg())
+	}
+	if err != nil {
+		return err
+	}
+	draft, err := capcut.ReadDraft(path)
+	if err != nil {
+		return err
+	}
+	if !draft.HasTextTracks() {
+		return capcut.ErrNoTextTracks
+	}
+
+	name := *output
+	if name == "" {
+		if name, err = defaultOutput(path, "", "words.json"); err != nil {
+			return err
+		}
+	}
+	words := capcut.Words(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts))
+	data, err := json.MarshalIndent(words, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	if len(words) == 0 {
+		printWarning("the draft has no word timings; only auto captions carry them")
+	}
+	fmt.Println(colorize(colorGreen, fmt.Sprintf("Wrote %d words to %s", len(words), name)))
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+	"capcut-subtitle/pkg/xliff"
+)
+
+// runXLIFF hands subtitles to translation tools as XLIFF: export writes
+// each cue as a translation unit with its times, and import writes the
+// translated units back out as timed subtitles.
+func runXLIFF(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "export":
+			return runXLIFFExport(ctx, args[1:])
+		case "import":
+			return runXLIFFImport(ctx, args[1:])
+		}
+	}
+	return fmt.Errorf("usage: capcut-subtitle xliff export [flags] [input], or xliff import [flags] <file.xlf>")
+}
+
+// runXLIFFExport writes the cues of the input, or the draft in
+// file-path.txt, as an XLIFF document to translate.
+func runXLIFFExport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("xliff export", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the input name, or the project's, with the .xlf extension)")
+	version := fs.String("xliff-version", xliff.Version12, "XLIFF version to write: 1.2 or 2.0")
+	sourceLang := fs.String("source-lang", "", "language of the subtitles, e.g. th (default: detected from their script)")
+	targetLang := fs.String("target-lang", "", "language they are to be translated into, e.g. en")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle xliff export [flags] [input]")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if *version != xliff.Version12 && *version != xliff.Version20 {
+		return fmt.Errorf("--xliff-version must be %s or %s, got %q", xliff.Version12, xliff.Version20, *version)
+	}
+	cues, err := inputCues(ctx, fs.Args(), opts)
+	if err != nil {
+		return err
+	}
+	doc := xliff.Document{
+		SourceLang: cmp.Or(*sourceLang, subtitle.DetectLanguage(cues).Code),
+		TargetLang: *targetLang,
+		Units:      make([]xliff.Unit, len(cues)),
+	}
+	if doc.SourceLang == "" {
+		return fmt.Errorf("cannot tell the language of the subtitles; name it with --source-lang")
+	}
+	for i, c := range cues {
+		doc.Units[i] = xliff.Unit{Start: c.Start, End: c.End, Source: c.Text}
+	}
+
+	name := *output
+	if name == "" {
+		if name, err = namedAfterInput(fs.Args(), "xlf"); err != nil {
+			return err
+		}
+	}
+	doc.Original = "subtitles"
+	if fs.NArg() == 1 {
+		doc.Original = filepath.Base(fs.Arg(0))
+	} else if path, err := draftPath(); err == nil {
+		doc.Original = fileName(capcut.ProjectName(path), doc.Original)
+	}
+	var buf bytes.Buffer
+	if err := xliff.Write(&buf, doc, *version); err != nil {
+		return err
+	}
+	if err := os.WriteFile(name, buf.Bytes(), 0644); err != nil {
+		return &writers.WriteError{Path: name, Err: err}
+	}
+	fmt.Printf("Wrote %d translation units (XLIFF %s, %s) to %s\n", len(doc.Units), *version, doc.SourceLang, name)
+	return nil
+}
+
+// runXLIFFImport writes the units of a translated XLIFF document as
+// subtitles at the times in their notes, keeping the source text of units
+// not yet translated.
+func runXLIFFImport(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("xliff import", flag.ExitOnError)
+	output := fs.String("o", "", "output file (default: the XLIFF name with the target language and the output format's extension)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: capcut-subtitle xliff import [flags] <file.xlf>")
+		fs.PrintDefaults()
+	}
+	opts, err := parseOptions(fs, args)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("xliff import needs one XLIFF file")
+	}
+	file, err := os.Open(expandPath(fs.Arg(0)))
+	if err != nil {
+		return fmt.Errorf("reading XLIFF: %w", err)
+	}
+	doc, err := xliff.Read(file)
+	file.Close()
+	if err != nil {
+		return err
+	}
+
+	cues := make([]subtitle.Cue, len(doc.Units))
+	translated := 0
+	for i, u := range doc.Units {
+		cues[i] = subtitle.Cue{Start: u.Start, End: u.End, Text: cmp.Or(u.Target, u.Source)}
+		if u.Target != "" {
+			translated++
+		}
+	}
+	if translated < len(cues) {
+		printWarning(fmt.Sprintf("%d of %d units have no translation; they keep their source text", len(cues)-translated, len(cues)))
+	}
+	if opts.lang == "" {
+		opts.lang = doc.TargetLang
+	}
+	name := *output
+	if name == "" {
+		ext := opts.Format
+		if doc.TargetLang != "" {
+			ext = doc.TargetLang + "." + ext
+		}
+		name = strings.TrimSuffix(fs.Arg(0), filepath.Ext(fs.Arg(0))) + "." + ext
+	}
+	written, err := writeResult(ctx, name, cues, opts)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d of %d translated cues; wrote %s\n", translated, len(cues), strings.Join(written, ", "))
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestXLIFFRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "movie.srt")
+	if err := os.WriteFile(input, []byte("1\n00:00:01,000 --> 00:00:02,000\nสวัสดีครับ\n\n2\n00:00:02,500 --> 00:00:04,000\nไปกันเถอะ\n\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	for _, version := range []string{"1.2", "2.0"} {
+		t.Run(version, func(t *testing.T) {
+			doc := filepath.Join(dir, "movie.xlf")
+			if err := runXLIFFExport(ctx, []string{"--xliff-version", version, "--target-lang", "en", input}); err != nil {
+				t.Fatal(err)
+			}
+			exported, err := os.ReadFile(doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A translator fills in the first unit only.
+			source := "<source>สวัสดีครับ</source>\n"
+			translated := strings.Replace(string(exported), source, source+"        <target>Hello</target>\n", 1)
+			if translated == string(exported) {
+				t.Fatalf("exported XLIFF has no unit for the first cue:\n%s", exported)
+			}
+			if err := os.WriteFile(doc, []byte(translated), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := runXLIFFImport(ctx, []string{doc}); err != nil {
+				t.Fatal(err)
+			}
+			got, err := os.ReadFile(filepath.Join(dir, "movie.en.srt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "1\n00:00:01,000 --> 00:00:02,000\nHello\n\n2\n00:00:02,500 --> 00:00:04,000\nไปกันเถอะ\n\n"; !strings.Contains(string(got), want) {
+				t.Errorf("imported:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
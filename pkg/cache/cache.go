@@ -0,0 +1,136 @@
+// Package cache remembers which inputs were already converted with which
+// settings, so repeated runs can skip unchanged projects.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Cache maps input paths to the hash they had when last converted and the
+// files that conversion wrote. It is stored as a small JSON state file.
+type Cache struct {
+	path    string
+	Entries map[string]Entry `json:"entries"`
+}
+
+// Entry records one conversion.
+type Entry struct {
+	Hash    string   `json:"hash"`
+	Outputs []Output `json:"outputs"`
+}
+
+// Output is a file a conversion wrote, with the hash of its contents so a
+// file overwritten since, for example by a run with other options, is not
+// mistaken for the cached result.
+type Output struct {
+	Name string `json:"name"`
+	Hash string `json:"hash"`
+}
+
+// Open loads the state file at path. A missing file is an empty cache.
+func Open(path string) (*Cache, error) {
+	c := &Cache{path: path, Entries: make(map[string]Entry)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache: %w", err)
+	}
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse cache %s: %w", path, err)
+	}
+	if c.Entries == nil {
+		c.Entries = make(map[string]Entry)
+	}
+	return c, nil
+}
+
+// Fresh reports whether key was last converted with the same hash and all
+// of the outputs it wrote still exist unchanged.
+func (c *Cache) Fresh(key, hash string) bool {
+	entry, ok := c.Entries[key]
+	if !ok || entry.Hash != hash {
+		return false
+	}
+	for _, output := range entry.Outputs {
+		if current, err := Hash([]string{output.Name}); err != nil || current != output.Hash {
+			return false
+		}
+	}
+	return true
+}
+
+// Store records a conversion of key that wrote the named outputs, hashing
+// their current contents.
+func (c *Cache) Store(key, hash string, outputs []string) error {
+	entry := Entry{Hash: hash, Outputs: make([]Output, len(outputs))}
+	for i, name := range outputs {
+		outputHash, err := Hash([]string{name})
+		if err != nil {
+			return err
+		}
+		entry.Outputs[i] = Output{Name: name, Hash: outputHash}
+	}
+	c.Entries[key] = entry
+	return nil
+}
+
+// Save writes the state file, replacing it atomically so an interrupted
+// run cannot leave it half-written.
+func (c *Cache) Save() error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(c.path), ".cache-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	if err := os.Rename(temp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write cache: %w", err)
+	}
+	return nil
+}
+
+// Hash returns a hex SHA-256 over the contents of files and the settings
+// strings, in order. Pass the input with every configuration file it is
+// converted with, and the settings that affect the output.
+func Hash(files []string, settings ...string) (string, error) {
+	h := sha256.New()
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		_, err = io.Copy(h, file)
+		file.Close()
+		if err != nil {
+			return "", fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		h.Write([]byte{0})
+	}
+	for _, s := range settings {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	draft := filepath.Join(dir, "draft_content.json")
+	output := filepath.Join(dir, "subtitles.srt")
+	state := filepath.Join(dir, "state.json")
+	for _, name := range []string{draft, output} {
+		if err := os.WriteFile(name, []byte("{}"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hash, err := Hash([]string{draft}, "--dedup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := Open(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Fresh(draft, hash) {
+		t.Error("Fresh() = true for an empty cache")
+	}
+	if err := c.Store(draft, hash, []string{output}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := Open(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reopened.Fresh(draft, hash) {
+		t.Error("Fresh() = false after Store and Save")
+	}
+
+	other, err := Hash([]string{draft}, "--max-chars=42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == hash || reopened.Fresh(draft, other) {
+		t.Error("Fresh() = true after the settings changed")
+	}
+
+	if err := os.WriteFile(output, []byte("overwritten"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Fresh(draft, hash) {
+		t.Error("Fresh() = true after an output was overwritten")
+	}
+
+	if err := os.Remove(output); err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Fresh(draft, hash) {
+		t.Error("Fresh() = true after an output was deleted")
+	}
+}
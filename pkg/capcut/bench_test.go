@@ -0,0 +1,38 @@
+package capcut_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func BenchmarkDecode(b *testing.B) {
+	for _, words := range []int{10000, 100000} {
+		data, err := json.Marshal(capcuttest.Generate(1, words, 1))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(fmt.Sprintf("words=%d", words), func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := capcut.Decode(bytes.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCues(b *testing.B) {
+	draft := capcuttest.Generate(4, 25000, 1)
+	textMap := capcut.BuildTextMap(draft.Materials.Texts)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		capcut.Cues(draft.Tracks, textMap, capcut.GranularityWords)
+	}
+}
@@ -0,0 +1,84 @@
+// Package capcuttest generates synthetic CapCut drafts for benchmarks and
+// tests. The same arguments always produce the same draft, so timings
+// measured on it can be compared between versions.
+package capcuttest
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+)
+
+// wordsPerSegment is how many words each generated caption holds, about
+// what CapCut's auto captions put on screen at once.
+const wordsPerSegment = 8
+
+// commonWords make up a fifth of the generated words, in several scripts;
+// the rest are built from syllables, giving thousands of distinct words
+// that repeat about as often as in real speech.
+var commonWords = []string{
+	"the", "and", "to", "we", "are", "going", "really", "today",
+	"สวัสดี", "ครับ", "こんにちは", "世界", "café", "naïve",
+}
+
+var syllables = []string{
+	"ka", "lo", "mi", "ne", "ru", "sa", "ti", "po", "den", "var",
+	"shi", "tor", "bel", "qu", "an", "est", "ion", "or", "ly", "ment",
+}
+
+// Generate returns a draft with the given number of text tracks, each
+// holding about words word-timed captions, drawn pseudo-randomly from seed.
+// Some captions carry the markup, brackets and entities CapCut stores, so
+// cleaning has work to do.
+func Generate(tracks, words int, seed int64) capcut.DraftContent {
+	random := rand.New(rand.NewSource(seed))
+	var draft capcut.DraftContent
+
+	for t := 0; t < tracks; t++ {
+		track := capcut.Track{ID: fmt.Sprintf("track-%d", t+1), Type: "text"}
+		var at int64
+		for w := 0; w < words; w += wordsPerSegment {
+			material := capcut.TextMaterial{ID: fmt.Sprintf("text-%d-%d", t+1, w/wordsPerSegment)}
+			start := at
+			texts := make([]string, 0, wordsPerSegment)
+			for i := 0; i < wordsPerSegment && w+i < words; i++ {
+				text := word(random)
+				duration := 200000 + random.Int63n(300000)
+				material.Words = append(material.Words, capcut.Word{Begin: at, End: at + duration, Text: text})
+				texts = append(texts, text)
+				at += duration
+			}
+
+			content := strings.Join(texts, " ")
+			switch random.Intn(10) {
+			case 0:
+				content = "<font color=\"#FFFFFF\">[" + content + "]</font>"
+			case 1:
+				content = "<b>" + content + "</b> &amp; more"
+			}
+			material.Content = content
+
+			draft.Materials.Texts = append(draft.Materials.Texts, material)
+			track.Segments = append(track.Segments, capcut.Segment{
+				MaterialID:      material.ID,
+				TargetTimerange: capcut.Timerange{Start: start, Duration: at - start},
+			})
+			at += random.Int63n(500000)
+		}
+		draft.Tracks = append(draft.Tracks, track)
+	}
+	return draft
+}
+
+func word(random *rand.Rand) string {
+	if random.Intn(5) == 0 {
+		return commonWords[random.Intn(len(commonWords))]
+	}
+	var b strings.Builder
+	for n := 1 + random.Intn(3); n > 0; n-- {
+		b.WriteString(syllables[random.Intn(len(syllables))])
+	}
+	return b.String()
+}
@@ -0,0 +1,25 @@
+package capcuttest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	draft := Generate(2, 20, 1)
+	if len(draft.Tracks) != 2 {
+		t.Fatalf("Generate() made %d tracks, want 2", len(draft.Tracks))
+	}
+
+	words := 0
+	for _, material := range draft.Materials.Texts {
+		words += len(material.Words)
+	}
+	if words != 40 {
+		t.Errorf("Generate() made %d words, want 40", words)
+	}
+
+	if !reflect.DeepEqual(Generate(2, 20, 1), draft) {
+		t.Error("Generate() is not reproducible for the same seed")
+	}
+}
@@ -0,0 +1,568 @@
+// Package capcut reads CapCut desktop project drafts (draft_content.json).
+package capcut
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"strconv"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+type DraftContent struct {
+	Materials struct {
+		Texts []TextMaterial `json:"texts"`
+	} `json:"materials"`
+	// OtherMaterials maps the IDs of the materials other than texts, such
+	// as stickers and videos, to the kind they are listed under.
+	OtherMaterials map[string]string `json:"-"`
+	Tracks         []Track           `json:"tracks"`
+}
+
+type TextMaterial struct {
+	ID      string `json:"id"`
+	Content string `json:"content"`
+	// Type is the kind of text: "subtitle" for auto captions, "lyrics"
+	// for auto lyrics and "text" for titles and other text added by hand.
+	Type  string `json:"type"`
+	Words []Word `json:"words"`
+	// TextColor, BackgroundColor and BorderColor are #RRGGBB colors, ""
+	// for none. BackgroundAlpha is the opacity of the background from 0 to
+	// 1, or nil for an opaque one.
+	TextColor       string   `json:"text_color"`
+	BackgroundColor string   `json:"background_color"`
+	BackgroundAlpha *float64 `json:"background_alpha"`
+	BorderColor     string   `json:"border_color"`
+	// FontName is the font family, or "" for CapCut's default font.
+	FontName string `json:"font_name"`
+}
+
+type Word struct {
+	Begin int64  `json:"begin"`
+	End   int64  `json:"end"`
+	Text  string `json:"text"`
+	// Style is the index of the material's text style the word is drawn
+	// in; words of one style look alike.
+	Style int `json:"style"`
+}
+
+type Track struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Name is the name the track was given in CapCut, or "".
+	Name string `json:"name"`
+	// Attribute holds the TrackMuted and TrackHidden flags.
+	Attribute int       `json:"attribute"`
+	Segments  []Segment `json:"segments"`
+}
+
+// The flags of Track.Attribute that CapCut sets with the buttons at the
+// head of a track.
+const (
+	TrackMuted = 1 << iota
+	TrackHidden
+)
+
+// Hidden reports whether the track is hidden or muted, so none of its
+// segments are seen in the video.
+func (t Track) Hidden() bool {
+	return t.Attribute&(TrackMuted|TrackHidden) != 0
+}
+
+type Segment struct {
+	MaterialID      string    `json:"material_id"`
+	TargetTimerange Timerange `json:"target_timerange"`
+	// Visible is false for a segment hidden in CapCut; nil counts as
+	// visible.
+	Visible *bool `json:"visible,omitempty"`
+}
+
+// Hidden reports whether the segment is hidden.
+func (s Segment) Hidden() bool {
+	return s.Visible != nil && !*s.Visible
+}
+
+type Timerange struct {
+	Start    int64 `json:"start"`
+	Duration int64 `json:"duration"`
+}
+
+// HasTextTracks reports whether the draft has at least one text track.
+func (d DraftContent) HasTextTracks() bool {
+	for _, track := range d.Tracks {
+		if track.Type == "text" {
+			return true
+		}
+	}
+	return false
+}
+
+func BuildTextMap(texts []TextMaterial) map[string]TextMaterial {
+	textMap := make(map[string]TextMaterial, len(texts))
+	for _, text := range texts {
+		textMap[text.ID] = text
+	}
+	return textMap
+}
+
+func ReadDraft(filename string) (DraftContent, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return DraftContent{}, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	return Decode(file)
+}
+
+// Decode reads a draft_content.json document from r. Errors wrap
+// ErrNotADraft or ErrUnsupportedVersion when the input is not a draft this
+// package can read; malformed or truncated JSON is reported as a
+// *DecodeError locating the failure.
+//
+// Drafts of long projects run to hundreds of megabytes, mostly video, audio
+// and effect materials, so Decode walks the document token by token and
+// keeps only materials.texts and the tracks, skipping everything else
+// without buffering it. Segments of tracks other than text tracks are
+// dropped too.
+func Decode(r io.Reader) (DraftContent, error) {
+	reader := bufio.NewReader(r)
+	if err := checkPlainJSON(reader); err != nil {
+		return DraftContent{}, err
+	}
+
+	var content DraftContent
+	decoder := json.NewDecoder(reader)
+	if err := decodeDraft(decoder, &content); err != nil {
+		return DraftContent{}, parseError(decoder, err)
+	}
+	if content.Tracks == nil {
+		return DraftContent{}, fmt.Errorf("%w: no tracks", ErrNotADraft)
+	}
+	return content, nil
+}
+
+// parseError reports err, which stopped decoder, as a draft that cannot
+// be parsed, located in a DecodeError.
+func parseError(decoder *json.Decoder, err error) error {
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		err = &DecodeError{Offset: decoder.InputOffset(), Err: err}
+	}
+	return fmt.Errorf("failed to parse JSON: %w: %w", ErrNotADraft, err)
+}
+
+func decodeDraft(decoder *json.Decoder, content *DraftContent) error {
+	return decodeObject(decoder, func(key string) error {
+		switch key {
+		case "materials":
+			return decodeObject(decoder, func(key string) error {
+				if key == "texts" {
+					return decodeArray(decoder, func() error {
+						var text TextMaterial
+						if err := decodeText(decoder, &text); err != nil {
+							return err
+						}
+						content.Materials.Texts = append(content.Materials.Texts, text)
+						return nil
+					}, func() { content.Materials.Texts = []TextMaterial{} })
+				}
+				return decodeMaterialIDs(decoder, key, &content.OtherMaterials)
+			})
+		case "tracks":
+			return decodeArray(decoder, func() error {
+				var track Track
+				if err := decodeTrack(decoder, &track); err != nil {
+					return err
+				}
+				if track.Type != "text" {
+					track.Segments = nil
+				}
+				content.Tracks = append(content.Tracks, track)
+				return nil
+			}, func() { content.Tracks = []Track{} })
+		}
+		return skipValue(decoder)
+	})
+}
+
+// decodeMaterialIDs records in others the IDs of the materials of kind
+// listed in the next value, skipping values that are no list of objects.
+func decodeMaterialIDs(decoder *json.Decoder, kind string, others *map[string]string) error {
+	var materials []json.RawMessage
+	if err := decoder.Decode(&materials); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil
+		}
+		return err
+	}
+	for _, raw := range materials {
+		var material struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(raw, &material) != nil || material.ID == "" {
+			continue
+		}
+		if *others == nil {
+			*others = make(map[string]string)
+		}
+		(*others)[material.ID] = kind
+	}
+	return nil
+}
+
+// decodeText and decodeTrack read their objects field by field, rather
+// than with one Decode, so errors name the word or segment at fault.
+func decodeText(decoder *json.Decoder, text *TextMaterial) error {
+	return decodeObject(decoder, func(key string) error {
+		switch key {
+		case "id":
+			return decodeValue(decoder, &text.ID)
+		case "content":
+			return decodeValue(decoder, &text.Content)
+		case "type":
+			return decodeValue(decoder, &text.Type)
+		case "text_color":
+			return decodeValue(decoder, &text.TextColor)
+		case "background_color":
+			return decodeValue(decoder, &text.BackgroundColor)
+		case "background_alpha":
+			return decodeValue(decoder, &text.BackgroundAlpha)
+		case "border_color":
+			return decodeValue(decoder, &text.BorderColor)
+		case "font_name":
+			return decodeValue(decoder, &text.FontName)
+		case "words":
+			text.Words = nil
+			return decodeArray(decoder, func() error {
+				var word Word
+				if err := decodeValue(decoder, &word); err != nil {
+					return err
+				}
+				text.Words = append(text.Words, word)
+				return nil
+			}, func() { text.Words = []Word{} })
+		}
+		return skipValue(decoder)
+	})
+}
+
+func decodeTrack(decoder *json.Decoder, track *Track) error {
+	return decodeObject(decoder, func(key string) error {
+		switch key {
+		case "id":
+			return decodeValue(decoder, &track.ID)
+		case "type":
+			return decodeValue(decoder, &track.Type)
+		case "name":
+			return decodeValue(decoder, &track.Name)
+		case "attribute":
+			return decodeValue(decoder, &track.Attribute)
+		case "segments":
+			track.Segments = nil
+			return decodeArray(decoder, func() error {
+				var segment Segment
+				if err := decodeValue(decoder, &segment); err != nil {
+					return err
+				}
+				track.Segments = append(track.Segments, segment)
+				return nil
+			}, func() { track.Segments = []Segment{} })
+		}
+		return skipValue(decoder)
+	})
+}
+
+// decodeValue decodes the next value into v, naming the field at fault
+// when it has the wrong type.
+func decodeValue(decoder *json.Decoder, v any) error {
+	err := decoder.Decode(v)
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return decodeError(decoder, typeErr.Field, err)
+	}
+	return err
+}
+
+// decodeObject reads a JSON object, calling field with each key; field must
+// consume the value. A null is accepted as an empty object.
+func decodeObject(decoder *json.Decoder, field func(key string) error) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected object, found %v", token)
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key := token.(string)
+		if err := field(key); err != nil {
+			return decodeError(decoder, key, err)
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// decodeArray reads a JSON array, calling start once it has begun and
+// element for each element; element must consume it. A null is accepted as
+// a missing array.
+func decodeArray(decoder *json.Decoder, element func() error, start func()) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		return nil
+	}
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected array, found %v", token)
+	}
+	start()
+	for i := 0; decoder.More(); i++ {
+		if err := element(); err != nil {
+			return decodeError(decoder, "["+strconv.Itoa(i)+"]", err)
+		}
+	}
+	_, err = decoder.Token()
+	return err
+}
+
+// decodeError adds the key or index of the value being decoded to the path
+// of err, which is wrapped in a DecodeError where the failure happened.
+// Errors are wrapped on their way out of the document, innermost value
+// first, so the path costs nothing until something fails.
+func decodeError(decoder *json.Decoder, segment string, err error) error {
+	var decodeErr *DecodeError
+	if !errors.As(err, &decodeErr) {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return &DecodeError{Path: segment, Offset: decoder.InputOffset(), Err: err}
+	}
+	if strings.HasPrefix(decodeErr.Path, "[") {
+		decodeErr.Path = segment + decodeErr.Path
+	} else {
+		decodeErr.Path = segment + "." + decodeErr.Path
+	}
+	return decodeErr
+}
+
+// skipValue consumes the next value, however deeply nested, one token at a
+// time.
+func skipValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// checkPlainJSON peeks at the start of a draft. Newer CapCut releases
+// encrypt draft_content.json, which then reads as a long run of base64
+// text instead of a JSON object.
+func checkPlainJSON(reader *bufio.Reader) error {
+	head, _ := reader.Peek(512)
+	head = bytes.TrimLeft(head, " \t\r\n")
+	switch {
+	case len(head) == 0:
+		return fmt.Errorf("%w: empty input", ErrNotADraft)
+	case head[0] == '{':
+		return nil
+	case len(head) >= 64 && isBase64(head):
+		return fmt.Errorf("%w: the draft is encrypted", ErrUnsupportedVersion)
+	}
+	return fmt.Errorf("%w: input is not a JSON object", ErrNotADraft)
+}
+
+func isBase64(data []byte) bool {
+	for _, b := range bytes.TrimRight(data, " \t\r\n") {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '+', b == '/', b == '=':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Granularity selects whether materials with word timings produce one cue
+// per word or one cue per segment.
+type Granularity int
+
+const (
+	// GranularityWords uses word timings when a material has them.
+	GranularityWords Granularity = iota
+	// GranularitySegments ignores word timings.
+	GranularitySegments
+)
+
+// ParseGranularity parses a --granularity value: words or segments.
+func ParseGranularity(s string) (Granularity, error) {
+	switch s {
+	case "words", "word":
+		return GranularityWords, nil
+	case "segments", "segment":
+		return GranularitySegments, nil
+	}
+	return 0, fmt.Errorf("unknown granularity %q (want words or segments)", s)
+}
+
+// Cues extracts the raw cues of every text track in track and segment order.
+// Materials with word timings produce one cue per word unless granularity is
+// GranularitySegments; others produce one cue spanning the segment. Segments
+// whose material is missing, hidden ones and those of hidden tracks are
+// skipped. The text is left exactly as stored
+// in the draft.
+func Cues(tracks []Track, textMap map[string]TextMaterial, granularity Granularity) []subtitle.Cue {
+	var cues []subtitle.Cue
+	for segment := range Segments(tracks, textMap, granularity) {
+		cues = append(cues, segment...)
+	}
+	return cues
+}
+
+// Segments yields the cues of Cues one segment at a time, so callers can
+// process a large draft without holding all of its cues at once. The slice
+// yielded is only valid until the next iteration.
+func Segments(tracks []Track, textMap map[string]TextMaterial, granularity Granularity) iter.Seq[[]subtitle.Cue] {
+	return func(yield func([]subtitle.Cue) bool) {
+		var cues []subtitle.Cue
+		var textTrackNumber = 0
+
+		for _, track := range tracks {
+			if track.Type != "text" {
+				continue
+			}
+			textTrackNumber++
+
+			if track.Hidden() {
+				continue
+			}
+			for segmentIndex, segment := range track.Segments {
+				textMaterial, found := textMap[segment.MaterialID]
+				if !found || segment.Hidden() {
+					continue
+				}
+				source := subtitle.Source{
+					MaterialID:  segment.MaterialID,
+					TrackID:     track.ID,
+					TrackNumber: textTrackNumber,
+					Segment:     segmentIndex,
+				}
+
+				cues = cues[:0]
+				if len(textMaterial.Words) > 0 && granularity != GranularitySegments {
+					emphasis := wordEmphasis(textMaterial.Words)
+					for i, word := range textMaterial.Words {
+						cues = append(cues, subtitle.Cue{Start: word.Begin, End: word.End, Text: word.Text, Source: source, Emphasis: emphasis[i]})
+					}
+				} else {
+					startTime := segment.TargetTimerange.Start
+					endTime := startTime + segment.TargetTimerange.Duration
+					cues = append(cues, subtitle.Cue{Start: startTime, End: endTime, Text: textMaterial.Content, Source: source})
+				}
+				if !yield(cues) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// wordEmphasis maps the styles of a material's words to emphasis. The
+// style most words use is plain text; the others, in the order they first
+// appear, are italic, bold and color, starting over for a fourth.
+func wordEmphasis(words []Word) []subtitle.Emphasis {
+	counts := make(map[int]int)
+	var styles []int
+	for _, w := range words {
+		if counts[w.Style] == 0 {
+			styles = append(styles, w.Style)
+		}
+		counts[w.Style]++
+	}
+	plain := styles[0]
+	for _, style := range styles {
+		if counts[style] > counts[plain] {
+			plain = style
+		}
+	}
+	emphasisOf := make(map[int]subtitle.Emphasis)
+	for _, style := range styles {
+		if style != plain {
+			emphasisOf[style] = subtitle.EmphasisItalic + subtitle.Emphasis(len(emphasisOf)%3)
+		}
+	}
+	emphasis := make([]subtitle.Emphasis, len(words))
+	for i, w := range words {
+		emphasis[i] = emphasisOf[w.Style]
+	}
+	return emphasis
+}
+
+// DraftWord is a word of a material with word timings, located in the
+// draft.
+type DraftWord struct {
+	Word  string `json:"word"`
+	Begin int64  `json:"begin"`
+	End   int64  `json:"end"`
+	// Track is the 1-based position of the text track among the draft's
+	// text tracks, and Segment the index of the segment within it.
+	Track    int    `json:"track"`
+	Segment  int    `json:"segment"`
+	Material string `json:"material"`
+	// Style is the word's text style index, as in Word.
+	Style int `json:"style"`
+}
+
+// Words lists the words of every segment whose material has word timings,
+// in track and segment order, exactly as stored in the draft. Materials
+// without word timings and segments whose material is missing are left
+// out.
+func Words(tracks []Track, textMap map[string]TextMaterial) []DraftWord {
+	words := []DraftWord{}
+	for segment := range Segments(tracks, textMap, GranularityWords) {
+		material := textMap[segment[0].Source.MaterialID]
+		if len(material.Words) == 0 {
+			continue
+		}
+		for i, c := range segment {
+			words = append(words, DraftWord{
+				Word: c.Text, Begin: c.Start, End: c.End,
+				Track: c.Source.TrackNumber, Segment: c.Source.Segment, Material: c.Source.MaterialID,
+				Style: material.Words[i].Style,
+			})
+		}
+	}
+	return words
+}
@@ -0,0 +1,394 @@
+package capcut
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestBuildTextMap(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []TextMaterial
+		want  map[string]TextMaterial
+	}{
+		{
+			name:  "empty slice",
+			input: []TextMaterial{},
+			want:  map[string]TextMaterial{},
+		},
+		{
+			name: "single text material",
+			input: []TextMaterial{
+				{ID: "1", Content: "Hello"},
+			},
+			want: map[string]TextMaterial{
+				"1": {ID: "1", Content: "Hello"},
+			},
+		},
+		{
+			name: "multiple text materials",
+			input: []TextMaterial{
+				{ID: "1", Content: "Hello"},
+				{ID: "2", Content: "World"},
+			},
+			want: map[string]TextMaterial{
+				"1": {ID: "1", Content: "Hello"},
+				"2": {ID: "2", Content: "World"},
+			},
+		},
+		{
+			name: "duplicate IDs (should overwrite)",
+			input: []TextMaterial{
+				{ID: "1", Content: "Hello"},
+				{ID: "1", Content: "World"},
+			},
+			want: map[string]TextMaterial{
+				"1": {ID: "1", Content: "World"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildTextMap(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("BuildTextMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadDraft(t *testing.T) {
+	// Create a temporary file for testing
+	tempFile, err := os.CreateTemp("", "test-draft-*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	// Write test data to the temporary file
+	testDraft := DraftContent{
+		Materials: struct {
+			Texts []TextMaterial `json:"texts"`
+		}{
+			Texts: []TextMaterial{
+				{ID: "1", Content: "Test content"},
+			},
+		},
+		Tracks: []Track{
+			{Type: "text", Segments: []Segment{
+				{MaterialID: "1", TargetTimerange: Timerange{Start: 0, Duration: 1000}},
+			}},
+		},
+	}
+
+	if err := json.NewEncoder(tempFile).Encode(testDraft); err != nil {
+		t.Fatal(err)
+	}
+	tempFile.Close()
+
+	tests := []struct {
+		name       string
+		filename   string
+		want       DraftContent
+		wantErr    bool
+		beforeTest func()
+	}{
+		{
+			name:     "valid file",
+			filename: tempFile.Name(),
+			want:     testDraft,
+			wantErr:  false,
+		},
+		{
+			name:     "non-existent file",
+			filename: "nonexistent.json",
+			want:     DraftContent{},
+			wantErr:  true,
+		},
+		{
+			name: "invalid JSON",
+			filename: func() string {
+				f, err := os.CreateTemp("", "invalid-json-*.json")
+				if err != nil {
+					t.Fatal(err)
+				}
+				f.WriteString("{invalid json}")
+				f.Close()
+				return f.Name()
+			}(),
+			want:    DraftContent{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.beforeTest != nil {
+				tt.beforeTest()
+			}
+
+			got, err := ReadDraft(tt.filename)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ReadDraft() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ReadDraft() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  error
+	}{
+		{name: "empty", input: "", want: ErrNotADraft},
+		{name: "invalid JSON", input: "{invalid json}", want: ErrNotADraft},
+		{name: "JSON array", input: "[1, 2]", want: ErrNotADraft},
+		{name: "JSON without tracks", input: `{"segments": []}`, want: ErrNotADraft},
+		{name: "encrypted draft", input: strings.Repeat("QUJDREVGR0hJSktMTU5PUA==", 8), want: ErrUnsupportedVersion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tt.input)); !errors.Is(err, tt.want) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeSkipsOtherMaterials(t *testing.T) {
+	input := `{
+		"canvas_config": {"width": 1920, "height": 1080},
+		"materials": {
+			"videos": [{"id": "v1", "path": "clip.mp4", "crop": {"lower_left_x": 0.0}}, {"id": "v2"}],
+			"texts": [{"id": "t1", "content": "Hello", "words": [{"begin": 0, "end": 500, "text": "Hello"}]}],
+			"audios": null
+		},
+		"tracks": [
+			{"id": "a", "type": "video", "segments": [{"material_id": "v1", "target_timerange": {"start": 0, "duration": 5}}]},
+			{"id": "b", "type": "text", "attribute": 2, "segments": [
+				{"material_id": "t1", "target_timerange": {"start": 0, "duration": 500}},
+				{"material_id": "t1", "visible": false, "target_timerange": {"start": 500, "duration": 500}}
+			]}
+		],
+		"version": 360000
+	}`
+
+	got, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want DraftContent
+	want.Materials.Texts = []TextMaterial{{ID: "t1", Content: "Hello", Words: []Word{{Begin: 0, End: 500, Text: "Hello"}}}}
+	want.OtherMaterials = map[string]string{"v1": "videos", "v2": "videos"}
+	want.Tracks = []Track{
+		{ID: "a", Type: "video"},
+		{ID: "b", Type: "text", Attribute: TrackHidden, Segments: []Segment{
+			{MaterialID: "t1", TargetTimerange: Timerange{Start: 0, Duration: 500}},
+			{MaterialID: "t1", TargetTimerange: Timerange{Start: 500, Duration: 500}, Visible: new(bool)},
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decode() = %+v, want %+v", got, want)
+	}
+	if !got.Tracks[1].Hidden() || got.Tracks[1].Segments[0].Hidden() || !got.Tracks[1].Segments[1].Hidden() {
+		t.Errorf("Hidden() of the text track and its segments = %v, %v, %v; want true, false, true",
+			got.Tracks[1].Hidden(), got.Tracks[1].Segments[0].Hidden(), got.Tracks[1].Segments[1].Hidden())
+	}
+
+	if _, err := Decode(strings.NewReader(`{"materials": null, "tracks": []}`)); err != nil {
+		t.Errorf("Decode() with null materials error = %v", err)
+	}
+}
+
+func TestDecodeErrorPath(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		path  string
+	}{
+		{name: "word time", input: `{"materials": {"texts": [{"id": "a"}, {"words": [{"begin": 0}, {"begin": "1"}]}]}, "tracks": []}`, path: "materials.texts[1].words[1].begin"},
+		{name: "segment time", input: `{"tracks": [{"segments": [{"target_timerange": {"start": true}}]}]}`, path: "tracks[0].segments[0].target_timerange.start"},
+		{name: "track type", input: `{"tracks": [{"type": ["text"]}]}`, path: "tracks[0].type"},
+		{name: "tracks object", input: `{"tracks": {}}`, path: "tracks"},
+		{name: "truncated", input: `{"materials": {"texts": [{"content": "Hi`, path: "materials.texts[0].content"},
+		{name: "unclosed document", input: `{"tracks": []`, path: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(tt.input))
+			var decodeErr *DecodeError
+			if !errors.As(err, &decodeErr) || !errors.Is(err, ErrNotADraft) {
+				t.Fatalf("Decode() error = %v, want a *DecodeError", err)
+			}
+			if decodeErr.Path != tt.path || decodeErr.Offset <= 0 || decodeErr.Offset > int64(len(tt.input)) {
+				t.Errorf("Decode() error at %q, byte %d, want %q", decodeErr.Path, decodeErr.Offset, tt.path)
+			}
+		})
+	}
+}
+
+func FuzzDecode(f *testing.F) {
+	f.Add(`{"materials": {"texts": [{"id": "t1", "content": "Hello", "words": [{"begin": 0, "end": 500, "text": "Hello"}]}]}, "tracks": [{"id": "b", "type": "text", "segments": [{"material_id": "t1", "target_timerange": {"start": 0, "duration": 500}}]}]}`)
+	f.Add(`{"materials": null, "tracks": [{"type": "video", "segments": null}]}`)
+	f.Add(`{"tracks": [{"segments": [{"target_timerange": {"start": 1e30}}]}]}`)
+	f.Add(`{"materials": {"texts": [{"words": [{"begin": "0"}]}]}}`)
+	f.Add(strings.Repeat("QUJDREVGR0hJSktMTU5PUA==", 8))
+
+	f.Fuzz(func(t *testing.T, input string) {
+		content, err := Decode(strings.NewReader(input))
+		if err != nil {
+			if !errors.Is(err, ErrNotADraft) && !errors.Is(err, ErrUnsupportedVersion) {
+				t.Fatalf("Decode() error = %v, want ErrNotADraft or ErrUnsupportedVersion", err)
+			}
+			var decodeErr *DecodeError
+			if errors.As(err, &decodeErr) && (decodeErr.Offset < 0 || decodeErr.Offset > int64(len(input))) {
+				t.Fatalf("Decode() error offset %d outside the %d byte input", decodeErr.Offset, len(input))
+			}
+			return
+		}
+		if content.Tracks == nil {
+			t.Fatal("Decode() succeeded without tracks")
+		}
+		for _, track := range content.Tracks {
+			if track.Type != "text" && track.Segments != nil {
+				t.Fatalf("Decode() kept segments of a %q track", track.Type)
+			}
+		}
+	})
+}
+
+func TestProjectName(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "My Vlog")
+	os.Mkdir(dir, 0755)
+	draft := filepath.Join(dir, "draft_content.json")
+
+	if got := ProjectName(draft); got != "My Vlog" {
+		t.Errorf("ProjectName() without metadata = %q, want the folder name", got)
+	}
+	os.WriteFile(filepath.Join(dir, "draft_meta_info.json"), []byte(`{"draft_id": "1", "draft_name": "ทริปเชียงใหม่"}`), 0644)
+	if got := ProjectName(draft); got != "ทริปเชียงใหม่" {
+		t.Errorf("ProjectName() = %q, want the draft_name", got)
+	}
+}
+
+func TestWords(t *testing.T) {
+	textMap := BuildTextMap([]TextMaterial{
+		{ID: "w", Content: "<b>Hi there</b>", Words: []Word{{Begin: 0, End: 400, Text: "Hi"}, {Begin: 400, End: 900, Text: " there", Style: 1}}},
+		{ID: "plain", Content: "No timings"},
+	})
+	tracks := []Track{
+		{ID: "video", Type: "video"},
+		{ID: "t1", Type: "text", Segments: []Segment{{MaterialID: "plain"}, {MaterialID: "missing"}, {MaterialID: "w"}}},
+	}
+	want := []DraftWord{
+		{Word: "Hi", Begin: 0, End: 400, Track: 1, Segment: 2, Material: "w"},
+		{Word: " there", Begin: 400, End: 900, Track: 1, Segment: 2, Material: "w", Style: 1},
+	}
+	if got := Words(tracks, textMap); !reflect.DeepEqual(got, want) {
+		t.Errorf("Words() = %+v, want %+v", got, want)
+	}
+	if got := Words(nil, textMap); got == nil || len(got) != 0 {
+		t.Errorf("Words(nil) = %#v, want an empty list", got)
+	}
+}
+
+func TestWordEmphasis(t *testing.T) {
+	words := []Word{{Style: 3}, {Style: 0}, {Style: 0}, {Style: 5}, {Style: 0}, {Style: 2}, {Style: 7}, {Style: 3}}
+	want := []subtitle.Emphasis{
+		subtitle.EmphasisItalic, subtitle.EmphasisNone, subtitle.EmphasisNone, subtitle.EmphasisBold,
+		subtitle.EmphasisNone, subtitle.EmphasisColor, subtitle.EmphasisItalic, subtitle.EmphasisItalic,
+	}
+	if got := wordEmphasis(words); !reflect.DeepEqual(got, want) {
+		t.Errorf("wordEmphasis() = %v, want %v", got, want)
+	}
+	if got := wordEmphasis([]Word{{Style: 4}, {Style: 4}}); !reflect.DeepEqual(got, []subtitle.Emphasis{0, 0}) {
+		t.Errorf("wordEmphasis() of one style = %v, want no emphasis", got)
+	}
+}
+
+func TestMainStyle(t *testing.T) {
+	draft, err := Decode(strings.NewReader(`{"materials": {"texts": [
+		{"id": "title", "content": "Trip", "text_color": "#FFCC00", "font_name": "Kanit"},
+		{"id": "a", "content": "Hi", "text_color": "#FFFFFF", "background_color": "#000000", "background_alpha": 0.6},
+		{"id": "b", "content": "there", "text_color": "#FFFFFF", "background_color": "#000000", "background_alpha": 0.6}
+	]}, "tracks": [
+		{"id": "t1", "type": "text", "segments": [{"material_id": "title"}]},
+		{"id": "v", "type": "video", "segments": [{"material_id": "a"}, {"material_id": "a"}]},
+		{"id": "t2", "type": "text", "segments": [{"material_id": "a"}, {"material_id": "b"}, {"material_id": "missing"}]}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, ok := MainStyle(draft.Tracks, BuildTextMap(draft.Materials.Texts))
+	want := TextStyle{Color: "#FFFFFF", Background: "#000000", BackgroundAlpha: 0.6}
+	if !ok || got != want {
+		t.Errorf("MainStyle() = %+v, %v, want %+v", got, ok, want)
+	}
+	if got := (TextMaterial{BackgroundColor: "#000000"}).Style(); got.BackgroundAlpha != 1 {
+		t.Errorf("Style() without background_alpha = %+v, want an opaque background", got)
+	}
+	if _, ok := MainStyle(nil, nil); ok {
+		t.Error("MainStyle() of no tracks reported a style")
+	}
+}
+
+func TestFilterTracks(t *testing.T) {
+	tracks := []Track{
+		{ID: "v", Type: "video", Segments: []Segment{{MaterialID: "video"}}},
+		{ID: "a", Type: "text", Name: "Titles", Segments: []Segment{{MaterialID: "title"}}},
+		{ID: "b", Type: "text", Name: "Dialogue", Segments: []Segment{{MaterialID: "title"}, {MaterialID: "caption"}, {MaterialID: "missing"}}},
+	}
+	textMap := BuildTextMap([]TextMaterial{
+		{ID: "title", Type: "text", Content: "Day 1"},
+		{ID: "caption", Type: "subtitle", Content: "We made it"},
+	})
+	tests := []struct {
+		name   string
+		filter Filter
+		want   [][]string
+	}{
+		{"none", Filter{}, [][]string{{"video"}, {"title"}, {"title", "caption", "missing"}}},
+		{"include tracks", Filter{IncludeTracks: regexp.MustCompile("^Dia")}, [][]string{{"video"}, nil, {"title", "caption", "missing"}}},
+		{"exclude tracks", Filter{ExcludeTracks: regexp.MustCompile("Titles")}, [][]string{{"video"}, nil, {"title", "caption", "missing"}}},
+		{"include materials", Filter{IncludeMaterials: regexp.MustCompile("^subtitle$")}, [][]string{{"video"}, {""}, {"", "caption", ""}}},
+		{"exclude materials", Filter{ExcludeMaterials: regexp.MustCompile("^text$")}, [][]string{{"video"}, {""}, {"", "caption", "missing"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			for _, track := range tt.filter.Tracks(tracks, textMap) {
+				var ids []string
+				for _, segment := range track.Segments {
+					ids = append(ids, segment.MaterialID)
+				}
+				got = append(got, ids)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tracks() segments = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// The caption keeps its index in the track with the title before it
+	// left out.
+	cues := Cues(Filter{ExcludeMaterials: regexp.MustCompile("^text$")}.Tracks(tracks, textMap), textMap, GranularityWords)
+	if len(cues) != 1 || cues[0].Source.TrackNumber != 2 || cues[0].Source.Segment != 1 {
+		t.Errorf("Cues() of the filtered tracks = %+v, want the caption as segment 1 of track 2", cues)
+	}
+}
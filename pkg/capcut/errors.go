@@ -0,0 +1,42 @@
+package capcut
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotADraft reports input that is not a CapCut draft_content.json
+	// document: not JSON, or JSON without the draft's tracks.
+	ErrNotADraft = errors.New("not a CapCut draft")
+
+	// ErrUnsupportedVersion reports a draft in a form this tool cannot
+	// read, such as the encrypted draft_content.json written by newer
+	// CapCut releases.
+	ErrUnsupportedVersion = errors.New("unsupported CapCut draft version")
+
+	// ErrNoTextTracks reports a draft without any text track to take
+	// subtitles from.
+	ErrNoTextTracks = errors.New("draft has no text tracks")
+)
+
+// A DecodeError reports where in a draft decoding failed. Path names the
+// value at fault, such as materials.texts[42].words[3].begin, and is empty
+// for the document itself; Offset is the byte offset decoding had reached.
+type DecodeError struct {
+	Path   string
+	Offset int64
+	Err    error
+}
+
+func (e *DecodeError) Error() string {
+	path := e.Path
+	if path == "" {
+		path = "document"
+	}
+	return fmt.Sprintf("%s (byte %d): %v", path, e.Offset, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,60 @@
+package capcut
+
+import "regexp"
+
+// Filter selects the text segments cues are taken from, so title cards and
+// lower thirds can be kept out of the dialogue. A nil pattern matches
+// everything for the Include fields and nothing for the Exclude ones.
+type Filter struct {
+	// IncludeTracks and ExcludeTracks select text tracks by name.
+	IncludeTracks, ExcludeTracks *regexp.Regexp
+	// IncludeMaterials and ExcludeMaterials select segments by the type of
+	// their material, as in TextMaterial.Type.
+	IncludeMaterials, ExcludeMaterials *regexp.Regexp
+}
+
+// IsZero reports whether f keeps every segment.
+func (f Filter) IsZero() bool {
+	return f == Filter{}
+}
+
+// Tracks returns tracks without the text segments f leaves out. Tracks
+// left out keep their place with no segments, so text tracks are still
+// numbered as in the draft, and segments left out keep theirs as zero
+// Segments, which have no material, so the others keep the index in their
+// track that Source.Segment reports.
+func (f Filter) Tracks(tracks []Track, textMap map[string]TextMaterial) []Track {
+	if f.IsZero() {
+		return tracks
+	}
+	filtered := make([]Track, len(tracks))
+	for i, track := range tracks {
+		filtered[i] = track
+		if track.Type != "text" {
+			continue
+		}
+		if !matches(f.IncludeTracks, track.Name, true) || matches(f.ExcludeTracks, track.Name, false) {
+			filtered[i].Segments = nil
+			continue
+		}
+		if f.IncludeMaterials == nil && f.ExcludeMaterials == nil {
+			continue
+		}
+		filtered[i].Segments = make([]Segment, len(track.Segments))
+		for j, segment := range track.Segments {
+			kind := textMap[segment.MaterialID].Type
+			if matches(f.IncludeMaterials, kind, true) && !matches(f.ExcludeMaterials, kind, false) {
+				filtered[i].Segments[j] = segment
+			}
+		}
+	}
+	return filtered
+}
+
+// matches reports whether re matches s, or whether a nil re should.
+func matches(re *regexp.Regexp, s string, nilMatches bool) bool {
+	if re == nil {
+		return nilMatches
+	}
+	return re.MatchString(s)
+}
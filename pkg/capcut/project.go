@@ -0,0 +1,26 @@
+package capcut
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ProjectName returns the name of the project the draft at draftPath
+// belongs to: the draft_name CapCut records in draft_meta_info.json next
+// to the draft or, failing that, the name of the draft's folder.
+func ProjectName(draftPath string) string {
+	dir := filepath.Dir(draftPath)
+	if data, err := os.ReadFile(filepath.Join(dir, "draft_meta_info.json")); err == nil {
+		var meta struct {
+			Name string `json:"draft_name"`
+		}
+		if json.Unmarshal(data, &meta) == nil && meta.Name != "" {
+			return meta.Name
+		}
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	return filepath.Base(dir)
+}
@@ -0,0 +1,53 @@
+package capcut
+
+// TextStyle is how CapCut draws the text of a material.
+type TextStyle struct {
+	// Color, Background and Border are #RRGGBB colors as the draft stores
+	// them, "" for none.
+	Color      string
+	Background string
+	// BackgroundAlpha is the opacity of the background, from 0 to 1.
+	BackgroundAlpha float64
+	Border          string
+	// Font is the font family, or "" for CapCut's default font.
+	Font string
+}
+
+// Style is the look of the material's text.
+func (m TextMaterial) Style() TextStyle {
+	alpha := 1.0
+	if m.BackgroundAlpha != nil {
+		alpha = min(max(*m.BackgroundAlpha, 0), 1)
+	}
+	return TextStyle{
+		Color:           m.TextColor,
+		Background:      m.BackgroundColor,
+		BackgroundAlpha: alpha,
+		Border:          m.BorderColor,
+		Font:            m.FontName,
+	}
+}
+
+// MainStyle returns the style most segments of the text tracks are drawn
+// in, the first of them on a tie, and false if no segment has a material.
+func MainStyle(tracks []Track, textMap map[string]TextMaterial) (TextStyle, bool) {
+	counts := make(map[TextStyle]int)
+	var main TextStyle
+	for _, track := range tracks {
+		if track.Type != "text" {
+			continue
+		}
+		for _, segment := range track.Segments {
+			material, ok := textMap[segment.MaterialID]
+			if !ok {
+				continue
+			}
+			style := material.Style()
+			counts[style]++
+			if counts[style] > counts[main] {
+				main = style
+			}
+		}
+	}
+	return main, len(counts) > 0
+}
@@ -0,0 +1,214 @@
+package capcut
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// textEdit replaces the bytes of a draft from start to end with value.
+type textEdit struct {
+	start, end int64
+	value      []byte
+}
+
+// SetTexts copies the draft in r to w with the content of the text
+// materials in texts, keyed by material ID, replaced, and returns how many
+// materials changed. Styled content keeps its markup, with only the text
+// inside it replaced; see withText. Everything else is copied byte for
+// byte, so the draft stays as CapCut wrote it. A material whose text
+// changes loses its word timings, which no longer match it. An ID not in
+// the draft is an error.
+//
+// Like Decode, SetTexts walks the draft without buffering it; it reads r a
+// second time to copy it.
+func SetTexts(r io.ReadSeeker, w io.Writer, texts map[string]string) (int, error) {
+	edits, changed, err := textEdits(r, texts)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	var offset int64
+	for _, e := range edits {
+		if _, err := io.CopyN(w, r, e.start-offset); err != nil {
+			return 0, err
+		}
+		if _, err := w.Write(e.value); err != nil {
+			return 0, err
+		}
+		if _, err := r.Seek(e.end, io.SeekStart); err != nil {
+			return 0, err
+		}
+		offset = e.end
+	}
+	if _, err := io.Copy(w, r); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// textEdits finds the content, and the words, of the text materials whose
+// text texts changes, in the order they appear in the draft, and how many
+// materials they change.
+func textEdits(r io.Reader, texts map[string]string) ([]textEdit, int, error) {
+	reader := bufio.NewReader(r)
+	if err := checkPlainJSON(reader); err != nil {
+		return nil, 0, err
+	}
+	decoder := json.NewDecoder(reader)
+	var edits []textEdit
+	changed := 0
+	found := make(map[string]bool, len(texts))
+	err := decodeObject(decoder, func(key string) error {
+		if key != "materials" {
+			return skipValue(decoder)
+		}
+		return decodeObject(decoder, func(key string) error {
+			if key != "texts" {
+				return skipValue(decoder)
+			}
+			return decodeArray(decoder, func() error {
+				var id, content string
+				var contentEdit, wordsEdit *textEdit
+				err := decodeObject(decoder, func(key string) error {
+					var err error
+					switch key {
+					case "id":
+						return decodeValue(decoder, &id)
+					case "content":
+						if contentEdit, err = rawEdit(decoder); err != nil {
+							return err
+						}
+						return json.Unmarshal(contentEdit.value, &content)
+					case "words":
+						wordsEdit, err = rawEdit(decoder)
+						return err
+					}
+					return skipValue(decoder)
+				})
+				if err != nil {
+					return err
+				}
+				text, ok := texts[id]
+				if !ok {
+					return nil
+				}
+				found[id] = true
+				if contentEdit == nil {
+					return fmt.Errorf("text material %s has no content", id)
+				}
+				if text = withText(content, text); text == content {
+					return nil
+				}
+				contentEdit.value = marshalText(text)
+				edits = append(edits, *contentEdit)
+				changed++
+				if wordsEdit != nil && !bytes.Equal(wordsEdit.value, []byte("[]")) && !bytes.Equal(wordsEdit.value, []byte("null")) {
+					wordsEdit.value = []byte("[]")
+					edits = append(edits, *wordsEdit)
+				}
+				return nil
+			}, func() {})
+		})
+	})
+	if err != nil {
+		return nil, 0, parseError(decoder, err)
+	}
+
+	var missing []string
+	for id := range texts {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		slices.Sort(missing)
+		return nil, 0, fmt.Errorf("text material %s not found in the draft", missing[0])
+	}
+	slices.SortFunc(edits, func(a, b textEdit) int { return cmp.Compare(a.start, b.start) })
+	return edits, changed, nil
+}
+
+// rawEdit reads the next value, returning it with where it is in the
+// draft.
+func rawEdit(decoder *json.Decoder) (*textEdit, error) {
+	var raw json.RawMessage
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	end := decoder.InputOffset()
+	return &textEdit{start: end - int64(len(raw)), end: end, value: raw}, nil
+}
+
+// markupEscaper escapes the characters that would read as markup in
+// styled content.
+var markupEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// withText returns content with its text replaced by text. Plain content
+// becomes text itself. Styled content, such as
+//
+//	<font id="" path="Sans.ttf"><color=(1,1,1,1)><size=8>[Hello]</size></color></font>
+//
+// keeps its tags: the first run of text between them takes text, escaped,
+// inside the run's brackets, and any later runs are emptied.
+func withText(content, text string) string {
+	if !strings.Contains(content, "<") {
+		return text
+	}
+	var b strings.Builder
+	replaced := false
+	for content != "" {
+		if content[0] == '<' {
+			end := strings.IndexByte(content, '>') + 1
+			if end == 0 {
+				end = len(content)
+			}
+			b.WriteString(content[:end])
+			content = content[end:]
+			continue
+		}
+		end := strings.IndexByte(content, '<')
+		if end < 0 {
+			end = len(content)
+		}
+		run := content[:end]
+		content = content[end:]
+		trimmed := strings.TrimSpace(run)
+		if trimmed == "" {
+			b.WriteString(run)
+			continue
+		}
+		bracketed := strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]")
+		if bracketed {
+			b.WriteByte('[')
+		}
+		if !replaced {
+			b.WriteString(markupEscaper.Replace(text))
+			replaced = true
+		}
+		if bracketed {
+			b.WriteByte(']')
+		}
+	}
+	if !replaced {
+		b.WriteString(markupEscaper.Replace(text))
+	}
+	return b.String()
+}
+
+// marshalText encodes text as a JSON string, leaving <, > and & as they
+// are, as CapCut writes them.
+func marshalText(text string) []byte {
+	var b bytes.Buffer
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(text)
+	return bytes.TrimSuffix(b.Bytes(), []byte("\n"))
+}
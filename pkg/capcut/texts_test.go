@@ -0,0 +1,46 @@
+package capcut
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetTexts(t *testing.T) {
+	draft := `{"materials": {"videos": [{"id": "v"}], "texts": [
+  {"content": "Hello!", "id": "a", "words": [{"begin": 0, "end": 1, "text": "Hello!"}]},
+  {"id": "b", "content" :  "Same", "font_name": "Sans"},
+  {"id": "c", "content": "Untouched"},
+  {"id": "d", "content": "<font id=\"\" path=\"Sans.ttf\"><color=(1,1,1,1)><size=8>[Helo world]</size></color></font>"},
+  {"id": "e", "content": "<size=8>[Two]</size> <color=(1,0,0,1)>[runs]</color>"},
+  {"id": "f", "content": "<size=8>[Styled &amp; same]</size>"}
+]}, "tracks": [{"type": "text", "segments": []}], "version": 360000}`
+	want := `{"materials": {"videos": [{"id": "v"}], "texts": [
+  {"content": "Hi <there> & \"you\"", "id": "a", "words": []},
+  {"id": "b", "content" :  "Same", "font_name": "Sans"},
+  {"id": "c", "content": "Untouched"},
+  {"id": "d", "content": "<font id=\"\" path=\"Sans.ttf\"><color=(1,1,1,1)><size=8>[Hello &amp; &lt;world&gt;]</size></color></font>"},
+  {"id": "e", "content": "<size=8>[Two runs, one text]</size> <color=(1,0,0,1)>[]</color>"},
+  {"id": "f", "content": "<size=8>[Styled &amp; same]</size>"}
+]}, "tracks": [{"type": "text", "segments": []}], "version": 360000}`
+
+	var b strings.Builder
+	changed, err := SetTexts(strings.NewReader(draft), &b, map[string]string{
+		"a": `Hi <there> & "you"`, "b": "Same", "d": "Hello & <world>", "e": "Two runs, one text", "f": "Styled & same",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 3 {
+		t.Errorf("SetTexts() changed %d materials, want 3", changed)
+	}
+	if b.String() != want {
+		t.Errorf("SetTexts() wrote\n%s\nwant\n%s", b.String(), want)
+	}
+	if _, err := Decode(strings.NewReader(b.String())); err != nil {
+		t.Errorf("Decode() of the result: %v", err)
+	}
+
+	if _, err := SetTexts(strings.NewReader(draft), &strings.Builder{}, map[string]string{"z": "x"}); err == nil || !strings.Contains(err.Error(), "z not found") {
+		t.Errorf("SetTexts() with an unknown material = %v, want not found", err)
+	}
+}
@@ -0,0 +1,25 @@
+package convert
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut/capcuttest"
+)
+
+func BenchmarkConvert(b *testing.B) {
+	data, err := json.Marshal(capcuttest.Generate(4, 25000, 1))
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Convert(bytes.NewReader(data), io.Discard, NewOptions(WithDedup())); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
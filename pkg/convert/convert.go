@@ -0,0 +1,321 @@
+// Package convert turns CapCut drafts into subtitle files in one call, for
+// programs that embed the converter instead of running the command-line tool.
+package convert
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/transform"
+	"capcut-subtitle/pkg/writers"
+)
+
+// Options selects the passes applied between reading a draft and writing
+// its cues. The zero value writes SRT, cleans text with the default bracket
+// handling and otherwise keeps the draft's cues as they are. Options can be
+// set field by field or built with NewOptions.
+type Options struct {
+	// Format names the writer registered in package writers that renders
+	// the output; "" selects "srt".
+	Format string
+	// Writer, if set, renders the output instead of the writer registered
+	// for Format, which still names the format, for writers configured at
+	// run time such as an ASS style.
+	Writer      writers.Writer
+	Granularity capcut.Granularity
+	// Tracks limits the output to these text track numbers, counted from 1
+	// in draft order; empty selects every text track.
+	Tracks []int
+	// Filter leaves the text segments it does not select out of the draft
+	// before cues are made.
+	Filter capcut.Filter
+	// Offset shifts every cue by this many microseconds.
+	Offset int64
+	// KeepOriginal records the text of each cue as the draft stores it in
+	// the cue's Original.
+	KeepOriginal bool
+	// NoClean keeps material text exactly as stored in the draft.
+	NoClean  bool
+	Brackets subtitle.BracketMode
+	Emoji    subtitle.EmojiMode
+	Width    subtitle.WidthMode
+	Glossary subtitle.Glossary
+	// Fillers removes filler words, dropping the cues left without text.
+	Fillers subtitle.Fillers
+	// Grep, if set, keeps only the cues whose cleaned text it matches.
+	Grep *regexp.Regexp
+	// Speakers maps material IDs, track IDs or text track numbers to a
+	// name prefixed to the cue text.
+	Speakers map[string]string
+	Dedup    bool
+	// MaxChars wraps cue text to lines of at most this many characters;
+	// 0 disables wrapping.
+	MaxChars int
+	// MaxLines splits cues that need more than this many wrapped lines
+	// into consecutive cues sharing their time; 0 allows any number. It
+	// only applies with MaxChars.
+	MaxLines  int
+	LineShape subtitle.LineShape
+	// FPS snaps cue boundaries to frames at this rate; 0 disables snapping.
+	FPS      float64
+	Rounding subtitle.Rounding
+	Negative subtitle.NegativePolicy
+	// Punctuator, if set, restores the punctuation and capitalization of
+	// the cues once they are sorted. A cue whose words it changes keeps its
+	// text, with a warning, so the output keeps the draft's words and
+	// timing.
+	Punctuator Punctuator
+	// Sentences groups word cues into a cue for each sentence once they
+	// are sorted, for drafts with word timings but no sentence segments;
+	// see transform.Sentences. SentenceLang names the language whose rules
+	// apply, or "" for the language each track's script suggests, and
+	// SentenceGap, if positive, is the longest pause within a sentence in
+	// place of the language's.
+	Sentences    bool
+	SentenceLang string
+	SentenceGap  int64
+	// Capitalize capitalizes the first letter of each sentence and of the
+	// cues starting one; see subtitle.SentenceCase.
+	Capitalize bool
+	// MaxMemory, if positive, caps the bytes of cues ConvertContext and
+	// ConvertStreamContext hold at once; beyond it, sorted runs of cues are
+	// spilled to temporary files and merged while writing. The draft itself
+	// is still decoded into memory. It is ignored when Pipeline,
+	// Sentences, Punctuator or Skipped is set, and only formats with a
+	// writers.CueWriter can be written this way.
+	MaxMemory int64
+	// Skipped, if set, is called for every segment the conversion leaves
+	// out, with the reason, as the passes selected by the fields above
+	// drop its last cue.
+	Skipped func(Skip)
+	// Pipeline, if set, replaces the passes selected by the fields above;
+	// only Format, Granularity and Filter still apply.
+	Pipeline transform.Pipeline
+}
+
+// Punctuator restores punctuation and capitalization in lines of text,
+// returning one line for each, as a *punctuate.Client does. When only
+// some lines fail, it may return every line, the failed ones unchanged,
+// with the error, which the conversion then reports as a warning.
+type Punctuator interface {
+	Punctuate(ctx context.Context, texts []string) ([]string, error)
+}
+
+// Report summarizes a conversion.
+type Report struct {
+	// Cues is the number of cues written.
+	Cues     int
+	Warnings []Warning
+}
+
+// Convert reads a draft_content.json document from r and writes its
+// subtitles to w in opts.Format.
+func Convert(r io.Reader, w io.Writer, opts Options) (Report, error) {
+	return ConvertContext(context.Background(), r, w, opts)
+}
+
+// ConvertContext is like Convert but stops with ctx.Err() once ctx is
+// cancelled or its deadline passes, whether reading, transforming or
+// writing.
+func ConvertContext(ctx context.Context, r io.Reader, w io.Writer, opts Options) (Report, error) {
+	format := opts.Format
+	if format == "" {
+		format = "srt"
+	}
+	writer := opts.Writer
+	if writer == nil {
+		var err error
+		if writer, err = writers.Lookup(format); err != nil {
+			return Report{}, err
+		}
+	}
+
+	draft, err := capcut.Decode(contextReader{ctx, r})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Report{}, ctxErr
+		}
+		return Report{}, err
+	}
+
+	if opts.spills() {
+		cueWriter, err := writers.NewCueWriter(contextWriter{ctx, w}, format)
+		if err != nil {
+			return Report{}, err
+		}
+		report, err := spill(ctx, draft, cueWriter.WriteCue, opts)
+		if err != nil {
+			return Report{}, err
+		}
+		return report, cueWriter.Close()
+	}
+
+	cues, warnings, err := CuesContext(ctx, draft, opts)
+	if err != nil {
+		return Report{}, err
+	}
+	subs := subtitle.Subtitles(cues)
+	if err := writer.Write(contextWriter{ctx, w}, &subs); err != nil {
+		return Report{}, err
+	}
+	return Report{Cues: len(cues), Warnings: warnings}, nil
+}
+
+// Cues collects the cues of every text track of draft in chronological
+// order, applying the passes selected in opts. It also returns warnings
+// about segments it skipped or adjusted.
+func Cues(draft capcut.DraftContent, opts Options) ([]subtitle.Cue, []Warning, error) {
+	return CuesContext(context.Background(), draft, opts)
+}
+
+// CuesContext is like Cues but checks ctx between passes.
+//
+// Both return capcut.ErrNoTextTracks for drafts without a text track.
+func CuesContext(ctx context.Context, draft capcut.DraftContent, opts Options) ([]subtitle.Cue, []Warning, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+	if !draft.HasTextTracks() {
+		return nil, nil, capcut.ErrNoTextTracks
+	}
+	textMap := capcut.BuildTextMap(draft.Materials.Texts)
+	warnings := missingMaterials(draft, textMap)
+	tracks := opts.Filter.Tracks(draft.Tracks, textMap)
+	if skipped := opts.Skipped; skipped != nil {
+		skipDecoded(draft, tracks, textMap, skipped)
+		opts.Skipped = func(s Skip) {
+			s.Text = textMap[s.Source.MaterialID].Content
+			skipped(s)
+		}
+	}
+
+	subs := subtitle.Subtitles(capcut.Cues(tracks, textMap, opts.Granularity))
+	more, err := Apply(ctx, &subs, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return subs, append(warnings, more...), nil
+}
+
+// Apply runs the passes selected in opts on cues from any source, such as
+// an existing subtitle file, and returns the warnings they raised.
+func Apply(ctx context.Context, subs *subtitle.Subtitles, opts Options) ([]Warning, error) {
+	var warnings []Warning
+	pipeline := opts.Pipeline
+	if pipeline == nil {
+		pipeline = opts.transforms(ctx, func(w Warning) { warnings = append(warnings, w) })
+	}
+	if err := pipeline.RunContext(ctx, subs); err != nil {
+		return nil, err
+	}
+	return warnings, nil
+}
+
+// transforms builds the pipeline selected by the option fields. warn is
+// called for cues that are dropped or written out of the ordinary.
+func (opts Options) transforms(ctx context.Context, warn func(Warning)) transform.Pipeline {
+	p := opts.segmentTransforms(warn)
+	p = append(p, transform.Sort())
+	if opts.Sentences {
+		p = append(p, transform.Sentences(opts.SentenceLang, opts.SentenceGap))
+	}
+	if opts.Dedup {
+		p = append(p, opts.skipping(transform.Dedup(), SkipDuplicate))
+	}
+	p = append(p, transform.Negative(opts.Negative))
+	if opts.Punctuator != nil {
+		p = append(p, punctuate(ctx, opts.Punctuator, warn))
+	}
+	if opts.Capitalize {
+		p = append(p, transform.Capitalize())
+	}
+	if len(opts.Speakers) > 0 {
+		p = append(p, transform.Speakers(opts.Speakers))
+	}
+	return append(p, opts.cueTransforms()...)
+}
+
+// punctuate restores the punctuation of every cue with p, keeping the
+// text of cues whose words p changes.
+func punctuate(ctx context.Context, p Punctuator, warn func(Warning)) transform.Transform {
+	return func(subs *subtitle.Subtitles) error {
+		texts := make([]string, len(*subs))
+		for i, c := range *subs {
+			texts[i] = c.Text
+		}
+		restored, err := p.Punctuate(ctx, texts)
+		if err != nil && len(restored) != len(texts) {
+			return err
+		}
+		if err != nil {
+			warn(Warning{Message: fmt.Sprintf("%v; those cues keep their punctuation", err)})
+		}
+		if len(restored) != len(texts) {
+			return fmt.Errorf("punctuation returned %d lines for %d cues", len(restored), len(texts))
+		}
+		for i := range *subs {
+			c := &(*subs)[i]
+			if !subtitle.SameWords(c.Text, restored[i]) {
+				warn(Warning{Source: c.Source, Message: fmt.Sprintf("punctuation changed the words to %q and is not applied", restored[i])})
+				continue
+			}
+			c.Text = restored[i]
+		}
+		return nil
+	}
+}
+
+// segmentTransforms returns the passes before sorting, which only need the
+// cues of one segment at a time.
+func (opts Options) segmentTransforms(warn func(Warning)) transform.Pipeline {
+	var p transform.Pipeline
+	if opts.KeepOriginal {
+		p = append(p, transform.KeepOriginal())
+	}
+	if len(opts.Tracks) > 0 {
+		p = append(p, opts.skipping(transform.Tracks(opts.Tracks...), SkipFiltered))
+	}
+	if opts.Offset != 0 {
+		p = append(p, transform.Offset(opts.Offset))
+	}
+	if !opts.NoClean {
+		p = append(p, transform.Clean(opts.Brackets))
+	}
+	if opts.Emoji != subtitle.EmojiKeep {
+		p = append(p, transform.Emoji(opts.Emoji))
+	}
+	if opts.Width != subtitle.WidthKeep {
+		p = append(p, transform.Width(opts.Width))
+	}
+	if len(opts.Glossary) > 0 {
+		p = append(p, transform.Glossary(opts.Glossary))
+	}
+	p = append(p, checkCues(opts.Negative, warn), opts.skipping(transform.DropEmpty(), SkipEmpty))
+	if !opts.Fillers.IsZero() {
+		p = append(p, opts.skipping(transform.Fillers(opts.Fillers), SkipFillers))
+	}
+	if opts.Grep != nil {
+		p = append(p, opts.skipping(transform.Grep(opts.Grep), SkipNoMatch))
+	}
+	return p
+}
+
+// cueTransforms returns the passes after the negative-time policy, which
+// look at one cue at a time, though MaxLines may turn it into several.
+func (opts Options) cueTransforms() transform.Pipeline {
+	var p transform.Pipeline
+	if opts.MaxChars > 0 && opts.MaxLines > 0 {
+		p = append(p, transform.MaxLines(opts.MaxChars, opts.MaxLines))
+	}
+	if opts.FPS > 0 {
+		p = append(p, transform.SnapFrames(opts.FPS, opts.Rounding))
+	}
+	if opts.MaxChars > 0 {
+		p = append(p, transform.Wrap(opts.MaxChars, opts.LineShape))
+	}
+	return p
+}
@@ -0,0 +1,932 @@
+package convert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/capcut/capcuttest"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+func TestCues(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracks []capcut.Track
+		texts  []capcut.TextMaterial
+		opts   Options
+		want   string
+	}{
+		{
+			name:   "empty text track",
+			tracks: []capcut.Track{{Type: "text"}},
+			texts:  []capcut.TextMaterial{},
+			want:   "",
+		},
+		{
+			name: "text track with words",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "Full content",
+					Words: []capcut.Word{
+						{Begin: 1000000, End: 1500000, Text: "Hello"},
+						{Begin: 1500000, End: 3000000, Text: "world"},
+					},
+				},
+			},
+			want: `1
+00:00:01,000 --> 00:00:01,500
+Hello
+
+2
+00:00:01,500 --> 00:00:03,000
+world
+
+`,
+		},
+		{
+			name: "text track without words",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "Hello world",
+					Words:   []capcut.Word{},
+				},
+			},
+			want: `1
+00:00:01,000 --> 00:00:03,000
+Hello world
+
+`,
+		},
+		{
+			name: "multiple segments",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+						{
+							MaterialID: "2",
+							TargetTimerange: capcut.Timerange{
+								Start:    4000000,
+								Duration: 1000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "First segment",
+					Words:   []capcut.Word{},
+				},
+				{
+					ID:      "2",
+					Content: "Second segment",
+					Words:   []capcut.Word{},
+				},
+			},
+			want: `1
+00:00:01,000 --> 00:00:03,000
+First segment
+
+2
+00:00:04,000 --> 00:00:05,000
+Second segment
+
+`,
+		},
+		{
+			name: "non-text track ignored",
+			tracks: []capcut.Track{
+				{
+					Type: "video",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+					},
+				},
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "2",
+							TargetTimerange: capcut.Timerange{
+								Start:    4000000,
+								Duration: 1000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "This should be ignored",
+					Words:   []capcut.Word{},
+				},
+				{
+					ID:      "2",
+					Content: "This should be included",
+					Words:   []capcut.Word{},
+				},
+			},
+			want: `1
+00:00:04,000 --> 00:00:05,000
+This should be included
+
+`,
+		},
+		{
+			name: "missing material ID",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+						{
+							MaterialID: "999", // Doesn't exist in textMap
+							TargetTimerange: capcut.Timerange{
+								Start:    4000000,
+								Duration: 1000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "This should be included",
+					Words:   []capcut.Word{},
+				},
+			},
+			want: `1
+00:00:01,000 --> 00:00:03,000
+This should be included
+
+`,
+		},
+		{
+			name: "text with tags and special characters",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "<b>Hello</b> &lt;world&gt; [test]",
+					Words:   []capcut.Word{},
+				},
+			},
+			want: `1
+00:00:01,000 --> 00:00:03,000
+Hello <world> test
+
+`,
+		},
+		{
+			name: "cleaning disabled",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{
+							MaterialID: "1",
+							TargetTimerange: capcut.Timerange{
+								Start:    1000000,
+								Duration: 2000000,
+							},
+						},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{
+					ID:      "1",
+					Content: "<b>Hello</b> &lt;world&gt; [test]",
+					Words:   []capcut.Word{},
+				},
+			},
+			opts: Options{NoClean: true},
+			want: `1
+00:00:01,000 --> 00:00:03,000
+<b>Hello</b> &lt;world&gt; [test]
+
+`,
+		},
+		{
+			name: "cue empty after removing annotations",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+						{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "1", Content: "[music]"},
+				{ID: "2", Content: "Hello [laughs]"},
+			},
+			opts: Options{Brackets: subtitle.BracketsRemove},
+			want: `1
+00:00:01,000 --> 00:00:02,000
+Hello
+
+`,
+		},
+		{
+			name: "cues sorted across text tracks",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 3000000, Duration: 1000000}},
+					},
+				},
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+						{MaterialID: "3", TargetTimerange: capcut.Timerange{Start: 3000000, Duration: 500000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "1", Content: "Second"},
+				{ID: "2", Content: "First"},
+				{ID: "3", Content: "Third"},
+			},
+			want: `1
+00:00:01,000 --> 00:00:02,000
+First
+
+2
+00:00:03,000 --> 00:00:04,000
+Second
+
+3
+00:00:03,000 --> 00:00:03,500
+Third
+
+`,
+		},
+		{
+			name: "speaker prefixes",
+			tracks: []capcut.Track{
+				{
+					ID:   "track-a",
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "m1", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+						{MaterialID: "m2", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+					},
+				},
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "m3", TargetTimerange: capcut.Timerange{Start: 2000000, Duration: 1000000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "m1", Content: "Welcome"},
+				{ID: "m2", Words: []capcut.Word{
+					{Begin: 1000000, End: 1500000, Text: "Hi"},
+					{Begin: 1500000, End: 2000000, Text: "there"},
+				}},
+				{ID: "m3", Content: "Thanks"},
+			},
+			opts: Options{Speakers: map[string]string{
+				"m1":      "HOST",
+				"track-a": "GUEST",
+				"2":       "ANN",
+			}},
+			want: `1
+00:00:00,000 --> 00:00:01,000
+HOST: Welcome
+
+2
+00:00:01,000 --> 00:00:01,500
+GUEST: Hi
+
+3
+00:00:01,500 --> 00:00:02,000
+there
+
+4
+00:00:02,000 --> 00:00:03,000
+ANN: Thanks
+
+`,
+		},
+		{
+			name: "segment granularity, selected track and offset",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 2000000}},
+					},
+				},
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "1", Content: "Hello world", Words: []capcut.Word{
+					{Begin: 1000000, End: 1500000, Text: "Hello"},
+					{Begin: 1500000, End: 3000000, Text: "world"},
+				}},
+				{ID: "2", Content: "Other track"},
+			},
+			opts: NewOptions(
+				WithGranularity(capcut.GranularitySegments),
+				WithTracks(1),
+				WithOffset(-500000),
+			),
+			want: `1
+00:00:00,500 --> 00:00:02,500
+Hello world
+
+`,
+		},
+		{
+			name: "filtered by track name and material type",
+			tracks: []capcut.Track{
+				{
+					Type: "text", Name: "Dialogue",
+					Segments: []capcut.Segment{
+						{MaterialID: "title", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+						{MaterialID: "caption", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+					},
+				},
+				{
+					Type: "text", Name: "Lower thirds",
+					Segments: []capcut.Segment{
+						{MaterialID: "lower", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "title", Type: "text", Content: "Day 1"},
+				{ID: "caption", Type: "subtitle", Content: "We made it"},
+				{ID: "lower", Type: "subtitle", Content: "Nok, host"},
+			},
+			opts: NewOptions(
+				WithFilter(capcut.Filter{ExcludeTracks: regexp.MustCompile(`(?i)lower`), ExcludeMaterials: regexp.MustCompile(`^text$`)}),
+				WithGranularity(capcut.GranularitySegments),
+			),
+			want: `1
+00:00:01,000 --> 00:00:02,000
+We made it
+
+`,
+		},
+		{
+			name: "grep",
+			tracks: []capcut.Track{
+				{
+					Type: "text",
+					Segments: []capcut.Segment{
+						{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+						{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+						{MaterialID: "3", TargetTimerange: capcut.Timerange{Start: 2000000, Duration: 1000000}},
+					},
+				},
+			},
+			texts: []capcut.TextMaterial{
+				{ID: "1", Content: "<b>Acme</b> keeps us going"},
+				{ID: "2", Content: "On to the recipe"},
+				{ID: "3", Content: "Thanks again, ACME"},
+			},
+			// Matched after cleaning, so the first cue's tags do not hide it.
+			opts: NewOptions(WithGrep(regexp.MustCompile(`(?i)^acme\b|acme$`))),
+			want: `1
+00:00:00,000 --> 00:00:01,000
+Acme keeps us going
+
+2
+00:00:02,000 --> 00:00:03,000
+Thanks again, ACME
+
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draft := capcut.DraftContent{Tracks: tt.tracks}
+			draft.Materials.Texts = tt.texts
+
+			cues, _, err := Cues(draft, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writers.WriteSRT(&buf, cues); err != nil {
+				t.Fatal(err)
+			}
+			got := buf.String()
+			if got != tt.want {
+				t.Errorf("Cues() = \n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	input := `{
+		"materials": {"texts": [{"id": "1", "content": "<b>Hello</b> world"}]},
+		"tracks": [{"type": "text", "segments": [
+			{"material_id": "1", "target_timerange": {"start": 1000000, "duration": 2000000}}
+		]}]
+	}`
+
+	var out bytes.Buffer
+	report, err := Convert(strings.NewReader(input), &out, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Cues != 1 {
+		t.Errorf("Convert() report = %+v, want 1 cue", report)
+	}
+
+	want := "1\n00:00:01,000 --> 00:00:03,000\nHello world\n\n"
+	if got := out.String(); got != want {
+		t.Errorf("Convert() = %q, want %q", got, want)
+	}
+
+	if _, err := Convert(strings.NewReader("{invalid"), &out, Options{}); err == nil {
+		t.Error("Convert() expected error for invalid JSON")
+	}
+	if _, err := Convert(strings.NewReader(input), &out, NewOptions(WithFormat("sub"))); err == nil {
+		t.Error("Convert() expected error for unsupported format")
+	}
+}
+
+func TestConvertContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var out bytes.Buffer
+	_, err := ConvertContext(ctx, strings.NewReader(`{"tracks": []}`), &out, Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ConvertContext() error = %v, want %v", err, context.Canceled)
+	}
+	if out.Len() != 0 {
+		t.Errorf("ConvertContext() wrote %q after cancellation", out.String())
+	}
+
+	if _, _, err := CuesContext(ctx, capcut.DraftContent{}, Options{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("CuesContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestCuesWarnings(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{{
+		ID:   "t1",
+		Type: "text",
+		Segments: []capcut.Segment{
+			{MaterialID: "gone", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+			{MaterialID: "empty", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+			{MaterialID: "early", TargetTimerange: capcut.Timerange{Start: -500000, Duration: 1000000}},
+		},
+	}}}
+	draft.Materials.Texts = []capcut.TextMaterial{
+		{ID: "empty", Content: "<b></b>"},
+		{ID: "early", Content: "Hello"},
+	}
+
+	_, warnings, err := Cues(draft, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.String())
+	}
+	want := []string{
+		`text track 1, segment 1: material "gone" not found; segment skipped`,
+		"text track 1, segment 2: cue has no text and is dropped",
+		"text track 1, segment 3: cue starts before 00:00:00 and is clamped to zero",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Cues() warnings = %q, want %q", got, want)
+	}
+}
+
+// punctuatorFunc is a Punctuator calling itself.
+type punctuatorFunc func(ctx context.Context, texts []string) ([]string, error)
+
+func (f punctuatorFunc) Punctuate(ctx context.Context, texts []string) ([]string, error) {
+	return f(ctx, texts)
+}
+
+func TestCuesPunctuator(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{{
+		Type: "text",
+		Segments: []capcut.Segment{
+			{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 2000000, Duration: 1000000}},
+			{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 0, Duration: 2000000}},
+		},
+	}}}
+	draft.Materials.Texts = []capcut.TextMaterial{
+		{ID: "1", Content: "so we went home"},
+		{ID: "2", Content: "and then gonna eat"},
+	}
+	var sent []string
+	punctuator := punctuatorFunc(func(ctx context.Context, texts []string) ([]string, error) {
+		sent = texts
+		return []string{"So, we went home.", "And then going to eat."}, nil
+	})
+
+	cues, warnings, err := Cues(draft, NewOptions(WithPunctuator(punctuator), WithWrap(12, subtitle.ShapeBottomHeavy)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Cues are sent in time order, before wrapping.
+	if want := []string{"so we went home", "and then gonna eat"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+	want := []subtitle.Cue{
+		{Start: 0, End: 2000000, Text: "So, we\nwent home.", Source: subtitle.Source{MaterialID: "1", TrackNumber: 1, Segment: 1}},
+		{Start: 2000000, End: 3000000, Text: "and then\ngonna eat", Source: subtitle.Source{MaterialID: "2", TrackNumber: 1}},
+	}
+	if !reflect.DeepEqual(cues, want) {
+		t.Errorf("Cues() = %+v, want %+v", cues, want)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0].String(), `changed the words to "And then going to eat."`) {
+		t.Errorf("Cues() warnings = %v, want one about the changed words", warnings)
+	}
+}
+
+func TestCuesSpeakersAfterText(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{{
+		Type: "text",
+		Segments: []capcut.Segment{
+			{MaterialID: "1", TargetTimerange: capcut.Timerange{Start: 0, Duration: 600000}},
+			{MaterialID: "2", TargetTimerange: capcut.Timerange{Start: 800000, Duration: 600000}},
+		},
+	}}}
+	draft.Materials.Texts = []capcut.TextMaterial{
+		{ID: "1", Words: []capcut.Word{{Begin: 0, End: 300000, Text: "so"}, {Begin: 300000, End: 600000, Text: "we"}}},
+		{ID: "2", Words: []capcut.Word{{Begin: 800000, End: 1100000, Text: "went"}, {Begin: 1100000, End: 1400000, Text: "home"}}},
+	}
+	var sent []string
+	punctuator := punctuatorFunc(func(ctx context.Context, texts []string) ([]string, error) {
+		sent = texts
+		return []string{"so we went home."}, nil
+	})
+
+	cues, _, err := Cues(draft, NewOptions(
+		WithSpeakers(map[string]string{"1": "Bob"}),
+		WithSentences("en", 0),
+		WithPunctuator(punctuator),
+		WithCapitalize(),
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sentence is punctuated and capitalized without the name, which
+	// it gets once, though its words come from two segments.
+	if want := []string{"so we went home"}; !reflect.DeepEqual(sent, want) {
+		t.Errorf("sent %q, want %q", sent, want)
+	}
+	if len(cues) != 1 || cues[0].Text != "Bob: So we went home." || cues[0].Speaker != "Bob" {
+		t.Errorf("Cues() = %+v, want one cue of Bob's", cues)
+	}
+}
+
+func TestCuesSkipped(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{
+		{Type: "text", Segments: []capcut.Segment{
+			{MaterialID: "hello", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+			{MaterialID: "gone", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+			{MaterialID: "blank", TargetTimerange: capcut.Timerange{Start: 2000000, Duration: 1000000}},
+			{MaterialID: "um", TargetTimerange: capcut.Timerange{Start: 3000000, Duration: 1000000}},
+			{MaterialID: "bye", TargetTimerange: capcut.Timerange{Start: 4000000, Duration: 1000000}},
+			{MaterialID: "again", TargetTimerange: capcut.Timerange{Start: 500000, Duration: 1000000}},
+			{MaterialID: "sticker", TargetTimerange: capcut.Timerange{Start: 5000000, Duration: 1000000}},
+			{MaterialID: "bye", TargetTimerange: capcut.Timerange{Start: 6000000, Duration: 1000000}, Visible: new(bool)},
+		}},
+		{Type: "text", Name: "Titles", Segments: []capcut.Segment{
+			{MaterialID: "title", TargetTimerange: capcut.Timerange{Start: 0, Duration: 3000000}},
+		}},
+		{Type: "text", Attribute: capcut.TrackHidden, Segments: []capcut.Segment{
+			{MaterialID: "hello", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+		}},
+	}}
+	draft.OtherMaterials = map[string]string{"sticker": "stickers"}
+	draft.Materials.Texts = []capcut.TextMaterial{
+		{ID: "hello", Content: "Hello"},
+		{ID: "blank", Content: "<b></b>"},
+		{ID: "um", Content: "um"},
+		{ID: "bye", Content: "Goodbye"},
+		{ID: "again", Content: "Hello"},
+		{ID: "title", Content: "Episode 1"},
+	}
+
+	var skipped []Skip
+	opts := NewOptions(
+		WithFilter(capcut.Filter{ExcludeTracks: regexp.MustCompile("Titles")}),
+		WithFillers(subtitle.NewFillers(subtitle.DefaultFillers)),
+		WithGrep(regexp.MustCompile("Hello")),
+		WithDedup(),
+		WithSkipped(func(s Skip) { skipped = append(skipped, s) }),
+	)
+	cues, _, err := Cues(draft, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cues) != 1 || cues[0].Text != "Hello" {
+		t.Errorf("Cues() = %+v, want the first Hello", cues)
+	}
+	want := []Skip{
+		{Source: subtitle.Source{MaterialID: "gone", TrackNumber: 1, Segment: 1}, Reason: SkipMissingMaterial},
+		{Source: subtitle.Source{MaterialID: "sticker", TrackNumber: 1, Segment: 6}, Reason: SkipNonText},
+		{Source: subtitle.Source{MaterialID: "bye", TrackNumber: 1, Segment: 7}, Text: "Goodbye", Reason: SkipHidden},
+		{Source: subtitle.Source{MaterialID: "title", TrackNumber: 2}, Text: "Episode 1", Reason: SkipFiltered},
+		{Source: subtitle.Source{MaterialID: "hello", TrackNumber: 3}, Text: "Hello", Reason: SkipHidden},
+		{Source: subtitle.Source{MaterialID: "blank", TrackNumber: 1, Segment: 2}, Text: "<b></b>", Reason: SkipEmpty},
+		{Source: subtitle.Source{MaterialID: "um", TrackNumber: 1, Segment: 3}, Text: "um", Reason: SkipFillers},
+		{Source: subtitle.Source{MaterialID: "bye", TrackNumber: 1, Segment: 4}, Text: "Goodbye", Reason: SkipNoMatch},
+		{Source: subtitle.Source{MaterialID: "again", TrackNumber: 1, Segment: 5}, Text: "Hello", Reason: SkipDuplicate},
+	}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %+v, want %+v", skipped, want)
+	}
+}
+
+func TestCuesFilteredSegments(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{
+		{Type: "text", Segments: []capcut.Segment{
+			{MaterialID: "title", TargetTimerange: capcut.Timerange{Start: 0, Duration: 1000000}},
+			{MaterialID: "caption", TargetTimerange: capcut.Timerange{Start: 1000000, Duration: 1000000}},
+		}},
+	}}
+	draft.Materials.Texts = []capcut.TextMaterial{
+		{ID: "title", Type: "text", Content: "Episode 1"},
+		{ID: "caption", Type: "subtitle", Content: "Hello"},
+	}
+
+	var skipped []Skip
+	opts := NewOptions(
+		WithFilter(capcut.Filter{ExcludeMaterials: regexp.MustCompile("^text$")}),
+		WithSkipped(func(s Skip) { skipped = append(skipped, s) }),
+	)
+	cues, _, err := Cues(draft, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cues) != 1 || cues[0].Source.Segment != 1 {
+		t.Errorf("Cues() = %+v, want the caption as segment 1", cues)
+	}
+	want := []Skip{{Source: subtitle.Source{MaterialID: "title", TrackNumber: 1}, Text: "Episode 1", Reason: SkipFiltered}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %+v, want %+v", skipped, want)
+	}
+}
+
+func TestConvertStream(t *testing.T) {
+	input := `{
+		"materials": {"texts": [
+			{"id": "1", "content": "Second"},
+			{"id": "2", "content": "First"}
+		]},
+		"tracks": [{"type": "text", "segments": [
+			{"material_id": "1", "target_timerange": {"start": 2000000, "duration": 1000000}},
+			{"material_id": "2", "target_timerange": {"start": 0, "duration": 1000000}}
+		]}]
+	}`
+
+	var texts []string
+	report, err := ConvertStream(strings.NewReader(input), func(c subtitle.Cue) error {
+		texts = append(texts, c.Text)
+		return nil
+	}, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"First", "Second"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("ConvertStream() delivered %v, want %v", texts, want)
+	}
+	if report.Cues != 2 {
+		t.Errorf("ConvertStream() report = %+v, want 2 cues", report)
+	}
+
+	stop := errors.New("stop")
+	report, err = ConvertStream(strings.NewReader(input), func(subtitle.Cue) error { return stop }, Options{})
+	if !errors.Is(err, stop) {
+		t.Errorf("ConvertStream() error = %v, want the callback's error", err)
+	}
+	if report.Cues != 0 {
+		t.Errorf("ConvertStream() counted %d cues after the callback failed", report.Cues)
+	}
+}
+
+func TestCuesNoTextTracks(t *testing.T) {
+	draft := capcut.DraftContent{Tracks: []capcut.Track{{Type: "video"}}}
+	if _, _, err := Cues(draft, Options{}); !errors.Is(err, capcut.ErrNoTextTracks) {
+		t.Errorf("Cues() error = %v, want %v", err, capcut.ErrNoTextTracks)
+	}
+}
+
+func TestConvertMaxMemory(t *testing.T) {
+	input := `{
+		"materials": {"texts": [
+			{"id": "1", "content": "Title"},
+			{"id": "2", "content": "Later", "words": [
+				{"begin": 4000000, "end": 4500000, "text": "Later"},
+				{"begin": 4500000, "end": 5000000, "text": "on"}
+			]},
+			{"id": "3", "content": "Early bird catches the worm"},
+			{"id": "4", "content": "Second track"}
+		]},
+		"tracks": [
+			{"id": "a", "type": "text", "segments": [
+				{"material_id": "1", "target_timerange": {"start": 0, "duration": 2000000}},
+				{"material_id": "2", "target_timerange": {"start": 4000000, "duration": 1000000}},
+				{"material_id": "3", "target_timerange": {"start": -1000000, "duration": 2000000}}
+			]},
+			{"id": "b", "type": "text", "segments": [
+				{"material_id": "1", "target_timerange": {"start": 1000000, "duration": 2000000}},
+				{"material_id": "4", "target_timerange": {"start": 0, "duration": 1000000}}
+			]}
+		]
+	}`
+	base := []Option{
+		WithDedup(),
+		WithSpeakers(map[string]string{"b": "Bo"}),
+		WithNegativePolicy(subtitle.NegativeOffset),
+		WithWrap(12, subtitle.ShapeBalanced),
+		WithCapitalize(),
+	}
+
+	for _, format := range []string{"srt", "vtt"} {
+		t.Run(format, func(t *testing.T) {
+			opts := NewOptions(append(base, WithFormat(format))...)
+			var want bytes.Buffer
+			wantReport, err := Convert(strings.NewReader(input), &want, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// A one-byte budget spills every segment to its own run.
+			opts = NewOptions(append(base, WithFormat(format), WithMaxMemory(1))...)
+			var got bytes.Buffer
+			report, err := Convert(strings.NewReader(input), &got, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("Convert() with MaxMemory = %q, want %q", got.String(), want.String())
+			}
+			if !reflect.DeepEqual(report, wantReport) {
+				t.Errorf("Convert() with MaxMemory report = %+v, want %+v", report, wantReport)
+			}
+		})
+	}
+
+	opts := NewOptions(WithFormat("json"), WithMaxMemory(1))
+	if _, err := Convert(strings.NewReader(input), io.Discard, opts); err == nil {
+		t.Error("Convert() expected error for a format without a cue writer")
+	}
+}
+
+func TestConvertMaxMemoryManyRuns(t *testing.T) {
+	// More runs than maxOpenRuns are merged in several passes.
+	draft := capcuttest.Generate(2, 8*(maxOpenRuns*2+5), 1)
+	data, err := json.Marshal(draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want, got bytes.Buffer
+	if _, err := Convert(bytes.NewReader(data), &want, NewOptions(WithDedup())); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Convert(bytes.NewReader(data), &got, NewOptions(WithDedup(), WithMaxMemory(1))); err != nil {
+		t.Fatal(err)
+	}
+	if got.String() != want.String() {
+		t.Error("Convert() with MaxMemory differs from the in-memory conversion")
+	}
+}
+
+func TestConvertSentences(t *testing.T) {
+	input := `{
+		"materials": {"texts": [
+			{"id": "1", "content": "", "words": [
+				{"begin": 0, "end": 400000, "text": "Hello"},
+				{"begin": 400000, "end": 800000, "text": "there."},
+				{"begin": 900000, "end": 1200000, "text": "Shall"},
+				{"begin": 1200000, "end": 1400000, "text": "we"}
+			]},
+			{"id": "2", "content": "", "words": [
+				{"begin": 3000000, "end": 3500000, "text": "go?"}
+			]},
+			{"id": "3", "content": "", "words": [
+				{"begin": 0, "end": 500000, "text": "สวัสดี"},
+				{"begin": 500000, "end": 900000, "text": "ครับ"},
+				{"begin": 1500000, "end": 2000000, "text": "ไป"}
+			]}
+		]},
+		"tracks": [
+			{"id": "a", "type": "text", "segments": [
+				{"material_id": "1", "target_timerange": {"start": 0, "duration": 1400000}},
+				{"material_id": "2", "target_timerange": {"start": 3000000, "duration": 500000}}
+			]},
+			{"id": "b", "type": "text", "segments": [
+				{"material_id": "3", "target_timerange": {"start": 0, "duration": 2000000}}
+			]}
+		]
+	}`
+	var got bytes.Buffer
+	if _, err := Convert(strings.NewReader(input), &got, NewOptions(WithSentences("", 0))); err != nil {
+		t.Fatal(err)
+	}
+	// The Thai track breaks at a shorter pause and joins its words
+	// without spaces; the English one breaks at sentence marks and at the
+	// long pause before "go?".
+	want := "1\n00:00:00,000 --> 00:00:00,800\nHello there.\n\n" +
+		"2\n00:00:00,000 --> 00:00:00,900\nสวัสดีครับ\n\n" +
+		"3\n00:00:00,900 --> 00:00:01,400\nShall we\n\n" +
+		"4\n00:00:01,500 --> 00:00:02,000\nไป\n\n" +
+		"5\n00:00:03,000 --> 00:00:03,500\ngo?\n\n"
+	if got.String() != want {
+		t.Errorf("Convert() =\n%s\nwant\n%s", got.String(), want)
+	}
+}
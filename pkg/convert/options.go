@@ -0,0 +1,158 @@
+package convert
+
+import (
+	"regexp"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/transform"
+)
+
+// An Option sets one field of Options. Options are applied in order, so a
+// later option overrides an earlier one for the same field.
+type Option func(*Options)
+
+// NewOptions returns the zero Options with opts applied.
+func NewOptions(opts ...Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// WithFormat selects the output format.
+func WithFormat(format string) Option {
+	return func(o *Options) { o.Format = format }
+}
+
+// WithGranularity selects word or segment cues.
+func WithGranularity(g capcut.Granularity) Option {
+	return func(o *Options) { o.Granularity = g }
+}
+
+// WithTracks limits the output to the given text track numbers.
+func WithTracks(numbers ...int) Option {
+	return func(o *Options) { o.Tracks = numbers }
+}
+
+// WithFilter leaves the text segments f does not select out of the draft.
+func WithFilter(f capcut.Filter) Option {
+	return func(o *Options) { o.Filter = f }
+}
+
+// WithOffset shifts every cue by offset microseconds.
+func WithOffset(offset int64) Option {
+	return func(o *Options) { o.Offset = offset }
+}
+
+// WithOriginal records the text of each cue as the draft stores it.
+func WithOriginal() Option {
+	return func(o *Options) { o.KeepOriginal = true }
+}
+
+// WithoutCleaning keeps material text exactly as stored in the draft.
+func WithoutCleaning() Option {
+	return func(o *Options) { o.NoClean = true }
+}
+
+// WithBrackets selects how bracketed text is cleaned.
+func WithBrackets(mode subtitle.BracketMode) Option {
+	return func(o *Options) { o.Brackets = mode }
+}
+
+// WithEmoji selects how emoji and their shortcodes are rewritten.
+func WithEmoji(mode subtitle.EmojiMode) Option {
+	return func(o *Options) { o.Emoji = mode }
+}
+
+// WithWidth selects the width of Latin letters, digits and punctuation.
+func WithWidth(mode subtitle.WidthMode) Option {
+	return func(o *Options) { o.Width = mode }
+}
+
+// WithGlossary applies g to every cue.
+func WithGlossary(g subtitle.Glossary) Option {
+	return func(o *Options) { o.Glossary = g }
+}
+
+// WithFillers removes the filler words of f.
+func WithFillers(f subtitle.Fillers) Option {
+	return func(o *Options) { o.Fillers = f }
+}
+
+// WithGrep keeps only the cues whose text matches re.
+func WithGrep(re *regexp.Regexp) Option {
+	return func(o *Options) { o.Grep = re }
+}
+
+// WithSpeakers prefixes cues with the speaker names in speakers.
+func WithSpeakers(speakers map[string]string) Option {
+	return func(o *Options) { o.Speakers = speakers }
+}
+
+// WithDedup merges repeated overlapping cues.
+func WithDedup() Option {
+	return func(o *Options) { o.Dedup = true }
+}
+
+// WithWrap wraps cue text to lines of at most maxChars characters.
+func WithWrap(maxChars int, shape subtitle.LineShape) Option {
+	return func(o *Options) {
+		o.MaxChars = maxChars
+		o.LineShape = shape
+	}
+}
+
+// WithMaxLines splits cues that need more than maxLines lines once
+// wrapped; it needs WithWrap.
+func WithMaxLines(maxLines int) Option {
+	return func(o *Options) { o.MaxLines = maxLines }
+}
+
+// WithFrameSnap snaps cue boundaries to frames at fps.
+func WithFrameSnap(fps float64, rounding subtitle.Rounding) Option {
+	return func(o *Options) {
+		o.FPS = fps
+		o.Rounding = rounding
+	}
+}
+
+// WithNegativePolicy selects how cues before zero are handled.
+func WithNegativePolicy(policy subtitle.NegativePolicy) Option {
+	return func(o *Options) { o.Negative = policy }
+}
+
+// WithPunctuator restores the punctuation and capitalization of the cues
+// with p.
+func WithPunctuator(p Punctuator) Option {
+	return func(o *Options) { o.Punctuator = p }
+}
+
+// WithSentences groups word cues into sentences by the rules of lang, or
+// of each track's script if lang is "", with gap, if positive, as the
+// longest pause within one.
+func WithSentences(lang string, gap int64) Option {
+	return func(o *Options) { o.Sentences, o.SentenceLang, o.SentenceGap = true, lang, gap }
+}
+
+// WithCapitalize capitalizes the first letter of each sentence.
+func WithCapitalize() Option {
+	return func(o *Options) { o.Capitalize = true }
+}
+
+// WithMaxMemory caps the bytes of cues held in memory, spilling the rest
+// to temporary files.
+func WithMaxMemory(bytes int64) Option {
+	return func(o *Options) { o.MaxMemory = bytes }
+}
+
+// WithSkipped calls fn for every segment the conversion leaves out.
+func WithSkipped(fn func(Skip)) Option {
+	return func(o *Options) { o.Skipped = fn }
+}
+
+// WithPipeline replaces the passes selected by the other options with p.
+func WithPipeline(p transform.Pipeline) Option {
+	return func(o *Options) { o.Pipeline = p }
+}
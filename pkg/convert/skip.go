@@ -0,0 +1,99 @@
+package convert
+
+import (
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/transform"
+)
+
+// SkipReason is why a segment was left out of the output.
+type SkipReason string
+
+const (
+	SkipMissingMaterial SkipReason = "material not found"
+	// SkipNonText is for segments of text tracks whose material is not a
+	// text, such as a sticker.
+	SkipNonText SkipReason = "not a text material"
+	// SkipHidden is for segments hidden in CapCut and those of hidden or
+	// muted tracks.
+	SkipHidden SkipReason = "hidden"
+	// SkipFiltered is for segments that Options.Filter or Options.Tracks
+	// leaves out.
+	SkipFiltered  SkipReason = "filtered out"
+	SkipEmpty     SkipReason = "empty after cleaning"
+	SkipFillers   SkipReason = "only filler words"
+	SkipNoMatch   SkipReason = "no grep match"
+	SkipDuplicate SkipReason = "duplicate of an earlier cue"
+)
+
+// Skip is a segment none of whose cues made it into the output.
+type Skip struct {
+	Source subtitle.Source
+	// Text is the text of the segment's material, or "" if the material is
+	// missing or the cues did not come from a draft.
+	Text   string
+	Reason SkipReason
+}
+
+// skipping wraps t to report every segment whose cues t drops all of to
+// opts.Skipped, if set, as skipped for reason.
+func (opts Options) skipping(t transform.Transform, reason SkipReason) transform.Transform {
+	if opts.Skipped == nil {
+		return t
+	}
+	return func(subs *subtitle.Subtitles) error {
+		var before []subtitle.Source
+		for i, c := range *subs {
+			if i == 0 || c.Source != (*subs)[i-1].Source {
+				before = append(before, c.Source)
+			}
+		}
+		if err := t(subs); err != nil {
+			return err
+		}
+		after := make(map[subtitle.Source]bool, len(*subs))
+		for _, c := range *subs {
+			after[c.Source] = true
+		}
+		for _, source := range before {
+			if !after[source] {
+				opts.Skipped(Skip{Source: source, Reason: reason})
+				after[source] = true
+			}
+		}
+		return nil
+	}
+}
+
+// skipDecoded reports the segments of draft that never become cues:
+// those whose material is missing or not a text, hidden ones and those
+// that filtered, the draft's tracks as Options.Filter leaves them, lacks.
+func skipDecoded(draft capcut.DraftContent, filtered []capcut.Track, textMap map[string]capcut.TextMaterial, skip func(Skip)) {
+	textTrackNumber := 0
+	for i, track := range draft.Tracks {
+		if track.Type != "text" {
+			continue
+		}
+		textTrackNumber++
+		// Filters keep segments in their place, leaving zero ones for
+		// those left out, or no segments for tracks left out.
+		kept := filtered[i].Segments
+		for j, segment := range track.Segments {
+			s := Skip{Source: subtitle.Source{MaterialID: segment.MaterialID, TrackID: track.ID, TrackNumber: textTrackNumber, Segment: j}}
+			material, found := textMap[segment.MaterialID]
+			switch {
+			case !found && draft.OtherMaterials[segment.MaterialID] != "":
+				s.Reason = SkipNonText
+			case !found:
+				s.Reason = SkipMissingMaterial
+			case track.Hidden() || segment.Hidden():
+				s.Text, s.Reason = material.Content, SkipHidden
+			case j >= len(kept) || kept[j] != segment:
+				s.Text, s.Reason = material.Content, SkipFiltered
+			default:
+				continue
+			}
+			skip(s)
+		}
+	}
+}
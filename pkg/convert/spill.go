@@ -0,0 +1,292 @@
+package convert
+
+import (
+	"bufio"
+	"cmp"
+	"container/heap"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"slices"
+	"time"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// cueOverhead approximates the memory a cue takes besides its strings.
+const cueOverhead = 80
+
+func (opts Options) spills() bool {
+	return opts.MaxMemory > 0 && opts.Pipeline == nil && !opts.Sentences && opts.Punctuator == nil && opts.Skipped == nil
+}
+
+// spill converts draft like CuesContext but keeps at most opts.MaxMemory
+// bytes of cues in memory, calling fn with each cue in output order. Cues
+// are transformed a segment at a time, sorted in runs that are written to
+// temporary files whenever the limit is reached, and merged back in start
+// time order. The merge is stable, so the output matches CuesContext.
+func spill(ctx context.Context, draft capcut.DraftContent, fn func(subtitle.Cue) error, opts Options) (Report, error) {
+	if err := ctx.Err(); err != nil {
+		return Report{}, err
+	}
+	if !draft.HasTextTracks() {
+		return Report{}, capcut.ErrNoTextTracks
+	}
+	textMap := capcut.BuildTextMap(draft.Materials.Texts)
+	report := Report{Warnings: missingMaterials(draft, textMap)}
+	warn := func(w Warning) { report.Warnings = append(report.Warnings, w) }
+
+	runs := &runSet{limit: opts.MaxMemory}
+	defer runs.remove()
+
+	// The negative-time policy needs the earliest time of the whole
+	// timeline, so it is found while the runs are collected.
+	var earliest int64
+	segmentPasses := opts.segmentTransforms(warn)
+	for segment := range capcut.Segments(opts.Filter.Tracks(draft.Tracks, textMap), textMap, opts.Granularity) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		batch := subtitle.Subtitles(slices.Clone(segment))
+		if err := segmentPasses.Run(&batch); err != nil {
+			return report, err
+		}
+		for _, c := range batch {
+			if first := min(c.Start, c.End); first < 0 {
+				if opts.Negative == subtitle.NegativeError {
+					return report, errors.New(Warning{Source: c.Source, Message: fmt.Sprintf("cue has a negative time (%v)", time.Duration(first)*time.Microsecond)}.String())
+				}
+				earliest = min(earliest, first)
+			}
+		}
+		if err := runs.add(batch); err != nil {
+			return report, err
+		}
+	}
+
+	cues, err := runs.merged()
+	if err != nil {
+		return report, err
+	}
+	if opts.Dedup {
+		cues = subtitle.DedupSorted(cues)
+	}
+	cuePasses := opts.cueTransforms()
+	var sentences subtitle.SentenceCase
+	speakers := subtitle.SpeakerPrefix{Speakers: opts.Speakers}
+	for c := range cues {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if opts.Negative == subtitle.NegativeOffset {
+			c.Start -= earliest
+			c.End -= earliest
+		}
+		if opts.Capitalize {
+			sentences.Apply(&c)
+		}
+		speakers.Apply(&c)
+		single := subtitle.Subtitles{c}
+		if err := cuePasses.Run(&single); err != nil {
+			return report, err
+		}
+		for _, c := range single {
+			if err := fn(c); err != nil {
+				return report, err
+			}
+			report.Cues++
+		}
+	}
+	return report, runs.err
+}
+
+// maxOpenRuns bounds how many spill files are read at once. Beyond it,
+// runs are first merged in groups into fewer, longer runs.
+const maxOpenRuns = 64
+
+// runSet collects cues into runs sorted by start time. The newest run stays
+// in memory until it outgrows the limit and is written to a temporary file.
+type runSet struct {
+	limit   int64
+	current subtitle.Subtitles
+	size    int64
+	files   []string
+	// err records the first failure reading a run back during a merge.
+	err error
+}
+
+func (r *runSet) add(cues []subtitle.Cue) error {
+	for _, c := range cues {
+		r.current = append(r.current, c)
+		r.size += cueOverhead + int64(len(c.Text)+len(c.Original)+len(c.Speaker)+len(c.Source.MaterialID)+len(c.Source.TrackID))
+	}
+	if r.size > r.limit {
+		r.current.Sort()
+		if err := r.write(slices.Values(r.current)); err != nil {
+			return err
+		}
+		r.current, r.size = r.current[:0], 0
+	}
+	return nil
+}
+
+// write stores a run in a new temporary file.
+func (r *runSet) write(run iter.Seq[subtitle.Cue]) error {
+	file, err := os.CreateTemp("", "capcut-subtitle-*.cues")
+	if err != nil {
+		return fmt.Errorf("failed to create spill file: %w", err)
+	}
+	r.files = append(r.files, file.Name())
+
+	w := bufio.NewWriter(file)
+	encoder := gob.NewEncoder(w)
+	for c := range run {
+		if err = encoder.Encode(c); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write spill file: %w", err)
+	}
+	return r.err
+}
+
+// merged yields the cues of every run in start time order. Cues starting
+// together come in run order, and runs are in draft order, so the result
+// is what a stable sort of all cues gives.
+func (r *runSet) merged() (iter.Seq[subtitle.Cue], error) {
+	for len(r.files) > maxOpenRuns {
+		files := r.files
+		r.files = nil
+		for start := 0; start < len(files); start += maxOpenRuns {
+			group := files[start:min(start+maxOpenRuns, len(files))]
+			runs := make([]iter.Seq[subtitle.Cue], len(group))
+			for i, name := range group {
+				runs[i] = r.read(name)
+			}
+			if err := r.write(merge(runs)); err != nil {
+				r.files = append(r.files, files[start:]...)
+				return nil, err
+			}
+			for _, name := range group {
+				os.Remove(name)
+			}
+		}
+	}
+
+	r.current.Sort()
+	runs := make([]iter.Seq[subtitle.Cue], 0, len(r.files)+1)
+	for _, name := range r.files {
+		runs = append(runs, r.read(name))
+	}
+	return merge(append(runs, slices.Values(r.current))), nil
+}
+
+// read yields the cues of a run written by write, keeping its file open
+// only while iterating.
+func (r *runSet) read(name string) iter.Seq[subtitle.Cue] {
+	return func(yield func(subtitle.Cue) bool) {
+		file, err := os.Open(name)
+		if err != nil {
+			r.fail(err)
+			return
+		}
+		defer file.Close()
+
+		decoder := gob.NewDecoder(bufio.NewReader(file))
+		for {
+			var c subtitle.Cue
+			if err := decoder.Decode(&c); err != nil {
+				if err != io.EOF {
+					r.fail(err)
+				}
+				return
+			}
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+func (r *runSet) fail(err error) {
+	if r.err == nil {
+		r.err = fmt.Errorf("failed to read spill file: %w", err)
+	}
+}
+
+// remove deletes the temporary files.
+func (r *runSet) remove() {
+	for _, name := range r.files {
+		os.Remove(name)
+	}
+}
+
+// merge yields the cues of sorted runs in start time order, taking cues
+// that start together from the earlier run first.
+func merge(runs []iter.Seq[subtitle.Cue]) iter.Seq[subtitle.Cue] {
+	return func(yield func(subtitle.Cue) bool) {
+		var heads cueHeap
+		for i, run := range runs {
+			next, stop := iter.Pull(run)
+			defer stop()
+			if c, ok := next(); ok {
+				heads = append(heads, runHead{cue: c, run: i, next: next})
+			}
+		}
+		heap.Init(&heads)
+
+		for len(heads) > 0 {
+			if !yield(heads[0].cue) {
+				return
+			}
+			if c, ok := heads[0].next(); ok {
+				heads[0].cue = c
+				heap.Fix(&heads, 0)
+			} else {
+				heap.Pop(&heads)
+			}
+		}
+	}
+}
+
+// runHead is the next cue of a run being merged.
+type runHead struct {
+	cue  subtitle.Cue
+	run  int
+	next func() (subtitle.Cue, bool)
+}
+
+// cueHeap orders run heads by start time, then by run, for heap.
+type cueHeap []runHead
+
+func (h cueHeap) Len() int { return len(h) }
+
+func (h cueHeap) Less(i, j int) bool {
+	if c := cmp.Compare(h[i].cue.Start, h[j].cue.Start); c != 0 {
+		return c < 0
+	}
+	return h[i].run < h[j].run
+}
+
+func (h cueHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *cueHeap) Push(x any) { *h = append(*h, x.(runHead)) }
+
+func (h *cueHeap) Pop() any {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
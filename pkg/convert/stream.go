@@ -0,0 +1,54 @@
+package convert
+
+import (
+	"context"
+	"io"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// ConvertStream reads a draft_content.json document from r and calls fn
+// with each cue in output order instead of rendering a subtitle file. If fn
+// returns an error, ConvertStream stops and returns it. Report.Cues counts
+// the cues fn accepted.
+//
+// Sorting and deduplication need the whole timeline, so the first cue is
+// delivered once the draft has been decoded and those passes have run. With
+// opts.MaxMemory set, they run over temporary files instead of in memory.
+func ConvertStream(r io.Reader, fn func(subtitle.Cue) error, opts Options) (Report, error) {
+	return ConvertStreamContext(context.Background(), r, fn, opts)
+}
+
+// ConvertStreamContext is like ConvertStream but stops with ctx.Err() once
+// ctx is done, including between callbacks.
+func ConvertStreamContext(ctx context.Context, r io.Reader, fn func(subtitle.Cue) error, opts Options) (Report, error) {
+	draft, err := capcut.Decode(contextReader{ctx, r})
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Report{}, ctxErr
+		}
+		return Report{}, err
+	}
+
+	if opts.spills() {
+		return spill(ctx, draft, fn, opts)
+	}
+
+	cues, warnings, err := CuesContext(ctx, draft, opts)
+	if err != nil {
+		return Report{}, err
+	}
+
+	report := Report{Warnings: warnings}
+	for c := range subtitle.Subtitles(cues).All() {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if err := fn(c); err != nil {
+			return report, err
+		}
+		report.Cues++
+	}
+	return report, nil
+}
@@ -0,0 +1,69 @@
+package convert
+
+import (
+	"fmt"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/transform"
+)
+
+// Warning describes something in a draft the conversion skipped or
+// adjusted without failing, for the caller to surface as it sees fit.
+type Warning struct {
+	// Source locates the segment the warning is about, if it is about one.
+	Source  subtitle.Source
+	Message string
+}
+
+func (w Warning) String() string {
+	if w.Source == (subtitle.Source{}) {
+		return w.Message
+	}
+	return fmt.Sprintf("text track %d, segment %d: %s", w.Source.TrackNumber, w.Source.Segment+1, w.Message)
+}
+
+// missingMaterials warns about text segments whose material is not in the
+// draft or not a text, which capcut.Cues skips.
+func missingMaterials(draft capcut.DraftContent, textMap map[string]capcut.TextMaterial) []Warning {
+	var warnings []Warning
+	textTrackNumber := 0
+	for _, track := range draft.Tracks {
+		if track.Type != "text" {
+			continue
+		}
+		textTrackNumber++
+		for i, segment := range track.Segments {
+			if _, found := textMap[segment.MaterialID]; found {
+				continue
+			}
+			message := fmt.Sprintf("material %q not found; segment skipped", segment.MaterialID)
+			if kind := draft.OtherMaterials[segment.MaterialID]; kind != "" {
+				message = fmt.Sprintf("material %q is not a text but one of the %s; segment skipped", segment.MaterialID, kind)
+			}
+			warnings = append(warnings, Warning{
+				Source:  subtitle.Source{MaterialID: segment.MaterialID, TrackID: track.ID, TrackNumber: textTrackNumber, Segment: i},
+				Message: message,
+			})
+		}
+	}
+	return warnings
+}
+
+// checkCues warns about cues that are about to be dropped for having no
+// text or that will be written out of the ordinary.
+func checkCues(negative subtitle.NegativePolicy, warn func(Warning)) transform.Transform {
+	return func(subs *subtitle.Subtitles) error {
+		for _, c := range *subs {
+			switch {
+			case c.Text == "":
+				warn(Warning{Source: c.Source, Message: "cue has no text and is dropped"})
+			case c.End < c.Start:
+				warn(Warning{Source: c.Source, Message: "cue ends before it starts"})
+			case c.Start < 0 && negative == subtitle.NegativeClamp:
+				warn(Warning{Source: c.Source, Message: "cue starts before 00:00:00 and is clamped to zero"})
+			}
+		}
+		return nil
+	}
+}
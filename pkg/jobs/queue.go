@@ -0,0 +1,290 @@
+// Package jobs is a persistent first-in, first-out queue of conversion
+// jobs, so large conversions can run in the background of a server and
+// survive restarts. Each job is a directory holding its state, its input
+// and, once done, its result.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Status is the stage a job is in.
+type Status string
+
+const (
+	Queued    Status = "queued"
+	Running   Status = "running"
+	Succeeded Status = "succeeded"
+	Failed    Status = "failed"
+)
+
+// ErrNotFound is returned for job IDs the queue does not know.
+var ErrNotFound = errors.New("job not found")
+
+// Job describes a submitted conversion.
+type Job struct {
+	ID     string `json:"id"`
+	Status Status `json:"status"`
+	// Args are the conversion settings, in whatever form the worker
+	// understands.
+	Args      []string  `json:"args"`
+	Submitted time.Time `json:"submitted"`
+	// Finished is set once the job is done.
+	Finished *time.Time `json:"finished,omitempty"`
+	Cues     int        `json:"cues"`
+	Warnings []string   `json:"warnings,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// Done reports whether the job has finished, successfully or not.
+func (j Job) Done() bool {
+	return j.Status == Succeeded || j.Status == Failed
+}
+
+// Result is what a worker reports about a finished job.
+type Result struct {
+	Cues     int
+	Warnings []string
+}
+
+// WorkFunc converts input into output for job.
+type WorkFunc func(ctx context.Context, job Job, input io.Reader, output io.Writer) (Result, error)
+
+// Queue holds the jobs stored under a directory.
+type Queue struct {
+	dir string
+
+	mu     sync.Mutex
+	jobs   map[string]Job
+	queued []string
+	wake   chan struct{}
+}
+
+// Open loads the queue stored in dir, creating the directory if needed.
+// Jobs that were running when the previous process stopped are queued
+// again.
+func Open(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create job directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read job directory: %w", err)
+	}
+
+	q := &Queue{dir: dir, jobs: make(map[string]Job), wake: make(chan struct{}, 1)}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			// Not a job, such as a .DS_Store left by a file manager.
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name(), "job.json"))
+		if errors.Is(err, os.ErrNotExist) {
+			// A submission interrupted before its state was saved.
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read job %s: %w", entry.Name(), err)
+		}
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("failed to parse job %s: %w", entry.Name(), err)
+		}
+		if job.Status == Running {
+			job.Status = Queued
+		}
+		q.jobs[job.ID] = job
+		if job.Status == Queued {
+			q.queued = append(q.queued, job.ID)
+		}
+	}
+	slices.SortFunc(q.queued, func(a, b string) int {
+		return q.jobs[a].Submitted.Compare(q.jobs[b].Submitted)
+	})
+	return q, nil
+}
+
+// Submit stores input as a new job converted with args and queues it.
+func (q *Queue) Submit(input io.Reader, args []string) (Job, error) {
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+	dir := filepath.Join(q.dir, id)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return Job{}, fmt.Errorf("failed to create job: %w", err)
+	}
+	if err := writeFile(filepath.Join(dir, "input"), input); err != nil {
+		os.RemoveAll(dir)
+		return Job{}, fmt.Errorf("failed to store job input: %w", err)
+	}
+
+	job := Job{ID: id, Status: Queued, Args: args, Submitted: time.Now().UTC()}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.save(job); err != nil {
+		os.RemoveAll(dir)
+		return Job{}, err
+	}
+	q.jobs[id] = job
+	q.queued = append(q.queued, id)
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, nil
+}
+
+// Get returns the job with the given ID.
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return job, nil
+}
+
+// Result opens the output of a job that succeeded.
+func (q *Queue) Result(id string) (*os.File, error) {
+	job, err := q.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != Succeeded {
+		return nil, fmt.Errorf("job %s is %s, not succeeded", id, job.Status)
+	}
+	return os.Open(filepath.Join(q.dir, id, "result"))
+}
+
+// Run works through the queue one job at a time until ctx is cancelled.
+// A job interrupted by the cancellation stays running and is queued again
+// when the queue is next opened.
+func (q *Queue) Run(ctx context.Context, work WorkFunc) error {
+	for {
+		job, ok := q.next()
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-q.wake:
+				continue
+			}
+		}
+
+		result, err := q.process(ctx, job, work)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		finished := time.Now().UTC()
+		job.Finished = &finished
+		job.Cues, job.Warnings = result.Cues, result.Warnings
+		job.Status = Succeeded
+		if err != nil {
+			job.Status, job.Error = Failed, err.Error()
+		}
+		if err := q.update(job); err != nil {
+			return err
+		}
+	}
+}
+
+// next takes the oldest queued job and marks it running.
+func (q *Queue) next() (Job, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.queued) == 0 {
+		return Job{}, false
+	}
+	job := q.jobs[q.queued[0]]
+	q.queued = q.queued[1:]
+	job.Status = Running
+	q.jobs[job.ID] = job
+	return job, true
+}
+
+func (q *Queue) process(ctx context.Context, job Job, work WorkFunc) (Result, error) {
+	if err := q.update(job); err != nil {
+		return Result{}, err
+	}
+	dir := filepath.Join(q.dir, job.ID)
+	input, err := os.Open(filepath.Join(dir, "input"))
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to open job input: %w", err)
+	}
+	defer input.Close()
+
+	output, err := os.CreateTemp(dir, "result-*.tmp")
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to create job result: %w", err)
+	}
+	defer os.Remove(output.Name())
+	result, err := work(ctx, job, input, output)
+	if closeErr := output.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return result, err
+	}
+	return result, os.Rename(output.Name(), filepath.Join(dir, "result"))
+}
+
+func (q *Queue) update(job Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.save(job); err != nil {
+		return err
+	}
+	q.jobs[job.ID] = job
+	return nil
+}
+
+// save writes the job's state, replacing it atomically so a crash cannot
+// leave it half-written.
+func (q *Queue) save(job Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return err
+	}
+	name := filepath.Join(q.dir, job.ID, "job.json")
+	temp := name + ".tmp"
+	if err := os.WriteFile(temp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	if err := os.Rename(temp, name); err != nil {
+		return fmt.Errorf("failed to save job %s: %w", job.ID, err)
+	}
+	return nil
+}
+
+func writeFile(name string, r io.Reader) error {
+	file, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(file, r)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to create job ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,131 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// upper is a worker that upper-cases its input and fails on "fail".
+func upper(ctx context.Context, job Job, input io.Reader, output io.Writer) (Result, error) {
+	data, err := io.ReadAll(input)
+	if err != nil {
+		return Result{}, err
+	}
+	if string(data) == "fail" {
+		return Result{}, errors.New("bad input")
+	}
+	_, err = io.WriteString(output, strings.ToUpper(string(data)))
+	return Result{Cues: len(job.Args)}, err
+}
+
+// wait polls until the job is done.
+func wait(t *testing.T, q *Queue, id string) Job {
+	t.Helper()
+	for range 500 {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if job.Done() {
+			return job
+		}
+		time.Sleep(2 * time.Millisecond)
+	}
+	t.Fatalf("job %s did not finish", id)
+	return Job{}
+}
+
+func TestQueue(t *testing.T) {
+	q, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go q.Run(ctx, upper)
+
+	ok, err := q.Submit(strings.NewReader("hello"), []string{"--dedup"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := q.Submit(strings.NewReader("fail"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if job := wait(t, q, ok.ID); job.Status != Succeeded || job.Cues != 1 || job.Finished == nil {
+		t.Errorf("job = %+v, want succeeded", job)
+	}
+	result, err := q.Result(ok.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(result)
+	result.Close()
+	if string(data) != "HELLO" {
+		t.Errorf("result = %q, want HELLO", data)
+	}
+
+	if job := wait(t, q, bad.ID); job.Status != Failed || job.Error != "bad input" {
+		t.Errorf("job = %+v, want failed with the worker's error", job)
+	}
+	if _, err := q.Result(bad.ID); err == nil {
+		t.Errorf("Result() of a failed job succeeded")
+	}
+	if _, err := q.Get("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestQueueRestart(t *testing.T) {
+	dir := t.TempDir()
+	q, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _ := q.Submit(strings.NewReader("first"), nil)
+	second, _ := q.Submit(strings.NewReader("second"), nil)
+
+	// Stop in the middle of the first job, as a restarted server would.
+	ctx, cancel := context.WithCancel(context.Background())
+	started := make(chan struct{})
+	go q.Run(ctx, func(ctx context.Context, job Job, input io.Reader, output io.Writer) (Result, error) {
+		close(started)
+		<-ctx.Done()
+		return Result{}, ctx.Err()
+	})
+	<-started
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if job, _ := q.Get(first.ID); job.Status != Running {
+		t.Fatalf("interrupted job = %s, want running", job.Status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, first.ID, "result")); err == nil {
+		t.Errorf("interrupted job left a result")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".DS_Store"), []byte("not a job"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job, _ := reopened.Get(first.ID); job.Status != Queued {
+		t.Errorf("reopened job = %s, want queued", job.Status)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go reopened.Run(ctx, upper)
+	for _, id := range []string{first.ID, second.ID} {
+		if job := wait(t, reopened, id); job.Status != Succeeded {
+			t.Errorf("job %s = %s, want succeeded", id, job.Status)
+		}
+	}
+}
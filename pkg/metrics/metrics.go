@@ -0,0 +1,104 @@
+// Package metrics counts conversions in long-lived modes and serves the
+// counts in the Prometheus text format, next to the net/http/pprof profiles.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// durationBuckets are the upper bounds, in seconds, of the conversion
+// duration histogram.
+var durationBuckets = [...]float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60}
+
+// Metrics records conversions. The zero value is ready to use and safe for
+// concurrent use.
+type Metrics struct {
+	mu      sync.Mutex
+	total   uint64
+	errors  uint64
+	seconds float64
+	// buckets counts the conversions that took at most the matching
+	// duration bucket.
+	buckets [len(durationBuckets)]uint64
+}
+
+// Observe records one conversion that took d and failed if err is non-nil.
+func (m *Metrics) Observe(d time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.total++
+	if err != nil {
+		m.errors++
+	}
+	seconds := d.Seconds()
+	m.seconds += seconds
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var b []byte
+	b = append(b, "# HELP capcut_subtitle_conversions_total Conversions run.\n"...)
+	b = append(b, "# TYPE capcut_subtitle_conversions_total counter\n"...)
+	b = fmt.Appendf(b, "capcut_subtitle_conversions_total %d\n", m.total)
+	b = append(b, "# HELP capcut_subtitle_conversion_errors_total Conversions that failed.\n"...)
+	b = append(b, "# TYPE capcut_subtitle_conversion_errors_total counter\n"...)
+	b = fmt.Appendf(b, "capcut_subtitle_conversion_errors_total %d\n", m.errors)
+	b = append(b, "# HELP capcut_subtitle_conversion_duration_seconds Time taken by conversions.\n"...)
+	b = append(b, "# TYPE capcut_subtitle_conversion_duration_seconds histogram\n"...)
+	for i, bound := range durationBuckets {
+		b = fmt.Appendf(b, "capcut_subtitle_conversion_duration_seconds_bucket{le=%q} %d\n", strconv.FormatFloat(bound, 'g', -1, 64), m.buckets[i])
+	}
+	b = fmt.Appendf(b, "capcut_subtitle_conversion_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.total)
+	b = fmt.Appendf(b, "capcut_subtitle_conversion_duration_seconds_sum %s\n", strconv.FormatFloat(m.seconds, 'g', -1, 64))
+	b = fmt.Appendf(b, "capcut_subtitle_conversion_duration_seconds_count %d\n", m.total)
+
+	n, err := w.Write(b)
+	return int64(n), err
+}
+
+// Handler serves m at /metrics and the runtime profiles under /debug/pprof/.
+func Handler(m *Metrics) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.WriteTo(w)
+	})
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// ListenAndServe serves Handler(m) on addr until ctx is done. The profiles
+// expose internals of the process, so addr should not be reachable from
+// untrusted networks.
+func ListenAndServe(ctx context.Context, addr string, m *Metrics) error {
+	server := &http.Server{Addr: addr, Handler: Handler(m)}
+	failed := make(chan error, 1)
+	go func() { failed <- server.ListenAndServe() }()
+
+	select {
+	case err := <-failed:
+		return fmt.Errorf("failed to serve metrics: %w", err)
+	case <-ctx.Done():
+		return server.Shutdown(context.Background())
+	}
+}
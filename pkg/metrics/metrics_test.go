@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	var m Metrics
+	m.Observe(20*time.Millisecond, nil)
+	m.Observe(2*time.Second, errors.New("broken draft"))
+
+	server := httptest.NewServer(Handler(&m))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"capcut_subtitle_conversions_total 2\n",
+		"capcut_subtitle_conversion_errors_total 1\n",
+		`capcut_subtitle_conversion_duration_seconds_bucket{le="0.01"} 0` + "\n",
+		`capcut_subtitle_conversion_duration_seconds_bucket{le="0.05"} 1` + "\n",
+		`capcut_subtitle_conversion_duration_seconds_bucket{le="5"} 2` + "\n",
+		`capcut_subtitle_conversion_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"capcut_subtitle_conversion_duration_seconds_sum 2.02\n",
+		"capcut_subtitle_conversion_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics is missing %q:\n%s", want, body)
+		}
+	}
+
+	resp, err = http.Get(server.URL + "/debug/pprof/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("/debug/pprof/ status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
@@ -0,0 +1,296 @@
+// Package punctuate restores the punctuation and sentence case auto
+// captions lack through a large language model behind a chat completions
+// API compatible with OpenAI's, such as OpenAI itself, a local Ollama or
+// llama.cpp server, or a proxy in front of another provider.
+package punctuate
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultBaseURL is the root of the OpenAI API.
+	DefaultBaseURL = "https://api.openai.com/v1"
+	// DefaultModel is the model asked when none is set.
+	DefaultModel = "gpt-4o-mini"
+	// DefaultBatchSize is how many lines go in one request when BatchSize
+	// is 0: enough context to punctuate across lines, few enough for a
+	// quick answer.
+	DefaultBatchSize = 40
+)
+
+// prompt tells the model what to do with the lines it is sent.
+const prompt = `You restore punctuation and capitalization in automatic speech transcripts.
+The user sends a JSON object {"lines": [...]} holding consecutive caption lines.
+Reply with only a JSON object {"lines": [...]} holding exactly as many lines in the same order.
+Keep every word of every line, in the same order and on the same line: only add punctuation and fix capitalization, as sentence case for the language.
+Do not add, remove, merge, split, translate or correct words.`
+
+// Client asks the API to punctuate lines of text.
+type Client struct {
+	// BaseURL replaces DefaultBaseURL when set; requests go to its
+	// /chat/completions endpoint.
+	BaseURL string
+	// Token, if set, is sent as a bearer token. Local servers usually do
+	// not need one.
+	Token string
+	// Model replaces DefaultModel when set.
+	Model string
+	// BatchSize replaces DefaultBatchSize when positive.
+	BatchSize int
+	// Cache, if set, answers batches asked before without a request.
+	Cache *Cache
+	// HTTPClient replaces http.DefaultClient when set.
+	HTTPClient *http.Client
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("punctuation API: %s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// Punctuate returns texts with punctuation and capitalization restored,
+// one for each in the same order. Lines are sent in batches of consecutive
+// lines, so the model sees where sentences run across them. The model may
+// still change words; callers that must keep them compare each line with
+// its original.
+//
+// A batch that fails does not stop the others: its lines are returned
+// unchanged, with an error joining the failure of every such batch. Only
+// when ctx ends are no lines returned.
+func (c *Client) Punctuate(ctx context.Context, texts []string) ([]string, error) {
+	size := c.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+	restored := make([]string, 0, len(texts))
+	var failures []error
+	for start := 0; start < len(texts); start += size {
+		batch := texts[start:min(start+size, len(texts))]
+		lines, err := c.batch(ctx, batch)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		if err != nil {
+			failures = append(failures, fmt.Errorf("failed to punctuate lines %d to %d: %w", start+1, start+len(batch), err))
+			lines = batch
+		}
+		restored = append(restored, lines...)
+	}
+	return restored, errors.Join(failures...)
+}
+
+// batch punctuates lines in one request, or from the cache.
+func (c *Client) batch(ctx context.Context, lines []string) ([]string, error) {
+	model := c.Model
+	if model == "" {
+		model = DefaultModel
+	}
+	key := cacheKey(model, lines)
+	if cached, ok := c.Cache.lookup(key); ok {
+		return cached, nil
+	}
+
+	user, err := json.Marshal(struct {
+		Lines []string `json:"lines"`
+	}{lines})
+	if err != nil {
+		return nil, err
+	}
+	type message struct {
+		Role    string `json:"role"`
+		Content string `json:"content"`
+	}
+	body, err := json.Marshal(struct {
+		Model       string    `json:"model"`
+		Messages    []message `json:"messages"`
+		Temperature float64   `json:"temperature"`
+	}{model, []message{{"system", prompt}, {"user", string(user)}}, 0})
+	if err != nil {
+		return nil, err
+	}
+
+	baseURL := c.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(baseURL, "/")+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	var resp struct {
+		Choices []struct {
+			Message message `json:"message"`
+		} `json:"choices"`
+	}
+	if err := c.do(req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, errors.New("the API answered without a message")
+	}
+
+	restored, err := parseLines(resp.Choices[0].Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if len(restored) != len(lines) {
+		return nil, fmt.Errorf("the model answered %d lines for %d", len(restored), len(lines))
+	}
+	if err := c.Cache.store(key, restored); err != nil {
+		return nil, err
+	}
+	return restored, nil
+}
+
+// parseLines reads the {"lines": [...]} object of a reply, which models
+// sometimes put in a Markdown code block or after a sentence.
+func parseLines(content string) ([]string, error) {
+	start, end := strings.IndexByte(content, '{'), strings.LastIndexByte(content, '}')
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("the model did not answer with JSON: %q", content)
+	}
+	var reply struct {
+		Lines []string `json:"lines"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &reply); err != nil {
+		return nil, fmt.Errorf("failed to parse the model's answer: %w", err)
+	}
+	return reply.Lines, nil
+}
+
+// do sends req and decodes the JSON response into v.
+func (c *Client) do(req *http.Request, v any) error {
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+			apiErr.Message = body.Error.Message
+		}
+		return apiErr
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Cache remembers the punctuated lines of batches in a JSON file, so runs
+// over unchanged captions do not ask the API again. It is safe for
+// concurrent use.
+type Cache struct {
+	path string
+
+	mu      sync.Mutex
+	entries map[string][]string
+}
+
+// OpenCache loads the cache file at path. A missing file is an empty
+// cache.
+func OpenCache(path string) (*Cache, error) {
+	c := &Cache{path: path, entries: make(map[string][]string)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read punctuation cache: %w", err)
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		return nil, fmt.Errorf("failed to parse punctuation cache %s: %w", path, err)
+	}
+	if c.entries == nil {
+		c.entries = make(map[string][]string)
+	}
+	return c, nil
+}
+
+// cacheKey identifies a batch of lines asked of model.
+func cacheKey(model string, lines []string) string {
+	h := sha256.New()
+	h.Write([]byte(model))
+	for _, line := range lines {
+		h.Write([]byte{0})
+		h.Write([]byte(line))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (c *Cache) lookup(key string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	lines, ok := c.entries[key]
+	return lines, ok
+}
+
+// store records a batch and saves the file at once, so an interrupted run
+// keeps the batches it paid for. The file is replaced atomically.
+func (c *Cache) store(key string, lines []string) error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = lines
+	data, err := json.Marshal(c.entries)
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(c.path), ".punctuation-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write punctuation cache: %w", err)
+	}
+	defer os.Remove(temp.Name())
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		return fmt.Errorf("failed to write punctuation cache: %w", err)
+	}
+	if err := temp.Close(); err != nil {
+		return fmt.Errorf("failed to write punctuation cache: %w", err)
+	}
+	if err := os.Rename(temp.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to write punctuation cache: %w", err)
+	}
+	return nil
+}
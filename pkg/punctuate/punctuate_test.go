@@ -0,0 +1,97 @@
+package punctuate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" || r.Header.Get("Authorization") != "Bearer key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":{"message":"Incorrect API key provided"}}`)
+			return
+		}
+		var body struct {
+			Model    string `json:"model"`
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		var user struct {
+			Lines []string `json:"lines"`
+		}
+		if err := json.Unmarshal([]byte(body.Messages[1].Content), &user); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, body.Model+": "+strings.Join(user.Lines, " | "))
+		for i, line := range user.Lines {
+			user.Lines[i] = strings.ToUpper(line[:1]) + line[1:] + "."
+		}
+		reply, _ := json.Marshal(user)
+		content := "```json\n" + string(reply) + "\n```"
+		json.NewEncoder(w).Encode(map[string]any{"choices": []any{map[string]any{"message": map[string]string{"role": "assistant", "content": content}}}})
+	}))
+	defer server.Close()
+
+	cache, err := OpenCache(filepath.Join(t.TempDir(), "punctuation.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &Client{BaseURL: server.URL + "/v1/", Token: "key", Model: "test", BatchSize: 2, Cache: cache}
+	ctx := context.Background()
+	got, err := client.Punctuate(ctx, []string{"hello there", "how are you", "fine"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"Hello there.", "How are you.", "Fine."}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Punctuate() = %q, want %q", got, want)
+	}
+	if want := []string{"test: hello there | how are you", "test: fine"}; !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %q, want %q", requests, want)
+	}
+
+	// A reopened cache answers the same batches without asking again.
+	if client.Cache, err = OpenCache(cache.path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Punctuate(ctx, []string{"hello there", "how are you"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(requests) != 2 {
+		t.Errorf("cached batch was sent again: %q", requests)
+	}
+
+	// A failed batch keeps its lines and does not stop the cached one.
+	client.Token = "wrong"
+	got, err = client.Punctuate(ctx, []string{"hello there", "how are you", "new line"})
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Incorrect API key provided" {
+		t.Errorf("Punctuate() error = %v, want the API's 401", err)
+	}
+	if want := []string{"Hello there.", "How are you.", "new line"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Punctuate() = %q, want %q", got, want)
+	}
+}
+
+func TestParseLines(t *testing.T) {
+	if _, err := parseLines("Sure! Here you go."); err == nil {
+		t.Error("parseLines() expected error for an answer without JSON")
+	}
+	lines, err := parseLines(`Here: {"lines": ["A.", "B?"]}`)
+	if err != nil || !reflect.DeepEqual(lines, []string{"A.", "B?"}) {
+		t.Errorf("parseLines() = %q, %v", lines, err)
+	}
+}
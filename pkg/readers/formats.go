@@ -0,0 +1,85 @@
+package readers
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// ReadCapCut reads the raw cues of a draft_content.json document, with the
+// text exactly as stored in the draft. Use package convert to also clean it.
+func ReadCapCut(r io.Reader) (subtitle.Subtitles, error) {
+	draft, err := capcut.Decode(r)
+	if err != nil {
+		return nil, err
+	}
+	return capcut.Cues(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts), capcut.GranularityWords), nil
+}
+
+// ReadJSON reads cues in the versioned JSON schema of subtitle.Subtitles.
+func ReadJSON(r io.Reader) (subtitle.Subtitles, error) {
+	var subs subtitle.Subtitles
+	if err := json.NewDecoder(bufio.NewReader(r)).Decode(&subs); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	return subs, nil
+}
+
+// ReadSRT reads SubRip cues.
+func ReadSRT(r io.Reader) (subtitle.Subtitles, error) {
+	return subtitle.ParseSRT(r)
+}
+
+// ReadVTT reads WebVTT cues.
+func ReadVTT(r io.Reader) (subtitle.Subtitles, error) {
+	return subtitle.ParseVTT(r)
+}
+
+type whisperTranscript struct {
+	Segments []struct {
+		Start float64 `json:"start"`
+		End   float64 `json:"end"`
+		Text  string  `json:"text"`
+	} `json:"segments"`
+	// Transcription holds the segments in whisper.cpp's output, timed by
+	// offsets in milliseconds.
+	Transcription []struct {
+		Offsets struct {
+			From int64 `json:"from"`
+			To   int64 `json:"to"`
+		} `json:"offsets"`
+		Text string `json:"text"`
+	} `json:"transcription"`
+}
+
+// ReadWhisper reads the segments of an OpenAI Whisper JSON transcript,
+// whose times are in seconds, or of whisper.cpp's JSON output.
+func ReadWhisper(r io.Reader) (subtitle.Subtitles, error) {
+	var transcript whisperTranscript
+	if err := json.NewDecoder(bufio.NewReader(r)).Decode(&transcript); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+
+	subs := make(subtitle.Subtitles, 0, len(transcript.Segments))
+	for _, segment := range transcript.Segments {
+		subs = append(subs, subtitle.Cue{
+			Start: int64(math.Round(segment.Start * 1e6)),
+			End:   int64(math.Round(segment.End * 1e6)),
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+	for _, segment := range transcript.Transcription {
+		subs = append(subs, subtitle.Cue{
+			Start: segment.Offsets.From * 1000,
+			End:   segment.Offsets.To * 1000,
+			Text:  strings.TrimSpace(segment.Text),
+		})
+	}
+	return subs, nil
+}
@@ -0,0 +1,182 @@
+// Package readers parses subtitle sources into cues, with a registry keyed
+// by format name and content sniffing to pick one automatically.
+package readers
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// Reader parses one input format.
+type Reader interface {
+	Read(r io.Reader) (subtitle.Subtitles, error)
+}
+
+// ReaderFunc adapts a function to the Reader interface.
+type ReaderFunc func(r io.Reader) (subtitle.Subtitles, error)
+
+func (f ReaderFunc) Read(r io.Reader) (subtitle.Subtitles, error) {
+	return f(r)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Reader{
+		"capcut":  ReaderFunc(ReadCapCut),
+		"json":    ReaderFunc(ReadJSON),
+		"srt":     ReaderFunc(ReadSRT),
+		"vtt":     ReaderFunc(ReadVTT),
+		"whisper": ReaderFunc(ReadWhisper),
+	}
+)
+
+// Register makes a reader available under a format name. It panics if the
+// name is already taken or r is nil.
+func Register(name string, r Reader) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if r == nil {
+		panic("readers: Register reader is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("readers: Register called twice for format " + name)
+	}
+	registry[name] = r
+}
+
+// Lookup returns the reader registered for a format name.
+func Lookup(name string) (Reader, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown input format %q", name)
+	}
+	return r, nil
+}
+
+// Formats returns the registered format names in sorted order.
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Detect names the built-in format of data by its content: a WEBVTT
+// header, a JSON object with CapCut's "tracks" or "materials", Whisper's
+// "segments" or the "cues" of the subtitle.Subtitles schema, or SRT timing
+// lines. data may be just the start of the input, as long as it reaches the
+// identifying key.
+func Detect(data []byte) (string, error) {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, []byte("\ufeff")), " \t\r\n")
+
+	switch {
+	case bytes.HasPrefix(data, []byte("WEBVTT")):
+		return "vtt", nil
+	case bytes.HasPrefix(data, []byte("{")):
+		return detectJSON(data)
+	case bytes.Contains(data, []byte("-->")):
+		return "srt", nil
+	}
+	return "", fmt.Errorf("unrecognized subtitle format")
+}
+
+// detectJSON walks the top-level keys of a JSON object, skipping values,
+// until it finds one that identifies the format.
+func detectJSON(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if _, err := decoder.Token(); err != nil {
+		return "", fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		switch key {
+		case "tracks", "materials":
+			return "capcut", nil
+		case "segments", "transcription":
+			return "whisper", nil
+		case "cues":
+			return "json", nil
+		}
+		if err := skipValue(decoder); err != nil {
+			return "", fmt.Errorf("failed to parse JSON: %w", err)
+		}
+	}
+	return "", fmt.Errorf("unrecognized JSON subtitle format")
+}
+
+// skipValue consumes the next JSON value one token at a time, so skipping
+// does not buffer it.
+func skipValue(decoder *json.Decoder) error {
+	depth := 0
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		switch token {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+// sniffSize is how much of the input Sniff looks at.
+const sniffSize = 1 << 20
+
+// Sniff detects the format of r from its first bytes, transcoding UTF-16
+// to UTF-8. The returned reader yields the whole input, including the
+// bytes looked at. A JSON object whose identifying key lies beyond those
+// bytes is taken for a CapCut draft, the only format whose other keys
+// (such as "keyframes") grow that large.
+func Sniff(r io.Reader) (string, io.Reader, error) {
+	reader := bufio.NewReaderSize(subtitle.NewTextReader(r), sniffSize)
+	head, err := reader.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return "", nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	format, err := Detect(head)
+	if err != nil && len(head) == sniffSize && bytes.HasPrefix(bytes.TrimLeft(head, " \t\r\n"), []byte("{")) {
+		return "capcut", reader, nil
+	}
+	return format, reader, err
+}
+
+// ReadAuto detects the format of r with Sniff and parses it with the
+// matching registered reader. It returns the detected format name.
+func ReadAuto(r io.Reader) (subtitle.Subtitles, string, error) {
+	format, input, err := Sniff(r)
+	if err != nil {
+		return nil, "", err
+	}
+	reader, err := Lookup(format)
+	if err != nil {
+		return nil, "", err
+	}
+	subs, err := reader.Read(input)
+	return subs, format, err
+}
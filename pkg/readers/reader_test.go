@@ -0,0 +1,137 @@
+package readers
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{name: "webvtt", input: "WEBVTT\n\n00:01.000 --> 00:02.000\nHi\n", want: "vtt"},
+		{name: "srt with BOM", input: "\ufeff1\n00:00:01,000 --> 00:00:02,000\nHi\n", want: "srt"},
+		{name: "capcut draft", input: `{"canvas_config": {"width": 1920}, "materials": {}, "tracks": []}`, want: "capcut"},
+		{name: "whisper transcript", input: `{"text": " Hi", "segments": [], "language": "en"}`, want: "whisper"},
+		{name: "whisper.cpp transcript", input: `{"systeminfo": "AVX = 1", "model": {"type": "base"}, "transcription": []}`, want: "whisper"},
+		{name: "cue schema", input: `{"version": 1, "cues": []}`, want: "json"},
+		{name: "unknown JSON", input: `{"captions": []}`, wantErr: true},
+		{name: "plain text", input: "just some text", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Detect([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Detect() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Detect() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadWhisper(t *testing.T) {
+	input := `{"text": " Hello world", "segments": [
+		{"id": 0, "start": 0.0, "end": 1.52, "text": " Hello"},
+		{"id": 1, "start": 1.52, "end": 3.0, "text": " world"}
+	]}`
+
+	got, err := ReadWhisper(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := subtitle.Subtitles{
+		{Start: 0, End: 1520000, Text: "Hello"},
+		{Start: 1520000, End: 3000000, Text: "world"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadWhisper() = %v, want %v", got, want)
+	}
+
+	input = `{"transcription": [
+		{"timestamps": {"from": "00:00:00,000", "to": "00:00:01,520"}, "offsets": {"from": 0, "to": 1520}, "text": " Hello"},
+		{"timestamps": {"from": "00:00:01,520", "to": "00:00:03,000"}, "offsets": {"from": 1520, "to": 3000}, "text": " world"}
+	]}`
+	if got, err = ReadWhisper(strings.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadWhisper() of whisper.cpp output = %v, want %v", got, want)
+	}
+}
+
+func TestReadAuto(t *testing.T) {
+	draft := `{
+		"materials": {"texts": [{"id": "1", "content": "<b>Hi</b>"}]},
+		"tracks": [{"id": "t", "type": "text", "segments": [
+			{"material_id": "1", "target_timerange": {"start": 0, "duration": 1000000}}
+		]}]
+	}`
+
+	got, format, err := ReadAuto(strings.NewReader(draft))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "capcut" {
+		t.Errorf("ReadAuto() format = %q, want capcut", format)
+	}
+	want := subtitle.Subtitles{{
+		Start: 0, End: 1000000, Text: "<b>Hi</b>",
+		Source: subtitle.Source{MaterialID: "1", TrackID: "t", TrackNumber: 1},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAuto() = %v, want %v", got, want)
+	}
+
+	// Keys before "materials" can outgrow what Sniff looks at.
+	large := `{"keyframes": {"texts": ["` + strings.Repeat("x", 2*sniffSize) + `"]},` + draft[1:]
+	got, format, err = ReadAuto(strings.NewReader(large))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "capcut" || !reflect.DeepEqual(got, want) {
+		t.Errorf("ReadAuto() with large keyframes = %v (%s), want %v (capcut)", got, format, want)
+	}
+}
+
+func TestReadJSONRoundTrip(t *testing.T) {
+	subs := subtitle.Subtitles{
+		{Start: 0, End: 1500000, Text: "Hi", Source: subtitle.Source{MaterialID: "m", TrackNumber: 1}},
+	}
+	data, err := json.Marshal(subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, format, err := ReadAuto(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if format != "json" || !reflect.DeepEqual(got, subs) {
+		t.Errorf("ReadAuto() = %v (%s), want %v (json)", got, format, subs)
+	}
+}
+
+func FuzzReadAuto(f *testing.F) {
+	f.Add("WEBVTT\n\n00:01.000 --> 00:02.000\nHi\n")
+	f.Add("1\n00:00:01,000 --> 00:00:02,000\nHi\n\n2\n00:00:02,000 --> 00:00:03,000\n")
+	f.Add(`{"segments": [{"start": 0.5, "end": 1, "text": "Hi"}]}`)
+	f.Add(`{"transcription": [{"offsets": {"from": 0, "to": 500}, "text": "Hi"}]}`)
+	f.Add(`{"version": 1, "cues": [{"start_us": 0, "end_us": 1000000, "text": "Hi"}]}`)
+	f.Add(`{"materials": {"texts": [{"id": "t", "content": "Hi"}]}, "tracks": [{"type": "text", "segments": [{"material_id": "t"}]}]}`)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		// Any input may be rejected, but none may crash the readers.
+		ReadAuto(strings.NewReader(input))
+	})
+}
@@ -0,0 +1,168 @@
+// Package retry paces the requests sent to rate-limited APIs and retries
+// those that fail for a moment, such as with 429 Too Many Requests, with
+// exponential backoff.
+package retry
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultWait is the delay before the first retry when Wait is 0.
+	DefaultWait = time.Second
+	// DefaultMaxWait caps the delay between retries when MaxWait is 0.
+	DefaultMaxWait = 30 * time.Second
+)
+
+// Transport is an http.RoundTripper sending requests through Base at most
+// Rate times a second, and sending again those that fail with a network
+// error or a 429, 500, 502, 503 or 504 status. It is safe for concurrent
+// use, with the rate shared by all requests.
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport when nil.
+	Base http.RoundTripper
+	// Retries is how many times a failed request is sent again. Requests
+	// with a body are only sent again if it can be read afresh, as with
+	// http.Request.GetBody.
+	Retries int
+	// Wait is the delay before the first retry, doubling with each retry
+	// up to MaxWait; a Retry-After header of the response replaces it, up
+	// to MaxWait too.
+	Wait, MaxWait time.Duration
+	// Rate, if positive, caps the requests sent per second, retries
+	// included.
+	Rate float64
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// Client returns an http.Client sending its requests through t.
+func (t *Transport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	ctx := req.Context()
+	wait := cmp.Or(t.Wait, DefaultWait)
+	maxWait := cmp.Or(t.MaxWait, DefaultMaxWait)
+	for attempt := 0; ; attempt++ {
+		if err := t.pace(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := base.RoundTrip(req)
+		if attempt >= t.Retries || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+		delay := wait
+		for i := 0; i < attempt && delay < maxWait; i++ {
+			delay *= 2
+		}
+		delay = min(delay, maxWait)
+		if resp != nil {
+			if after, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = min(after, maxWait)
+			}
+		}
+		next, ok := rewind(req)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+		req = next
+	}
+}
+
+// pace waits until the next request may be sent under t.Rate.
+func (t *Transport) pace(ctx context.Context) error {
+	if t.Rate <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	now := time.Now()
+	at := t.next
+	if at.Before(now) {
+		at = now
+	}
+	t.next = at.Add(time.Duration(float64(time.Second) / t.Rate))
+	t.mu.Unlock()
+	return sleep(ctx, at.Sub(now))
+}
+
+// retryable reports whether a request that got resp or err may succeed if
+// sent again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter parses a Retry-After header, which gives seconds or a date.
+func retryAfter(header string) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}
+
+// rewind returns a copy of req to send again with its body read afresh,
+// and false if the body cannot be.
+func rewind(req *http.Request) (*http.Request, bool) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next.Body = body
+	return next, true
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,95 @@
+package retry
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransport(t *testing.T) {
+	tests := []struct {
+		name     string
+		failures int
+		status   int
+		body     func() io.Reader
+		wantCode int
+		wantSent int
+	}{
+		{"retried until it succeeds", 2, http.StatusTooManyRequests, func() io.Reader { return strings.NewReader("hello") }, http.StatusOK, 3},
+		{"gives up after the retries", 5, http.StatusServiceUnavailable, func() io.Reader { return strings.NewReader("hello") }, http.StatusServiceUnavailable, 4},
+		{"client errors are final", 1, http.StatusBadRequest, func() io.Reader { return strings.NewReader("hello") }, http.StatusBadRequest, 1},
+		// A body without GetBody cannot be sent again.
+		{"body read once", 1, http.StatusTooManyRequests, func() io.Reader { return io.MultiReader(strings.NewReader("hello")) }, http.StatusTooManyRequests, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sent := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				sent++
+				if body, _ := io.ReadAll(r.Body); string(body) != "hello" {
+					t.Errorf("request %d has body %q", sent, body)
+				}
+				if sent <= tt.failures {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(tt.status)
+				}
+			}))
+			defer server.Close()
+
+			client := (&Transport{Retries: 3, Wait: time.Millisecond}).Client()
+			req, err := http.NewRequest(http.MethodPost, server.URL, tt.body())
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.wantCode || sent != tt.wantSent {
+				t.Errorf("got %d after %d requests, want %d after %d", resp.StatusCode, sent, tt.wantCode, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestTransportRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	client := (&Transport{Rate: 20}).Client()
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// The second and third requests wait 50ms each.
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("3 requests at 20 a second took %v", elapsed)
+	}
+}
+
+func TestTransportCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&Transport{Retries: 3}).Client().Do(req); err == nil {
+		t.Error("Do() succeeded after the context ended")
+	}
+}
@@ -0,0 +1,147 @@
+package spell
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// maxSuggestions is how many corrections a Misspelling offers at most.
+const maxSuggestions = 3
+
+// maxSegmentLength is the longest word, in runes, tried when splitting
+// text written without spaces.
+const maxSegmentLength = 20
+
+// A Checker checks cue text against one or more dictionaries. A word is
+// correct if any of them knows it, so text mixing languages can be checked
+// in one pass.
+type Checker struct {
+	dictionaries []*Dictionary
+}
+
+// NewChecker returns a Checker using the given dictionaries.
+func NewChecker(dictionaries ...*Dictionary) *Checker {
+	return &Checker{dictionaries: dictionaries}
+}
+
+// Misspelling is an unknown word in a cue, with suggested corrections.
+// Cue is the 1-based cue number, as written in SRT.
+type Misspelling struct {
+	Cue         int
+	Word        string
+	Suggestions []string
+}
+
+func (m Misspelling) String() string {
+	if len(m.Suggestions) == 0 {
+		return fmt.Sprintf("cue %d: unknown word %q", m.Cue, m.Word)
+	}
+	return fmt.Sprintf("cue %d: unknown word %q (did you mean %s?)", m.Cue, m.Word, strings.Join(m.Suggestions, ", "))
+}
+
+// Check returns the unknown words in cues, in cue order. Words containing
+// digits are skipped. Thai, Lao, Khmer and Burmese, written without spaces
+// between words, are accepted when the run of text splits entirely into
+// known words; otherwise the whole run is reported.
+func (c *Checker) Check(cues []subtitle.Cue) []Misspelling {
+	var misspellings []Misspelling
+	for i, cue := range cues {
+		for _, word := range words(cue.Text) {
+			if c.known(word) {
+				continue
+			}
+			m := Misspelling{Cue: i + 1, Word: word}
+			if !unspaced(word) {
+				m.Suggestions = c.suggest(word)
+			}
+			misspellings = append(misspellings, m)
+		}
+	}
+	return misspellings
+}
+
+func (c *Checker) known(word string) bool {
+	if c.check(word) {
+		return true
+	}
+	if !unspaced(word) {
+		return false
+	}
+
+	// splits[i] records whether the first i runes split into words.
+	runes := []rune(word)
+	splits := make([]bool, len(runes)+1)
+	splits[0] = true
+	for end := 1; end <= len(runes); end++ {
+		for start := max(end-maxSegmentLength, 0); start < end && !splits[end]; start++ {
+			splits[end] = splits[start] && c.check(string(runes[start:end]))
+		}
+	}
+	return splits[len(runes)]
+}
+
+func (c *Checker) check(word string) bool {
+	for _, d := range c.dictionaries {
+		if d.Check(word) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Checker) suggest(word string) []string {
+	var suggestions []string
+	for _, d := range c.dictionaries {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		for _, s := range d.Suggest(word, maxSuggestions-len(suggestions)) {
+			if !slices.Contains(suggestions, s) {
+				suggestions = append(suggestions, s)
+			}
+		}
+	}
+	return suggestions
+}
+
+// words splits text into runs of letters and combining marks, keeping
+// apostrophes between letters so contractions stay whole. Words with
+// digits are dropped.
+func words(text string) []string {
+	var list []string
+	runes := []rune(strings.ReplaceAll(text, "’", "'"))
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			i++
+			continue
+		}
+		start, digits := i, false
+		for i < len(runes) && (isWordRune(runes[i]) || runes[i] == '\'' && i+1 < len(runes) && isWordRune(runes[i+1])) {
+			digits = digits || unicode.IsDigit(runes[i])
+			i++
+		}
+		if !digits {
+			list = append(list, string(runes[start:i]))
+		}
+	}
+	return list
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r)
+}
+
+// unspaced reports whether word is in a script written without spaces
+// between words.
+func unspaced(word string) bool {
+	for _, r := range word {
+		if unicode.In(r, unicode.Thai, unicode.Lao, unicode.Khmer, unicode.Myanmar) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,427 @@
+// Package spell checks words against Hunspell dictionaries.
+//
+// Only the parts of the format needed to recognize words are read: the
+// word list, prefix and suffix rules (including one prefix combined with
+// one suffix), and the FORBIDDENWORD and NEEDAFFIX flags. Compound rules,
+// morphology and conversion tables are ignored, so a word Hunspell builds
+// only by compounding is reported as unknown.
+package spell
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// A Dictionary is a Hunspell dictionary loaded from its .aff and .dic
+// files.
+type Dictionary struct {
+	words     map[string]map[string]bool
+	prefixes  []affix
+	suffixes  []affix
+	try       []rune
+	flagType  string
+	forbidden string
+	needAffix string
+}
+
+// affix is one PFX or SFX rule: for a root carrying flag whose start (for
+// prefixes) or end (for suffixes) matches condition, strip is removed and
+// add put in its place.
+type affix struct {
+	flag      string
+	cross     bool
+	strip     string
+	add       string
+	condition []charClass
+}
+
+// charClass matches one character of an affix condition: any character,
+// or one of (or, negated, none of) a set.
+type charClass struct {
+	any    bool
+	negate bool
+	chars  string
+}
+
+func (c charClass) matches(r rune) bool {
+	return c.any || strings.ContainsRune(c.chars, r) != c.negate
+}
+
+// Open loads the dictionary for lang from the first of dirs holding it,
+// as <lang>.dic or, failing that, the first <lang>_<region>.dic, with the
+// .aff file of the same name next to it.
+func Open(dirs []string, lang string) (*Dictionary, error) {
+	for _, dir := range dirs {
+		dic := filepath.Join(dir, lang+".dic")
+		if _, err := os.Stat(dic); err != nil {
+			regional, _ := filepath.Glob(filepath.Join(dir, lang+"_*.dic"))
+			if len(regional) == 0 {
+				continue
+			}
+			sort.Strings(regional)
+			dic = regional[0]
+		}
+		return LoadFiles(strings.TrimSuffix(dic, ".dic")+".aff", dic)
+	}
+	return nil, fmt.Errorf("no %s dictionary in %s", lang, strings.Join(dirs, ", "))
+}
+
+// LoadFiles loads a dictionary from its .aff and .dic files.
+func LoadFiles(affPath, dicPath string) (*Dictionary, error) {
+	aff, err := os.Open(affPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer aff.Close()
+	dic, err := os.Open(dicPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dictionary: %w", err)
+	}
+	defer dic.Close()
+
+	d, err := Load(aff, dic)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dicPath, err)
+	}
+	return d, nil
+}
+
+// Load reads a dictionary from its .aff and .dic contents, which must be
+// UTF-8.
+func Load(aff, dic io.Reader) (*Dictionary, error) {
+	d := &Dictionary{words: make(map[string]map[string]bool)}
+	if err := d.readAffixes(aff); err != nil {
+		return nil, err
+	}
+	if err := d.readWords(dic); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *Dictionary) readAffixes(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	cross := make(map[string]bool)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		switch fields[0] {
+		case "SET":
+			if !strings.EqualFold(fields[1], "UTF-8") {
+				return fmt.Errorf("affix line %d: unsupported encoding %s (want UTF-8)", lineNumber, fields[1])
+			}
+		case "FLAG":
+			d.flagType = fields[1]
+		case "TRY":
+			d.try = []rune(fields[1])
+		case "FORBIDDENWORD":
+			d.forbidden = fields[1]
+		case "NEEDAFFIX":
+			d.needAffix = fields[1]
+		case "PFX", "SFX":
+			// The header "SFX flag cross count" is followed by rules
+			// "SFX flag strip add condition".
+			if len(fields) < 4 {
+				return fmt.Errorf("affix line %d: want %s flag strip add condition", lineNumber, fields[0])
+			}
+			if _, err := strconv.Atoi(fields[3]); err == nil && len(fields) == 4 && (fields[2] == "Y" || fields[2] == "N") {
+				cross[fields[0]+fields[1]] = fields[2] == "Y"
+				continue
+			}
+			a, err := parseAffix(fields, fields[0] == "PFX")
+			if err != nil {
+				return fmt.Errorf("affix line %d: %w", lineNumber, err)
+			}
+			a.cross = cross[fields[0]+fields[1]]
+			if fields[0] == "PFX" {
+				d.prefixes = append(d.prefixes, a)
+			} else {
+				d.suffixes = append(d.suffixes, a)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read affixes: %w", err)
+	}
+	return nil
+}
+
+func parseAffix(fields []string, prefix bool) (affix, error) {
+	a := affix{flag: fields[1], strip: fields[2], add: fields[3]}
+	if a.strip == "0" {
+		a.strip = ""
+	}
+	// Continuation flags on the added text are not supported.
+	a.add, _, _ = strings.Cut(a.add, "/")
+	if a.add == "0" {
+		a.add = ""
+	}
+	condition, err := parseCondition(".")
+	if len(fields) > 4 {
+		condition, err = parseCondition(fields[4])
+	}
+	if err != nil {
+		return affix{}, err
+	}
+	if condition != nil && !prefix {
+		// Suffix conditions are matched from the end of the root.
+		for i, j := 0, len(condition)-1; i < j; i, j = i+1, j-1 {
+			condition[i], condition[j] = condition[j], condition[i]
+		}
+	}
+	a.condition = condition
+	return a, nil
+}
+
+func parseCondition(s string) ([]charClass, error) {
+	if s == "." {
+		return nil, nil
+	}
+	var classes []charClass
+	for s != "" {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch r {
+		case '.':
+			classes = append(classes, charClass{any: true})
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed [ in condition")
+			}
+			set := s[:end]
+			s = s[end+1:]
+			class := charClass{chars: set}
+			if strings.HasPrefix(set, "^") {
+				class = charClass{negate: true, chars: set[1:]}
+			}
+			classes = append(classes, class)
+		default:
+			classes = append(classes, charClass{chars: string(r)})
+		}
+	}
+	return classes, nil
+}
+
+func (d *Dictionary) readWords(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	first := true
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if first {
+			// The approximate word count.
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		// Morphological fields follow whitespace.
+		if i := strings.IndexFunc(line, unicode.IsSpace); i >= 0 {
+			line = line[:i]
+		}
+		word, flags, _ := strings.Cut(line, "/")
+		set := d.words[word]
+		if set == nil {
+			set = make(map[string]bool)
+			d.words[word] = set
+		}
+		for _, flag := range d.splitFlags(flags) {
+			set[flag] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read words: %w", err)
+	}
+	return nil
+}
+
+// splitFlags splits a word's flags as declared by the FLAG option: single
+// characters by default, character pairs for "long" and comma-separated
+// numbers for "num".
+func (d *Dictionary) splitFlags(s string) []string {
+	if s == "" {
+		return nil
+	}
+	switch d.flagType {
+	case "long":
+		var flags []string
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags = append(flags, string(runes[i:i+2]))
+		}
+		return flags
+	case "num":
+		return strings.Split(s, ",")
+	}
+	var flags []string
+	for _, r := range s {
+		flags = append(flags, string(r))
+	}
+	return flags
+}
+
+// Check reports whether word is in the dictionary, directly or through
+// its affix rules. A capitalized or upper-case word is also accepted in
+// lower case.
+func (d *Dictionary) Check(word string) bool {
+	if d.check(word) {
+		return true
+	}
+	lower := strings.ToLower(word)
+	if lower == word {
+		return false
+	}
+	if d.check(lower) {
+		return true
+	}
+	// An upper-case word may be a capitalized name.
+	r, size := utf8.DecodeRuneInString(lower)
+	return d.check(string(unicode.ToUpper(r)) + lower[size:])
+}
+
+func (d *Dictionary) check(word string) bool {
+	if flags, ok := d.words[word]; ok && !flags[d.forbidden] && !flags[d.needAffix] {
+		return true
+	}
+	if d.forbiddenWord(word) {
+		return false
+	}
+	for _, s := range d.suffixes {
+		if root, ok := s.root(word, false); ok && d.hasFlag(root, s.flag) {
+			return true
+		}
+	}
+	for _, p := range d.prefixes {
+		root, ok := p.root(word, true)
+		if !ok {
+			continue
+		}
+		if d.hasFlag(root, p.flag) {
+			return true
+		}
+		if !p.cross {
+			continue
+		}
+		for _, s := range d.suffixes {
+			if inner, ok := s.root(root, false); ok && s.cross && d.hasFlag(inner, s.flag) && d.hasFlag(inner, p.flag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *Dictionary) forbiddenWord(word string) bool {
+	return d.forbidden != "" && d.words[word][d.forbidden]
+}
+
+func (d *Dictionary) hasFlag(root, flag string) bool {
+	flags := d.words[root]
+	return flags[flag] && !flags[d.forbidden]
+}
+
+// root undoes the affix on word, returning the root it applies to.
+func (a affix) root(word string, prefix bool) (string, bool) {
+	var root string
+	if prefix {
+		rest, ok := strings.CutPrefix(word, a.add)
+		if !ok {
+			return "", false
+		}
+		root = a.strip + rest
+	} else {
+		rest, ok := strings.CutSuffix(word, a.add)
+		if !ok {
+			return "", false
+		}
+		root = rest + a.strip
+	}
+	if root == "" || !a.matches(root, prefix) {
+		return "", false
+	}
+	return root, true
+}
+
+func (a affix) matches(root string, prefix bool) bool {
+	runes := []rune(root)
+	if len(runes) < len(a.condition) {
+		return false
+	}
+	for i, class := range a.condition {
+		r := runes[i]
+		if !prefix {
+			r = runes[len(runes)-1-i]
+		}
+		if !class.matches(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// Suggest returns up to max known words one edit away from word, trying
+// the characters listed in the dictionary's TRY option. Words differing
+// only in case are suggested once.
+func (d *Dictionary) Suggest(word string, max int) []string {
+	runes := []rune(word)
+	seen := map[string]bool{word: true}
+	var suggestions []string
+	try := func(candidate []rune) bool {
+		s := string(candidate)
+		if !seen[s] {
+			seen[s] = true
+			duplicate := slices.ContainsFunc(suggestions, func(t string) bool { return strings.EqualFold(s, t) })
+			if !duplicate && d.Check(s) {
+				suggestions = append(suggestions, s)
+			}
+		}
+		return len(suggestions) >= max
+	}
+
+	edit := make([]rune, 0, len(runes)+1)
+	for i := 0; i+1 < len(runes); i++ {
+		edit = append(edit[:0], runes...)
+		edit[i], edit[i+1] = edit[i+1], edit[i]
+		if try(edit) {
+			return suggestions
+		}
+	}
+	for i := range runes {
+		edit = append(append(edit[:0], runes[:i]...), runes[i+1:]...)
+		if try(edit) {
+			return suggestions
+		}
+	}
+	for _, c := range d.try {
+		for i := range runes {
+			edit = append(edit[:0], runes...)
+			edit[i] = c
+			if try(edit) {
+				return suggestions
+			}
+		}
+		for i := 0; i <= len(runes); i++ {
+			edit = append(append(append(edit[:0], runes[:i]...), c), runes[i:]...)
+			if try(edit) {
+				return suggestions
+			}
+		}
+	}
+	return suggestions
+}
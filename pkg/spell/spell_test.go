@@ -0,0 +1,111 @@
+package spell
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+const testAffixes = `SET UTF-8
+TRY esianrtolcdugmphbyfvkwzESIANRTOLCDUGMPHBYFVKWZ
+FORBIDDENWORD !
+NEEDAFFIX ~
+
+PFX U Y 1
+PFX U 0 un .
+
+SFX S Y 2
+SFX S y ies [^aeiou]y
+SFX S 0 s [^y]
+
+SFX D Y 2
+SFX D 0 d e
+SFX D 0 ed [^e]
+`
+
+const testWords = `9
+receive/D
+happy
+city/S
+car/S
+do/U
+walk/~DU
+tell/U
+don't
+ok/!
+สวัสดี
+ครับ
+`
+
+func loadTestDictionary(t *testing.T) *Dictionary {
+	t.Helper()
+	d, err := Load(strings.NewReader(testAffixes), strings.NewReader(testWords))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDictionaryCheck(t *testing.T) {
+	d := loadTestDictionary(t)
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"receive", true},
+		{"received", true},
+		{"Received", true},
+		{"RECEIVED", true},
+		{"cities", true},
+		{"citys", false},
+		{"cars", true},
+		{"undo", true},
+		{"walk", false},
+		{"walked", true},
+		{"unwalked", true},
+		{"untelled", false},
+		{"don't", true},
+		{"ok", false},
+		{"recieve", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.word, func(t *testing.T) {
+			if got := d.Check(tt.word); got != tt.want {
+				t.Errorf("Check(%q) = %v, want %v", tt.word, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDictionarySuggest(t *testing.T) {
+	d := loadTestDictionary(t)
+	if got, want := d.Suggest("recieve", 3), []string{"receive"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %v, want %v", got, want)
+	}
+	if got, want := d.Suggest("happpy", 3), []string{"happy"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Suggest() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckerCheck(t *testing.T) {
+	checker := NewChecker(loadTestDictionary(t))
+	cues := []subtitle.Cue{
+		{Text: "Happy cities, don’t walk"},
+		{Text: "สวัสดีครับ 42 cars"},
+		{Text: "I recieved\nสวัสดีคับ"},
+	}
+	want := []Misspelling{
+		{Cue: 1, Word: "walk"},
+		{Cue: 3, Word: "I"},
+		{Cue: 3, Word: "recieved", Suggestions: []string{"received"}},
+		{Cue: 3, Word: "สวัสดีคับ"},
+	}
+	if got := checker.Check(cues); !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %+v, want %+v", got, want)
+	}
+	if got, want := want[2].String(), `cue 3: unknown word "recieved" (did you mean received?)`; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,164 @@
+// Package storage writes outputs to cloud object stores named by URL, so
+// converted subtitles can go straight to a bucket:
+//
+//	s3://bucket/key.srt
+//	gs://bucket/object.srt
+//	azure://account/container/blob.srt
+//
+// Credentials come from the environment. S3 uses AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, the optional AWS_SESSION_TOKEN and AWS_REGION,
+// and AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL for S3-compatible stores.
+// Google Cloud Storage uses the OAuth 2.0 access token in
+// GOOGLE_OAUTH_ACCESS_TOKEN, and STORAGE_EMULATOR_HOST when set. Azure Blob
+// Storage uses the shared access signature in AZURE_STORAGE_SAS_TOKEN.
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Client uploads outputs to object stores. The zero value is ready to use.
+type Client struct {
+	// HTTPClient sends the uploads, replacing http.DefaultClient when set,
+	// for example to retry them with a retry.Transport.
+	HTTPClient *http.Client
+}
+
+// schemes maps URL schemes to the function building the upload request for
+// an object, given its bucket and key.
+var schemes = map[string]func(ctx context.Context, bucket, key string, body io.ReadSeeker) (*http.Request, error){
+	"s3":    s3Request,
+	"gs":    gcsRequest,
+	"azure": azureRequest,
+}
+
+// IsURL reports whether name is an object store URL rather than a local
+// file name.
+func IsURL(name string) bool {
+	scheme, _, ok := strings.Cut(name, "://")
+	_, known := schemes[scheme]
+	return ok && known
+}
+
+// Put uploads body to the object named by rawURL, replacing the object if
+// it exists.
+func (c *Client) Put(ctx context.Context, rawURL string, body io.ReadSeeker) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("invalid storage URL: %w", err)
+	}
+	newRequest, ok := schemes[u.Scheme]
+	if !ok {
+		return fmt.Errorf("unsupported storage URL %s (want s3://, gs:// or azure://)", rawURL)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return fmt.Errorf("storage URL %s needs a bucket and an object name", rawURL)
+	}
+
+	req, err := newRequest(ctx, u.Host, key, body)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", rawURL, err)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("failed to upload %s: %s: %s", rawURL, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// newPut returns a PUT request sending all of body, rewinding it when the
+// request is sent again.
+func newPut(ctx context.Context, target string, body io.ReadSeeker) (*http.Request, error) {
+	size, err := body.Seek(0, io.SeekEnd)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target, io.NopCloser(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		_, err := body.Seek(0, io.SeekStart)
+		return io.NopCloser(body), err
+	}
+	if size == 0 {
+		req.Body, req.GetBody = http.NoBody, nil
+	}
+	return req, nil
+}
+
+func gcsRequest(ctx context.Context, bucket, key string, body io.ReadSeeker) (*http.Request, error) {
+	token := os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")
+	endpoint := "https://storage.googleapis.com"
+	if host := os.Getenv("STORAGE_EMULATOR_HOST"); host != "" {
+		endpoint = strings.TrimSuffix(host, "/")
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+	} else if token == "" {
+		return nil, fmt.Errorf("GOOGLE_OAUTH_ACCESS_TOKEN is not set")
+	}
+
+	req, err := newPut(ctx, endpoint+"/"+bucket+"/"+escapePath(key), body)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req, nil
+}
+
+func azureRequest(ctx context.Context, account, path string, body io.ReadSeeker) (*http.Request, error) {
+	sas := strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?")
+	if sas == "" {
+		return nil, fmt.Errorf("AZURE_STORAGE_SAS_TOKEN is not set")
+	}
+	if container, blob, _ := strings.Cut(path, "/"); container == "" || blob == "" {
+		return nil, fmt.Errorf("azure URLs need a container and a blob name")
+	}
+
+	req, err := newPut(ctx, "https://"+account+".blob.core.windows.net/"+escapePath(path)+"?"+sas, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-version", "2021-08-06")
+	return req, nil
+}
+
+// escapePath percent-encodes an object name the way S3's signature
+// requires, which the other stores accept too: every byte except
+// unreserved characters and slashes.
+func escapePath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-._~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
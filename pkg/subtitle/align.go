@@ -0,0 +1,169 @@
+package subtitle
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// alignWindow is how many cue words are aligned with the reference at a
+// time. Each window is compared with twice as many reference words, so a
+// reference may run ahead of the cues by about that much.
+const alignWindow = 400
+
+// Realign corrects the timing of cues against reference, a transcript of
+// the final video such as one produced by Whisper, for captions that
+// drifted when the edit changed after they were generated. Words of the
+// cues are matched with words of the reference, each cue is shifted by the
+// median offset of its matched words, and cues without a match keep the
+// offset of the cue before them. Durations are kept. It returns the
+// realigned cues and how many of them matched the reference; when none
+// did, the cues come back unchanged.
+func Realign(cues, reference []Cue) ([]Cue, int) {
+	words, wordCues := alignWords(cues)
+	refWords, _ := alignWords(reference)
+
+	offsets := make([][]int64, len(cues))
+	for _, m := range alignSequences(words, refWords) {
+		w, ref := words[m[0]], refWords[m[1]]
+		offsets[wordCues[m[0]]] = append(offsets[wordCues[m[0]]], ref.at-w.at)
+	}
+
+	realigned := slices.Clone(cues)
+	matched := 0
+	var shift int64
+	first := slices.IndexFunc(offsets, func(o []int64) bool { return len(o) > 0 })
+	if first < 0 {
+		return realigned, 0
+	}
+	for i := range realigned {
+		if o := offsets[max(i, first)]; len(o) > 0 {
+			slices.Sort(o)
+			shift = o[len(o)/2]
+			if i >= first {
+				matched++
+			}
+		}
+		realigned[i].Start += shift
+		realigned[i].End += shift
+	}
+	return realigned, matched
+}
+
+// alignWord is a normalized word and the time it is estimated to be spoken
+// at, spreading each cue's duration evenly over its words.
+type alignWord struct {
+	text string
+	at   int64
+}
+
+// alignWords splits cues into words and returns, for each word, the index
+// of its cue.
+func alignWords(cues []Cue) ([]alignWord, []int) {
+	var words []alignWord
+	var wordCues []int
+	for i, c := range cues {
+		tokens := alignTokens(c.Text)
+		for k, token := range tokens {
+			at := c.Start + (c.End-c.Start)*int64(k)/int64(len(tokens))
+			words = append(words, alignWord{text: token, at: at})
+			wordCues = append(wordCues, i)
+		}
+	}
+	return words, wordCues
+}
+
+// alignTokens lowercases text and splits it into words, ignoring
+// punctuation. Scripts written without spaces, like Thai, Chinese and
+// Japanese, are split into single characters instead.
+func alignTokens(text string) []string {
+	var tokens []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.In(r, unicode.Thai, unicode.Han, unicode.Hiragana, unicode.Katakana):
+			flush()
+			tokens = append(tokens, string(r))
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+			word.WriteRune(r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// alignSequences returns the index pairs of words matched between a and b,
+// in order. It takes the longest common subsequence of a window of a and a
+// wider window of b, keeps the matches in the first half of the window and
+// continues after the last of them, which keeps the work linear in the
+// length of long transcripts.
+func alignSequences(a, b []alignWord) [][2]int {
+	var matches [][2]int
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		aEnd, bEnd := min(i+alignWindow, len(a)), min(j+2*alignWindow, len(b))
+		last := aEnd == len(a)
+		limit := i + alignWindow/2
+
+		committed := false
+		for _, m := range longestCommon(a[i:aEnd], b[j:bEnd]) {
+			m = [2]int{m[0] + i, m[1] + j}
+			if !last && m[0] >= limit {
+				break
+			}
+			matches = append(matches, m)
+			committed = true
+		}
+		switch {
+		case last:
+			return matches
+		case committed:
+			i, j = matches[len(matches)-1][0]+1, matches[len(matches)-1][1]+1
+		default:
+			i = limit
+		}
+	}
+	return matches
+}
+
+// longestCommon returns the index pairs of a longest common subsequence of
+// the words' texts.
+func longestCommon(a, b []alignWord) [][2]int {
+	n, m := len(a), len(b)
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i].text == b[j].text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var pairs [][2]int
+	for i, j := 0, 0; i < n && j < m; {
+		switch {
+		case a[i].text == b[j].text:
+			pairs = append(pairs, [2]int{i, j})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return pairs
+}
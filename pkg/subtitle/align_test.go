@@ -0,0 +1,82 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRealign(t *testing.T) {
+	const s = 1_000_000
+	cues := []Cue{
+		{Start: 0, End: 2 * s, Text: "Hello, world!"},
+		{Start: 2 * s, End: 6 * s, Text: "This is a test."},
+		{Start: 10 * s, End: 12 * s, Text: "Good bye"},
+		{Start: 12 * s, End: 13 * s, Text: "[applause]"},
+	}
+	// The first two cues start 1.5s later in the final video and the
+	// third 2s earlier, after a cut. Whisper segments the speech
+	// differently and hears an extra word.
+	reference := []Cue{
+		{Start: 1.5 * s, End: 5.5 * s, Text: "hello world this is"},
+		{Start: 5.5 * s, End: 7.5 * s, Text: "a test"},
+		{Start: 7 * s, End: 10 * s, Text: "so good bye"},
+	}
+
+	got, matched := Realign(cues, reference)
+	want := []Cue{
+		{Start: 1.5 * s, End: 3.5 * s, Text: "Hello, world!"},
+		{Start: 3.5 * s, End: 7.5 * s, Text: "This is a test."},
+		{Start: 8 * s, End: 10 * s, Text: "Good bye"},
+		{Start: 10 * s, End: 11 * s, Text: "[applause]"},
+	}
+	if !reflect.DeepEqual(got, want) || matched != 3 {
+		t.Errorf("Realign() = %v, %d, want %v, 3", got, matched, want)
+	}
+	if cues[0].Start != 0 {
+		t.Errorf("Realign() modified its input")
+	}
+
+	if got, matched := Realign(cues, []Cue{{Start: 0, End: s, Text: "nothing alike"}}); !reflect.DeepEqual(got, cues) || matched != 0 {
+		t.Errorf("Realign() without matches = %v, %d, want the cues unchanged", got, matched)
+	}
+}
+
+func TestAlignTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "Don't stop, 2024!", want: []string{"don", "t", "stop", "2024"}},
+		{input: "สวัสดี", want: []string{"ส", "ว", "ั", "ส", "ด", "ี"}},
+		{input: "日本語 ok", want: []string{"日", "本", "語", "ok"}},
+	}
+
+	for _, tt := range tests {
+		if got := alignTokens(tt.input); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("alignTokens(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestAlignSequencesLong(t *testing.T) {
+	// Longer than several windows, with the reference missing every tenth
+	// word: every other word must still be matched with itself.
+	var a, b []alignWord
+	for i := range 5 * alignWindow {
+		w := alignWord{text: string(rune('a' + i%7)), at: int64(i)}
+		a = append(a, w)
+		if i%10 != 0 {
+			b = append(b, w)
+		}
+	}
+
+	matches := alignSequences(a, b)
+	if want := len(b); len(matches) != want {
+		t.Fatalf("alignSequences() matched %d words, want %d", len(matches), want)
+	}
+	for _, m := range matches {
+		if a[m[0]].at != b[m[1]].at {
+			t.Fatalf("alignSequences() matched word %d with %d", a[m[0]].at, b[m[1]].at)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package subtitle
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Chapter is a titled section of a video starting at Start.
+type Chapter struct {
+	Start int64
+	Title string
+}
+
+// Chapters turns the cues of a marker track into chapters ordered by start
+// time, joining the lines of each cue into one title. Of markers starting
+// together, the first is kept.
+func Chapters(cues []Cue) []Chapter {
+	markers := slices.Clone(cues)
+	Sort(markers)
+
+	var chapters []Chapter
+	for _, c := range markers {
+		title := strings.Join(strings.Fields(c.Text), " ")
+		if title == "" || len(chapters) > 0 && chapters[len(chapters)-1].Start == c.Start {
+			continue
+		}
+		chapters = append(chapters, Chapter{Start: max(c.Start, 0), Title: title})
+	}
+	return chapters
+}
+
+// YouTube only turns a chapter list into chapters when it has at least
+// minChapters entries of at least minChapterLength each.
+const (
+	minChapters      = 3
+	minChapterLength = 10 * int64(time.Second/time.Microsecond)
+)
+
+// CheckYouTubeChapters describes why YouTube would ignore chapters. The
+// first chapter always counts as starting at 00:00, as YouTube requires and
+// the list is written. The video's length is not known, so the last
+// chapter is not checked.
+func CheckYouTubeChapters(chapters []Chapter) []string {
+	var problems []string
+	if len(chapters) < minChapters {
+		problems = append(problems, fmt.Sprintf("YouTube needs at least %d chapters, got %d", minChapters, len(chapters)))
+	}
+	for i := 1; i < len(chapters); i++ {
+		start := chapters[i-1].Start
+		if i == 1 {
+			start = 0
+		}
+		if length := chapters[i].Start - start; length < minChapterLength {
+			problems = append(problems, fmt.Sprintf("chapter %q is %v long, shorter than YouTube's minimum of 10s", chapters[i-1].Title, time.Duration(length)*time.Microsecond))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,55 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChapters(t *testing.T) {
+	cues := []Cue{
+		{Start: 133_000_000, End: 134_000_000, Text: "Main\ntopic"},
+		{Start: 500_000, End: 2_000_000, Text: "Intro"},
+		{Start: 133_000_000, End: 135_000_000, Text: "Duplicate"},
+		{Start: 200_000_000, End: 201_000_000, Text: " "},
+	}
+
+	got := Chapters(cues)
+	want := []Chapter{
+		{Start: 500_000, Title: "Intro"},
+		{Start: 133_000_000, Title: "Main topic"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Chapters() = %v, want %v", got, want)
+	}
+}
+
+func TestCheckYouTubeChapters(t *testing.T) {
+	tests := []struct {
+		name     string
+		chapters []Chapter
+		want     int
+	}{
+		{
+			name:     "valid",
+			chapters: []Chapter{{Start: 2_000_000, Title: "Intro"}, {Start: 10_000_000, Title: "A"}, {Start: 20_000_000, Title: "B"}},
+		},
+		{
+			name:     "too few",
+			chapters: []Chapter{{Start: 0, Title: "Intro"}, {Start: 60_000_000, Title: "A"}},
+			want:     1,
+		},
+		{
+			name:     "too short",
+			chapters: []Chapter{{Start: 0, Title: "Intro"}, {Start: 60_000_000, Title: "A"}, {Start: 65_000_000, Title: "B"}},
+			want:     1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CheckYouTubeChapters(tt.chapters); len(got) != tt.want {
+				t.Errorf("CheckYouTubeChapters() = %q, want %d problems", got, tt.want)
+			}
+		})
+	}
+}
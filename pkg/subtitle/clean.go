@@ -0,0 +1,176 @@
+package subtitle
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// BracketMode selects how CleanText treats "[" and "]".
+type BracketMode int
+
+const (
+	// BracketsStrip drops the bracket characters but keeps their content.
+	BracketsStrip BracketMode = iota
+	// BracketsRemove drops bracketed content, such as "[music]", entirely.
+	BracketsRemove
+	// BracketsKeep leaves brackets and their content untouched.
+	BracketsKeep
+)
+
+func ParseBracketMode(s string) (BracketMode, error) {
+	switch s {
+	case "strip":
+		return BracketsStrip, nil
+	case "remove":
+		return BracketsRemove, nil
+	case "keep":
+		return BracketsKeep, nil
+	}
+	return 0, fmt.Errorf("unknown bracket mode %q (want strip, remove or keep)", s)
+}
+
+// CleanText strips the markup CapCut stores in material content: tags are
+// removed, HTML character references are decoded and brackets are handled
+// according to brackets.
+func CleanText(input string, brackets BracketMode) string {
+	if len(input) == 0 {
+		return input
+	}
+
+	var sb strings.Builder
+	inTag := false
+	depth := 0
+
+	for i := 0; i < len(input); {
+		switch input[i] {
+		case '<':
+			inTag = true
+			i++
+		case '>':
+			inTag = false
+			i++
+		case '[':
+			if brackets == BracketsKeep {
+				if !inTag {
+					sb.WriteByte('[')
+				}
+			} else if brackets == BracketsRemove {
+				depth++
+			}
+			i++
+		case ']':
+			if brackets == BracketsKeep {
+				if !inTag {
+					sb.WriteByte(']')
+				}
+			} else if brackets == BracketsRemove && depth > 0 {
+				depth--
+				// Swallow the space after a removed annotation so
+				// "Hello [music] world" doesn't end up with a double space.
+				if depth == 0 && i+1 < len(input) && input[i+1] == ' ' {
+					if out := sb.String(); len(out) == 0 || out[len(out)-1] == ' ' {
+						i++
+					}
+				}
+			}
+			i++
+		case '&':
+			if inTag || depth > 0 {
+				// References in tag bodies, such as a font path, are
+				// part of the markup, not the text.
+				i++
+			} else if decoded, n := decodeEntity(input[i:]); n > 0 {
+				sb.WriteString(decoded)
+				i += n
+			} else {
+				sb.WriteByte(input[i])
+				i++
+			}
+		default:
+			if !inTag && depth == 0 {
+				sb.WriteByte(input[i])
+			}
+			i++
+		}
+	}
+
+	if brackets == BracketsRemove {
+		return strings.TrimRight(sb.String(), " ")
+	}
+	return sb.String()
+}
+
+// maxEntityLen bounds the scan for a terminating ';' after '&'; the longest
+// named HTML entity is &CounterClockwiseContourIntegral; at 33 bytes.
+const maxEntityLen = 40
+
+// decodeEntity decodes the HTML character reference at the start of s, which
+// must begin with '&'. It returns the decoded text and the number of bytes
+// consumed, or 0 if s does not start with a complete, known reference.
+func decodeEntity(s string) (string, int) {
+	end := strings.IndexByte(s[:min(len(s), maxEntityLen)], ';')
+	if end < 2 {
+		return "", 0
+	}
+
+	if s[1] == '#' {
+		r, ok := parseCharRef(s[2:end])
+		if !ok {
+			return "", 0
+		}
+		return string(r), end + 1
+	}
+
+	switch s[1:end] {
+	case "lt":
+		return "<", end + 1
+	case "gt":
+		return ">", end + 1
+	case "amp":
+		return "&", end + 1
+	case "quot":
+		return "\"", end + 1
+	case "apos":
+		return "'", end + 1
+	case "nbsp":
+		return "\u00a0", end + 1
+	}
+
+	entity := s[:end+1]
+	if decoded := html.UnescapeString(entity); decoded != entity {
+		return decoded, end + 1
+	}
+	return "", 0
+}
+
+// parseCharRef parses the digits of a numeric reference such as "233" or
+// "xE9". Out-of-range and surrogate code points decode to U+FFFD.
+func parseCharRef(ref string) (rune, bool) {
+	base := 10
+	if len(ref) > 0 && (ref[0] == 'x' || ref[0] == 'X') {
+		base = 16
+		ref = ref[1:]
+	}
+	if len(ref) == 0 {
+		return 0, false
+	}
+
+	n, err := strconv.ParseUint(ref, base, 32)
+	if err != nil {
+		var numErr *strconv.NumError
+		if errors.As(err, &numErr) && errors.Is(numErr.Err, strconv.ErrRange) {
+			return utf8.RuneError, true
+		}
+		return 0, false
+	}
+
+	r := rune(n)
+	if r == 0 || !utf8.ValidRune(r) {
+		return utf8.RuneError, true
+	}
+	return r, true
+}
@@ -0,0 +1,127 @@
+package subtitle
+
+import "testing"
+
+func TestCleanText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		brackets BracketMode
+		want     string
+	}{
+		{
+			name:  "empty string",
+			input: "",
+			want:  "",
+		},
+		{
+			name:  "no tags",
+			input: "Hello world",
+			want:  "Hello world",
+		},
+		{
+			name:  "with tags",
+			input: "Hello <b>world</b>",
+			want:  "Hello world",
+		},
+		{
+			name:  "with square brackets",
+			input: "Hello [world]",
+			want:  "Hello world",
+		},
+		{
+			name:  "with escaped lt",
+			input: "Hello &lt;world&gt;",
+			want:  "Hello <world>",
+		},
+		{
+			name:  "with escaped gt",
+			input: "Hello &gt;world&lt;",
+			want:  "Hello >world<",
+		},
+		{
+			name:  "entities inside tags",
+			input: `<font path="Tom&amp;Jerry.ttf"><color=&gt;>Hi &amp; bye</color></font>`,
+			want:  "Hi & bye",
+		},
+		{
+			name:  "mixed content",
+			input: "<b>Hello</b> [world] &lt;3",
+			want:  "Hello world <3",
+		},
+		{
+			name:  "multiple tags",
+			input: "<i><b>Hello</b> world</i>",
+			want:  "Hello world",
+		},
+		{
+			name:  "self-closing tag",
+			input: "Hello<br/>world",
+			want:  "Helloworld",
+		},
+		{
+			name:  "named entities",
+			input: "Tom &amp; Jerry &quot;caf&eacute;&quot; &copy;",
+			want:  "Tom & Jerry \"café\" ©",
+		},
+		{
+			name:  "numeric entities",
+			input: "&#3586;&#x0E01; &#X1F600;",
+			want:  "ขก 😀",
+		},
+		{
+			name:  "invalid numeric entity",
+			input: "&#xD800; &#99999999999;",
+			want:  "\uFFFD \uFFFD",
+		},
+		{
+			name:  "unknown or unterminated entities",
+			input: "AT&T &bogus; &amp",
+			want:  "AT&T &bogus; &amp",
+		},
+		{
+			name:  "decoded entity is not treated as a tag",
+			input: "&lt;b&gt;bold&lt;/b&gt;",
+			want:  "<b>bold</b>",
+		},
+		{
+			name:     "remove bracketed content",
+			input:    "Hello [music] world",
+			brackets: BracketsRemove,
+			want:     "Hello world",
+		},
+		{
+			name:     "remove leading and trailing annotations",
+			input:    "[music] Hello world [laughs]",
+			brackets: BracketsRemove,
+			want:     "Hello world",
+		},
+		{
+			name:     "remove nested brackets",
+			input:    "Hi [a [b] c] there",
+			brackets: BracketsRemove,
+			want:     "Hi there",
+		},
+		{
+			name:     "only an annotation",
+			input:    "[music]",
+			brackets: BracketsRemove,
+			want:     "",
+		},
+		{
+			name:     "keep brackets",
+			input:    "<i>Hello</i> [music]",
+			brackets: BracketsKeep,
+			want:     "Hello [music]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CleanText(tt.input, tt.brackets)
+			if got != tt.want {
+				t.Errorf("CleanText() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
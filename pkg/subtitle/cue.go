@@ -0,0 +1,227 @@
+// Package subtitle holds the cue model shared by the readers and writers and
+// the transforms applied between them.
+package subtitle
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+)
+
+// Cue is a single timed subtitle. Times are in microseconds, the unit CapCut
+// drafts use.
+type Cue struct {
+	Start  int64
+	End    int64
+	Text   string
+	Source Source
+	// Speaker is who says the cue, if known.
+	Speaker string
+	// Original is the text before any pass changed it, when kept for
+	// tracing the cue back to its draft.
+	Original string
+	// Emphasis is how the cue stands out from the text around it, in the
+	// formats that can show it.
+	Emphasis Emphasis
+}
+
+// Emphasis marks words CapCut styles differently from the rest of their
+// caption, such as a highlighted keyword.
+type Emphasis int
+
+const (
+	EmphasisNone Emphasis = iota
+	EmphasisItalic
+	EmphasisBold
+	// EmphasisColor highlights the text in yellow.
+	EmphasisColor
+)
+
+var emphasisNames = []string{"", "italic", "bold", "color"}
+
+func (e Emphasis) String() string {
+	if e < 0 || int(e) >= len(emphasisNames) {
+		return fmt.Sprintf("Emphasis(%d)", int(e))
+	}
+	return emphasisNames[e]
+}
+
+// ParseEmphasis parses the name String returns; "" is EmphasisNone.
+func ParseEmphasis(s string) (Emphasis, error) {
+	if i := slices.Index(emphasisNames, s); i >= 0 {
+		return Emphasis(i), nil
+	}
+	return 0, fmt.Errorf("unknown emphasis %q (want italic, bold or color)", s)
+}
+
+// Source records where in a draft a cue came from. It is zero for cues read
+// from subtitle files.
+type Source struct {
+	MaterialID string
+	TrackID    string
+	// TrackNumber is the 1-based position of the track among the draft's
+	// text tracks.
+	TrackNumber int
+	// Segment is the index of the segment within its track.
+	Segment int
+}
+
+// DropEmpty removes cues without text, which would be invalid in most
+// subtitle formats.
+func DropEmpty(cues []Cue) []Cue {
+	return slices.DeleteFunc(cues, func(c Cue) bool {
+		return c.Text == ""
+	})
+}
+
+// Sort orders cues from all text tracks by start time. The sort is stable
+// so cues starting together keep their track and segment order.
+func Sort(cues []Cue) {
+	slices.SortStableFunc(cues, byStart)
+}
+
+func byStart(a, b Cue) int {
+	return cmp.Compare(a.Start, b.Start)
+}
+
+// Dedup collapses cues that repeat the same text over overlapping time
+// ranges, as text templates tend to produce, into a single cue spanning both.
+// Cues sorted by start time, as they are after Sort, take linear time; other
+// orders compare each cue with every earlier one of the same text.
+func Dedup(cues []Cue) []Cue {
+	if slices.IsSortedFunc(cues, byStart) {
+		return slices.Collect(DedupSorted(slices.Values(cues)))
+	}
+	return dedupUnsorted(cues)
+}
+
+func dedupUnsorted(cues []Cue) []Cue {
+	kept := cues[:0:0]
+	byText := make(map[string][]int)
+
+	for _, c := range cues {
+		duplicate := false
+		for _, i := range byText[c.Text] {
+			if overlaps(kept[i], c) {
+				kept[i].Start = min(kept[i].Start, c.Start)
+				kept[i].End = max(kept[i].End, c.End)
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			byText[c.Text] = append(byText[c.Text], len(kept))
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// DedupSorted is Dedup for cues already sorted by start time, reading and
+// yielding them one at a time. It holds only the cues that a later cue could
+// still overlap, so it suits inputs too large for memory and stays linear
+// when the same words repeat throughout a long video.
+func DedupSorted(cues iter.Seq[Cue]) iter.Seq[Cue] {
+	return func(yield func(Cue) bool) {
+		// pending holds kept cues not yet yielded, in order; head is the
+		// index of the first one. byText lists, per text, the indexes of
+		// the pending cues a later cue may still merge into.
+		var pending []Cue
+		head := 0
+		byText := make(map[string][]int)
+
+		for c := range cues {
+			ids := slices.DeleteFunc(byText[c.Text], func(i int) bool {
+				return i < head || settled(pending[i-head], c.Start)
+			})
+			duplicate := false
+			for _, i := range ids {
+				if overlaps(pending[i-head], c) {
+					pending[i-head].End = max(pending[i-head].End, c.End)
+					duplicate = true
+					break
+				}
+			}
+			if !duplicate {
+				ids = append(ids, head+len(pending))
+				pending = append(pending, c)
+			}
+			byText[c.Text] = ids
+
+			for len(pending) > 0 && settled(pending[0], c.Start) {
+				done := pending[0]
+				pending = pending[1:]
+				head++
+				if ids := slices.DeleteFunc(byText[done.Text], func(i int) bool { return i < head }); len(ids) == 0 {
+					delete(byText, done.Text)
+				} else {
+					byText[done.Text] = ids
+				}
+				if !yield(done) {
+					return
+				}
+			}
+		}
+		for _, c := range pending {
+			if !yield(c) {
+				return
+			}
+		}
+	}
+}
+
+// settled reports whether no cue starting at start or later can overlap
+// kept.
+func settled(kept Cue, start int64) bool {
+	return kept.End <= start && kept.Start < start
+}
+
+func overlaps(a, b Cue) bool {
+	return a.Start == b.Start || a.Start < b.End && b.Start < a.End
+}
+
+type Part struct {
+	Number int
+	Cues   []Cue
+}
+
+// Split groups sorted cues into consecutive chunks of the given length in
+// microseconds, rebasing each chunk's timings to start at zero. A cue belongs
+// to the chunk it starts in. Part numbers follow the chunk's position on the
+// timeline, so chunks without cues leave a gap rather than shifting later
+// parts.
+func Split(cues []Cue, every int64) []Part {
+	var parts []Part
+	for _, c := range cues {
+		number := int(max(c.Start, 0)/every) + 1
+		if len(parts) == 0 || parts[len(parts)-1].Number != number {
+			parts = append(parts, Part{Number: number})
+		}
+
+		offset := int64(number-1) * every
+		part := &parts[len(parts)-1]
+		c.Start -= offset
+		c.End -= offset
+		part.Cues = append(part.Cues, c)
+	}
+	return parts
+}
+
+// Merge combines two cue lists into one chronological timeline after
+// shifting each by its offset in microseconds. The inputs are not modified.
+func Merge(a []Cue, offsetA int64, b []Cue, offsetB int64) []Cue {
+	merged := make([]Cue, 0, len(a)+len(b))
+	for _, c := range a {
+		c.Start += offsetA
+		c.End += offsetA
+		merged = append(merged, c)
+	}
+	for _, c := range b {
+		c.Start += offsetB
+		c.End += offsetB
+		merged = append(merged, c)
+	}
+	Sort(merged)
+	return merged
+}
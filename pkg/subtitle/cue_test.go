@@ -0,0 +1,123 @@
+package subtitle
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestDedup(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []Cue
+		want  []Cue
+	}{
+		{
+			name:  "no cues",
+			input: nil,
+			want:  nil,
+		},
+		{
+			name: "overlapping duplicates merged",
+			input: []Cue{
+				{Start: 0, End: 2000, Text: "Hello"},
+				{Start: 1000, End: 3000, Text: "Hello"},
+				{Start: 0, End: 2000, Text: "World"},
+			},
+			want: []Cue{
+				{Start: 0, End: 3000, Text: "Hello"},
+				{Start: 0, End: 2000, Text: "World"},
+			},
+		},
+		{
+			name: "repeated text at different times kept",
+			input: []Cue{
+				{Start: 0, End: 1000, Text: "Yes"},
+				{Start: 1000, End: 2000, Text: "Yes"},
+			},
+			want: []Cue{
+				{Start: 0, End: 1000, Text: "Yes"},
+				{Start: 1000, End: 2000, Text: "Yes"},
+			},
+		},
+		{
+			name: "identical template copies",
+			input: []Cue{
+				{Start: 500, End: 1500, Text: "Title"},
+				{Start: 500, End: 1500, Text: "Title"},
+				{Start: 500, End: 1500, Text: "Title"},
+			},
+			want: []Cue{
+				{Start: 500, End: 1500, Text: "Title"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Dedup(tt.input)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Dedup() = %v, want %v", got, tt.want)
+			}
+
+			sorted := slices.Clone(tt.input)
+			Sort(sorted)
+			if got, want := slices.Collect(DedupSorted(slices.Values(sorted))), dedupUnsorted(sorted); !reflect.DeepEqual(got, want) {
+				t.Errorf("DedupSorted() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	const minute = 60 * 1000 * 1000
+
+	cues := []Cue{
+		{Start: 0, End: 2000000, Text: "Intro"},
+		{Start: 9*minute + 59000000, End: 10*minute + 2000000, Text: "Crossing"},
+		{Start: 10*minute + 5000000, End: 10*minute + 6000000, Text: "Part two"},
+		{Start: 31 * minute, End: 31*minute + 1000000, Text: "Part four"},
+	}
+
+	want := []Part{
+		{Number: 1, Cues: []Cue{
+			{Start: 0, End: 2000000, Text: "Intro"},
+			{Start: 9*minute + 59000000, End: 10*minute + 2000000, Text: "Crossing"},
+		}},
+		{Number: 2, Cues: []Cue{
+			{Start: 5000000, End: 6000000, Text: "Part two"},
+		}},
+		{Number: 4, Cues: []Cue{
+			{Start: minute, End: minute + 1000000, Text: "Part four"},
+		}},
+	}
+
+	got := Split(cues, 10*minute)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Split() = %v, want %v", got, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	a := []Cue{
+		{Start: 0, End: 1000000, Text: "A1"},
+		{Start: 4000000, End: 5000000, Text: "A2"},
+	}
+	b := []Cue{
+		{Start: 0, End: 1000000, Text: "B1"},
+	}
+
+	want := []Cue{
+		{Start: 0, End: 1000000, Text: "A1"},
+		{Start: 2000000, End: 3000000, Text: "B1"},
+		{Start: 4000000, End: 5000000, Text: "A2"},
+	}
+
+	got := Merge(a, 0, b, 2000000)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Merge() = %v, want %v", got, want)
+	}
+	if a[0].Start != 0 || b[0].Start != 0 {
+		t.Error("Merge() modified its inputs")
+	}
+}
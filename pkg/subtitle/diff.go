@@ -0,0 +1,114 @@
+package subtitle
+
+type ChangeKind int
+
+const (
+	ChangeTiming ChangeKind = iota
+	ChangeText
+	ChangeRemoved
+	ChangeAdded
+)
+
+// Change describes one difference between two cue lists. Indexes are the
+// 1-based cue numbers in the old and new list; 0 means the cue is absent.
+type Change struct {
+	Kind     ChangeKind
+	OldIndex int
+	NewIndex int
+	Old      Cue
+	New      Cue
+}
+
+// maxDiffCells caps the size of the LCS table; larger inputs are compared
+// position by position instead.
+const maxDiffCells = 16 << 20
+
+// Diff aligns cues by text using a longest common subsequence, so an
+// inserted or deleted cue doesn't make every following cue look changed.
+// Aligned cues are compared for timing; unaligned cues between two aligned
+// ones are paired up as text changes, and any left over are removals or
+// additions.
+func Diff(a, b []Cue) []Change {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix].Text == b[prefix].Text {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix].Text == b[len(b)-1-suffix].Text {
+		suffix++
+	}
+
+	var changes []Change
+	for i := 0; i < prefix; i++ {
+		changes = appendTimingChange(changes, a, b, i, i)
+	}
+	changes = append(changes, diffMiddle(a, b, prefix, len(a)-suffix, prefix, len(b)-suffix)...)
+	for k := suffix; k > 0; k-- {
+		changes = appendTimingChange(changes, a, b, len(a)-k, len(b)-k)
+	}
+	return changes
+}
+
+func diffMiddle(a, b []Cue, aStart, aEnd, bStart, bEnd int) []Change {
+	n, m := aEnd-aStart, bEnd-bStart
+	if n == 0 || m == 0 || n*m > maxDiffCells {
+		return diffGap(nil, a, b, aStart, aEnd, bStart, bEnd)
+	}
+
+	// lcs[i][j] is the LCS length of a[aStart+i:aEnd] and b[bStart+j:bEnd].
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[aStart+i].Text == b[bStart+j].Text {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var changes []Change
+	i, j := 0, 0
+	gapA, gapB := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[aStart+i].Text == b[bStart+j].Text:
+			changes = diffGap(changes, a, b, aStart+gapA, aStart+i, bStart+gapB, bStart+j)
+			changes = appendTimingChange(changes, a, b, aStart+i, bStart+j)
+			i++
+			j++
+			gapA, gapB = i, j
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return diffGap(changes, a, b, aStart+gapA, aEnd, bStart+gapB, bEnd)
+}
+
+// diffGap reports a run of cues that have no counterpart with the same text.
+func diffGap(changes []Change, a, b []Cue, aStart, aEnd, bStart, bEnd int) []Change {
+	for aStart < aEnd && bStart < bEnd {
+		changes = append(changes, Change{Kind: ChangeText, OldIndex: aStart + 1, NewIndex: bStart + 1, Old: a[aStart], New: b[bStart]})
+		aStart++
+		bStart++
+	}
+	for ; aStart < aEnd; aStart++ {
+		changes = append(changes, Change{Kind: ChangeRemoved, OldIndex: aStart + 1, Old: a[aStart]})
+	}
+	for ; bStart < bEnd; bStart++ {
+		changes = append(changes, Change{Kind: ChangeAdded, NewIndex: bStart + 1, New: b[bStart]})
+	}
+	return changes
+}
+
+func appendTimingChange(changes []Change, a, b []Cue, i, j int) []Change {
+	if a[i].Start == b[j].Start && a[i].End == b[j].End {
+		return changes
+	}
+	return append(changes, Change{Kind: ChangeTiming, OldIndex: i + 1, NewIndex: j + 1, Old: a[i], New: b[j]})
+}
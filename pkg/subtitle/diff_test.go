@@ -0,0 +1,92 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffCues(t *testing.T) {
+	tests := []struct {
+		name string
+		a    []Cue
+		b    []Cue
+		want []Change
+	}{
+		{
+			name: "identical",
+			a:    []Cue{{Start: 0, End: 1000, Text: "Hello"}},
+			b:    []Cue{{Start: 0, End: 1000, Text: "Hello"}},
+			want: nil,
+		},
+		{
+			name: "timing shift",
+			a:    []Cue{{Start: 0, End: 1000, Text: "Hello"}},
+			b:    []Cue{{Start: 200, End: 1200, Text: "Hello"}},
+			want: []Change{
+				{Kind: ChangeTiming, OldIndex: 1, NewIndex: 1, Old: Cue{Start: 0, End: 1000, Text: "Hello"}, New: Cue{Start: 200, End: 1200, Text: "Hello"}},
+			},
+		},
+		{
+			name: "inserted cue does not shift alignment",
+			a: []Cue{
+				{Start: 0, End: 1000, Text: "One"},
+				{Start: 2000, End: 3000, Text: "Three"},
+			},
+			b: []Cue{
+				{Start: 0, End: 1000, Text: "One"},
+				{Start: 1000, End: 2000, Text: "Two"},
+				{Start: 2000, End: 3000, Text: "Three"},
+			},
+			want: []Change{
+				{Kind: ChangeAdded, NewIndex: 2, New: Cue{Start: 1000, End: 2000, Text: "Two"}},
+			},
+		},
+		{
+			name: "text change and removal",
+			a: []Cue{
+				{Start: 0, End: 1000, Text: "One"},
+				{Start: 1000, End: 2000, Text: "Tow"},
+				{Start: 2000, End: 3000, Text: "Extra"},
+				{Start: 3000, End: 4000, Text: "Four"},
+			},
+			b: []Cue{
+				{Start: 0, End: 1000, Text: "One"},
+				{Start: 1000, End: 2000, Text: "Two"},
+				{Start: 3000, End: 4000, Text: "Four"},
+			},
+			want: []Change{
+				{Kind: ChangeText, OldIndex: 2, NewIndex: 2, Old: Cue{Start: 1000, End: 2000, Text: "Tow"}, New: Cue{Start: 1000, End: 2000, Text: "Two"}},
+				{Kind: ChangeRemoved, OldIndex: 3, Old: Cue{Start: 2000, End: 3000, Text: "Extra"}},
+			},
+		},
+		{
+			name: "changes between aligned cues",
+			a: []Cue{
+				{Start: 0, End: 1000, Text: "A"},
+				{Start: 1000, End: 2000, Text: "B"},
+				{Start: 2000, End: 3000, Text: "C"},
+				{Start: 3000, End: 4000, Text: "D"},
+			},
+			b: []Cue{
+				{Start: 0, End: 1000, Text: "X"},
+				{Start: 1000, End: 2000, Text: "B"},
+				{Start: 2500, End: 3000, Text: "C"},
+				{Start: 3000, End: 4000, Text: "Y"},
+			},
+			want: []Change{
+				{Kind: ChangeText, OldIndex: 1, NewIndex: 1, Old: Cue{Start: 0, End: 1000, Text: "A"}, New: Cue{Start: 0, End: 1000, Text: "X"}},
+				{Kind: ChangeTiming, OldIndex: 3, NewIndex: 3, Old: Cue{Start: 2000, End: 3000, Text: "C"}, New: Cue{Start: 2500, End: 3000, Text: "C"}},
+				{Kind: ChangeText, OldIndex: 4, NewIndex: 4, Old: Cue{Start: 3000, End: 4000, Text: "D"}, New: Cue{Start: 3000, End: 4000, Text: "Y"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Diff(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package subtitle
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DuplicateGroup is a cluster of cues repeating the same text. Cues are
+// the 1-based cue numbers, as written in SRT, in cue order.
+type DuplicateGroup struct {
+	Cues []int
+	// Exact reports whether all the cues have the same text once case,
+	// punctuation and spacing are ignored.
+	Exact bool
+}
+
+// Duplicates finds cues whose text repeats elsewhere in cues, catching
+// lines pasted from a template and left unedited. Text is compared
+// ignoring case, punctuation and spacing; cues shorter than minLength
+// letters and digits are skipped, since short interjections repeat
+// legitimately. Texts at least similarity alike (from 0 to 1, by edit
+// distance) are grouped as near duplicates; similarity 1 groups only
+// exact repeats. Groups are ordered by their first cue.
+func Duplicates(cues []Cue, similarity float64, minLength int) []DuplicateGroup {
+	// Cues with the same normalized text are grouped first, so the fuzzy
+	// comparison only runs once per distinct text.
+	var texts [][]rune
+	members := make(map[string][]int)
+	for i, c := range cues {
+		key := duplicateKey(c.Text)
+		if utf8.RuneCountInString(key)-strings.Count(key, " ") < minLength {
+			continue
+		}
+		if _, ok := members[key]; !ok {
+			texts = append(texts, []rune(key))
+		}
+		members[key] = append(members[key], i+1)
+	}
+
+	parent := make([]int, len(texts))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	if similarity < 1 {
+		// Texts can only be alike if their lengths are, so after sorting
+		// by length each is compared with the next few only.
+		order := make([]int, len(texts))
+		for i := range order {
+			order[i] = i
+		}
+		sort.SliceStable(order, func(a, b int) bool { return len(texts[order[a]]) < len(texts[order[b]]) })
+		for a, i := range order {
+			for _, j := range order[a+1:] {
+				longest := len(texts[j])
+				if float64(len(texts[i])) < similarity*float64(longest) {
+					break
+				}
+				limit := int((1 - similarity) * float64(longest))
+				if editDistance(texts[i], texts[j], limit) <= limit {
+					parent[find(i)] = find(j)
+				}
+			}
+		}
+	}
+
+	clusters := make(map[int]*DuplicateGroup)
+	for i, text := range texts {
+		root := find(i)
+		group, ok := clusters[root]
+		if !ok {
+			group = &DuplicateGroup{Exact: true}
+			clusters[root] = group
+		} else {
+			group.Exact = false
+		}
+		group.Cues = append(group.Cues, members[string(text)]...)
+	}
+
+	var groups []DuplicateGroup
+	for _, group := range clusters {
+		if len(group.Cues) < 2 {
+			continue
+		}
+		sort.Ints(group.Cues)
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(a, b int) bool { return groups[a].Cues[0] < groups[b].Cues[0] })
+	return groups
+}
+
+// duplicateKey is text lowercased, keeping only letters, digits and marks
+// with single spaces between words.
+func duplicateKey(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// editDistance is the Levenshtein distance between a and b, or some value
+// above limit once it is certain to exceed it.
+func editDistance(a, b []rune, limit int) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		best := current[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			best = min(best, current[j])
+		}
+		if best > limit {
+			return best
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
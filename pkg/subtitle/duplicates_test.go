@@ -0,0 +1,52 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDuplicates(t *testing.T) {
+	cues := []Cue{
+		{Text: "Subscribe to the channel!"},
+		{Text: "Yes"},
+		{Text: "Welcome back, everyone"},
+		{Text: "subscribe to the  channel"},
+		{Text: "Yes"},
+		{Text: "Subscribe to the chanel"},
+		{Text: "Welcome back everyone!"},
+		{Text: "Something else entirely"},
+	}
+
+	tests := []struct {
+		name       string
+		similarity float64
+		want       []DuplicateGroup
+	}{
+		{
+			name:       "exact",
+			similarity: 1,
+			want:       []DuplicateGroup{{Cues: []int{1, 4}, Exact: true}, {Cues: []int{3, 7}, Exact: true}},
+		},
+		{
+			name:       "near",
+			similarity: 0.9,
+			want:       []DuplicateGroup{{Cues: []int{1, 4, 6}}, {Cues: []int{3, 7}, Exact: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Duplicates(cues, tt.similarity, 4); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Duplicates() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	if got := editDistance([]rune("kitten"), []rune("sitting"), 5); got != 3 {
+		t.Errorf("editDistance() = %d, want 3", got)
+	}
+	if got := editDistance([]rune("abcdef"), []rune("uvwxyz"), 2); got <= 2 {
+		t.Errorf("editDistance() with limit = %d, want above 2", got)
+	}
+}
@@ -0,0 +1,138 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EmojiMode selects how ConvertEmoji rewrites emoji, for platforms that
+// draw emoji and for those that show them as empty boxes.
+type EmojiMode int
+
+const (
+	// EmojiKeep leaves emoji and shortcodes as they are.
+	EmojiKeep EmojiMode = iota
+	// EmojiUnicode replaces known shortcodes such as ":smile:", which some
+	// caption templates contain, with the emoji they name.
+	EmojiUnicode
+	// EmojiShortcodes replaces known emoji with their shortcode.
+	EmojiShortcodes
+)
+
+func ParseEmojiMode(s string) (EmojiMode, error) {
+	switch s {
+	case "keep":
+		return EmojiKeep, nil
+	case "unicode":
+		return EmojiUnicode, nil
+	case "shortcodes":
+		return EmojiShortcodes, nil
+	}
+	return 0, fmt.Errorf("unknown emoji mode %q (want keep, unicode or shortcodes)", s)
+}
+
+// emojiShortcodes pairs the shortcodes of the emoji most used in captions
+// with their emoji, as GitHub and Slack name them. Where several
+// shortcodes name one emoji, the first is the one EmojiShortcodes writes.
+var emojiShortcodes = [][2]string{
+	{"smile", "😄"}, {"smiley", "😃"}, {"grinning", "😀"}, {"grin", "😁"},
+	{"laughing", "😆"}, {"satisfied", "😆"}, {"joy", "😂"}, {"rofl", "🤣"},
+	{"sweat_smile", "😅"}, {"blush", "😊"}, {"slightly_smiling_face", "🙂"},
+	{"upside_down_face", "🙃"}, {"wink", "😉"}, {"innocent", "😇"},
+	{"heart_eyes", "😍"}, {"star_struck", "🤩"}, {"kissing_heart", "😘"},
+	{"yum", "😋"}, {"stuck_out_tongue", "😛"}, {"stuck_out_tongue_winking_eye", "😜"},
+	{"zany_face", "🤪"}, {"hugs", "🤗"}, {"thinking", "🤔"}, {"shushing_face", "🤫"},
+	{"neutral_face", "😐"}, {"expressionless", "😑"}, {"no_mouth", "😶"},
+	{"smirk", "😏"}, {"unamused", "😒"}, {"roll_eyes", "🙄"}, {"grimacing", "😬"},
+	{"relieved", "😌"}, {"pensive", "😔"}, {"sleepy", "😪"}, {"sleeping", "😴"},
+	{"mask", "😷"}, {"nerd_face", "🤓"}, {"sunglasses", "😎"}, {"partying_face", "🥳"},
+	{"confused", "😕"}, {"worried", "😟"}, {"open_mouth", "😮"}, {"astonished", "😲"},
+	{"flushed", "😳"}, {"pleading_face", "🥺"}, {"cry", "😢"}, {"sob", "😭"},
+	{"scream", "😱"}, {"fearful", "😨"}, {"triumph", "😤"}, {"rage", "😡"},
+	{"angry", "😠"}, {"exploding_head", "🤯"}, {"skull", "💀"}, {"poop", "💩"},
+	{"hankey", "💩"}, {"clown_face", "🤡"}, {"ghost", "👻"}, {"alien", "👽"},
+	{"robot", "🤖"}, {"see_no_evil", "🙈"},
+	{"wave", "👋"}, {"ok_hand", "👌"}, {"v", "✌️"}, {"crossed_fingers", "🤞"},
+	{"+1", "👍"}, {"thumbsup", "👍"}, {"-1", "👎"}, {"thumbsdown", "👎"},
+	{"clap", "👏"}, {"raised_hands", "🙌"}, {"pray", "🙏"}, {"muscle", "💪"},
+	{"point_up", "☝️"}, {"point_down", "👇"}, {"point_left", "👈"}, {"point_right", "👉"},
+	{"fist", "✊"}, {"handshake", "🤝"}, {"eyes", "👀"}, {"brain", "🧠"},
+	{"heart", "❤️"}, {"orange_heart", "🧡"}, {"yellow_heart", "💛"}, {"green_heart", "💚"},
+	{"blue_heart", "💙"}, {"purple_heart", "💜"}, {"black_heart", "🖤"},
+	{"broken_heart", "💔"}, {"two_hearts", "💕"}, {"sparkling_heart", "💖"},
+	{"fire", "🔥"}, {"sparkles", "✨"}, {"star", "⭐"}, {"star2", "🌟"}, {"boom", "💥"},
+	{"100", "💯"}, {"zap", "⚡"}, {"rainbow", "🌈"}, {"sunny", "☀️"}, {"snowflake", "❄️"},
+	{"tada", "🎉"}, {"confetti_ball", "🎊"}, {"gift", "🎁"}, {"trophy", "🏆"},
+	{"medal_sports", "🏅"}, {"rocket", "🚀"}, {"bulb", "💡"}, {"moneybag", "💰"},
+	{"dollar", "💵"}, {"gem", "💎"}, {"crown", "👑"}, {"bell", "🔔"}, {"mega", "📣"},
+	{"loudspeaker", "📢"}, {"musical_note", "🎵"}, {"notes", "🎶"}, {"microphone", "🎤"},
+	{"camera", "📷"}, {"movie_camera", "🎥"}, {"iphone", "📱"}, {"computer", "💻"},
+	{"pushpin", "📌"}, {"link", "🔗"}, {"lock", "🔒"}, {"key", "🔑"}, {"hourglass", "⌛"},
+	{"alarm_clock", "⏰"}, {"calendar", "📅"}, {"memo", "📝"}, {"book", "📖"},
+	{"chart_with_upwards_trend", "📈"}, {"warning", "⚠️"}, {"no_entry", "⛔"},
+	{"x", "❌"}, {"heavy_check_mark", "✔️"}, {"white_check_mark", "✅"},
+	{"question", "❓"}, {"exclamation", "❗"}, {"arrow_right", "➡️"}, {"arrow_left", "⬅️"},
+	{"arrow_up", "⬆️"}, {"arrow_down", "⬇️"},
+	{"coffee", "☕"}, {"pizza", "🍕"}, {"hamburger", "🍔"}, {"cake", "🍰"},
+	{"birthday", "🎂"}, {"beers", "🍻"}, {"wine_glass", "🍷"}, {"apple", "🍎"},
+	{"dog", "🐶"}, {"cat", "🐱"}, {"unicorn", "🦄"}, {"earth_asia", "🌏"},
+	{"airplane", "✈️"}, {"car", "🚗"}, {"house", "🏠"}, {"thailand", "🇹🇭"},
+}
+
+var (
+	emojiByShortcode = make(map[string]string, len(emojiShortcodes))
+	shortcodeOfEmoji *strings.Replacer
+)
+
+func init() {
+	// Emoji ending in the emoji variation selector are also matched
+	// without it, as text often stores them; the replacer tries its
+	// pairs in order, so the longer form comes first.
+	var pairs []string
+	seen := make(map[string]bool)
+	for _, pair := range emojiShortcodes {
+		code, emoji := pair[0], pair[1]
+		emojiByShortcode[":"+code+":"] = emoji
+		if seen[emoji] {
+			continue
+		}
+		seen[emoji] = true
+		pairs = append(pairs, emoji, ":"+code+":")
+		if bare, ok := strings.CutSuffix(emoji, "\ufe0f"); ok {
+			pairs = append(pairs, bare, ":"+code+":")
+		}
+	}
+	shortcodeOfEmoji = strings.NewReplacer(pairs...)
+}
+
+// ConvertEmoji rewrites the emoji of text as mode selects. Unknown
+// shortcodes, and other text between colons such as times, are left as
+// they are.
+func ConvertEmoji(text string, mode EmojiMode) string {
+	switch mode {
+	case EmojiUnicode:
+		var b strings.Builder
+		for {
+			i := strings.IndexByte(text, ':')
+			if i < 0 {
+				break
+			}
+			if j := strings.IndexByte(text[i+1:], ':'); j >= 0 {
+				if emoji, ok := emojiByShortcode[text[i:i+j+2]]; ok {
+					b.WriteString(text[:i])
+					b.WriteString(emoji)
+					text = text[i+j+2:]
+					continue
+				}
+			}
+			// The colon may start a shortcode after all, as in "10:30:smile:".
+			b.WriteString(text[:i+1])
+			text = text[i+1:]
+		}
+		b.WriteString(text)
+		return b.String()
+	case EmojiShortcodes:
+		return shortcodeOfEmoji.Replace(text)
+	}
+	return text
+}
@@ -0,0 +1,40 @@
+package subtitle
+
+import "testing"
+
+func TestConvertEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		mode  EmojiMode
+		want  string
+	}{
+		{"keep", "Hi :smile: 😄", EmojiKeep, "Hi :smile: 😄"},
+		{"shortcodes to emoji", "Nice :+1: :fire::fire:", EmojiUnicode, "Nice 👍 🔥🔥"},
+		{"unknown shortcode", "See :not_an_emoji: here", EmojiUnicode, "See :not_an_emoji: here"},
+		{"after a time", "At 10:30:tada: we start", EmojiUnicode, "At 10:30🎉 we start"},
+		{"unclosed", "Note: :smile", EmojiUnicode, "Note: :smile"},
+		{"emoji to shortcodes", "Nice 👍 🔥", EmojiShortcodes, "Nice :+1: :fire:"},
+		{"first shortcode wins", "😆 💩", EmojiShortcodes, ":laughing: :poop:"},
+		{"with and without variation selector", "❤️ ❤", EmojiShortcodes, ":heart: :heart:"},
+		{"unknown emoji", "🫠", EmojiShortcodes, "🫠"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConvertEmoji(tt.input, tt.mode); got != tt.want {
+				t.Errorf("ConvertEmoji(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseEmojiMode(t *testing.T) {
+	for s, want := range map[string]EmojiMode{"keep": EmojiKeep, "unicode": EmojiUnicode, "shortcodes": EmojiShortcodes} {
+		if got, err := ParseEmojiMode(s); err != nil || got != want {
+			t.Errorf("ParseEmojiMode(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := ParseEmojiMode("ascii"); err == nil {
+		t.Error("ParseEmojiMode() expected error")
+	}
+}
@@ -0,0 +1,133 @@
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// DefaultFillers are the hesitation sounds of English and Thai auto
+// captions. Words that also carry meaning, such as "like", are only
+// removed when listed.
+var DefaultFillers = []string{"um", "umm", "uh", "uhh", "uhm", "er", "erm", "hm", "hmm", "mm", "เอ่อ", "เอ่", "อืม", "อ่า"}
+
+// Fillers removes filler words and phrases from cue text.
+type Fillers struct {
+	// phrases holds the words of each filler in lower case, longest first.
+	phrases [][]string
+}
+
+// NewFillers returns Fillers removing each of words, which may be phrases
+// of several words such as "you know". Fillers are matched without regard
+// to case as whole space-separated words, so a Thai filler is only found
+// where spaces surround it, as in word-level captions.
+func NewFillers(words []string) Fillers {
+	var f Fillers
+	for _, word := range words {
+		if phrase := strings.Fields(strings.ToLower(word)); len(phrase) > 0 {
+			f.phrases = append(f.phrases, phrase)
+		}
+	}
+	slices.SortStableFunc(f.phrases, func(a, b []string) int { return len(b) - len(a) })
+	return f
+}
+
+// ReadFillers loads a list of fillers with one word or phrase per line.
+// Blank lines and lines starting with # are ignored.
+func ReadFillers(filename string) (Fillers, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return Fillers{}, fmt.Errorf("failed to open filler list: %w", err)
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			words = append(words, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Fillers{}, fmt.Errorf("failed to read filler list: %w", err)
+	}
+	if len(words) == 0 {
+		return Fillers{}, fmt.Errorf("filler list %s is empty", filename)
+	}
+	return NewFillers(words), nil
+}
+
+// IsZero reports whether f removes nothing.
+func (f Fillers) IsZero() bool {
+	return len(f.phrases) == 0
+}
+
+// Remove drops the fillers of text with the commas around them, so "So,
+// um, we" becomes "So, we". A sentence ending a filler carried, as in "we
+// went, uh.", ends the word before instead: "we went.". Lines without a
+// filler are left exactly as they are.
+func (f Fillers) Remove(text string) string {
+	if f.IsZero() {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = f.removeFromLine(line)
+	}
+	return strings.Join(slices.DeleteFunc(lines, func(line string) bool { return line == "" }), "\n")
+}
+
+func (f Fillers) removeFromLine(line string) string {
+	words := strings.Fields(line)
+	var kept []string
+	removed, endsInFiller := false, false
+	for i := 0; i < len(words); {
+		n := f.match(words[i:])
+		if endsInFiller = n > 0; !endsInFiller {
+			kept = append(kept, words[i])
+			i++
+			continue
+		}
+		removed = true
+		last := words[i+n-1]
+		if end := last[len(strings.TrimRightFunc(last, isSentenceEnd)):]; end != "" && len(kept) > 0 {
+			kept[len(kept)-1] = strings.TrimRight(kept[len(kept)-1], ",，、;:") + end
+		}
+		i += n
+	}
+	if !removed {
+		return line
+	}
+	if endsInFiller && len(kept) > 0 {
+		kept[len(kept)-1] = strings.TrimRight(kept[len(kept)-1], ",，、;:")
+	}
+	return strings.Join(kept, " ")
+}
+
+// match returns how many of words, from the first, a filler covers, or 0.
+func (f Fillers) match(words []string) int {
+	for _, phrase := range f.phrases {
+		if len(phrase) > len(words) {
+			continue
+		}
+		matched := true
+		for j, part := range phrase {
+			if strings.ToLower(strings.TrimFunc(words[j], unicode.IsPunct)) != part {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return len(phrase)
+		}
+	}
+	return 0
+}
+
+// isSentenceEnd reports whether r ends a sentence.
+func isSentenceEnd(r rune) bool {
+	return strings.ContainsRune(".!?…。！？", r)
+}
@@ -0,0 +1,56 @@
+package subtitle
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFillersRemove(t *testing.T) {
+	fillers := NewFillers(append([]string{"like", "You know"}, DefaultFillers...))
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"no filler", "So,  we  went", "So,  we  went"},
+		{"leading", "Um, so we went", "so we went"},
+		{"between commas", "So, uh, we went", "So, we went"},
+		{"sentence end", "We went, uh.", "We went."},
+		{"trailing", "and then, erm", "and then"},
+		{"phrase", "It was, you know, fine", "It was, fine"},
+		{"case", "UM okay", "okay"},
+		{"inside words kept", "Umbrella summer", "Umbrella summer"},
+		{"only fillers", "Uh, um...", ""},
+		{"lines", "Hmm\nright, like, now", "right, now"},
+		{"thai", "เอ่อ ผมอ่านแล้ว", "ผมอ่านแล้ว"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fillers.Remove(tt.input); got != tt.want {
+				t.Errorf("Remove(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadFillers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fillers.txt")
+	if err := os.WriteFile(path, []byte("# fillers\nbasically\n\n  sort of  \n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fillers, err := ReadFillers(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fillers.Remove("It's sort of, basically done"); got != "It's done" {
+		t.Errorf("Remove() = %q, want %q", got, "It's done")
+	}
+
+	if err := os.WriteFile(path, []byte("# nothing\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadFillers(path); err == nil {
+		t.Error("ReadFillers() expected error for an empty list")
+	}
+}
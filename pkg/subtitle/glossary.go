@@ -0,0 +1,105 @@
+package subtitle
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Glossary rewrites terms in cue text to their preferred spelling.
+type Glossary []GlossaryEntry
+
+type GlossaryEntry struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// ReadGlossary loads a CSV glossary with one "term,replacement[,case-sensitive]"
+// record per line. Terms match case-insensitively unless the third column is true.
+func ReadGlossary(filename string) (Glossary, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open glossary: %w", err)
+	}
+	defer file.Close()
+
+	reader := newCSVReader(file)
+
+	var entries Glossary
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse glossary: %w", err)
+		}
+		if len(record) < 2 || record[0] == "" {
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("invalid glossary entry on line %d", line)
+		}
+
+		caseSensitive := false
+		if len(record) > 2 && record[2] != "" {
+			caseSensitive, err = strconv.ParseBool(record[2])
+			if err != nil {
+				line, _ := reader.FieldPos(2)
+				return nil, fmt.Errorf("invalid case-sensitive flag on line %d: %w", line, err)
+			}
+		}
+		entry, err := NewGlossaryEntry(record[0], record[1], caseSensitive)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+func newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// NewGlossaryEntry returns an entry rewriting term to replacement. The term
+// must not be empty.
+func NewGlossaryEntry(term, replacement string, caseSensitive bool) (GlossaryEntry, error) {
+	if term == "" {
+		return GlossaryEntry{}, fmt.Errorf("empty glossary term")
+	}
+	expr := regexp.QuoteMeta(term)
+	// Only anchor on word boundaries where the term itself starts or ends
+	// with a word character, so "Ann" does not rewrite "Annual".
+	if isWordByte(term[0]) {
+		expr = `\b` + expr
+	}
+	if isWordByte(term[len(term)-1]) {
+		expr += `\b`
+	}
+	if !caseSensitive {
+		expr = "(?i)" + expr
+	}
+	return GlossaryEntry{
+		pattern:     regexp.MustCompile(expr),
+		replacement: replacement,
+	}, nil
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func (g Glossary) Apply(text string) string {
+	for _, entry := range g {
+		text = entry.pattern.ReplaceAllLiteralString(text, entry.replacement)
+	}
+	return text
+}
@@ -0,0 +1,89 @@
+package subtitle
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGlossaryApply(t *testing.T) {
+	var glossary Glossary
+	for _, e := range []struct {
+		term, replacement string
+		caseSensitive     bool
+	}{
+		{"capcut", "CapCut", false},
+		{"Ann", "Anne", true},
+		{"c++", "C++", false},
+	} {
+		entry, err := NewGlossaryEntry(e.term, e.replacement, e.caseSensitive)
+		if err != nil {
+			t.Fatal(err)
+		}
+		glossary = append(glossary, entry)
+	}
+	if _, err := NewGlossaryEntry("", "x", false); err == nil {
+		t.Error("NewGlossaryEntry() expected error for an empty term")
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "case-insensitive term",
+			input: "Edited in CAPCUT and capcut",
+			want:  "Edited in CapCut and CapCut",
+		},
+		{
+			name:  "case-sensitive term",
+			input: "Ann met ann",
+			want:  "Anne met ann",
+		},
+		{
+			name:  "whole words only",
+			input: "Annual capcuts",
+			want:  "Annual capcuts",
+		},
+		{
+			name:  "term with symbols",
+			input: "I write c++ daily",
+			want:  "I write C++ daily",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := glossary.Apply(tt.input)
+			if got != tt.want {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadGlossary(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test-glossary-*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	tempFile.WriteString("# term,replacement,case-sensitive\ncapcut,CapCut\nAnn,Anne,true\n")
+	tempFile.Close()
+
+	glossary, err := ReadGlossary(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(glossary) != 2 {
+		t.Fatalf("ReadGlossary() returned %d entries, want 2", len(glossary))
+	}
+	if got := glossary.Apply("capcut with ann and Ann"); got != "CapCut with ann and Anne" {
+		t.Errorf("Apply() = %v", got)
+	}
+
+	if _, err := ReadGlossary("nonexistent.csv"); err == nil {
+		t.Error("ReadGlossary() expected error for missing file")
+	}
+}
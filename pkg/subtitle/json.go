@@ -0,0 +1,87 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the JSON form of Subtitles written by
+// MarshalJSON. Fields may be added within a version; renaming or removing
+// one, or changing its meaning, requires a new version.
+const SchemaVersion = 1
+
+// jsonDocument is the JSON form of Subtitles:
+//
+//	{"version": 1, "cues": [{"start_us": 0, "end_us": 1000000, "text": "Hi",
+//	  "source": {"material_id": "...", "track_id": "...", "track_number": 1, "segment": 0}}]}
+//
+// Times are integer microseconds, as in the drafts, so they round-trip
+// exactly. "source" is omitted for cues that did not come from a draft,
+// and "emphasis" ("italic", "bold" or "color") for cues without one.
+type jsonDocument struct {
+	Version int       `json:"version"`
+	Cues    []jsonCue `json:"cues"`
+}
+
+type jsonCue struct {
+	Start    int64       `json:"start_us"`
+	End      int64       `json:"end_us"`
+	Text     string      `json:"text"`
+	Source   *jsonSource `json:"source,omitempty"`
+	Emphasis string      `json:"emphasis,omitempty"`
+}
+
+type jsonSource struct {
+	MaterialID  string `json:"material_id,omitempty"`
+	TrackID     string `json:"track_id,omitempty"`
+	TrackNumber int    `json:"track_number,omitempty"`
+	Segment     int    `json:"segment"`
+}
+
+// MarshalJSON encodes s in the versioned schema described at SchemaVersion.
+func (s Subtitles) MarshalJSON() ([]byte, error) {
+	doc := jsonDocument{Version: SchemaVersion, Cues: make([]jsonCue, len(s))}
+	for i, c := range s {
+		doc.Cues[i] = jsonCue{Start: c.Start, End: c.End, Text: c.Text, Emphasis: c.Emphasis.String()}
+		if c.Source != (Source{}) {
+			doc.Cues[i].Source = &jsonSource{
+				MaterialID:  c.Source.MaterialID,
+				TrackID:     c.Source.TrackID,
+				TrackNumber: c.Source.TrackNumber,
+				Segment:     c.Source.Segment,
+			}
+		}
+	}
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON decodes the versioned schema. Documents from a newer
+// schema version are rejected rather than read partially.
+func (s *Subtitles) UnmarshalJSON(data []byte) error {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	if doc.Version < 1 || doc.Version > SchemaVersion {
+		return fmt.Errorf("unsupported subtitle schema version %d (want 1 to %d)", doc.Version, SchemaVersion)
+	}
+
+	subs := make(Subtitles, len(doc.Cues))
+	for i, c := range doc.Cues {
+		emphasis, err := ParseEmphasis(c.Emphasis)
+		if err != nil {
+			return fmt.Errorf("cue %d: %w", i+1, err)
+		}
+		subs[i] = Cue{Start: c.Start, End: c.End, Text: c.Text, Emphasis: emphasis}
+		if c.Source != nil {
+			subs[i].Source = Source{
+				MaterialID:  c.Source.MaterialID,
+				TrackID:     c.Source.TrackID,
+				TrackNumber: c.Source.TrackNumber,
+				Segment:     c.Source.Segment,
+			}
+		}
+	}
+	*s = subs
+	return nil
+}
@@ -0,0 +1,44 @@
+package subtitle
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSubtitlesJSON(t *testing.T) {
+	subs := Subtitles{
+		{Start: -500000, End: 1000001, Text: "Hello\n\"world\"", Source: Source{MaterialID: "m1", TrackID: "t1", TrackNumber: 2, Segment: 3}},
+		{Start: 2000000, End: 3000000, Text: "From an SRT file"},
+		{Start: 3000000, End: 3500000, Text: "keyword", Emphasis: EmphasisBold},
+	}
+
+	data, err := json.Marshal(subs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"version":1,"cues":[` +
+		`{"start_us":-500000,"end_us":1000001,"text":"Hello\n\"world\"","source":{"material_id":"m1","track_id":"t1","track_number":2,"segment":3}},` +
+		`{"start_us":2000000,"end_us":3000000,"text":"From an SRT file"},` +
+		`{"start_us":3000000,"end_us":3500000,"text":"keyword","emphasis":"bold"}]}`
+	if string(data) != want {
+		t.Errorf("Marshal() = %s, want %s", data, want)
+	}
+
+	var got Subtitles
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, subs) {
+		t.Errorf("Unmarshal() = %v, want %v", got, subs)
+	}
+}
+
+func TestSubtitlesJSONVersion(t *testing.T) {
+	for _, input := range []string{`{"cues": []}`, `{"version": 2, "cues": []}`} {
+		var subs Subtitles
+		if err := json.Unmarshal([]byte(input), &subs); err == nil {
+			t.Errorf("Unmarshal(%s) expected version error", input)
+		}
+	}
+}
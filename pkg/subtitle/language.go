@@ -0,0 +1,206 @@
+package subtitle
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// scripts are the writing systems DetectLanguage tells apart, with the
+// language each implies when it is the predominant one. Latin is shared
+// by many languages and narrowed down by common words; Cyrillic is taken
+// as Russian.
+var scripts = []struct {
+	name  string
+	table *unicode.RangeTable
+	lang  string
+}{
+	{"Latin", unicode.Latin, ""},
+	{"Thai", unicode.Thai, "th"},
+	{"Lao", unicode.Lao, "lo"},
+	{"Khmer", unicode.Khmer, "km"},
+	{"Myanmar", unicode.Myanmar, "my"},
+	{"Hangul", unicode.Hangul, "ko"},
+	// Kana and kanji are counted together as Japanese; Han without kana
+	// is Chinese.
+	{"Japanese", kana, "ja"},
+	{"Han", unicode.Han, "zh"},
+	{"Cyrillic", unicode.Cyrillic, "ru"},
+	{"Greek", unicode.Greek, "el"},
+	{"Arabic", unicode.Arabic, "ar"},
+	{"Hebrew", unicode.Hebrew, "he"},
+	{"Devanagari", unicode.Devanagari, "hi"},
+	{"Georgian", unicode.Georgian, "ka"},
+	{"Armenian", unicode.Armenian, "hy"},
+}
+
+var kana = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x3040, Hi: 0x309f, Stride: 1}, // Hiragana
+		{Lo: 0x30a0, Hi: 0x30ff, Stride: 1}, // Katakana
+	},
+}
+
+// commonWords are frequent words of languages written in Latin script,
+// in the order ties are broken.
+var commonWords = []struct {
+	lang  string
+	words []string
+}{
+	{"en", strings.Fields("the and you is to of it that in this what for are we with was have not")},
+	{"es", strings.Fields("el la de que y en los es por un una las con no lo para muy pero")},
+	{"fr", strings.Fields("le la les de et est je vous que un une des pas pour ce il nous")},
+	{"de", strings.Fields("der die das und ist ich nicht du sie es ein eine zu mit wir den")},
+	{"pt", strings.Fields("o a de que e do da em um uma não é os para com você eu")},
+	{"it", strings.Fields("il di che e la non un una per sono è mi ho lo questo gli")},
+	{"nl", strings.Fields("de het een en van ik je is niet dat op te wat zijn met")},
+	{"id", strings.Fields("yang dan ini itu di ke saya tidak ada dengan untuk aku kamu apa")},
+	{"vi", strings.Fields("và của không là có tôi một được những này cho người với bạn")},
+}
+
+// minCommonWords is the share of words that must be common words of a
+// language for Latin-script text to be recognized as that language.
+const minCommonWords = 0.1
+
+// minMixedShare is the share of letters a second script must reach for a
+// track to count as mixing scripts.
+const minMixedShare = 0.25
+
+// Language is the result of DetectLanguage.
+type Language struct {
+	// Code is the ISO 639-1 code of the predominant language, or "" if
+	// it is not known.
+	Code string
+	// Script is the predominant script, or "" for text without letters.
+	Script string
+	// Scripts holds the share of letters written in each script.
+	Scripts map[string]float64
+}
+
+// DetectLanguage guesses the predominant language of the cues' text from
+// its script and, for Latin script, from common words.
+func DetectLanguage(cues []Cue) Language {
+	counts := make(map[string]int)
+	letters, hasKana := 0, false
+	for _, c := range cues {
+		for _, r := range c.Text {
+			if !unicode.IsLetter(r) {
+				continue
+			}
+			hasKana = hasKana || unicode.Is(kana, r)
+			for _, s := range scripts {
+				if unicode.Is(s.table, r) {
+					counts[s.name]++
+					letters++
+					break
+				}
+			}
+		}
+	}
+	if hasKana {
+		counts["Japanese"] += counts["Han"]
+		delete(counts, "Han")
+	}
+
+	lang := Language{Scripts: make(map[string]float64, len(counts))}
+	best := 0
+	for _, s := range scripts {
+		n := counts[s.name]
+		if n == 0 {
+			continue
+		}
+		lang.Scripts[s.name] = float64(n) / float64(letters)
+		if n > best {
+			best, lang.Script, lang.Code = n, s.name, s.lang
+		}
+	}
+	if lang.Script == "Latin" {
+		lang.Code = latinLanguage(cues)
+	}
+	return lang
+}
+
+func latinLanguage(cues []Cue) string {
+	var words []string
+	for _, c := range cues {
+		words = append(words, strings.FieldsFunc(strings.ToLower(c.Text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsMark(r)
+		})...)
+	}
+	if len(words) == 0 {
+		return ""
+	}
+
+	code, best := "", 0
+	for _, l := range commonWords {
+		n := 0
+		for _, w := range words {
+			if slices.Contains(l.words, w) {
+				n++
+			}
+		}
+		if n > best {
+			code, best = l.lang, n
+		}
+	}
+	if float64(best) < minCommonWords*float64(len(words)) {
+		return ""
+	}
+	return code
+}
+
+// scriptOf returns the script implied by lang, or "" if it is not one of
+// the languages with a script of their own.
+func scriptOf(lang string) string {
+	for _, s := range scripts {
+		if s.lang == lang {
+			return s.name
+		}
+	}
+	return ""
+}
+
+// CheckLanguage describes tracks of s whose predominant language is not
+// lang, an ISO 639-1 code optionally followed by a region such as "en-US",
+// and tracks mixing scripts. An empty lang only checks the scripts.
+func CheckLanguage(s Subtitles, lang string) []string {
+	want, _, _ := strings.Cut(strings.ToLower(lang), "-")
+	want, _, _ = strings.Cut(want, "_")
+
+	var problems []string
+	for _, track := range s.Tracks() {
+		name := fmt.Sprintf("text track %d", track.Number)
+		if track.Number == 0 {
+			name = "the subtitles"
+		}
+		detected := DetectLanguage(track.Cues)
+		if detected.Script == "" {
+			continue
+		}
+		switch {
+		case want == "" || detected.Code == want:
+		case detected.Code != "":
+			problems = append(problems, fmt.Sprintf("%s looks like %s (%s), not %s", name, detected.Code, detected.Script, lang))
+		case scriptOf(want) != "" && detected.Scripts[scriptOf(want)] < minMixedShare:
+			problems = append(problems, fmt.Sprintf("%s is in %s script, not %s", name, detected.Script, lang))
+		}
+
+		var mixed []string
+		for _, script := range scripts {
+			if detected.Scripts[script.name] >= minMixedShare {
+				mixed = append(mixed, script.name)
+			}
+		}
+		if len(mixed) > 1 {
+			sort.SliceStable(mixed, func(i, j int) bool { return detected.Scripts[mixed[i]] > detected.Scripts[mixed[j]] })
+			parts := make([]string, len(mixed))
+			for i, script := range mixed {
+				parts[i] = fmt.Sprintf("%.0f%% %s", detected.Scripts[script]*100, script)
+			}
+			problems = append(problems, fmt.Sprintf("%s mixes scripts: %s", name, strings.Join(parts, ", ")))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,52 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		code   string
+		script string
+	}{
+		{"thai", "สวัสดีครับ ทุกคน", "th", "Thai"},
+		{"english", "This is what we have for you", "en", "Latin"},
+		{"spanish", "Hola, ¿qué tal? Es muy bueno para los niños", "es", "Latin"},
+		{"unknown latin", "Sawasdee khrap", "", "Latin"},
+		{"japanese", "今日はいい天気ですね", "ja", "Japanese"},
+		{"chinese", "今天天气很好", "zh", "Han"},
+		{"no letters", "123 !!", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := DetectLanguage([]Cue{{Text: tt.text}})
+			if got.Code != tt.code || got.Script != tt.script {
+				t.Errorf("DetectLanguage(%q) = %+v, want %s in %s", tt.text, got, tt.code, tt.script)
+			}
+		})
+	}
+}
+
+func TestCheckLanguage(t *testing.T) {
+	subs := Subtitles{
+		{Text: "สวัสดีครับ", Source: Source{TrackNumber: 1}},
+		{Text: "This is the English track", Source: Source{TrackNumber: 2}},
+		{Text: "ไปกัน let's go now", Source: Source{TrackNumber: 3}},
+		{Text: "Sawasdee khrap", Source: Source{TrackNumber: 4}},
+	}
+	got := CheckLanguage(subs, "th-TH")
+	want := []string{
+		"text track 2 looks like en (Latin), not th-TH",
+		"text track 3 mixes scripts: 69% Latin, 31% Thai",
+		"text track 4 is in Latin script, not th-TH",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CheckLanguage() = %q, want %q", got, want)
+	}
+	if got := CheckLanguage(subs[:1], ""); got != nil {
+		t.Errorf("CheckLanguage() without a language = %q, want none", got)
+	}
+}
@@ -0,0 +1,39 @@
+package subtitle
+
+import "strings"
+
+// Pair is a cue and its counterpart in another language.
+type Pair struct {
+	Source, Target Cue
+}
+
+// Pairs matches each cue of source, in order, with the cue of target it
+// overlaps longest in time, for subtitles of one video in two languages.
+// Both must be sorted by start time. A source cue overlapping no target
+// cue is paired with the zero Cue.
+func Pairs(source, target []Cue) []Pair {
+	pairs := make([]Pair, len(source))
+	first := 0
+	for i, s := range source {
+		pairs[i].Source = s
+		for first < len(target) && target[first].End <= s.Start {
+			first++
+		}
+		var longest int64
+		for _, t := range target[first:] {
+			if t.Start >= s.End {
+				break
+			}
+			if overlap := min(s.End, t.End) - max(s.Start, t.Start); overlap > longest {
+				pairs[i].Target, longest = t, overlap
+			}
+		}
+	}
+	return pairs
+}
+
+// OneLine joins the lines of a cue's text with spaces, for formats and
+// lookups that take a segment as a single line.
+func OneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,34 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPairs(t *testing.T) {
+	source := []Cue{
+		{Start: 0, End: 2000000, Text: "สวัสดี"},
+		{Start: 2000000, End: 4000000, Text: "ไปกันเถอะ"},
+		{Start: 9000000, End: 10000000, Text: "ลาก่อน"},
+	}
+	target := []Cue{
+		{Start: 100000, End: 1900000, Text: "Hello"},
+		// Overlaps the second cue longer than the first.
+		{Start: 1500000, End: 4200000, Text: "Let's go"},
+		{Start: 5000000, End: 6000000, Text: "Unpaired"},
+	}
+	want := []Pair{
+		{source[0], target[0]},
+		{source[1], target[1]},
+		{Source: source[2]},
+	}
+	if got := Pairs(source, target); !reflect.DeepEqual(got, want) {
+		t.Errorf("Pairs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOneLine(t *testing.T) {
+	if got := OneLine(" Hello\nthere  you "); got != "Hello there you" {
+		t.Errorf("OneLine() = %q", got)
+	}
+}
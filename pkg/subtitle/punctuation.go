@@ -0,0 +1,72 @@
+package subtitle
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// SameWords reports whether a and b hold the same words in the same order,
+// regardless of case, punctuation, apostrophes and spacing, as when one
+// only restores the punctuation of the other.
+func SameWords(a, b string) bool {
+	return slices.Equal(wordsOf(a), wordsOf(b))
+}
+
+var apostrophes = strings.NewReplacer("'", "", "’", "")
+
+// wordsOf splits text into words in lower case, without apostrophes.
+func wordsOf(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(apostrophes.Replace(text)), isWordBreak)
+}
+
+// SentenceCase capitalizes the first letter of each sentence across a
+// stream of cues in time order: the first letter after a sentence ends
+// with ".", "!", "?" or "…" and a space, and the first of every cue
+// starting a sentence. A cue starts one unless it goes on with the
+// segment the cue before it is part of, as the words of word-level
+// captions do, after text that did not end a sentence. Scripts without
+// case, such as Thai, are left as they are, and abbreviations like "e.g."
+// are taken for sentence ends. The zero value is at the start of a
+// sentence.
+type SentenceCase struct {
+	// midSentence is set when the last cue did not end its sentence.
+	midSentence bool
+	last        Source
+}
+
+// Apply capitalizes the sentences of c, the cue after the last one
+// applied.
+func (s *SentenceCase) Apply(c *Cue) {
+	sameSegment := c.Source.MaterialID != "" && c.Source == s.last
+	upper := !s.midSentence || !sameSegment
+	ended := false
+	c.Text = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r):
+			if upper {
+				r = unicode.ToUpper(r)
+			}
+			upper, ended = false, false
+		case unicode.IsDigit(r):
+			upper, ended = false, false
+		case isSentenceEnd(r):
+			ended = true
+		case unicode.IsSpace(r):
+			upper = upper || ended
+		case !strings.ContainsRune(`"')]”’»`, r):
+			ended = false
+		}
+		return r
+	}, c.Text)
+	s.midSentence = !ended
+	s.last = c.Source
+}
+
+// Capitalize applies a SentenceCase to cues, which must be sorted.
+func Capitalize(cues []Cue) {
+	var s SentenceCase
+	for i := range cues {
+		s.Apply(&cues[i])
+	}
+}
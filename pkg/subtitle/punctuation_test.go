@@ -0,0 +1,65 @@
+package subtitle
+
+import "testing"
+
+func TestSameWords(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"so we went home", "So, we went home.", true},
+		{"dont stop", "Don't stop!", true},
+		{"สวัสดี ครับ", "สวัสดีครับ", false},
+		{"สวัสดี ครับ", "สวัสดี, ครับ", true},
+		{"we went home", "We walked home.", false},
+		{"we went home", "We went home today.", false},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		if got := SameWords(tt.a, tt.b); got != tt.want {
+			t.Errorf("SameWords(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCapitalize(t *testing.T) {
+	word := func(text string, segment int) Cue {
+		return Cue{Text: text, Source: Source{MaterialID: "m", TrackNumber: 1, Segment: segment}}
+	}
+	tests := []struct {
+		name string
+		cues []Cue
+		want []string
+	}{
+		{
+			name: "sentences in cues",
+			cues: []Cue{{Text: "hello there. how are you?"}, {Text: "“fine,” she said... ok!"}, {Text: "the end"}},
+			want: []string{"Hello there. How are you?", "“Fine,” she said... Ok!", "The end"},
+		},
+		{
+			name: "no space after the stop",
+			cues: []Cue{{Text: "version 2.5 costs $3.99. e.g.this"}},
+			want: []string{"Version 2.5 costs $3.99. E.g.this"},
+		},
+		{
+			name: "words of a segment",
+			cues: []Cue{word("so", 0), word("we", 0), word("went.", 0), word("then", 0), word("next", 1), word("one", 1)},
+			want: []string{"So", "we", "went.", "Then", "Next", "one"},
+		},
+		{
+			name: "caseless script",
+			cues: []Cue{{Text: "สวัสดี ครับ. ok"}},
+			want: []string{"สวัสดี ครับ. Ok"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			Capitalize(tt.cues)
+			for i, c := range tt.cues {
+				if c.Text != tt.want[i] {
+					t.Errorf("cue %d = %q, want %q", i, c.Text, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package subtitle
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// CueQuality holds the readability metrics of one cue. Cue is the 1-based
+// cue number and times are in microseconds. Characters count spaces and
+// punctuation but not line breaks, as StyleGuide.MaxCPS does.
+type CueQuality struct {
+	Cue         int     `json:"cue"`
+	Start       int64   `json:"start_us"`
+	End         int64   `json:"end_us"`
+	Duration    int64   `json:"duration_us"`
+	Characters  int     `json:"characters"`
+	Words       int     `json:"words"`
+	Lines       int     `json:"lines"`
+	LongestLine int     `json:"longest_line"`
+	CPS         float64 `json:"cps"`
+	WPM         float64 `json:"wpm"`
+	// Gap is the time from the end of the cue to the start of the next,
+	// negative when they overlap. It is nil for the last cue.
+	Gap *int64 `json:"gap_us,omitempty"`
+}
+
+// Overlaps reports whether the next cue starts before this one ends.
+func (q CueQuality) Overlaps() bool {
+	return q.Gap != nil && *q.Gap < 0
+}
+
+// QualitySummary aggregates the metrics of all cues. The mean reading
+// speeds are the total text over the total duration, so short cues do not
+// outweigh long ones. MinGap only counts cues that do not overlap.
+type QualitySummary struct {
+	Cues         int     `json:"cues"`
+	Duration     int64   `json:"duration_us"`
+	MeanCPS      float64 `json:"mean_cps"`
+	MaxCPS       float64 `json:"max_cps"`
+	MeanWPM      float64 `json:"mean_wpm"`
+	MaxWPM       float64 `json:"max_wpm"`
+	MeanDuration int64   `json:"mean_duration_us"`
+	MinDuration  int64   `json:"min_duration_us"`
+	MaxDuration  int64   `json:"max_duration_us"`
+	MaxLines     int     `json:"max_lines"`
+	LongestLine  int     `json:"longest_line"`
+	MinGap       *int64  `json:"min_gap_us,omitempty"`
+	Overlaps     int     `json:"overlaps"`
+}
+
+// QualityReport is the output of Quality.
+type QualityReport struct {
+	Summary QualitySummary `json:"summary"`
+	Cues    []CueQuality   `json:"cues"`
+}
+
+// Quality measures the readability of cues, which must be sorted by start
+// time.
+func Quality(cues []Cue) QualityReport {
+	report := QualityReport{Cues: make([]CueQuality, len(cues))}
+	sum := &report.Summary
+	sum.Cues = len(cues)
+
+	var characters, words int
+	for i, c := range cues {
+		q := CueQuality{Cue: i + 1, Start: c.Start, End: c.End, Duration: c.End - c.Start}
+		lines := strings.Split(c.Text, "\n")
+		q.Lines = len(lines)
+		for _, line := range lines {
+			length := utf8.RuneCountInString(line)
+			q.Characters += length
+			q.LongestLine = max(q.LongestLine, length)
+		}
+		q.Words = len(strings.Fields(c.Text))
+		if q.Duration > 0 {
+			q.CPS = float64(q.Characters) * 1e6 / float64(q.Duration)
+			q.WPM = float64(q.Words) * 60e6 / float64(q.Duration)
+		}
+		if i+1 < len(cues) {
+			gap := cues[i+1].Start - c.End
+			q.Gap = &gap
+			if gap < 0 {
+				sum.Overlaps++
+			} else if sum.MinGap == nil || gap < *sum.MinGap {
+				sum.MinGap = &gap
+			}
+		}
+		report.Cues[i] = q
+
+		characters += q.Characters
+		words += q.Words
+		sum.Duration += q.Duration
+		sum.MaxCPS = max(sum.MaxCPS, q.CPS)
+		sum.MaxWPM = max(sum.MaxWPM, q.WPM)
+		if i == 0 || q.Duration < sum.MinDuration {
+			sum.MinDuration = q.Duration
+		}
+		sum.MaxDuration = max(sum.MaxDuration, q.Duration)
+		sum.MaxLines = max(sum.MaxLines, q.Lines)
+		sum.LongestLine = max(sum.LongestLine, q.LongestLine)
+	}
+
+	if sum.Duration > 0 {
+		sum.MeanCPS = float64(characters) * 1e6 / float64(sum.Duration)
+		sum.MeanWPM = float64(words) * 60e6 / float64(sum.Duration)
+	}
+	if len(cues) > 0 {
+		sum.MeanDuration = sum.Duration / int64(len(cues))
+	}
+	return report
+}
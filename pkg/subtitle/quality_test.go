@@ -0,0 +1,51 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestQuality(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 2_000_000, Text: "Hello there"},
+		{Start: 2_500_000, End: 3_000_000, Text: "Two\nlines"},
+		{Start: 2_800_000, End: 4_000_000, Text: ""},
+	}
+	report := Quality(cues)
+
+	gap1, gap2, minGap := int64(500_000), int64(-200_000), int64(500_000)
+	wantCues := []CueQuality{
+		{Cue: 1, Start: 0, End: 2_000_000, Duration: 2_000_000, Characters: 11, Words: 2, Lines: 1, LongestLine: 11, CPS: 5.5, WPM: 60, Gap: &gap1},
+		{Cue: 2, Start: 2_500_000, End: 3_000_000, Duration: 500_000, Characters: 8, Words: 2, Lines: 2, LongestLine: 5, CPS: 16, WPM: 240, Gap: &gap2},
+		{Cue: 3, Start: 2_800_000, End: 4_000_000, Duration: 1_200_000, Lines: 1},
+	}
+	if !reflect.DeepEqual(report.Cues, wantCues) {
+		t.Errorf("Quality() cues = %+v, want %+v", report.Cues, wantCues)
+	}
+	if !report.Cues[1].Overlaps() || report.Cues[0].Overlaps() || report.Cues[2].Overlaps() {
+		t.Errorf("Overlaps() is wrong for %+v", report.Cues)
+	}
+
+	wantSummary := QualitySummary{
+		Cues:         3,
+		Duration:     3_700_000,
+		MeanCPS:      19 / 3.7,
+		MaxCPS:       16,
+		MeanWPM:      4 * 60 / 3.7,
+		MaxWPM:       240,
+		MeanDuration: 1_233_333,
+		MinDuration:  500_000,
+		MaxDuration:  2_000_000,
+		MaxLines:     2,
+		LongestLine:  11,
+		MinGap:       &minGap,
+		Overlaps:     1,
+	}
+	if !reflect.DeepEqual(report.Summary, wantSummary) {
+		t.Errorf("Quality() summary = %+v, want %+v", report.Summary, wantSummary)
+	}
+
+	if empty := Quality(nil); empty.Summary.Cues != 0 || len(empty.Cues) != 0 {
+		t.Errorf("Quality(nil) = %+v", empty)
+	}
+}
@@ -0,0 +1,360 @@
+package subtitle
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// Romanizer converts Thai (RTGS, approximate) and Japanese kana (Hepburn)
+// to Latin script. Other characters, such as Chinese hanzi, are looked up in
+// an optional user-supplied table; anything unknown is copied through.
+type Romanizer struct {
+	Table map[rune]string
+}
+
+// ReadRomanizeTable loads a CSV of "character,romanization" records, e.g. a
+// pinyin list extracted from Unihan's kMandarin field.
+func ReadRomanizeTable(filename string) (map[rune]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open romanization table: %w", err)
+	}
+	defer file.Close()
+
+	records, err := newCSVReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse romanization table: %w", err)
+	}
+
+	table := make(map[rune]string, len(records))
+	for i, record := range records {
+		if len(record) < 2 || utf8.RuneCountInString(record[0]) != 1 {
+			return nil, fmt.Errorf("invalid romanization entry %d", i+1)
+		}
+		r, _ := utf8.DecodeRuneInString(record[0])
+		table[r] = record[1]
+	}
+	return table, nil
+}
+
+func (z Romanizer) Romanize(text string) string {
+	runes := []rune(text)
+	var sb strings.Builder
+	lastFromTable := false
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case isThai(r):
+			var s string
+			s, i = romanizeThaiSyllable(runes, i)
+			sb.WriteString(s)
+			lastFromTable = false
+		case isKana(r):
+			var s string
+			s, i = romanizeKana(runes, i)
+			sb.WriteString(s)
+			lastFromTable = false
+		default:
+			if s, ok := z.Table[r]; ok {
+				// Table entries are whole syllables, as with pinyin, so
+				// keep them apart.
+				if lastFromTable {
+					sb.WriteByte(' ')
+				}
+				sb.WriteString(s)
+				lastFromTable = true
+			} else {
+				sb.WriteRune(r)
+				lastFromTable = false
+			}
+			i++
+		}
+	}
+	return sb.String()
+}
+
+func isKana(r rune) bool {
+	return r >= 0x3041 && r <= 0x3096 || r >= 0x30A1 && r <= 0x30FA || r == 'ー'
+}
+
+// hiragana maps each hiragana to its Hepburn romanization. Katakana are
+// folded onto hiragana before the lookup.
+var hiragana = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu", 'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "ya", 'ゅ': "yu", 'ょ': "yo", 'ゎ': "wa", 'ゕ': "ka", 'ゖ': "ke",
+}
+
+func toHiragana(r rune) rune {
+	if r >= 0x30A1 && r <= 0x30F6 {
+		return r - 0x60
+	}
+	return r
+}
+
+// romanizeKana romanizes one kana, combining it with a following small
+// kana (きゃ, ファ) and handling the sokuon (っ) and long vowel mark (ー).
+func romanizeKana(runes []rune, i int) (string, int) {
+	r := toHiragana(runes[i])
+
+	switch r {
+	case 'っ':
+		if i+1 < len(runes) && isKana(runes[i+1]) {
+			next, end := romanizeKana(runes, i+1)
+			if strings.HasPrefix(next, "ch") {
+				return "t" + next, end
+			}
+			if next != "" && !strings.ContainsRune("aeioun", rune(next[0])) {
+				return next[:1] + next, end
+			}
+			return next, end
+		}
+		return "", i + 1
+	case 'ー':
+		if i > 0 {
+			if prev, _ := romanizeKana(runes, i-1); prev != "" {
+				return prev[len(prev)-1:], i + 1
+			}
+		}
+		return "", i + 1
+	}
+
+	base := hiragana[r]
+	if i+1 >= len(runes) || base == "" {
+		return base, i + 1
+	}
+
+	switch small := toHiragana(runes[i+1]); small {
+	case 'ゃ', 'ゅ', 'ょ':
+		if !strings.HasSuffix(base, "i") || len(base) == 1 {
+			return base, i + 1
+		}
+		stem := base[:len(base)-1]
+		if stem == "sh" || stem == "ch" || stem == "j" {
+			return stem + hiragana[small][1:], i + 2
+		}
+		return stem + hiragana[small], i + 2
+	case 'ぁ', 'ぃ', 'ぅ', 'ぇ', 'ぉ':
+		stem := base[:len(base)-1]
+		if stem == "" {
+			stem = "w" // ウィ, ウェ
+		}
+		return stem + hiragana[small], i + 2
+	}
+	return base, i + 1
+}
+
+func isThai(r rune) bool {
+	return r >= 0x0E01 && r <= 0x0E5B
+}
+
+func isThaiConsonant(r rune) bool {
+	return r >= 'ก' && r <= 'ฮ'
+}
+
+func isThaiLeadingVowel(r rune) bool {
+	return r >= 'เ' && r <= 'ไ'
+}
+
+func isThaiFollowingVowel(r rune) bool {
+	switch r {
+	case 'ะ', 'ั', 'า', 'ำ', 'ิ', 'ี', 'ึ', 'ื', 'ุ', 'ู', '็':
+		return true
+	}
+	return false
+}
+
+func isThaiToneMark(r rune) bool {
+	return r >= '่' && r <= '๋'
+}
+
+// thaiConsonants holds the RTGS initial and final sound of each consonant.
+// An empty final means the consonant doesn't occur in final position and is
+// romanized with its initial sound.
+var thaiConsonants = map[rune][2]string{
+	'ก': {"k", "k"}, 'ข': {"kh", "k"}, 'ฃ': {"kh", "k"}, 'ค': {"kh", "k"}, 'ฅ': {"kh", "k"}, 'ฆ': {"kh", "k"},
+	'ง': {"ng", "ng"}, 'จ': {"ch", "t"}, 'ฉ': {"ch", ""}, 'ช': {"ch", "t"}, 'ซ': {"s", "t"}, 'ฌ': {"ch", ""},
+	'ญ': {"y", "n"}, 'ฎ': {"d", "t"}, 'ฏ': {"t", "t"}, 'ฐ': {"th", "t"}, 'ฑ': {"th", "t"}, 'ฒ': {"th", "t"},
+	'ณ': {"n", "n"}, 'ด': {"d", "t"}, 'ต': {"t", "t"}, 'ถ': {"th", "t"}, 'ท': {"th", "t"}, 'ธ': {"th", "t"},
+	'น': {"n", "n"}, 'บ': {"b", "p"}, 'ป': {"p", "p"}, 'ผ': {"ph", ""}, 'ฝ': {"f", "p"}, 'พ': {"ph", "p"},
+	'ฟ': {"f", "p"}, 'ภ': {"ph", "p"}, 'ม': {"m", "m"}, 'ย': {"y", "i"}, 'ร': {"r", "n"}, 'ฤ': {"rue", ""},
+	'ล': {"l", "n"}, 'ฦ': {"lue", ""}, 'ว': {"w", "o"}, 'ศ': {"s", "t"}, 'ษ': {"s", "t"}, 'ส': {"s", "t"},
+	'ห': {"h", ""}, 'ฬ': {"l", "n"}, 'อ': {"", ""}, 'ฮ': {"h", ""},
+}
+
+// thaiClusters lists, per initial consonant, the consonants it can form a
+// true cluster with (e.g. ปล in ปลา).
+var thaiClusters = map[rune]string{
+	'ก': "รลว", 'ข': "รลว", 'ค': "รลว", 'ต': "ร", 'ป': "รล", 'พ': "รล",
+	'ผ': "ล", 'บ': "รล", 'ด': "ร", 'ฟ': "รล", 'ท': "ร",
+}
+
+// thaiVowels maps a leading vowel (or 0) plus the vowel marks that follow
+// the initial consonant to its RTGS romanization.
+var thaiVowels = map[rune]map[string]string{
+	0: {
+		"ะ": "a", "ั": "a", "า": "a", "ำ": "am", "ิ": "i", "ี": "i", "ึ": "ue", "ื": "ue", "ุ": "u", "ู": "u",
+		"ัว": "ua", "ัวะ": "ua", "ว": "ua", "ือ": "ue", "อ": "o", "็อ": "o", "ีย": "ia", "ียะ": "ia",
+	},
+	'เ': {
+		"": "e", "ะ": "e", "็": "e", "า": "ao", "าะ": "o", "ีย": "ia", "ียะ": "ia",
+		"ือ": "uea", "ือะ": "uea", "อ": "oe", "อะ": "oe", "ิ": "oe", "ย": "oei",
+	},
+	'แ': {"": "ae", "ะ": "ae", "็": "ae"},
+	'โ': {"": "o", "ะ": "o"},
+	'ใ': {"": "ai"},
+	'ไ': {"": "ai"},
+}
+
+// romanizeThaiSyllable romanizes the Thai syllable starting at runes[i] and
+// returns the index after it. Thai has no spaces between words and its
+// spelling isn't fully phonetic, so syllable boundaries are guessed from the
+// usual consonant-vowel-consonant patterns; the result is a readable
+// approximation of RTGS rather than a dictionary-backed transcription.
+func romanizeThaiSyllable(runes []rune, i int) (string, int) {
+	r := runes[i]
+	switch {
+	case r >= '๐' && r <= '๙':
+		return string('0' + (r - '๐')), i + 1
+	case r == 'ๆ' || r == 'ฯ' || isThaiToneMark(r) || r == '์':
+		return "", i + 1
+	case !isThaiLeadingVowel(r) && !isThaiConsonant(r):
+		return "", i + 1
+	}
+
+	at := func(k int) rune {
+		if k < len(runes) {
+			return runes[k]
+		}
+		return 0
+	}
+	skipTones := func() {
+		for isThaiToneMark(at(i)) {
+			i++
+		}
+	}
+
+	var lead rune
+	if isThaiLeadingVowel(r) {
+		lead = r
+		i++
+	}
+	if !isThaiConsonant(at(i)) {
+		if v, ok := thaiVowels[lead][""]; ok {
+			return v, i
+		}
+		return "", i
+	}
+
+	// Initial consonant, a silent ห or อ before a sonorant, and a cluster
+	// with ร, ล or ว.
+	c1 := at(i)
+	i++
+	if (c1 == 'ห' || c1 == 'อ') && strings.ContainsRune("งญนมยรลว", at(i)) && (lead != 0 || isThaiFollowingVowel(at(i+1)) || isThaiToneMark(at(i+1))) {
+		c1 = at(i)
+		i++
+	}
+	initial := thaiConsonants[c1][0]
+	skipTones()
+	if clusters := thaiClusters[c1]; clusters != "" && strings.ContainsRune(clusters, at(i)) {
+		next := at(i + 1)
+		if isThaiToneMark(next) {
+			next = at(i + 2)
+		}
+		if isThaiFollowingVowel(next) && next != 'ั' || lead != 0 && !isThaiConsonant(next) || next == 'ั' && at(i+2) != 'ว' {
+			initial += thaiConsonants[at(i)][0]
+			i++
+		}
+	}
+
+	// Vowel marks, including the glides that complete a compound vowel.
+	var marks strings.Builder
+	for skipTones(); isThaiFollowingVowel(at(i)); skipTones() {
+		marks.WriteRune(at(i))
+		i++
+	}
+	switch m := marks.String(); {
+	case m == "ี" && at(i) == 'ย', m == "ื" && at(i) == 'อ', m == "ั" && at(i) == 'ว', m == "็" && at(i) == 'อ':
+		marks.WriteRune(at(i))
+		i++
+	case m == "" && lead == 'เ' && (at(i) == 'อ' || at(i) == 'ย' && !isThaiFollowingVowel(at(i+1))):
+		marks.WriteRune(at(i))
+		i++
+	case m == "" && lead == 0 && at(i) == 'อ' && !isThaiFollowingVowel(at(i+1)):
+		marks.WriteRune(at(i))
+		i++
+	case m == "" && lead == 0 && at(i) == 'ว' && isThaiConsonant(at(i+1)) && !isThaiFollowingVowel(at(i+2)):
+		marks.WriteRune(at(i))
+		i++
+	}
+	skipTones()
+	if at(i) == 'ะ' {
+		marks.WriteRune('ะ')
+		i++
+	}
+
+	m := marks.String()
+	vowel, known := thaiVowels[lead][m]
+	if !known {
+		vowel = thaiVowels[lead][""]
+		if lead == 0 {
+			vowel = m
+		}
+	}
+	open := strings.HasSuffix(m, "ะ") || m == "ำ" || lead == 'ใ' || lead == 'ไ' && at(i) != 'ย' || lead == 'เ' && m == "า"
+
+	// Final consonant: a consonant that doesn't start the next syllable.
+	final := ""
+	startsCluster := func(k int) bool {
+		return strings.ContainsRune(thaiClusters[at(k)], at(k+1)) && at(k+1) != 0 && isThaiFollowingVowel(at(k+2))
+	}
+	if c := at(i); !open && isThaiConsonant(c) && c != 'อ' && !isThaiFollowingVowel(at(i+1)) && !isThaiToneMark(at(i+1)) && !startsCluster(i) {
+		if at(i+1) == '์' {
+			i += 2
+		} else {
+			final = thaiConsonants[c][1]
+			if final == "" {
+				final = thaiConsonants[c][0]
+			}
+			i++
+			// Consonants silenced by a thanthakhat after the final, as
+			// in จันทร์.
+			if isThaiConsonant(at(i)) && at(i+1) == '์' {
+				i += 2
+			} else if isThaiConsonant(at(i)) && isThaiConsonant(at(i+1)) && at(i+2) == '์' {
+				i += 3
+			}
+		}
+	}
+	if m == "" && lead == 0 {
+		vowel = "a"
+		if final != "" {
+			vowel = "o"
+		}
+	}
+	if strings.HasSuffix(vowel, "i") && final == "i" {
+		final = "" // ไทย
+	}
+	if vowel == "ua" && final == "o" {
+		final = ""
+	}
+
+	return initial + vowel + final, i
+}
@@ -0,0 +1,59 @@
+package subtitle
+
+import (
+	"os"
+	"testing"
+)
+
+func TestRomanize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		table map[rune]string
+		want  string
+	}{
+		{name: "latin passthrough", input: "Hello, world!", want: "Hello, world!"},
+		{name: "thai greeting", input: "สวัสดีครับ", want: "sawatdikhrap"},
+		{name: "thai words with spaces", input: "ประเทศไทย กรุงเทพ", want: "prathetthai krungthep"},
+		{name: "thai leading vowels", input: "เรียน เพื่อน แม่ โรง ไป", want: "rian phuean mae rong pai"},
+		{name: "thai silent letters", input: "จันทร์ หมา อยู่", want: "chan ma yu"},
+		{name: "thai digits", input: "๒๕๖๗", want: "2567"},
+		{name: "hiragana", input: "ありがとう", want: "arigatou"},
+		{name: "youon and sokuon", input: "きょう がっこう ちょっと", want: "kyou gakkou chotto"},
+		{name: "katakana with long vowels", input: "コーヒー ファイル", want: "koohii fairu"},
+		{
+			name:  "table lookup",
+			input: "你好 Go",
+			table: map[rune]string{'你': "nǐ", '好': "hǎo"},
+			want:  "nǐ hǎo Go",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Romanizer{Table: tt.table}.Romanize(tt.input)
+			if got != tt.want {
+				t.Errorf("Romanize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadRomanizeTable(t *testing.T) {
+	tempFile, err := os.CreateTemp("", "test-romanize-*.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	tempFile.WriteString("你,nǐ\n好,hǎo\n")
+	tempFile.Close()
+
+	table, err := ReadRomanizeTable(tempFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table['你'] != "nǐ" || table['好'] != "hǎo" {
+		t.Errorf("ReadRomanizeTable() = %v", table)
+	}
+}
@@ -0,0 +1,88 @@
+package subtitle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// SentenceRules say how the words of a language make up sentences.
+type SentenceRules struct {
+	// Gap is the longest pause between words of one sentence, in
+	// microseconds; a longer one starts the next.
+	Gap int64
+	// Separator goes between words: a space, or nothing for languages
+	// written without spaces between words, such as Thai and Japanese.
+	Separator string
+	// Enders are the marks that end a sentence when a word ends with one.
+	Enders string
+}
+
+// defaultSentenceRules are the rules of languages written with spaces
+// between words and Latin sentence marks.
+var defaultSentenceRules = SentenceRules{Gap: 700000, Separator: " ", Enders: ".!?…"}
+
+// sentenceRules are the rules of the languages whose writing differs from
+// defaultSentenceRules, by primary language subtag. Thai, Lao and Khmer
+// mark few sentence ends, so their sentences mostly end at pauses, which
+// speakers of them take between phrases more briefly.
+var sentenceRules = map[string]SentenceRules{
+	"th": {Gap: 500000},
+	"lo": {Gap: 500000},
+	"km": {Gap: 500000, Enders: "។?!"},
+	"my": {Gap: 500000, Enders: "။?!"},
+	"ja": {Gap: 700000, Enders: "。！？!?…"},
+	"zh": {Gap: 700000, Enders: "。！？!?…"},
+}
+
+// SentenceRulesFor returns the rules of lang, a language tag such as th or
+// en-US, or the rules of languages written with spaces and Latin
+// sentence marks for any other language or "".
+func SentenceRulesFor(lang string) SentenceRules {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.ReplaceAll(lang, "_", "-")), "-")
+	if rules, ok := sentenceRules[primary]; ok {
+		return rules
+	}
+	return defaultSentenceRules
+}
+
+// Sentences groups word cues of one track, sorted by start time, into a
+// cue for each sentence: a sentence runs until a word ending with one of
+// rules.Enders, or until a pause longer than rules.Gap. Words are joined
+// with rules.Separator, except marks standing alone as words, which join
+// the word before them. A sentence takes the source and speaker of its
+// first word, and its emphasis if every word shares it.
+func Sentences(cues []Cue, rules SentenceRules) []Cue {
+	var out []Cue
+	open := false
+	for _, c := range cues {
+		text := strings.TrimSpace(c.Text)
+		if text == "" {
+			continue
+		}
+		if open && c.Start-out[len(out)-1].End > rules.Gap {
+			open = false
+		}
+		if !open {
+			c.Text = text
+			out = append(out, c)
+		} else {
+			s := &out[len(out)-1]
+			separator := rules.Separator
+			if strings.IndexFunc(text, func(r rune) bool { return !unicode.IsPunct(r) }) < 0 {
+				separator = ""
+			}
+			s.Text += separator + text
+			if c.Original != "" {
+				s.Original += separator + c.Original
+			}
+			s.End = max(s.End, c.End)
+			if s.Emphasis != c.Emphasis {
+				s.Emphasis = EmphasisNone
+			}
+		}
+		last, _ := utf8.DecodeLastRuneInString(text)
+		open = !strings.ContainsRune(rules.Enders, last)
+	}
+	return out
+}
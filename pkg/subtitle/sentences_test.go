@@ -0,0 +1,65 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSentences(t *testing.T) {
+	words := func(texts ...string) []Cue {
+		cues := make([]Cue, len(texts))
+		for i, text := range texts {
+			cues[i] = Cue{Start: int64(i) * 300_000, End: int64(i)*300_000 + 250_000, Text: text}
+		}
+		return cues
+	}
+	tests := []struct {
+		name string
+		cues []Cue
+		lang string
+		want []Cue
+	}{
+		{
+			name: "sentence marks",
+			cues: words("Hello", "there.", "How", "are", "you", "?"),
+			lang: "en",
+			want: []Cue{
+				{Start: 0, End: 550_000, Text: "Hello there."},
+				{Start: 600_000, End: 1_750_000, Text: "How are you?"},
+			},
+		},
+		{
+			name: "pause",
+			cues: append(words("so", "we"), Cue{Start: 2_000_000, End: 2_400_000, Text: " went "}),
+			want: []Cue{
+				{Start: 0, End: 550_000, Text: "so we"},
+				{Start: 2_000_000, End: 2_400_000, Text: "went"},
+			},
+		},
+		{
+			name: "Thai without spaces, a shorter pause",
+			cues: append(words("สวัสดี", "ครับ"), Cue{Start: 1_150_000, End: 1_500_000, Text: "ไป"}),
+			lang: "th-TH",
+			want: []Cue{
+				{Start: 0, End: 550_000, Text: "สวัสดีครับ"},
+				{Start: 1_150_000, End: 1_500_000, Text: "ไป"},
+			},
+		},
+		{
+			name: "Japanese marks",
+			cues: words("今日は", "晴れ。", "明日"),
+			lang: "ja",
+			want: []Cue{
+				{Start: 0, End: 550_000, Text: "今日は晴れ。"},
+				{Start: 600_000, End: 850_000, Text: "明日"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sentences(tt.cues, SentenceRulesFor(tt.lang)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sentences() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
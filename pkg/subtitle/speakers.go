@@ -0,0 +1,89 @@
+package subtitle
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ReadSpeakers loads a CSV of "key,name" records mapping a material ID, a
+// track ID or a 1-based text track number to the speaker's name.
+func ReadSpeakers(filename string) (map[string]string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open speaker map: %w", err)
+	}
+	defer file.Close()
+
+	records, err := newCSVReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse speaker map: %w", err)
+	}
+
+	speakers := make(map[string]string, len(records))
+	for i, record := range records {
+		if len(record) < 2 || record[0] == "" || record[1] == "" {
+			return nil, fmt.Errorf("invalid speaker entry %d", i+1)
+		}
+		speakers[record[0]] = record[1]
+	}
+	return speakers, nil
+}
+
+// LookupSpeaker resolves the speaker for a cue, preferring the most specific
+// key: the material ID, then the track ID, then the track number.
+func LookupSpeaker(speakers map[string]string, source Source) string {
+	if len(speakers) == 0 {
+		return ""
+	}
+	if name, ok := speakers[source.MaterialID]; ok {
+		return name
+	}
+	if name, ok := speakers[source.TrackID]; ok && source.TrackID != "" {
+		return name
+	}
+	return speakers[strconv.Itoa(source.TrackNumber)]
+}
+
+// SpeakerPrefix prepends "NAME: " to cues whose speaker is known across a
+// stream of cues, in draft or time order, and sets their Speaker. Only the
+// first cue of each segment is prefixed, otherwise every word of
+// word-level captions would repeat the name.
+type SpeakerPrefix struct {
+	Speakers map[string]string
+	// seen holds the segments whose first cue was applied.
+	seen map[speakerSegment]bool
+}
+
+type speakerSegment struct {
+	track   int
+	segment int
+}
+
+// Apply prefixes c, the cue after the last one applied.
+func (p *SpeakerPrefix) Apply(c *Cue) {
+	if len(p.Speakers) == 0 {
+		return
+	}
+	c.Speaker = LookupSpeaker(p.Speakers, c.Source)
+	key := speakerSegment{c.Source.TrackNumber, c.Source.Segment}
+	if p.seen[key] {
+		return
+	}
+	if p.seen == nil {
+		p.seen = make(map[speakerSegment]bool)
+	}
+	p.seen[key] = true
+	if c.Speaker != "" {
+		c.Text = c.Speaker + ": " + c.Text
+	}
+}
+
+// PrefixSpeakers applies a SpeakerPrefix to cues, which must be in draft
+// or time order.
+func PrefixSpeakers(cues []Cue, speakers map[string]string) {
+	p := SpeakerPrefix{Speakers: speakers}
+	for i := range cues {
+		p.Apply(&cues[i])
+	}
+}
@@ -0,0 +1,127 @@
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseSRT reads SubRip cues. Cue numbers are not trusted; cues are returned
+// in file order and renumbered when written. A cue starting without the
+// blank line that should separate it from the previous one is still
+// recognized by its timing line. UTF-8 and UTF-16 input with a byte order
+// mark is accepted. Text, including HTML-style tags such as <i>, is kept as
+// written.
+func ParseSRT(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(NewTextReader(r))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var cues []Cue
+	var current *Cue
+	var lines []string
+	lineNumber := 0
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(lines, "\n")
+			cues = append(cues, *current)
+		}
+		current = nil
+		lines = lines[:0]
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case current == nil && strings.Contains(line, "-->"):
+			start, end, err := parseTimingLine(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+			current = &Cue{Start: start, End: end}
+		case current == nil:
+			// Cue number line; skipped.
+		default:
+			if start, end, err := parseTimingLine(line); err == nil {
+				// The blank line before this cue is missing; drop its
+				// number from the previous cue's text.
+				if n := len(lines); n > 0 && isCueNumber(lines[n-1]) {
+					lines = lines[:n-1]
+				}
+				flush()
+				current = &Cue{Start: start, End: end}
+				continue
+			}
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+	flush()
+
+	return cues, nil
+}
+
+func isCueNumber(line string) bool {
+	_, err := strconv.Atoi(strings.TrimSpace(line))
+	return err == nil
+}
+
+const (
+	millisPerHour   = 3600000
+	millisPerMinute = 60000
+	millisPerSecond = 1000
+)
+
+func parseTimingLine(line string) (int64, int64, error) {
+	from, to, _ := strings.Cut(line, "-->")
+	start, err := ParseTimestamp(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	// Anything after the end time (e.g. SRT position hints) is ignored.
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("missing end time")
+	}
+	end, err := ParseTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// ParseTimestamp parses "HH:MM:SS,mmm" (or with a '.' before the
+// milliseconds) into microseconds.
+func ParseTimestamp(s string) (int64, error) {
+	clock, fraction, found := strings.Cut(strings.Replace(s, ",", ".", 1), ".")
+	parts := strings.Split(clock, ":")
+	if len(parts) != 3 || !found || len(fraction) != 3 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	var values [4]int64
+	for i, field := range append(parts, fraction) {
+		n, err := strconv.ParseInt(field, 10, 64)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		values[i] = n
+	}
+	if values[1] > 59 || values[2] > 59 {
+		return 0, fmt.Errorf("invalid timestamp %q", s)
+	}
+
+	milliseconds := values[0]*millisPerHour + values[1]*millisPerMinute + values[2]*millisPerSecond + values[3]
+	return milliseconds * 1000, nil
+}
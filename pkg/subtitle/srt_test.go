@@ -0,0 +1,114 @@
+package subtitle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseSRT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []Cue
+		wantErr bool
+	}{
+		{
+			name:  "empty input",
+			input: "",
+			want:  nil,
+		},
+		{
+			name:  "single cue",
+			input: "1\n00:00:01,000 --> 00:00:02,500\nHello\n",
+			want:  []Cue{{Start: 1000000, End: 2500000, Text: "Hello"}},
+		},
+		{
+			name:  "multi-line cues with CRLF and BOM",
+			input: "\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nHello\r\nworld\r\n\r\n2\r\n00:01:00,000 --> 01:00:00,001\r\nBye\r\n",
+			want: []Cue{
+				{Start: 1000000, End: 2000000, Text: "Hello\nworld"},
+				{Start: 60000000, End: 3600001000, Text: "Bye"},
+			},
+		},
+		{
+			name:  "missing cue numbers",
+			input: "00:00:01.000 --> 00:00:02.000\nNo number\n\n\n00:00:03,000 --> 00:00:04,000 X1:0\nPosition hint\n",
+			want: []Cue{
+				{Start: 1000000, End: 2000000, Text: "No number"},
+				{Start: 3000000, End: 4000000, Text: "Position hint"},
+			},
+		},
+		{
+			name:  "missing blank line between cues",
+			input: "1\n00:00:01,000 --> 00:00:02,000\n<i>Hello</i>\n2\n00:00:03,000 --> 00:00:04,000\nWorld\n00:00:05,000 --> 00:00:06,000\nAgain\n",
+			want: []Cue{
+				{Start: 1000000, End: 2000000, Text: "<i>Hello</i>"},
+				{Start: 3000000, End: 4000000, Text: "World"},
+				{Start: 5000000, End: 6000000, Text: "Again"},
+			},
+		},
+		{
+			name:  "UTF-16 little endian",
+			input: utf16LE("\ufeff1\r\n00:00:01,000 --> 00:00:02,000\r\nสวัสดี\r\n"),
+			want:  []Cue{{Start: 1000000, End: 2000000, Text: "สวัสดี"}},
+		},
+		{
+			name:    "invalid timestamp",
+			input:   "1\n00:00:xx,000 --> 00:00:02,000\nHello\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSRT(strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSRT() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseSRT() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    int64
+		wantErr bool
+	}{
+		{input: "00:00:00,000", want: 0},
+		{input: "01:02:03,004", want: 3723004000},
+		{input: "00:00:01.500", want: 1500000},
+		{input: "123:00:00,000", want: 123 * 3600 * 1000 * 1000},
+		{input: "00:60:00,000", wantErr: true},
+		{input: "00:00:00", wantErr: true},
+		{input: "00:00:00,5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseTimestamp(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseTimestamp() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("ParseTimestamp() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func utf16LE(s string) string {
+	var b strings.Builder
+	for _, u := range utf16.Encode([]rune(s)) {
+		b.WriteByte(byte(u))
+		b.WriteByte(byte(u >> 8))
+	}
+	return b.String()
+}
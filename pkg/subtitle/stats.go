@@ -0,0 +1,158 @@
+package subtitle
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// phraseGap is the longest pause a phrase of Stats runs across: words
+// further apart are not counted as spoken together.
+const phraseGap = 1000000
+
+// TranscriptStats describes what is said in a transcript and when, for
+// content analysis. Times are in microseconds. Words are runs of letters,
+// digits and apostrophes, compared in lower case; text without spaces
+// between words, such as Thai, counts as one word per run unless its cues
+// hold a word each, as drafts with word timings give.
+type TranscriptStats struct {
+	Cues int `json:"cues"`
+	// Duration runs from the start of the first cue to the end of the last.
+	Duration int64 `json:"duration_us"`
+	// SpeakingTime is the time covered by at least one cue.
+	SpeakingTime int64 `json:"speaking_time_us"`
+	Words        int   `json:"words"`
+	// WPM is the words per minute of speaking time.
+	WPM     float64       `json:"wpm"`
+	Windows []WindowStats `json:"windows"`
+	// Silences are the longest gaps between speech, longest first.
+	Silences []Silence `json:"longest_silences"`
+	// TopWords and TopPhrases are the most frequent words and the most
+	// frequent runs of two or three words said more than once, most
+	// frequent first.
+	TopWords   []TermCount `json:"top_words"`
+	TopPhrases []TermCount `json:"top_phrases"`
+}
+
+// WindowStats counts the words of the cues starting in one window of
+// time.
+type WindowStats struct {
+	Start int64 `json:"start_us"`
+	Words int   `json:"words"`
+	// WPM is the words per minute of the whole window.
+	WPM float64 `json:"wpm"`
+}
+
+// Silence is a gap between the end of one cue and the start of the next
+// with no cue between.
+type Silence struct {
+	Start    int64 `json:"start_us"`
+	End      int64 `json:"end_us"`
+	Duration int64 `json:"duration_us"`
+}
+
+// TermCount is how often a word or phrase is said.
+type TermCount struct {
+	Term  string `json:"term"`
+	Count int    `json:"count"`
+}
+
+// Stats analyzes cues, which must be sorted by start time, counting words
+// per window of time and listing the top silences, words and phrases of
+// each kind.
+func Stats(cues []Cue, window int64, top int) TranscriptStats {
+	stats := TranscriptStats{Cues: len(cues), Windows: []WindowStats{}, Silences: []Silence{}}
+	wordCounts, phraseCounts := make(map[string]int), make(map[string]int)
+	if len(cues) == 0 {
+		stats.TopWords, stats.TopPhrases = topTerms(wordCounts, 1, top), topTerms(phraseCounts, 2, top)
+		return stats
+	}
+
+	first, last := cues[0].Start, cues[0].End
+	spokenUntil := cues[0].Start
+	silences := stats.Silences
+	var phrase []string
+	for _, c := range cues {
+		last = max(last, c.End)
+		if c.Start > spokenUntil {
+			silences = append(silences, Silence{Start: spokenUntil, End: c.Start, Duration: c.Start - spokenUntil})
+		}
+		if c.Start-spokenUntil > phraseGap {
+			phrase = phrase[:0]
+		}
+		stats.SpeakingTime += max(c.End-max(c.Start, spokenUntil), 0)
+		spokenUntil = max(spokenUntil, c.End)
+
+		words := 0
+		for j, clause := range strings.FieldsFunc(c.Text, isClauseBreak) {
+			if j > 0 {
+				phrase = phrase[:0]
+			}
+			for _, word := range strings.FieldsFunc(strings.ToLower(clause), isWordBreak) {
+				if word = strings.Trim(word, "'’"); word == "" {
+					continue
+				}
+				words++
+				wordCounts[word]++
+				phrase = append(phrase, word)
+				for n := 2; n <= min(3, len(phrase)); n++ {
+					phraseCounts[strings.Join(phrase[len(phrase)-n:], " ")]++
+				}
+			}
+		}
+		// A clause left open may go on in the next cue.
+		if text := []rune(strings.TrimSpace(c.Text)); len(text) > 0 && isClauseBreak(text[len(text)-1]) {
+			phrase = phrase[:0]
+		}
+		stats.Words += words
+
+		if window > 0 {
+			n := int((c.Start - first) / window)
+			for len(stats.Windows) <= n {
+				stats.Windows = append(stats.Windows, WindowStats{Start: first + int64(len(stats.Windows))*window})
+			}
+			stats.Windows[n].Words += words
+		}
+	}
+	stats.Duration = last - first
+	if stats.SpeakingTime > 0 {
+		stats.WPM = float64(stats.Words) * 60e6 / float64(stats.SpeakingTime)
+	}
+	for i := range stats.Windows {
+		stats.Windows[i].WPM = float64(stats.Windows[i].Words) * 60e6 / float64(window)
+	}
+
+	slices.SortStableFunc(silences, func(a, b Silence) int { return cmp.Compare(b.Duration, a.Duration) })
+	stats.Silences = silences[:min(top, len(silences))]
+	stats.TopWords = topTerms(wordCounts, 1, top)
+	stats.TopPhrases = topTerms(phraseCounts, 2, top)
+	return stats
+}
+
+// topTerms returns the top terms said at least minCount times, most
+// frequent first and alphabetically among equals.
+func topTerms(counts map[string]int, minCount, top int) []TermCount {
+	terms := []TermCount{}
+	for term, count := range counts {
+		if count >= minCount {
+			terms = append(terms, TermCount{term, count})
+		}
+	}
+	slices.SortFunc(terms, func(a, b TermCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Term, b.Term))
+	})
+	return terms[:min(top, len(terms))]
+}
+
+// isClauseBreak reports whether r ends a clause, so phrases do not run
+// across it.
+func isClauseBreak(r rune) bool {
+	return strings.ContainsRune(".,;:!?…。、，！？", r)
+}
+
+// isWordBreak reports whether r separates words. Combining marks, which
+// Thai vowels and tone marks are, stay with their letters.
+func isWordBreak(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r) && r != '\'' && r != '’'
+}
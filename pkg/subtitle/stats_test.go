@@ -0,0 +1,47 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	cues := []Cue{
+		{Start: 0, End: 2_000_000, Text: "Welcome back to the channel."},
+		{Start: 1_500_000, End: 3_000_000, Text: "Welcome back, everyone"},
+		{Start: 3_200_000, End: 4_000_000, Text: "to the"},
+		{Start: 4_000_000, End: 5_000_000, Text: "channel!"},
+		// Past phraseGap, so "the channel" is not heard again.
+		{Start: 65_000_000, End: 66_000_000, Text: "the"},
+		{Start: 67_500_000, End: 68_000_000, Text: "channel's สวัสดี"},
+	}
+	stats := Stats(cues, 60_000_000, 3)
+
+	want := TranscriptStats{
+		Cues:         6,
+		Duration:     68_000_000,
+		SpeakingTime: 6_300_000,
+		Words:        14,
+		WPM:          14 * 60 / 6.3,
+		Windows: []WindowStats{
+			{Start: 0, Words: 11, WPM: 11},
+			{Start: 60_000_000, Words: 3, WPM: 3},
+		},
+		Silences: []Silence{
+			{Start: 5_000_000, End: 65_000_000, Duration: 60_000_000},
+			{Start: 66_000_000, End: 67_500_000, Duration: 1_500_000},
+			{Start: 3_000_000, End: 3_200_000, Duration: 200_000},
+		},
+		// Equal counts are in alphabetical order.
+		TopWords:   []TermCount{{"the", 3}, {"back", 2}, {"channel", 2}},
+		TopPhrases: []TermCount{{"the channel", 2}, {"to the", 2}, {"to the channel", 2}},
+	}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("Stats() =\n%+v\nwant\n%+v", stats, want)
+	}
+
+	empty := Stats(nil, 60_000_000, 3)
+	if empty.Words != 0 || empty.Windows == nil || empty.Silences == nil || empty.TopWords == nil || empty.TopPhrases == nil {
+		t.Errorf("Stats(nil) = %+v, want zero counts and empty lists", empty)
+	}
+}
@@ -0,0 +1,129 @@
+package subtitle
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// StyleGuide holds the limits a subtitle style guide sets. Times are in
+// microseconds; zero limits are not checked.
+type StyleGuide struct {
+	Name          string
+	MaxLines      int
+	MaxLineLength int
+	// MaxCPS is the highest reading speed in characters per second,
+	// counting spaces and punctuation but not line breaks.
+	MaxCPS      float64
+	MinDuration int64
+	MaxDuration int64
+	// MinGap is the shortest pause allowed between consecutive cues.
+	// Cues may also follow each other without a gap.
+	MinGap int64
+}
+
+// styleGuides are the built-in profiles. Gaps are given in frames and
+// converted at the frame rate passed to ParseStyleGuide.
+var styleGuides = map[string]struct {
+	guide     StyleGuide
+	gapFrames float64
+}{
+	// Netflix's Timed Text Style Guide for adult programs.
+	"netflix": {
+		guide: StyleGuide{
+			Name:          "netflix",
+			MaxLines:      2,
+			MaxLineLength: 42,
+			MaxCPS:        20,
+			MinDuration:   833_333,
+			MaxDuration:   7_000_000,
+		},
+		gapFrames: 2,
+	},
+}
+
+// ParseStyleGuide returns the built-in style guide of the given name for a
+// video at fps frames per second.
+func ParseStyleGuide(name string, fps float64) (StyleGuide, error) {
+	profile, ok := styleGuides[name]
+	if !ok {
+		names := make([]string, 0, len(styleGuides))
+		for name := range styleGuides {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return StyleGuide{}, fmt.Errorf("unknown style guide %q (want %s)", name, strings.Join(names, ", "))
+	}
+	guide := profile.guide
+	guide.MinGap = int64(profile.gapFrames * 1e6 / fps)
+	return guide, nil
+}
+
+// Violation is a cue breaking a rule of a style guide, with a suggested
+// fix. Cue is the 1-based cue number, as written in SRT.
+type Violation struct {
+	Cue     int
+	Rule    string
+	Message string
+	Fix     string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("cue %d: %s; %s", v.Cue, v.Message, v.Fix)
+}
+
+// Check returns the violations of g in cues, which must be sorted by start
+// time, in cue order.
+func (g StyleGuide) Check(cues []Cue) []Violation {
+	var violations []Violation
+	add := func(i int, rule, fix, format string, args ...any) {
+		violations = append(violations, Violation{Cue: i + 1, Rule: rule, Message: fmt.Sprintf(format, args...), Fix: fix})
+	}
+
+	for i, c := range cues {
+		lines := strings.Split(c.Text, "\n")
+		if g.MaxLines > 0 && len(lines) > g.MaxLines {
+			add(i, "lines", fmt.Sprintf("rewrap it into %d lines or split the cue", g.MaxLines), "%d lines (max %d)", len(lines), g.MaxLines)
+		}
+		characters := 0
+		for n, line := range lines {
+			length := utf8.RuneCountInString(line)
+			characters += length
+			if g.MaxLineLength > 0 && length > g.MaxLineLength {
+				add(i, "line-length", fmt.Sprintf("wrap it with --max-chars %d", g.MaxLineLength), "line %d has %d characters (max %d)", n+1, length, g.MaxLineLength)
+			}
+		}
+
+		duration := c.End - c.Start
+		if g.MinDuration > 0 && duration < g.MinDuration {
+			add(i, "min-duration", "extend it to "+seconds(g.MinDuration), "lasts %s (min %s)", seconds(duration), seconds(g.MinDuration))
+		}
+		if g.MaxDuration > 0 && duration > g.MaxDuration {
+			add(i, "max-duration", "split it into shorter cues", "lasts %s (max %s)", seconds(duration), seconds(g.MaxDuration))
+		}
+		if g.MaxCPS > 0 && duration > 0 {
+			if cps := float64(characters) * 1e6 / float64(duration); cps > g.MaxCPS {
+				needed := int64(float64(characters) / g.MaxCPS * 1e6)
+				add(i, "reading-speed", fmt.Sprintf("extend it to %s or shorten the text", seconds(needed)), "reads at %.1f characters per second (max %g)", cps, g.MaxCPS)
+			}
+		}
+
+		if i+1 < len(cues) {
+			gap := cues[i+1].Start - c.End
+			switch {
+			case gap < 0:
+				add(i, "overlap", fmt.Sprintf("end it at %s, when cue %d starts", seconds(cues[i+1].Start), i+2), "overlaps cue %d by %s", i+2, seconds(-gap))
+			case g.MinGap > 0 && gap > 0 && gap < g.MinGap:
+				add(i, "gap", fmt.Sprintf("end it %s earlier, or at %s to chain the cues", seconds(g.MinGap-gap), seconds(cues[i+1].Start)), "is followed by a %s gap (min %s)", seconds(gap), seconds(g.MinGap))
+			}
+		}
+	}
+	return violations
+}
+
+// seconds formats a duration in microseconds to the millisecond.
+func seconds(microseconds int64) string {
+	return (time.Duration(microseconds) * time.Microsecond).Round(time.Millisecond).String()
+}
@@ -0,0 +1,47 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseStyleGuide(t *testing.T) {
+	guide, err := ParseStyleGuide("netflix", 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if guide.MaxLineLength != 42 || guide.MinGap != 80_000 {
+		t.Errorf("ParseStyleGuide() = %+v", guide)
+	}
+	if _, err := ParseStyleGuide("bbc", 25); err == nil {
+		t.Errorf("ParseStyleGuide() accepted an unknown guide")
+	}
+}
+
+func TestStyleGuideCheck(t *testing.T) {
+	guide, _ := ParseStyleGuide("netflix", 25)
+	cues := []Cue{
+		{Start: 0, End: 2_000_000, Text: "Fine"},
+		{Start: 2_000_000, End: 2_500_000, Text: "Too short"},
+		{Start: 2_540_000, End: 5_000_000, Text: "One\ntwo\nthree"},
+		{Start: 5_000_000, End: 6_000_000, Text: "This line is far longer than forty-two characters"},
+		{Start: 5_500_000, End: 14_000_000, Text: "Long"},
+	}
+
+	var got []string
+	for _, v := range guide.Check(cues) {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"cue 2: lasts 500ms (min 833ms); extend it to 833ms",
+		"cue 2: is followed by a 40ms gap (min 80ms); end it 40ms earlier, or at 2.54s to chain the cues",
+		"cue 3: 3 lines (max 2); rewrap it into 2 lines or split the cue",
+		"cue 4: line 1 has 49 characters (max 42); wrap it with --max-chars 42",
+		"cue 4: reads at 49.0 characters per second (max 20); extend it to 2.45s or shorten the text",
+		"cue 4: overlaps cue 5 by 500ms; end it at 5.5s, when cue 5 starts",
+		"cue 5: lasts 8.5s (max 7s); split it into shorter cues",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package subtitle
+
+import (
+	"iter"
+	"slices"
+)
+
+// Subtitles is an ordered collection of cues. Being a slice, it can be
+// indexed and sliced directly; the methods cover the common queries.
+type Subtitles []Cue
+
+// Track groups the cues that came from one text track of a draft.
+type Track struct {
+	// Number is the 1-based position of the track among the draft's text
+	// tracks, or 0 for cues without a draft source.
+	Number int
+	ID     string
+	Cues   Subtitles
+}
+
+// All yields every cue in order.
+func (s Subtitles) All() iter.Seq[Cue] {
+	return slices.Values(s)
+}
+
+// Indexed yields every cue with its position in s.
+func (s Subtitles) Indexed() iter.Seq2[int, Cue] {
+	return slices.All(s)
+}
+
+// Sort orders s by start time, keeping the order of cues that start
+// together.
+func (s Subtitles) Sort() {
+	Sort(s)
+}
+
+// Filter returns the cues for which keep returns true as a new collection.
+func (s Subtitles) Filter(keep func(Cue) bool) Subtitles {
+	var kept Subtitles
+	for _, c := range s {
+		if keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// Between returns the cues that overlap the time range [start, end) in
+// microseconds, unchanged.
+func (s Subtitles) Between(start, end int64) Subtitles {
+	return s.Filter(func(c Cue) bool {
+		return c.Start < end && c.End > start || c.Start == start
+	})
+}
+
+// Tracks groups s by source track, in order of first appearance. Cues keep
+// their order within each track.
+func (s Subtitles) Tracks() []Track {
+	var tracks []Track
+	index := make(map[int]int)
+	for _, c := range s {
+		i, ok := index[c.Source.TrackNumber]
+		if !ok {
+			i = len(tracks)
+			index[c.Source.TrackNumber] = i
+			tracks = append(tracks, Track{Number: c.Source.TrackNumber, ID: c.Source.TrackID})
+		}
+		tracks[i].Cues = append(tracks[i].Cues, c)
+	}
+	return tracks
+}
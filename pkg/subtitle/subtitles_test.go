@@ -0,0 +1,53 @@
+package subtitle
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSubtitles(t *testing.T) {
+	subs := Subtitles{
+		{Start: 3000000, End: 4000000, Text: "Third", Source: Source{TrackNumber: 2, TrackID: "b"}},
+		{Start: 0, End: 1000000, Text: "First", Source: Source{TrackNumber: 1, TrackID: "a"}},
+		{Start: 1000000, End: 2500000, Text: "Second", Source: Source{TrackNumber: 1, TrackID: "a"}},
+	}
+
+	subs.Sort()
+	var texts []string
+	for c := range subs.All() {
+		texts = append(texts, c.Text)
+	}
+	if want := []string{"First", "Second", "Third"}; !reflect.DeepEqual(texts, want) {
+		t.Errorf("All() after Sort() = %v, want %v", texts, want)
+	}
+
+	for i, c := range subs.Indexed() {
+		if c != subs[i] {
+			t.Errorf("Indexed() yielded %v at %d, want %v", c, i, subs[i])
+		}
+	}
+
+	long := subs.Filter(func(c Cue) bool { return c.End-c.Start > 1000000 })
+	if len(long) != 1 || long[0].Text != "Second" {
+		t.Errorf("Filter() = %v, want only Second", long)
+	}
+
+	if got := slices.Collect(subs.Between(2000000, 3500000).All()); !reflect.DeepEqual(got, []Cue{subs[1], subs[2]}) {
+		t.Errorf("Between() = %v, want Second and Third", got)
+	}
+	if got := subs.Between(4000000, 5000000); len(got) != 0 {
+		t.Errorf("Between() at end of last cue = %v, want none", got)
+	}
+
+	tracks := subs.Tracks()
+	if len(tracks) != 2 {
+		t.Fatalf("Tracks() returned %d tracks, want 2", len(tracks))
+	}
+	if tracks[0].Number != 1 || tracks[0].ID != "a" || len(tracks[0].Cues) != 2 {
+		t.Errorf("Tracks()[0] = %+v", tracks[0])
+	}
+	if tracks[1].Number != 2 || tracks[1].ID != "b" || len(tracks[1].Cues) != 1 {
+		t.Errorf("Tracks()[1] = %+v", tracks[1])
+	}
+}
@@ -0,0 +1,45 @@
+package subtitle
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// NewTextReader returns a reader of r as UTF-8. Text starting with a UTF-16
+// byte order mark, as Windows tools often save subtitles, is transcoded;
+// anything else, including UTF-8 with or without a BOM, passes through.
+func NewTextReader(r io.Reader) io.Reader {
+	reader := bufio.NewReader(r)
+	bom, _ := reader.Peek(2)
+
+	var order func([]byte) uint16
+	switch {
+	case bytes.Equal(bom, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.Equal(bom, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[0])<<8 | uint16(b[1]) }
+	default:
+		return reader
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return &errorReader{err}
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for i := 2; i+1 < len(data); i += 2 {
+		units = append(units, order(data[i:i+2]))
+	}
+	return strings.NewReader(string(utf16.Decode(units)))
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r *errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
@@ -0,0 +1,107 @@
+package subtitle
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+type Rounding int
+
+const (
+	RoundNearest Rounding = iota
+	RoundFloor
+	RoundCeil
+)
+
+func ParseRounding(s string) (Rounding, error) {
+	switch s {
+	case "round":
+		return RoundNearest, nil
+	case "floor":
+		return RoundFloor, nil
+	case "ceil":
+		return RoundCeil, nil
+	}
+	return 0, fmt.Errorf("unknown rounding mode %q (want floor, round or ceil)", s)
+}
+
+// frameEpsilon absorbs floating-point error so a time that already sits on a
+// frame boundary isn't pushed to the neighbouring frame by floor or ceil.
+const frameEpsilon = 1e-6
+
+// frameIndex returns the frame a time in microseconds falls on at fps,
+// rounded in the direction given by mode.
+func frameIndex(microseconds int64, fps float64, mode Rounding) int64 {
+	frames := float64(microseconds) * fps / 1e6
+	switch mode {
+	case RoundFloor:
+		frames = math.Floor(frames + frameEpsilon)
+	case RoundCeil:
+		frames = math.Ceil(frames - frameEpsilon)
+	default:
+		frames = math.Round(frames)
+	}
+	return int64(frames)
+}
+
+// frameTime returns the start of a frame in microseconds.
+func frameTime(frame int64, fps float64) int64 {
+	return int64(math.Round(float64(frame) * 1e6 / fps))
+}
+
+// SnapFrames moves every cue boundary onto a frame boundary. A cue that would
+// collapse to zero length keeps one frame so it isn't lost.
+func SnapFrames(cues []Cue, fps float64, mode Rounding) {
+	for i := range cues {
+		start := frameIndex(cues[i].Start, fps, mode)
+		end := max(frameIndex(cues[i].End, fps, mode), start+1)
+		cues[i].Start = frameTime(start, fps)
+		cues[i].End = frameTime(end, fps)
+	}
+}
+
+type NegativePolicy int
+
+const (
+	// NegativeClamp writes negative times as 00:00:00,000.
+	NegativeClamp NegativePolicy = iota
+	// NegativeError refuses to write cues with negative times.
+	NegativeError
+	// NegativeOffset shifts the whole timeline so the earliest cue starts at 0.
+	NegativeOffset
+)
+
+func ParseNegativePolicy(s string) (NegativePolicy, error) {
+	switch s {
+	case "clamp":
+		return NegativeClamp, nil
+	case "error":
+		return NegativeError, nil
+	case "offset":
+		return NegativeOffset, nil
+	}
+	return 0, fmt.Errorf("unknown negative-time policy %q (want clamp, error or offset)", s)
+}
+
+// ApplyNegativePolicy handles cues that start or end before zero, which
+// most writers would otherwise silently clamp to zero.
+func ApplyNegativePolicy(cues []Cue, policy NegativePolicy) error {
+	earliest := int64(0)
+	for i, c := range cues {
+		if first := min(c.Start, c.End); first < 0 {
+			if policy == NegativeError {
+				return fmt.Errorf("cue %d has a negative time (%v)", i+1, time.Duration(first)*time.Microsecond)
+			}
+			earliest = min(earliest, first)
+		}
+	}
+
+	if policy == NegativeOffset && earliest < 0 {
+		for i := range cues {
+			cues[i].Start -= earliest
+			cues[i].End -= earliest
+		}
+	}
+	return nil
+}
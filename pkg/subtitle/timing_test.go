@@ -0,0 +1,109 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSnapFrames(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []Cue
+		fps   float64
+		mode  Rounding
+		want  []Cue
+	}{
+		{
+			name:  "round to nearest",
+			input: []Cue{{Start: 1010000, End: 2020000, Text: "a"}},
+			fps:   30,
+			mode:  RoundNearest,
+			want:  []Cue{{Start: 1000000, End: 2033333, Text: "a"}},
+		},
+		{
+			name:  "floor",
+			input: []Cue{{Start: 1030000, End: 2020000, Text: "a"}},
+			fps:   30,
+			mode:  RoundFloor,
+			want:  []Cue{{Start: 1000000, End: 2000000, Text: "a"}},
+		},
+		{
+			name:  "ceil",
+			input: []Cue{{Start: 1010000, End: 2000000, Text: "a"}},
+			fps:   30,
+			mode:  RoundCeil,
+			want:  []Cue{{Start: 1033333, End: 2000000, Text: "a"}},
+		},
+		{
+			name:  "already on a boundary",
+			input: []Cue{{Start: 1001001, End: 2002002, Text: "a"}},
+			fps:   29.97,
+			mode:  RoundFloor,
+			want:  []Cue{{Start: 1001001, End: 2002002, Text: "a"}},
+		},
+		{
+			name:  "collapsed cue keeps one frame",
+			input: []Cue{{Start: 1000000, End: 1010000, Text: "a"}},
+			fps:   25,
+			mode:  RoundNearest,
+			want:  []Cue{{Start: 1000000, End: 1040000, Text: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SnapFrames(tt.input, tt.fps, tt.mode)
+			if !reflect.DeepEqual(tt.input, tt.want) {
+				t.Errorf("SnapFrames() = %v, want %v", tt.input, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyNegativePolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   []Cue
+		policy  NegativePolicy
+		want    []Cue
+		wantErr bool
+	}{
+		{
+			name:   "clamp leaves cues for formatTime",
+			input:  []Cue{{Start: -500, End: 1000, Text: "a"}},
+			policy: NegativeClamp,
+			want:   []Cue{{Start: -500, End: 1000, Text: "a"}},
+		},
+		{
+			name:    "error",
+			input:   []Cue{{Start: 0, End: 1000, Text: "a"}, {Start: -500, End: 1000, Text: "b"}},
+			policy:  NegativeError,
+			wantErr: true,
+		},
+		{
+			name:   "error without negative times",
+			input:  []Cue{{Start: 0, End: 1000, Text: "a"}},
+			policy: NegativeError,
+			want:   []Cue{{Start: 0, End: 1000, Text: "a"}},
+		},
+		{
+			name:   "offset shifts the whole timeline",
+			input:  []Cue{{Start: -500, End: 1000, Text: "a"}, {Start: 2000, End: 3000, Text: "b"}},
+			policy: NegativeOffset,
+			want:   []Cue{{Start: 0, End: 1500, Text: "a"}, {Start: 2500, End: 3500, Text: "b"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ApplyNegativePolicy(tt.input, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ApplyNegativePolicy() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(tt.input, tt.want) {
+				t.Errorf("ApplyNegativePolicy() = %v, want %v", tt.input, tt.want)
+			}
+		})
+	}
+}
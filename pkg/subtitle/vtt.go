@@ -0,0 +1,101 @@
+package subtitle
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// ParseVTT reads WebVTT cues. Cue identifiers and settings are ignored, as
+// are NOTE, STYLE and REGION blocks. Like ParseSRT, it accepts a byte order
+// mark and keeps tags in the cue text. Character references such as &amp;
+// are decoded, matching the escaping of the WebVTT writer.
+func ParseVTT(r io.Reader) ([]Cue, error) {
+	scanner := bufio.NewScanner(NewTextReader(r))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var cues []Cue
+	var block []string
+	lineNumber, blockStart := 0, 0
+
+	flush := func() error {
+		defer func() { block = block[:0] }()
+		if len(block) == 0 {
+			return nil
+		}
+		if blockStart == 1 {
+			if !strings.HasPrefix(block[0], "WEBVTT") {
+				return fmt.Errorf("line 1: missing WEBVTT header")
+			}
+			return nil
+		}
+
+		timing := 0
+		if !strings.Contains(block[0], "-->") {
+			// A cue identifier, or a NOTE, STYLE or REGION block.
+			timing = 1
+			if len(block) < 2 || !strings.Contains(block[1], "-->") {
+				return nil
+			}
+		}
+		start, end, err := parseVTTTiming(block[timing])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", blockStart+timing, err)
+		}
+		cues = append(cues, Cue{Start: start, End: end, Text: html.UnescapeString(strings.Join(block[timing+1:], "\n"))})
+		return nil
+	}
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if lineNumber == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.TrimSpace(line) == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(block) == 0 {
+			blockStart = lineNumber
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read subtitles: %w", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return cues, nil
+}
+
+func parseVTTTiming(line string) (int64, int64, error) {
+	from, to, _ := strings.Cut(line, "-->")
+	fields := strings.Fields(to)
+	if len(fields) == 0 {
+		return 0, 0, fmt.Errorf("missing end time")
+	}
+	start, err := parseVTTTimestamp(strings.TrimSpace(from))
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := parseVTTTimestamp(fields[0])
+	if err != nil {
+		return 0, 0, err
+	}
+	return start, end, nil
+}
+
+// parseVTTTimestamp accepts WebVTT's optional hours ("MM:SS.mmm").
+func parseVTTTimestamp(s string) (int64, error) {
+	if strings.Count(s, ":") == 1 {
+		s = "00:" + s
+	}
+	return ParseTimestamp(s)
+}
@@ -0,0 +1,34 @@
+package subtitle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVTT(t *testing.T) {
+	input := "WEBVTT - sample\nKind: captions\n\n" +
+		"NOTE written by hand\nacross two lines\n\n" +
+		"STYLE\n::cue { color: yellow }\n\n" +
+		"intro\n00:01.000 --> 00:02.500 align:start\nHello\nthere\n\n" +
+		"01:00:00.000 --> 01:00:01.000\nLater &amp; &lt;sooner&gt;\n"
+
+	got, err := ParseVTT(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Cue{
+		{Start: 1000000, End: 2500000, Text: "Hello\nthere"},
+		{Start: 3600000000, End: 3601000000, Text: "Later & <sooner>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseVTT() = %v, want %v", got, want)
+	}
+
+	if _, err := ParseVTT(strings.NewReader("00:01.000 --> 00:02.000\nHi\n")); err == nil {
+		t.Error("ParseVTT() expected error without WEBVTT header")
+	}
+	if _, err := ParseVTT(strings.NewReader("WEBVTT\n\n00:01 --> 00:02.000\nHi\n")); err == nil {
+		t.Error("ParseVTT() expected error for malformed timestamp")
+	}
+}
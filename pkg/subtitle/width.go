@@ -0,0 +1,69 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WidthMode selects the width NormalizeWidth gives Latin letters, digits
+// and punctuation in CJK text, as subtitle delivery specs for Chinese and
+// Japanese often fix one.
+type WidthMode int
+
+const (
+	// WidthKeep leaves characters as they are.
+	WidthKeep WidthMode = iota
+	// WidthHalf turns full-width forms such as "ＡＢＣ１２３！" into ASCII,
+	// and the ideographic space into a space.
+	WidthHalf
+	// WidthFull turns printable ASCII into its full-width form. Spaces are
+	// kept, as line wrapping breaks lines at them.
+	WidthFull
+)
+
+func ParseWidthMode(s string) (WidthMode, error) {
+	switch s {
+	case "keep":
+		return WidthKeep, nil
+	case "half":
+		return WidthHalf, nil
+	case "full":
+		return WidthFull, nil
+	}
+	return 0, fmt.Errorf("unknown character width %q (want keep, half or full)", s)
+}
+
+// fullWidthOffset is the distance from ASCII "!" to "~" to their
+// full-width forms, U+FF01 to U+FF5E.
+const fullWidthOffset = 0xFF01 - '!'
+
+// fullWidthSigns are the full-width signs outside that block, with their
+// usual forms.
+var fullWidthSigns = map[rune]rune{
+	'\u3000': ' ', '￠': '¢', '￡': '£', '￢': '¬', '￣': '¯', '￤': '¦', '￥': '¥', '￦': '₩',
+}
+
+// NormalizeWidth gives the Latin letters, digits and punctuation of text
+// the width mode selects.
+func NormalizeWidth(text string, mode WidthMode) string {
+	switch mode {
+	case WidthHalf:
+		return strings.Map(func(r rune) rune {
+			if r >= '!'+fullWidthOffset && r <= '~'+fullWidthOffset {
+				return r - fullWidthOffset
+			}
+			if half, ok := fullWidthSigns[r]; ok {
+				return half
+			}
+			return r
+		}, text)
+	case WidthFull:
+		return strings.Map(func(r rune) rune {
+			if r >= '!' && r <= '~' {
+				return r + fullWidthOffset
+			}
+			return r
+		}, text)
+	}
+	return text
+}
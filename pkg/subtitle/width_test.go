@@ -0,0 +1,29 @@
+package subtitle
+
+import "testing"
+
+func TestNormalizeWidth(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		mode  WidthMode
+		want  string
+	}{
+		{"keep", "ＣａｐＣｕｔ！", WidthKeep, "ＣａｐＣｕｔ！"},
+		{"to half width", "ＣａｐＣｕｔで１０分！（＃ｔａｇ）　￥５００", WidthHalf, "CapCutで10分!(#tag) ¥500"},
+		{"half width kept", "「映画」 OK", WidthHalf, "「映画」 OK"},
+		{"to full width", "CapCut 10分! (~)", WidthFull, "ＣａｐＣｕｔ １０分！ （～）"},
+		{"full width kept", "你好，世界。", WidthFull, "你好，世界。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeWidth(tt.input, tt.mode); got != tt.want {
+				t.Errorf("NormalizeWidth(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseWidthMode("narrow"); err == nil {
+		t.Error("ParseWidthMode() expected error")
+	}
+}
@@ -0,0 +1,160 @@
+package subtitle
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+type LineShape int
+
+const (
+	// ShapeBottomHeavy prefers a top line no longer than the bottom one,
+	// the "pyramid" most style guides ask for.
+	ShapeBottomHeavy LineShape = iota
+	// ShapeTopHeavy prefers a top line no shorter than the bottom one.
+	ShapeTopHeavy
+	// ShapeBalanced only minimizes the difference between the lines.
+	ShapeBalanced
+)
+
+func ParseLineShape(s string) (LineShape, error) {
+	switch s {
+	case "bottom-heavy", "pyramid":
+		return ShapeBottomHeavy, nil
+	case "top-heavy":
+		return ShapeTopHeavy, nil
+	case "balanced":
+		return ShapeBalanced, nil
+	}
+	return 0, fmt.Errorf("unknown line shape %q (want bottom-heavy, top-heavy or balanced)", s)
+}
+
+// weakWords are articles, prepositions and conjunctions that read badly at
+// the end of a line because they belong with the words that follow.
+var weakWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "to": true, "in": true,
+	"on": true, "at": true, "for": true, "with": true, "by": true, "from": true,
+	"into": true, "about": true, "and": true, "or": true, "but": true, "nor": true,
+	"as": true, "than": true, "that": true, "if": true, "my": true, "your": true,
+	"his": true, "her": true, "our": true, "their": true, "its": true,
+}
+
+// WrapText fits text into lines of at most maxChars characters. Text that
+// fits in two lines is split at the break that best matches shape, avoiding
+// breaks after weak words; longer text is wrapped greedily. Words longer than
+// a line, including unspaced Thai or CJK runs, are never broken.
+func WrapText(text string, maxChars int, shape LineShape) string {
+	words := strings.Fields(text)
+	if maxChars <= 0 || len(words) < 2 || utf8.RuneCountInString(strings.Join(words, " ")) <= maxChars {
+		return strings.Join(words, " ")
+	}
+
+	if top, bottom, ok := balanceLines(words, maxChars, shape); ok {
+		return top + "\n" + bottom
+	}
+	return strings.Join(greedyLines(words, maxChars), "\n")
+}
+
+func balanceLines(words []string, maxChars int, shape LineShape) (string, string, bool) {
+	lengths := make([]int, len(words))
+	total := -1
+	for i, w := range words {
+		lengths[i] = utf8.RuneCountInString(w)
+		total += lengths[i] + 1
+	}
+
+	best, bestScore := 0, 0
+	topLen := -1
+	for k := 1; k < len(words); k++ {
+		topLen += lengths[k-1] + 1
+		bottomLen := total - topLen - 1
+		if topLen > maxChars || bottomLen > maxChars {
+			continue
+		}
+
+		diff := topLen - bottomLen
+		var score int
+		switch shape {
+		case ShapeBottomHeavy:
+			score = max(-diff, 2*diff+1)
+		case ShapeTopHeavy:
+			score = max(diff, -2*diff+1)
+		default:
+			score = max(diff, -diff)
+		}
+		if weakWords[strings.ToLower(words[k-1])] {
+			score += maxChars
+		}
+
+		if best == 0 || score < bestScore {
+			best, bestScore = k, score
+		}
+	}
+
+	if best == 0 {
+		return "", "", false
+	}
+	return strings.Join(words[:best], " "), strings.Join(words[best:], " "), true
+}
+
+func greedyLines(words []string, maxChars int) []string {
+	var lines []string
+	line := words[0]
+	lineLen := utf8.RuneCountInString(line)
+	for _, w := range words[1:] {
+		n := utf8.RuneCountInString(w)
+		if lineLen+1+n > maxChars {
+			lines = append(lines, line)
+			line, lineLen = w, n
+			continue
+		}
+		line += " " + w
+		lineLen += 1 + n
+	}
+	return append(lines, line)
+}
+
+// SplitLongCues splits every cue whose text needs more than maxLines lines
+// of at most maxChars characters into consecutive cues of at most maxLines
+// lines each, filled in reading order. The cue's time is divided between
+// the parts in proportion to their length, so each stays on screen about
+// as long as it takes to read. The parts are left unwrapped for WrapText.
+func SplitLongCues(cues []Cue, maxChars, maxLines int) []Cue {
+	if maxChars <= 0 || maxLines <= 0 {
+		return cues
+	}
+	var out []Cue
+	for _, c := range cues {
+		words := strings.Fields(c.Text)
+		if len(words) < 2 {
+			out = append(out, c)
+			continue
+		}
+		lines := greedyLines(words, maxChars)
+		if len(lines) <= maxLines {
+			out = append(out, c)
+			continue
+		}
+
+		var parts []string
+		for i := 0; i < len(lines); i += maxLines {
+			parts = append(parts, strings.Join(lines[i:min(i+maxLines, len(lines))], " "))
+		}
+		total := 0
+		for _, p := range parts {
+			total += utf8.RuneCountInString(p)
+		}
+		start, done := c.Start, 0
+		for _, p := range parts {
+			done += utf8.RuneCountInString(p)
+			part := c
+			part.Start = start
+			part.End = c.Start + (c.End-c.Start)*int64(done)/int64(total)
+			part.Text = p
+			out = append(out, part)
+			start = part.End
+		}
+	}
+	return out
+}
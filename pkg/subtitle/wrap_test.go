@@ -0,0 +1,126 @@
+package subtitle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWrapText(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		maxChars int
+		shape    LineShape
+		want     string
+	}{
+		{
+			name:     "wrapping disabled",
+			input:    "This line is fairly long but wrapping is off",
+			maxChars: 0,
+			want:     "This line is fairly long but wrapping is off",
+		},
+		{
+			name:     "fits on one line",
+			input:    "Short  line",
+			maxChars: 42,
+			want:     "Short line",
+		},
+		{
+			name:     "bottom-heavy balance",
+			input:    "We are going to the market today",
+			maxChars: 20,
+			shape:    ShapeBottomHeavy,
+			want:     "We are going\nto the market today",
+		},
+		{
+			name:     "top-heavy balance",
+			input:    "Thank you all for coming here tonight",
+			maxChars: 25,
+			shape:    ShapeTopHeavy,
+			want:     "Thank you all for coming\nhere tonight",
+		},
+		{
+			name:     "avoid breaking after an article",
+			input:    "I bought a very nice car",
+			maxChars: 15,
+			shape:    ShapeBalanced,
+			want:     "I bought\na very nice car",
+		},
+		{
+			name:     "greedy beyond two lines",
+			input:    "one two three four five six seven",
+			maxChars: 10,
+			want:     "one two\nthree four\nfive six\nseven",
+		},
+		{
+			name:     "unspaced text is not broken",
+			input:    "สวัสดีครับยินดีต้อนรับทุกคน",
+			maxChars: 10,
+			want:     "สวัสดีครับยินดีต้อนรับทุกคน",
+		},
+		{
+			name:     "existing line breaks are rewrapped",
+			input:    "Hello\nworld",
+			maxChars: 42,
+			want:     "Hello world",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WrapText(tt.input, tt.maxChars, tt.shape)
+			if got != tt.want {
+				t.Errorf("WrapText() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitLongCues(t *testing.T) {
+	tests := []struct {
+		name     string
+		cue      Cue
+		maxLines int
+		want     []Cue
+	}{
+		{
+			name:     "fits",
+			cue:      Cue{Start: 0, End: 1000, Text: "one two three four"},
+			maxLines: 2,
+			want:     []Cue{{Start: 0, End: 1000, Text: "one two three four"}},
+		},
+		{
+			name:     "split by length",
+			cue:      Cue{Start: 1000, End: 4000, Text: "one two three four five six seven"},
+			maxLines: 2,
+			want: []Cue{
+				{Start: 1000, End: 2687, Text: "one two three four"},
+				{Start: 2687, End: 4000, Text: "five six seven"},
+			},
+		},
+		{
+			name:     "one line per cue",
+			cue:      Cue{Start: 0, End: 900, Text: "aaaaaaa bbbbbbb ccccccc", Source: Source{TrackNumber: 2}},
+			maxLines: 1,
+			want: []Cue{
+				{Start: 0, End: 300, Text: "aaaaaaa", Source: Source{TrackNumber: 2}},
+				{Start: 300, End: 600, Text: "bbbbbbb", Source: Source{TrackNumber: 2}},
+				{Start: 600, End: 900, Text: "ccccccc", Source: Source{TrackNumber: 2}},
+			},
+		},
+		{
+			name:     "unspaced text is not split",
+			cue:      Cue{Start: 0, End: 1000, Text: "สวัสดีครับยินดีต้อนรับทุกคน"},
+			maxLines: 1,
+			want:     []Cue{{Start: 0, End: 1000, Text: "สวัสดีครับยินดีต้อนรับทุกคน"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitLongCues([]Cue{tt.cue}, 10, tt.maxLines)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SplitLongCues() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
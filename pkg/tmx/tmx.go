@@ -0,0 +1,152 @@
+// Package tmx reads and writes translation memories in TMX 1.4, the
+// format translation tools exchange them in, so subtitles can be
+// pre-translated from the memory of earlier projects and their
+// translations fed back into it.
+package tmx
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// Unit is a translation unit: a segment of text and its translation.
+type Unit struct {
+	Source, Target string
+}
+
+// Memory is a translation memory from one language into another.
+type Memory struct {
+	// SourceLang and TargetLang are BCP-47 language tags, such as th and
+	// en-US.
+	SourceLang, TargetLang string
+	Units                  []Unit
+}
+
+// Write writes m as a TMX 1.4 document naming tool as its creation tool.
+// Segments are written as plain text.
+func Write(w io.Writer, m Memory, tool string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<?xml version="1.0" encoding="UTF-8"?>
+<tmx version="1.4">
+  <header creationtool="%s" creationtoolversion="1" segtype="sentence" o-tmf="%[1]s" adminlang="en" srclang="%s" datatype="plaintext"/>
+  <body>
+`, html.EscapeString(tool), html.EscapeString(m.SourceLang))
+	for _, u := range m.Units {
+		fmt.Fprintf(bw, "    <tu>\n      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n      <tuv xml:lang=\"%s\"><seg>%s</seg></tuv>\n    </tu>\n",
+			html.EscapeString(m.SourceLang), html.EscapeString(u.Source), html.EscapeString(m.TargetLang), html.EscapeString(u.Target))
+	}
+	bw.WriteString("  </body>\n</tmx>\n")
+	return bw.Flush()
+}
+
+// document is the part of a TMX document Read looks at.
+type document struct {
+	XMLName xml.Name `xml:"tmx"`
+	Header  struct {
+		SourceLang string `xml:"srclang,attr"`
+	} `xml:"header"`
+	Units []struct {
+		Variants []struct {
+			// Lang is xml:lang, or the lang of TMX 1.1 and earlier.
+			Lang    string  `xml:"lang,attr"`
+			Segment segment `xml:"seg"`
+		} `xml:"tuv"`
+	} `xml:"body>tu"`
+}
+
+// segment is the text of a seg element without its inline markup: the
+// native codes of bpt, ept, it, ph and ut are dropped, and the text of hi
+// and sub is kept.
+type segment string
+
+func (s *segment) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var text strings.Builder
+	codes := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "bpt", "ept", "it", "ph", "ut":
+				codes++
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "bpt", "ept", "it", "ph", "ut":
+				codes--
+			case start.Name.Local:
+				if codes == 0 {
+					*s = segment(text.String())
+					return nil
+				}
+			}
+		case xml.CharData:
+			if codes == 0 {
+				text.Write(t)
+			}
+		}
+	}
+}
+
+// Read reads the units of a TMX document translating sourceLang into
+// targetLang, or from the document's source language if sourceLang is "".
+// Languages match when they are equal or one is a subtag of the other, so
+// en matches en-US. Units without both languages are left out.
+func Read(r io.Reader, sourceLang, targetLang string) (Memory, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Memory{}, fmt.Errorf("failed to parse TMX: %w", err)
+	}
+	if sourceLang == "" {
+		sourceLang = doc.Header.SourceLang
+	}
+	if sourceLang == "" || sourceLang == "*all*" {
+		return Memory{}, fmt.Errorf("the TMX has no source language; name one")
+	}
+	m := Memory{SourceLang: sourceLang, TargetLang: targetLang}
+	for _, tu := range doc.Units {
+		var u Unit
+		var hasSource, hasTarget bool
+		for _, tuv := range tu.Variants {
+			switch {
+			case !hasSource && sameLanguage(tuv.Lang, sourceLang):
+				u.Source, hasSource = string(tuv.Segment), true
+			case !hasTarget && sameLanguage(tuv.Lang, targetLang):
+				u.Target, hasTarget = string(tuv.Segment), true
+			}
+		}
+		if hasSource && hasTarget {
+			m.Units = append(m.Units, u)
+		}
+	}
+	return m, nil
+}
+
+// sameLanguage reports whether the language tags a and b are equal or one
+// narrows the other.
+func sameLanguage(a, b string) bool {
+	a, b = strings.ToLower(strings.ReplaceAll(a, "_", "-")), strings.ToLower(strings.ReplaceAll(b, "_", "-"))
+	return a == b || strings.HasPrefix(a, b+"-") || strings.HasPrefix(b, a+"-")
+}
+
+// Translations maps the source segments of m, with their whitespace
+// collapsed, to their first translation.
+func (m Memory) Translations() map[string]string {
+	translations := make(map[string]string, len(m.Units))
+	for _, u := range m.Units {
+		key := subtitle.OneLine(u.Source)
+		if _, ok := translations[key]; !ok {
+			translations[key] = u.Target
+		}
+	}
+	return translations
+}
@@ -0,0 +1,52 @@
+package tmx
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	m := Memory{SourceLang: "th", TargetLang: "en", Units: []Unit{
+		{Source: "สวัสดี", Target: "Hello"},
+		{Source: "ราคา <10 บาท", Target: "Under 10 baht & up"},
+	}}
+	var b strings.Builder
+	if err := Write(&b, m, "capcut-subtitle"); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(strings.NewReader(b.String()), "", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("Read(Write()) = %+v, want %+v", got, m)
+	}
+}
+
+func TestRead(t *testing.T) {
+	doc := `<?xml version="1.0"?>
+<tmx version="1.4"><header srclang="en-US"/><body>
+  <tu><tuv xml:lang="en-US"><seg>Click <bpt i="1">&lt;b&gt;</bpt>Save<ept i="1">&lt;/b&gt;</ept></seg></tuv><tuv xml:lang="th-TH"><seg>คลิก<hi>บันทึก</hi></seg></tuv></tu>
+  <tu><tuv lang="EN"><seg>Old style</seg></tuv><tuv lang="TH"><seg>แบบเก่า</seg></tuv></tu>
+  <tu><tuv xml:lang="en-US"><seg>Untranslated</seg></tuv></tu>
+</body></tmx>`
+	got, err := Read(strings.NewReader(doc), "en", "th")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Unit{{"Click Save", "คลิกบันทึก"}, {"Old style", "แบบเก่า"}}
+	if !reflect.DeepEqual(got.Units, want) {
+		t.Errorf("Read() = %+v, want %+v", got.Units, want)
+	}
+	if _, err := Read(strings.NewReader(`<tmx><header/><body/></tmx>`), "", "th"); err == nil {
+		t.Error("Read() succeeded without a source language")
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	m := Memory{Units: []Unit{{"Hello  there", "A"}, {"Hello there", "B"}}}
+	if got := m.Translations(); !reflect.DeepEqual(got, map[string]string{"Hello there": "A"}) {
+		t.Errorf("Translations() = %v", got)
+	}
+}
@@ -0,0 +1,36 @@
+package transform
+
+import (
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/capcut/capcuttest"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func BenchmarkClean(b *testing.B) {
+	// Segment cues carry the generated markup for Clean to strip.
+	draft := capcuttest.Generate(1, 100000, 1)
+	cues := capcut.Cues(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts), capcut.GranularitySegments)
+	subs := make(subtitle.Subtitles, len(cues))
+	clean := Clean(subtitle.BracketsStrip)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(subs, cues)
+		clean(&subs)
+	}
+}
+
+func BenchmarkSortDedup(b *testing.B) {
+	draft := capcuttest.Generate(4, 25000, 1)
+	cues := subtitle.Subtitles(capcut.Cues(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts), capcut.GranularityWords))
+	subs := make(subtitle.Subtitles, len(cues))
+	p := Pipeline{Sort(), Dedup()}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		subs = append(subs[:0], cues...)
+		p.Run(&subs)
+	}
+}
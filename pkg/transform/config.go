@@ -0,0 +1,245 @@
+package transform
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// ReadPipeline loads a pipeline from a CSV file with one "stage[,arg...]"
+// record per line, run in file order:
+//
+//	tracks,1,2          keep only these text tracks
+//	offset,-1.5s        shift every cue
+//	clean[,strip]       strip markup; the argument is the bracket mode
+//	emoji,unicode       turn shortcodes into emoji, or emoji,shortcodes back
+//	char-width,half     make full-width Latin text half-width, or char-width,full
+//	glossary,terms.csv  apply a glossary file
+//	drop-empty          remove cues without text
+//	fillers[,list.txt]  remove filler words, the default ones or those listed
+//	grep,sponsor        keep only cues matching a regular expression
+//	sort                order by start time
+//	sentences[,th[,1s]] group word cues into sentences by the rules of a
+//	                    language, or of each track's script, and a pause
+//	                    (after sort)
+//	dedup               merge repeated overlapping cues
+//	negative,clamp      apply a negative-time policy
+//	capitalize          capitalize sentence starts (after sort)
+//	speakers,names.csv  prefix speaker names (after capitalize)
+//	snap,25[,round]     snap to frames at this rate
+//	max-lines,2,42      split cues needing more than 2 lines of 42 characters
+//	wrap,42[,balanced]  wrap text to this many characters per line
+//
+// Lines starting with # are ignored.
+func ReadPipeline(filename string) (Pipeline, error) {
+	pipeline, _, err := ReadPipelineFiles(filename)
+	return pipeline, err
+}
+
+// ReadPipelineFiles is like ReadPipeline but also returns the files its
+// stages read, such as glossaries, for callers that track changes to them.
+func ReadPipelineFiles(filename string) (Pipeline, []string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open pipeline: %w", err)
+	}
+	defer file.Close()
+	return parsePipeline(file)
+}
+
+// ParsePipeline reads a pipeline in the format described at ReadPipeline.
+func ParsePipeline(r io.Reader) (Pipeline, error) {
+	pipeline, _, err := parsePipeline(r)
+	return pipeline, err
+}
+
+func parsePipeline(r io.Reader) (Pipeline, []string, error) {
+	reader := csv.NewReader(bufio.NewReader(r))
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+
+	var pipeline Pipeline
+	var files []string
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse pipeline: %w", err)
+		}
+		stage, err := parseStage(record[0], record[1:])
+		if err != nil {
+			line, _ := reader.FieldPos(0)
+			return nil, nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		pipeline = append(pipeline, stage)
+		if name := record[0]; name == "glossary" || name == "speakers" || name == "fillers" && len(record) > 1 && record[1] != "" {
+			files = append(files, record[1])
+		}
+	}
+	return pipeline, files, nil
+}
+
+func parseStage(name string, args []string) (Transform, error) {
+	arg := func(i int, fallback string) string {
+		if i < len(args) && args[i] != "" {
+			return args[i]
+		}
+		return fallback
+	}
+	required := func() error {
+		if len(args) == 0 || args[0] == "" {
+			return fmt.Errorf("%s needs an argument", name)
+		}
+		return nil
+	}
+
+	switch name {
+	case "tracks":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		numbers := make([]int, len(args))
+		for i, field := range args {
+			n, err := strconv.Atoi(field)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid track number %q", field)
+			}
+			numbers[i] = n
+		}
+		return Tracks(numbers...), nil
+	case "offset":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		offset, err := time.ParseDuration(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid offset: %w", err)
+		}
+		return Offset(offset.Microseconds()), nil
+	case "clean":
+		brackets, err := subtitle.ParseBracketMode(arg(0, "strip"))
+		if err != nil {
+			return nil, err
+		}
+		return Clean(brackets), nil
+	case "emoji":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		mode, err := subtitle.ParseEmojiMode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Emoji(mode), nil
+	case "char-width":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		mode, err := subtitle.ParseWidthMode(args[0])
+		if err != nil {
+			return nil, err
+		}
+		return Width(mode), nil
+	case "glossary":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		glossary, err := subtitle.ReadGlossary(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("reading glossary: %w", err)
+		}
+		return Glossary(glossary), nil
+	case "drop-empty":
+		return DropEmpty(), nil
+	case "fillers":
+		if len(args) == 0 || args[0] == "" {
+			return Fillers(subtitle.NewFillers(subtitle.DefaultFillers)), nil
+		}
+		fillers, err := subtitle.ReadFillers(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("reading filler list: %w", err)
+		}
+		return Fillers(fillers), nil
+	case "grep":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid grep pattern: %w", err)
+		}
+		return Grep(re), nil
+	case "speakers":
+		if err := required(); err != nil {
+			return nil, err
+		}
+		speakers, err := subtitle.ReadSpeakers(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("reading speaker map: %w", err)
+		}
+		return Speakers(speakers), nil
+	case "sort":
+		return Sort(), nil
+	case "dedup":
+		return Dedup(), nil
+	case "negative":
+		policy, err := subtitle.ParseNegativePolicy(arg(0, "clamp"))
+		if err != nil {
+			return nil, err
+		}
+		return Negative(policy), nil
+	case "sentences":
+		var gap time.Duration
+		if field := arg(1, ""); field != "" {
+			var err error
+			if gap, err = time.ParseDuration(field); err != nil || gap <= 0 {
+				return nil, fmt.Errorf("sentences needs a positive pause, got %q", field)
+			}
+		}
+		return Sentences(arg(0, ""), gap.Microseconds()), nil
+	case "capitalize":
+		return Capitalize(), nil
+	case "snap":
+		fps, err := strconv.ParseFloat(arg(0, ""), 64)
+		if err != nil || fps <= 0 {
+			return nil, fmt.Errorf("snap needs a positive frame rate")
+		}
+		rounding, err := subtitle.ParseRounding(arg(1, "round"))
+		if err != nil {
+			return nil, err
+		}
+		return SnapFrames(fps, rounding), nil
+	case "max-lines":
+		maxLines, err := strconv.Atoi(arg(0, ""))
+		if err != nil || maxLines <= 0 {
+			return nil, fmt.Errorf("max-lines needs a positive number of lines")
+		}
+		maxChars, err := strconv.Atoi(arg(1, ""))
+		if err != nil || maxChars <= 0 {
+			return nil, fmt.Errorf("max-lines needs a positive line length")
+		}
+		return MaxLines(maxChars, maxLines), nil
+	case "wrap":
+		maxChars, err := strconv.Atoi(arg(0, ""))
+		if err != nil || maxChars <= 0 {
+			return nil, fmt.Errorf("wrap needs a positive line length")
+		}
+		shape, err := subtitle.ParseLineShape(arg(1, "bottom-heavy"))
+		if err != nil {
+			return nil, err
+		}
+		return Wrap(maxChars, shape), nil
+	}
+	return nil, fmt.Errorf("unknown stage %q", name)
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestParsePipeline(t *testing.T) {
+	config := `# example pipeline
+tracks,1
+offset,-1s
+clean,remove
+drop-empty
+grep,^(first|second)
+sort
+wrap,10,balanced
+`
+	pipeline, err := ParsePipeline(strings.NewReader(config))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subs := subtitle.Subtitles{
+		{Start: 3000000, End: 4000000, Text: "<i>second</i> line here", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 1000000, End: 2000000, Text: "[music]", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 2000000, End: 3000000, Text: "first", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 2500000, End: 3000000, Text: "third", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 0, End: 1000000, Text: "other track", Source: subtitle.Source{TrackNumber: 2}},
+	}
+	if err := pipeline.Run(&subs); err != nil {
+		t.Fatal(err)
+	}
+
+	want := subtitle.Subtitles{
+		{Start: 1000000, End: 2000000, Text: "first", Source: subtitle.Source{TrackNumber: 1}},
+		{Start: 2000000, End: 3000000, Text: "second\nline here", Source: subtitle.Source{TrackNumber: 1}},
+	}
+	if !reflect.DeepEqual(subs, want) {
+		t.Errorf("Run() = %q, want %q", subs, want)
+	}
+}
+
+func TestParsePipelineErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{name: "unknown stage", config: "sort\ntranslate,fr\n"},
+		{name: "missing argument", config: "offset\n"},
+		{name: "invalid track", config: "tracks,0\n"},
+		{name: "invalid frame rate", config: "snap,fast\n"},
+		{name: "invalid bracket mode", config: "clean,erase\n"},
+		{name: "max-lines without line length", config: "max-lines,2\n"},
+		{name: "invalid grep pattern", config: "grep,(\n"},
+		{name: "invalid character width", config: "char-width,narrow\n"},
+		{name: "invalid sentence pause", config: "sentences,en,soon\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParsePipeline(strings.NewReader(tt.config)); err == nil {
+				t.Error("ParsePipeline() expected error")
+			}
+		})
+	}
+}
+
+func TestReadPipelineFiles(t *testing.T) {
+	dir := t.TempDir()
+	glossary := filepath.Join(dir, "terms.csv")
+	if err := os.WriteFile(glossary, []byte("capcut,CapCut\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	fillers := filepath.Join(dir, "fillers.txt")
+	if err := os.WriteFile(fillers, []byte("like\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	pipeline := filepath.Join(dir, "stages.csv")
+	if err := os.WriteFile(pipeline, []byte("clean\nglossary,"+glossary+"\nfillers\nfillers,"+fillers+"\nsort\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stages, files, err := ReadPipelineFiles(pipeline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stages) != 5 || !reflect.DeepEqual(files, []string{glossary, fillers}) {
+		t.Errorf("ReadPipelineFiles() = %d stages, files %v, want 5 stages, files [%s %s]", len(stages), files, glossary, fillers)
+	}
+}
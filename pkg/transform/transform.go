@@ -0,0 +1,221 @@
+// Package transform provides the passes applied to cues between reading and
+// writing as composable stages.
+package transform
+
+import (
+	"cmp"
+	"context"
+	"regexp"
+	"slices"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// Transform is one stage of a pipeline. It may edit, reorder, add or remove
+// cues in place.
+type Transform func(subs *subtitle.Subtitles) error
+
+// Pipeline runs its stages in order.
+type Pipeline []Transform
+
+// Run applies every stage to subs, stopping at the first error.
+func (p Pipeline) Run(subs *subtitle.Subtitles) error {
+	return p.RunContext(context.Background(), subs)
+}
+
+// RunContext is like Run but checks ctx before each stage.
+func (p Pipeline) RunContext(ctx context.Context, subs *subtitle.Subtitles) error {
+	for _, stage := range p {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := stage(subs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// eachText returns a stage that rewrites the text of every cue.
+func eachText(rewrite func(string) string) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		for i := range *subs {
+			(*subs)[i].Text = rewrite((*subs)[i].Text)
+		}
+		return nil
+	}
+}
+
+// KeepOriginal records the text of every cue as its Original, so later
+// stages leave a record of what they changed.
+func KeepOriginal() Transform {
+	return func(subs *subtitle.Subtitles) error {
+		for i := range *subs {
+			(*subs)[i].Original = (*subs)[i].Text
+		}
+		return nil
+	}
+}
+
+// Tracks keeps only cues from the given 1-based text track numbers.
+func Tracks(numbers ...int) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		*subs = slices.DeleteFunc(*subs, func(c subtitle.Cue) bool {
+			return !slices.Contains(numbers, c.Source.TrackNumber)
+		})
+		return nil
+	}
+}
+
+// Offset shifts every cue by offset microseconds.
+func Offset(offset int64) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		for i := range *subs {
+			(*subs)[i].Start += offset
+			(*subs)[i].End += offset
+		}
+		return nil
+	}
+}
+
+// Clean strips markup from cue text; see subtitle.CleanText.
+func Clean(brackets subtitle.BracketMode) Transform {
+	return eachText(func(text string) string {
+		return subtitle.CleanText(text, brackets)
+	})
+}
+
+// Emoji rewrites emoji and their shortcodes; see subtitle.ConvertEmoji.
+func Emoji(mode subtitle.EmojiMode) Transform {
+	return eachText(func(text string) string {
+		return subtitle.ConvertEmoji(text, mode)
+	})
+}
+
+// Width normalizes the width of Latin text; see subtitle.NormalizeWidth.
+func Width(mode subtitle.WidthMode) Transform {
+	return eachText(func(text string) string {
+		return subtitle.NormalizeWidth(text, mode)
+	})
+}
+
+// Glossary rewrites terms to their preferred spelling.
+func Glossary(g subtitle.Glossary) Transform {
+	return eachText(g.Apply)
+}
+
+// DropEmpty removes cues without text.
+func DropEmpty() Transform {
+	return func(subs *subtitle.Subtitles) error {
+		*subs = subtitle.DropEmpty(*subs)
+		return nil
+	}
+}
+
+// Fillers removes filler words and the cues left without text; see
+// subtitle.Fillers.
+func Fillers(f subtitle.Fillers) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		for i := range *subs {
+			(*subs)[i].Text = f.Remove((*subs)[i].Text)
+		}
+		*subs = subtitle.DropEmpty(*subs)
+		return nil
+	}
+}
+
+// Grep keeps only cues whose text matches re.
+func Grep(re *regexp.Regexp) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		*subs = slices.DeleteFunc(*subs, func(c subtitle.Cue) bool {
+			return !re.MatchString(c.Text)
+		})
+		return nil
+	}
+}
+
+// Speakers prefixes cues with their speaker's name. It runs after the
+// passes changing the text, such as Capitalize, so they do not take the
+// name for part of it; see subtitle.PrefixSpeakers.
+func Speakers(speakers map[string]string) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		subtitle.PrefixSpeakers(*subs, speakers)
+		return nil
+	}
+}
+
+// Sort orders cues by start time.
+func Sort() Transform {
+	return func(subs *subtitle.Subtitles) error {
+		subs.Sort()
+		return nil
+	}
+}
+
+// Dedup merges cues repeating the same text over overlapping time ranges.
+func Dedup() Transform {
+	return func(subs *subtitle.Subtitles) error {
+		*subs = subtitle.Dedup(*subs)
+		return nil
+	}
+}
+
+// Sentences groups word cues into sentence cues, track by track, with the
+// rules of lang, or of the language each track's script suggests if lang
+// is "", and gap, if positive, as the longest pause within a sentence. It
+// must run after Sort; see subtitle.Sentences.
+func Sentences(lang string, gap int64) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		var out subtitle.Subtitles
+		for _, track := range subs.Tracks() {
+			rules := subtitle.SentenceRulesFor(cmp.Or(lang, subtitle.DetectLanguage(track.Cues).Code))
+			if gap > 0 {
+				rules.Gap = gap
+			}
+			out = append(out, subtitle.Sentences(track.Cues, rules)...)
+		}
+		out.Sort()
+		*subs = out
+		return nil
+	}
+}
+
+// Negative applies a policy to cues before zero.
+func Negative(policy subtitle.NegativePolicy) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		return subtitle.ApplyNegativePolicy(*subs, policy)
+	}
+}
+
+// Capitalize capitalizes the first letter of each sentence. It must run
+// after Sort; see subtitle.SentenceCase.
+func Capitalize() Transform {
+	return func(subs *subtitle.Subtitles) error {
+		subtitle.Capitalize(*subs)
+		return nil
+	}
+}
+
+// SnapFrames moves cue boundaries onto frame boundaries.
+func SnapFrames(fps float64, rounding subtitle.Rounding) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		subtitle.SnapFrames(*subs, fps, rounding)
+		return nil
+	}
+}
+
+// MaxLines splits cues needing more than maxLines lines of maxChars
+// characters; see subtitle.SplitLongCues.
+func MaxLines(maxChars, maxLines int) Transform {
+	return func(subs *subtitle.Subtitles) error {
+		*subs = subtitle.SplitLongCues(*subs, maxChars, maxLines)
+		return nil
+	}
+}
+
+// Wrap breaks cue text into lines of at most maxChars characters.
+func Wrap(maxChars int, shape subtitle.LineShape) Transform {
+	return eachText(func(text string) string {
+		return subtitle.WrapText(text, maxChars, shape)
+	})
+}
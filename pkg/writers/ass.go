@@ -0,0 +1,155 @@
+package writers
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// ASSStyle is how WriteASS lays out and animates cues.
+type ASSStyle struct {
+	Font string
+	// Size is the font size in pixels of the PlayResX by PlayResY canvas,
+	// which players scale to the video.
+	Size int
+	Bold bool
+	// Color and OutlineColor are #RRGGBB colors; Outline is the width of
+	// the outline in canvas pixels.
+	Color        string
+	OutlineColor string
+	Outline      int
+	// Alignment is the position on the screen as on a numeric keypad: 2
+	// is bottom center, 5 the center.
+	Alignment int
+	// MarginV is the distance from the top or bottom edge in pixels.
+	MarginV            int
+	PlayResX, PlayResY int
+	// Pop scales each cue up from 80% over its first 80 ms.
+	Pop bool
+	// SpeakerColors gives each speaker a style of their own, differing
+	// from the rest only in the text color, and names them on their lines.
+	// Speakers are those of the cues' Speaker or, without any, the text
+	// tracks of drafts with more than one, taking white, yellow, cyan,
+	// green, magenta and orange in order of appearance.
+	SpeakerColors bool
+	// DebugCues writes a Comment line before each cue locating it in the
+	// draft and quoting its Original text.
+	DebugCues bool
+}
+
+// ASSPresets are the styles the "ass" format offers by name. Classic is a
+// conventional caption at the bottom of a landscape video; word-pop is the
+// one word at a time, big, bold, centered style of vertical short videos,
+// meant for cues of one word each, as drafts with word timings give.
+var ASSPresets = map[string]ASSStyle{
+	"classic": {
+		Font: "Arial", Size: 64, Color: "#FFFFFF", OutlineColor: "#000000", Outline: 3,
+		Alignment: 2, MarginV: 60, PlayResX: 1920, PlayResY: 1080,
+	},
+	"word-pop": {
+		Font: "Arial", Size: 120, Bold: true, Color: "#FFFFFF", OutlineColor: "#000000", Outline: 8,
+		Alignment: 5, PlayResX: 1080, PlayResY: 1920, Pop: true,
+	},
+}
+
+// Validate reports style fields WriteASS cannot write.
+func (s ASSStyle) Validate() error {
+	if s.Size <= 0 {
+		return fmt.Errorf("ASS font size must be positive, got %d", s.Size)
+	}
+	if s.Alignment < 1 || s.Alignment > 9 {
+		return fmt.Errorf("ASS alignment must be from 1 to 9, got %d", s.Alignment)
+	}
+	for _, color := range []string{s.Color, s.OutlineColor} {
+		if _, err := assColor(color); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteASS writes cues as an Advanced SubStation Alpha (.ass) file in the
+// given style, which ffmpeg's subtitles filter renders as is when burning
+// captions into a video.
+func WriteASS(w io.Writer, cues []subtitle.Cue, style ASSStyle) error {
+	if err := style.Validate(); err != nil {
+		return err
+	}
+	color, _ := assColor(style.Color)
+	outline, _ := assColor(style.OutlineColor)
+	bold := 0
+	if style.Bold {
+		bold = -1
+	}
+	effect := ""
+	if style.Pop {
+		effect = `{\fscx80\fscy80\t(0,80,\fscx100\fscy100)}`
+	}
+
+	speaker, speakers := func(subtitle.Cue) string { return "" }, []string(nil)
+	if style.SpeakerColors {
+		speaker, speakers = speakersOf(cues)
+	}
+	styleOf := make(map[string]string, len(speakers))
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "[Script Info]\nScriptType: v4.00+\nPlayResX: %d\nPlayResY: %d\nWrapStyle: 0\nScaledBorderAndShadow: yes\n\n", style.PlayResX, style.PlayResY)
+	bw.WriteString("[V4+ Styles]\nFormat: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding\n")
+	writeStyle := func(name, color string) {
+		fmt.Fprintf(bw, "Style: %s,%s,%d,%s,&H000000FF,%s,&H80000000,%d,0,0,0,100,100,0,0,1,%d,0,%d,60,60,%d,1\n",
+			name, strings.ReplaceAll(style.Font, ",", " "), style.Size, color, outline, bold, style.Outline, style.Alignment, style.MarginV)
+	}
+	writeStyle("Default", color)
+	for i, name := range speakers {
+		styleOf[name] = fmt.Sprintf("Speaker%d", i+1)
+		speakerColor, _ := assColor(speakerColor(i))
+		writeStyle(styleOf[name], speakerColor)
+	}
+	bw.WriteString("\n[Events]\nFormat: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n")
+	for _, c := range cues {
+		text := assEmphasis.wrap(c.Emphasis, assText(c.Text))
+		if text == "" {
+			continue
+		}
+		if note := debugNote(c); style.DebugCues && note != "" {
+			fmt.Fprintf(bw, "Comment: 0,%s,%s,Default,,0,0,0,,%s\n", assTime(c.Start), assTime(c.End), assText(note))
+		}
+		name := speaker(c)
+		fmt.Fprintf(bw, "Dialogue: 0,%s,%s,%s,%s,0,0,0,,%s%s\n", assTime(c.Start), assTime(c.End),
+			cmp.Or(styleOf[name], "Default"), strings.ReplaceAll(name, ",", " "), effect, text)
+	}
+	return bw.Flush()
+}
+
+// assColor converts #RRGGBB to the &HAABBGGRR form of ASS, fully opaque.
+func assColor(color string) (string, error) {
+	hex := strings.TrimPrefix(color, "#")
+	if _, err := strconv.ParseUint(hex, 16, 32); err != nil || len(hex) != 6 {
+		return "", fmt.Errorf("invalid color %q (want #RRGGBB)", color)
+	}
+	hex = strings.ToUpper(hex)
+	return "&H00" + hex[4:6] + hex[2:4] + hex[0:2], nil
+}
+
+// assTime renders microseconds as H:MM:SS.cc, clamped at zero.
+func assTime(microseconds int64) string {
+	cs := max(microseconds, 0) / 10000
+	return fmt.Sprintf("%d:%02d:%02d.%02d", cs/360000, cs/6000%60, cs/100%60, cs%100)
+}
+
+// assText joins the non-blank lines of text with \N. Braces would open an
+// override block, so they are written as parentheses.
+func assText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, strings.NewReplacer("{", "(", "}", ")").Replace(line))
+		}
+	}
+	return strings.Join(lines, `\N`)
+}
@@ -0,0 +1,65 @@
+package writers
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// auditionHeader names the columns of an Audition marker list.
+const auditionHeader = "Name\tStart\tDuration\tTime Format\tType\tDescription\n"
+
+// WriteAuditionMarkers writes cues as an Adobe Audition marker list, which
+// Audition's Markers panel imports, so audio editors can navigate the
+// dialogue while mixing. Each cue becomes a range marker named after its
+// text, with its lines joined by spaces. Like Audition's own exports the
+// file is tab-separated, although it is saved with a .csv extension.
+func WriteAuditionMarkers(w io.Writer, cues []subtitle.Cue) error {
+	return writeAll(newAuditionCueWriter(w), cues)
+}
+
+func appendAuditionCue(b []byte, c subtitle.Cue) []byte {
+	b = appendMarkerName(b, c.Text)
+	b = append(b, '\t')
+	b = appendAuditionTime(b, c.Start)
+	b = append(b, '\t')
+	b = appendAuditionTime(b, max(c.End-max(c.Start, 0), 0))
+	return append(b, "\tdecimal\tCue\t\n"...)
+}
+
+// appendMarkerName appends text on one line, with the tabs and line breaks
+// that would split the row replaced by spaces.
+func appendMarkerName(b []byte, text string) []byte {
+	for i, field := range strings.Fields(text) {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = append(b, field...)
+	}
+	return b
+}
+
+// appendAuditionTime appends microseconds in Audition's decimal time
+// format, M:SS.mmm, with hours in front from the first hour on.
+func appendAuditionTime(b []byte, microseconds int64) []byte {
+	milliseconds := max(microseconds/1000, 0)
+
+	hours := milliseconds / millisPerHour
+	milliseconds -= hours * millisPerHour
+	minutes := milliseconds / millisPerMinute
+	milliseconds -= minutes * millisPerMinute
+	seconds := milliseconds / millisPerSecond
+	ms := milliseconds - seconds*millisPerSecond
+
+	if hours > 0 {
+		b = strconv.AppendInt(b, hours, 10)
+		b = append(b, ':', digits[minutes/10], digits[minutes%10])
+	} else {
+		b = strconv.AppendInt(b, minutes, 10)
+	}
+	return append(b,
+		':', digits[seconds/10], digits[seconds%10],
+		'.', digits[ms/100], digits[(ms/10)%10], digits[ms%10])
+}
@@ -0,0 +1,74 @@
+package writers
+
+import (
+	"io"
+	"testing"
+
+	"capcut-subtitle/pkg/capcut"
+	"capcut-subtitle/pkg/capcut/capcuttest"
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func BenchmarkWrite(b *testing.B) {
+	draft := capcuttest.Generate(1, 100000, 1)
+	subs := subtitle.Subtitles(capcut.Cues(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts), capcut.GranularityWords))
+
+	for _, format := range Formats() {
+		w, err := Lookup(format)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(format, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := w.Write(io.Discard, &subs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkFormatTime(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		FormatTime(int64(i) * 1000)
+	}
+}
+
+func BenchmarkWriteMillionWords(b *testing.B) {
+	draft := capcuttest.Generate(1, 1000000, 1)
+	subs := subtitle.Subtitles(capcut.Cues(draft.Tracks, capcut.BuildTextMap(draft.Materials.Texts), capcut.GranularityWords))
+
+	for _, format := range []string{"srt", "vtt"} {
+		w, err := Lookup(format)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(format, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := w.Write(io.Discard, &subs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(format+"/cue-writer", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				cw, err := NewCueWriter(io.Discard, format)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for _, c := range subs {
+					if err := cw.WriteCue(c); err != nil {
+						b.Fatal(err)
+					}
+				}
+				if err := cw.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,35 @@
+package writers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// WriteYouTubeChapters writes chapters as a list for a YouTube video
+// description, one "02:13 Title" line each. The first chapter is written
+// at 00:00, where YouTube requires the list to start, and hours are shown
+// only for videos with chapters past the first hour.
+func WriteYouTubeChapters(w io.Writer, chapters []subtitle.Chapter) error {
+	hours := len(chapters) > 0 && chapters[len(chapters)-1].Start >= 3600_000_000
+
+	bw := bufio.NewWriter(w)
+	for i, c := range chapters {
+		start := c.Start
+		if i == 0 {
+			start = 0
+		}
+		fmt.Fprintf(bw, "%s %s\n", formatChapterTime(start, hours), c.Title)
+	}
+	return bw.Flush()
+}
+
+func formatChapterTime(microseconds int64, hours bool) string {
+	seconds := microseconds / 1_000_000
+	if hours {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
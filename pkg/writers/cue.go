@@ -0,0 +1,189 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// A CueWriter renders cues one at a time, for outputs too large to collect
+// into subtitle.Subtitles first.
+type CueWriter interface {
+	WriteCue(c subtitle.Cue) error
+	// Close flushes the output. It does not close the underlying writer.
+	Close() error
+}
+
+// cueWriters holds the formats that can be written incrementally. JSON is
+// missing because its document is encoded in one piece.
+var cueWriters = map[string]func(w io.Writer) CueWriter{
+	"srt": newSRTCueWriter,
+	"vtt": newVTTCueWriter,
+	"csv": newAuditionCueWriter,
+}
+
+// NewCueWriter returns a CueWriter that writes format to w, producing the
+// same output as the format's Writer given the same cues.
+func NewCueWriter(w io.Writer, format string) (CueWriter, error) {
+	newWriter, ok := cueWriters[format]
+	if !ok {
+		return nil, fmt.Errorf("output format %q cannot be written cue by cue", format)
+	}
+	return newWriter(w), nil
+}
+
+func writeAll(cw CueWriter, cues []subtitle.Cue) error {
+	for _, c := range cues {
+		if err := cw.WriteCue(c); err != nil {
+			return err
+		}
+	}
+	return cw.Close()
+}
+
+// flushSize is how full the output buffer gets before it is written out.
+const flushSize = 64 << 10
+
+// output is the buffer every text writer formats into. Cues are appended
+// to one reused slice, so writing a cue allocates nothing once the buffer
+// has grown, and w sees a few large writes.
+type output struct {
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+func newOutput(w io.Writer) output {
+	return output{w: w, buf: make([]byte, 0, flushSize+4<<10)}
+}
+
+// flushFull writes the buffer out once it has reached flushSize.
+func (o *output) flushFull() error {
+	if len(o.buf) < flushSize {
+		return o.err
+	}
+	return o.flush()
+}
+
+func (o *output) flush() error {
+	if o.err == nil && len(o.buf) > 0 {
+		_, o.err = o.w.Write(o.buf)
+	}
+	o.buf = o.buf[:0]
+	return o.err
+}
+
+type srtCueWriter struct {
+	output
+	index int
+}
+
+func newSRTCueWriter(w io.Writer) CueWriter {
+	return &srtCueWriter{output: newOutput(w)}
+}
+
+func (s *srtCueWriter) WriteCue(c subtitle.Cue) error {
+	s.index++
+	s.buf = appendSRTCue(s.buf, s.index, c)
+	return s.flushFull()
+}
+
+func (s *srtCueWriter) Close() error {
+	return s.flush()
+}
+
+type vttCueWriter struct {
+	output
+	// speaker, if set, names the voice of each cue.
+	speaker func(subtitle.Cue) string
+	// debug writes a NOTE with the debugNote of each cue before it.
+	debug bool
+}
+
+func newVTTCueWriter(w io.Writer) CueWriter {
+	return newVTTMetadataWriter(w, Metadata{}, nil, nil)
+}
+
+// newVTTMetadataWriter writes the language as a header after WEBVTT, the
+// source, generator and time as a NOTE block and the style as a STYLE
+// block, all before the first cue as WebVTT requires of STYLE, and with
+// DebugCues a NOTE before each cue locating it in the draft. With
+// speaker set, cues are written in voice spans of the speaker it names,
+// and the STYLE block colors those of the listed speakers.
+func newVTTMetadataWriter(w io.Writer, meta Metadata, speaker func(subtitle.Cue) string, speakers []string) CueWriter {
+	v := &vttCueWriter{output: newOutput(w), speaker: speaker, debug: meta.DebugCues}
+	v.buf = append(v.buf, "WEBVTT\n"...)
+	if meta.Language != "" {
+		v.buf = append(v.buf, "Language: "+meta.Language+"\n"...)
+	}
+	v.buf = append(v.buf, '\n')
+
+	var note []string
+	if meta.Source != "" {
+		note = append(note, "Source: "+noteText.Replace(meta.Source))
+	}
+	if meta.Generator != "" {
+		note = append(note, "Generator: "+noteText.Replace(meta.Generator))
+	}
+	if !meta.Generated.IsZero() {
+		note = append(note, "Generated: "+meta.Generated.UTC().Format(time.RFC3339))
+	}
+	if len(note) > 0 {
+		v.buf = append(v.buf, "NOTE\n"+strings.Join(note, "\n")+"\n\n"...)
+	}
+	if css := meta.Style.css() + speakerCSS(speakers); css != "" {
+		v.buf = append(v.buf, "STYLE\n"+css+"\n"...)
+	}
+	return v
+}
+
+// speakerCSS renders a ::cue rule coloring the voice spans of each
+// speaker.
+func speakerCSS(speakers []string) string {
+	var b strings.Builder
+	quote := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ", "\r", " ")
+	for i, name := range speakers {
+		fmt.Fprintf(&b, "::cue(v[voice=\"%s\"]) {\n  color: %s;\n}\n", quote.Replace(name), speakerColor(i))
+	}
+	return b.String()
+}
+
+func (v *vttCueWriter) WriteCue(c subtitle.Cue) error {
+	if v.debug {
+		if note := debugNote(c); note != "" {
+			v.buf = append(v.buf, "NOTE "+note+"\n\n"...)
+		}
+	}
+	voice := ""
+	if v.speaker != nil {
+		voice = v.speaker(c)
+	}
+	v.buf = appendVTTCue(v.buf, c, voice)
+	return v.flushFull()
+}
+
+func (v *vttCueWriter) Close() error {
+	return v.flush()
+}
+
+type auditionCueWriter struct {
+	output
+}
+
+func newAuditionCueWriter(w io.Writer) CueWriter {
+	a := &auditionCueWriter{output: newOutput(w)}
+	a.buf = append(a.buf, auditionHeader...)
+	return a
+}
+
+func (a *auditionCueWriter) WriteCue(c subtitle.Cue) error {
+	a.buf = appendAuditionCue(a.buf, c)
+	return a.flushFull()
+}
+
+func (a *auditionCueWriter) Close() error {
+	return a.flush()
+}
@@ -0,0 +1,27 @@
+package writers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// noteText makes text safe for a WebVTT NOTE, which ends at a blank line
+// and must not hold "-->", which would make it a cue.
+var noteText = strings.NewReplacer("\r", " ", "\n", " ", "-->", "->")
+
+// debugNote locates c in its draft and quotes its Original text, for
+// tracing a cue back to where it came from; it is "" for cues with
+// neither.
+func debugNote(c subtitle.Cue) string {
+	var parts []string
+	if c.Source.MaterialID != "" {
+		parts = append(parts, fmt.Sprintf("text track %d, segment %d, material %s", c.Source.TrackNumber, c.Source.Segment+1, c.Source.MaterialID))
+	}
+	if c.Original != "" {
+		parts = append(parts, "original "+strconv.Quote(c.Original))
+	}
+	return noteText.Replace(strings.Join(parts, ", "))
+}
@@ -0,0 +1,47 @@
+package writers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// WriteError reports a failure to write an output file.
+type WriteError struct {
+	Path string
+	Err  error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("failed to write %s: %v", e.Path, e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// WriteFile renders subs with the writer registered for format and writes
+// them to path. Failures to create or write the file are returned as a
+// *WriteError.
+func WriteFile(path, format string, subs subtitle.Subtitles) error {
+	writer, err := Lookup(format)
+	if err != nil {
+		return err
+	}
+	return WriteFileWith(path, writer, subs)
+}
+
+// WriteFileWith is like WriteFile but renders subs with writer, such as
+// one configured at run time rather than registered.
+func WriteFileWith(path string, writer Writer, subs subtitle.Subtitles) error {
+	var buffer bytes.Buffer
+	if err := writer.Write(&buffer, &subs); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0644); err != nil {
+		return &WriteError{Path: path, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package writers
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// A transcript paragraph ends at a pause of paragraphGap or once it spans
+// paragraphLength, both in microseconds.
+const (
+	paragraphGap    = 2_000_000
+	paragraphLength = 60_000_000
+)
+
+const htmlHeader = `<!DOCTYPE html>
+<html%s>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Transcript</title>
+<style>
+body { font-family: system-ui, sans-serif; line-height: 1.6; max-width: 42em; margin: 2em auto; padding: 0 1em; }
+.time { color: #666; font-variant-numeric: tabular-nums; margin-right: 0.5em; text-decoration: none; }
+.time:hover { text-decoration: underline; }
+.cue:target { background: #ffef9e; }
+</style>
+</head>
+<body>
+<h1>Transcript</h1>
+`
+
+// WriteHTML writes cues as a standalone transcript page. Cues flow into
+// paragraphs that break at pauses, each opened by a timestamp linking to
+// it. Every cue is a span with the anchor cue-N, numbered from 1 as in
+// SRT, and its times in seconds in data-start and data-end, for linking
+// and for players that highlight the current cue. Speaker labels added by
+// --prefix-speaker show as part of the text.
+func WriteHTML(w io.Writer, cues []subtitle.Cue) error {
+	return writeHTML(w, cues, "")
+}
+
+// writeHTML is WriteHTML declaring the page language lang, unless it is
+// "".
+func writeHTML(w io.Writer, cues []subtitle.Cue, lang string) error {
+	bw := bufio.NewWriter(w)
+	if lang != "" {
+		lang = ` lang="` + html.EscapeString(lang) + `"`
+	}
+	fmt.Fprintf(bw, htmlHeader, lang)
+
+	var paragraphStart int64
+	for i, c := range cues {
+		if i == 0 || c.Start-cues[i-1].End >= paragraphGap || c.Start-paragraphStart >= paragraphLength {
+			if i > 0 {
+				bw.WriteString("</p>\n")
+			}
+			paragraphStart = c.Start
+			fmt.Fprintf(bw, `<p><a class="time" href="#cue-%d">%s</a>`, i+1, formatTranscriptTime(c.Start))
+		} else {
+			bw.WriteByte(' ')
+		}
+		fmt.Fprintf(bw, `<span class="cue" id="cue-%d" data-start="%.3f" data-end="%.3f">%s</span>`,
+			i+1, float64(max(c.Start, 0))/1e6, float64(max(c.End, 0))/1e6, htmlEmphasis.wrap(c.Emphasis, transcriptText(c.Text)))
+	}
+	if len(cues) > 0 {
+		bw.WriteString("</p>\n")
+	}
+	bw.WriteString("</body>\n</html>\n")
+	return bw.Flush()
+}
+
+// transcriptText escapes cue text for HTML, keeping its line breaks.
+func transcriptText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, html.EscapeString(line))
+		}
+	}
+	return strings.Join(lines, "<br>")
+}
+
+// formatTranscriptTime renders microseconds as M:SS, or H:MM:SS from the
+// first hour on.
+func formatTranscriptTime(microseconds int64) string {
+	seconds := max(microseconds, 0) / 1_000_000
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
@@ -0,0 +1,146 @@
+package writers
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// Metadata describes a subtitle file beyond its cues, for the formats that
+// record it.
+type Metadata struct {
+	// Language is the BCP 47 tag of the text, such as "th" or "en-US".
+	// Formats that need one detect it when it is "".
+	Language string
+	// Source names what the file was converted from, such as the CapCut
+	// project, and Generator the program and version that converted it.
+	// With Generated, the time of the conversion, they are written as a
+	// WebVTT NOTE block for tracing a caption file back to its draft.
+	Source    string
+	Generator string
+	Generated time.Time
+	// Style is the look a WebVTT STYLE block gives every cue.
+	Style VTTStyle
+	// SpeakerColors gives each speaker a color of their own in WebVTT, as
+	// ASSStyle.SpeakerColors does in ASS.
+	SpeakerColors bool
+	// DebugCues annotates each cue with where in the draft it came from
+	// and its Original text: as a NOTE before it in WebVTT, and as a last
+	// "[debug: ...]" line of its text in SRT, which has no comments.
+	DebugCues bool
+}
+
+// VTTStyle is the look of WebVTT cues, as CSS ::cue properties. Fields
+// left "" are up to the player.
+type VTTStyle struct {
+	// Color, Background and Outline are #RRGGBB colors. The outline is
+	// drawn as a text shadow, the nearest WebVTT allows.
+	Color      string
+	Background string
+	// BackgroundAlpha is the opacity of Background, from 0 to 1.
+	BackgroundAlpha float64
+	Outline         string
+	// Font is a font family, followed by sans-serif for players without it.
+	Font string
+}
+
+// css renders s as a ::cue rule, leaving out colors that are not #RRGGBB
+// and characters of the font name that could end the block.
+func (s VTTStyle) css() string {
+	var b strings.Builder
+	if _, ok := cssRGB(s.Color); ok {
+		fmt.Fprintf(&b, "  color: %s;\n", strings.ToUpper(s.Color))
+	}
+	if rgb, ok := cssRGB(s.Background); ok {
+		fmt.Fprintf(&b, "  background-color: rgba(%s, %.2f);\n", rgb, min(max(s.BackgroundAlpha, 0), 1))
+	}
+	if _, ok := cssRGB(s.Outline); ok {
+		fmt.Fprintf(&b, "  text-shadow: %[1]s 1px 1px 2px, %[1]s -1px -1px 2px;\n", strings.ToUpper(s.Outline))
+	}
+	font := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == ' ' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, s.Font)
+	if font = strings.TrimSpace(font); font != "" {
+		fmt.Fprintf(&b, "  font-family: \"%s\", sans-serif;\n", font)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "::cue {\n" + b.String() + "}\n"
+}
+
+// cssRGB converts #RRGGBB to the "r, g, b" of CSS rgba(), reporting
+// whether color is one.
+func cssRGB(color string) (string, bool) {
+	hex := strings.TrimPrefix(color, "#")
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || len(hex) != 6 || len(color) != 7 {
+		return "", false
+	}
+	return fmt.Sprintf("%d, %d, %d", v>>16, v>>8&0xff, v&0xff), true
+}
+
+// metadataWriters hold the formats that record Metadata.
+var metadataWriters = map[string]func(meta Metadata) Writer{
+	"srt": func(meta Metadata) Writer {
+		return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			cw := newSRTCueWriter(w)
+			for _, c := range *subs {
+				if note := debugNote(c); meta.DebugCues && note != "" {
+					c.Text += "\n[debug: " + note + "]"
+				}
+				if err := cw.WriteCue(c); err != nil {
+					return err
+				}
+			}
+			return cw.Close()
+		})
+	},
+	"vtt": func(meta Metadata) Writer {
+		return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			if !meta.SpeakerColors {
+				return writeAll(newVTTMetadataWriter(w, meta, nil, nil), *subs)
+			}
+			speaker, speakers := speakersOf(*subs)
+			return writeAll(newVTTMetadataWriter(w, meta, speaker, speakers), *subs)
+		})
+	},
+	"html": func(meta Metadata) Writer {
+		return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return writeHTML(w, *subs, meta.Language)
+		})
+	},
+	"smi": func(meta Metadata) Writer {
+		return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return writeSAMI(w, *subs, meta.Language)
+		})
+	},
+	"itt": func(meta Metadata) Writer {
+		return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return writeITT(w, *subs, ITTFrameRate, meta.Language)
+		})
+	},
+}
+
+// LookupMetadata is like Lookup but returns a writer that records meta in
+// formats that can: the language as a WebVTT Language header, the lang of
+// an HTML page, the language class of SAMI and the xml:lang of iTT, and the
+// source, generator and time of generation as a WebVTT NOTE, the style
+// and speaker colors as a WebVTT STYLE block, and the debug annotations of
+// WebVTT and SRT. Other formats are written as by the registered writer,
+// and so is every format for empty metadata.
+func LookupMetadata(format string, meta Metadata) (Writer, error) {
+	newWriter, ok := metadataWriters[format]
+	if !ok || meta == (Metadata{}) {
+		return Lookup(format)
+	}
+	return newWriter(meta), nil
+}
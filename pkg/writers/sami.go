@@ -0,0 +1,162 @@
+package writers
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+const samiHeader = `<SAMI>
+<HEAD>
+<TITLE>Subtitles</TITLE>
+<SAMIParam>
+  Metrics {time:ms;}
+  Spec {MSFT:1.0;}
+</SAMIParam>
+<STYLE TYPE="text/css">
+<!--
+P { margin: 2pt 8pt; text-align: center; font-family: Arial, sans-serif; font-size: 20pt; color: white; background-color: black; }
+`
+
+// languageNames are the English names SAMI language classes carry, for
+// the languages subtitle.DetectLanguage recognizes.
+var languageNames = map[string]string{
+	"ar": "Arabic", "de": "German", "el": "Greek", "en": "English", "es": "Spanish",
+	"fr": "French", "he": "Hebrew", "hi": "Hindi", "hy": "Armenian", "id": "Indonesian",
+	"it": "Italian", "ja": "Japanese", "ka": "Georgian", "km": "Khmer", "ko": "Korean",
+	"lo": "Lao", "my": "Burmese", "nl": "Dutch", "pt": "Portuguese", "ru": "Russian",
+	"th": "Thai", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+// samiClass is a SAMI language class, the stream a player lets viewers
+// pick.
+type samiClass struct {
+	name, lang string
+}
+
+// WriteSAMI writes cues as a SAMI (.smi) file for Windows Media players and
+// the accessibility tools that still take it. Each text track is put in a
+// language class named after the language detected in it, such as THCC
+// for Thai, so players offer the tracks as languages to choose from;
+// tracks in the same language share a class. Overlapping cues of one
+// class are shown together, one per line.
+func WriteSAMI(w io.Writer, cues []subtitle.Cue) error {
+	return writeSAMI(w, cues, "")
+}
+
+// writeSAMI is WriteSAMI putting every track in the class of lang, or of
+// its detected language if lang is "".
+func writeSAMI(w io.Writer, cues []subtitle.Cue, lang string) error {
+	var classes []samiClass
+	classOf := make(map[int]int)
+	for _, track := range subtitle.Subtitles(cues).Tracks() {
+		lang := lang
+		if lang == "" {
+			lang = cmp.Or(subtitle.DetectLanguage(track.Cues).Code, "und")
+		}
+		class := samiClass{name: strings.ToUpper(lang) + "CC", lang: lang}
+		i := len(classes)
+		for j, c := range classes {
+			if c == class {
+				i = j
+			}
+		}
+		if i == len(classes) {
+			classes = append(classes, class)
+		}
+		classOf[track.Number] = i
+	}
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString(samiHeader)
+	for _, c := range classes {
+		base, _, _ := strings.Cut(c.lang, "-")
+		name := languageNames[strings.ToLower(base)]
+		if name == "" {
+			name = "Undetermined"
+		}
+		fmt.Fprintf(bw, ".%s { Name: %s; lang: %s; SAMIType: CC; }\n", c.name, name, c.lang)
+	}
+	bw.WriteString("-->\n</STYLE>\n</HEAD>\n<BODY>\n")
+
+	// Cues start and end at millisecond SYNC points. At each point, every
+	// class whose text changed gets a new paragraph; &nbsp; clears it.
+	type event struct {
+		time  int64
+		cue   int
+		start bool
+	}
+	var events []event
+	for i, c := range cues {
+		start, end := max(c.Start, 0)/1000, max(c.End, 0)/1000
+		if start < end {
+			events = append(events, event{start, i, true}, event{end, i, false})
+		}
+	}
+	sort.SliceStable(events, func(a, b int) bool { return events[a].time < events[b].time })
+
+	active := make([][]int, len(classes))
+	shown := make([]string, len(classes))
+	for i := 0; i < len(events); {
+		time := events[i].time
+		changed := make([]bool, len(classes))
+		for ; i < len(events) && events[i].time == time; i++ {
+			e := events[i]
+			class := classOf[cues[e.cue].Source.TrackNumber]
+			changed[class] = true
+			if e.start {
+				active[class] = append(active[class], e.cue)
+			} else {
+				active[class] = removeCue(active[class], e.cue)
+			}
+		}
+
+		synced := false
+		for class, c := range classes {
+			if !changed[class] {
+				continue
+			}
+			text := samiText(cues, active[class])
+			if text == shown[class] {
+				continue
+			}
+			shown[class] = text
+			if !synced {
+				fmt.Fprintf(bw, "<SYNC Start=%d>\n", time)
+				synced = true
+			}
+			if text == "" {
+				text = "&nbsp;"
+			}
+			fmt.Fprintf(bw, "<P Class=%s>%s\n", c.name, text)
+		}
+	}
+	bw.WriteString("</BODY>\n</SAMI>\n")
+	return bw.Flush()
+}
+
+func removeCue(active []int, cue int) []int {
+	for i, c := range active {
+		if c == cue {
+			return append(active[:i], active[i+1:]...)
+		}
+	}
+	return active
+}
+
+// samiText is the text of the given cues, escaped and emphasized, with
+// line breaks as <br>.
+func samiText(cues []subtitle.Cue, active []int) string {
+	var lines []string
+	for _, i := range active {
+		if text := strings.Join(escapedLines(cues[i].Text), "<br>"); text != "" {
+			lines = append(lines, srtEmphasis.wrap(cues[i].Emphasis, text))
+		}
+	}
+	return strings.Join(lines, "<br>")
+}
@@ -0,0 +1,49 @@
+package writers
+
+import (
+	"slices"
+	"strconv"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// speakerPalette holds the colors speakers are told apart by, starting
+// with the white, yellow, cyan and green of broadcast subtitling and
+// reused from the start for more speakers than colors.
+var speakerPalette = []string{"#FFFFFF", "#FFFF00", "#00FFFF", "#00FF00", "#FF80FF", "#FFA040"}
+
+// speakerColor is the color of the i-th speaker.
+func speakerColor(i int) string {
+	return speakerPalette[i%len(speakerPalette)]
+}
+
+// speakersOf returns a function naming who says a cue, "" for no one
+// known, and the speakers of cues in order of first appearance. A cue's
+// speaker is its Speaker or, when no cue has one and the cues come from
+// more than one text track, "Track N", for drafts giving each speaker a
+// track of their own.
+func speakersOf(cues []subtitle.Cue) (func(subtitle.Cue) string, []string) {
+	named := slices.ContainsFunc(cues, func(c subtitle.Cue) bool { return c.Speaker != "" })
+	speaker := func(c subtitle.Cue) string { return c.Speaker }
+	if !named {
+		speaker = func(c subtitle.Cue) string {
+			if c.Source.TrackNumber == 0 {
+				return ""
+			}
+			return "Track " + strconv.Itoa(c.Source.TrackNumber)
+		}
+	}
+
+	var speakers []string
+	seen := make(map[string]bool)
+	for _, c := range cues {
+		if name := speaker(c); name != "" && !seen[name] {
+			seen[name] = true
+			speakers = append(speakers, name)
+		}
+	}
+	if !named && len(speakers) < 2 {
+		return func(subtitle.Cue) string { return "" }, nil
+	}
+	return speaker, speakers
+}
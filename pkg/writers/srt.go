@@ -0,0 +1,80 @@
+// Package writers renders cues in subtitle file formats.
+package writers
+
+import (
+	"io"
+	"strconv"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+const (
+	millisPerHour   = 3600000
+	millisPerMinute = 60000
+	millisPerSecond = 1000
+)
+
+var digits = [10]byte{'0', '1', '2', '3', '4', '5', '6', '7', '8', '9'}
+
+// timeBufferSize fits the longest timestamp appendTime can produce: the
+// hours of math.MaxInt64 microseconds take 10 digits.
+const timeBufferSize = 20
+
+// FormatTime renders microseconds as an SRT timestamp. Hours use at least
+// two digits and grow as needed, so 100 hours is "100:00:00,000". Negative
+// times are clamped to zero; see subtitle.ApplyNegativePolicy for
+// alternatives.
+func FormatTime(microseconds int64) string {
+	var buf [timeBufferSize]byte
+	return string(appendTime(buf[:0], microseconds, ','))
+}
+
+// appendTime appends microseconds as HH:MM:SS followed by the given
+// decimal separator and milliseconds.
+func appendTime(b []byte, microseconds int64, separator byte) []byte {
+	milliseconds := max(microseconds/1000, 0)
+
+	hours := milliseconds / millisPerHour
+	milliseconds -= hours * millisPerHour
+	minutes := milliseconds / millisPerMinute
+	milliseconds -= minutes * millisPerMinute
+	seconds := milliseconds / millisPerSecond
+	ms := milliseconds - seconds*millisPerSecond
+
+	if hours < 100 {
+		b = append(b, digits[hours/10], digits[hours%10])
+	} else {
+		b = strconv.AppendInt(b, hours, 10)
+	}
+	return append(b,
+		':', digits[minutes/10], digits[minutes%10],
+		':', digits[seconds/10], digits[seconds%10],
+		separator, digits[ms/100], digits[(ms/10)%10], digits[ms%10])
+}
+
+// WriteSRT writes cues as SubRip text, numbering them from 1.
+func WriteSRT(w io.Writer, cues []subtitle.Cue) error {
+	return WriteSRTFrom(w, cues, 1)
+}
+
+// WriteSRTFrom is like WriteSRT but numbers the cues from first, for files
+// that continue the numbering of the ones before them.
+func WriteSRTFrom(w io.Writer, cues []subtitle.Cue, first int) error {
+	s := newSRTCueWriter(w).(*srtCueWriter)
+	s.index = first - 1
+	return writeAll(s, cues)
+}
+
+func appendSRTCue(b []byte, index int, c subtitle.Cue) []byte {
+	b = strconv.AppendInt(b, int64(index), 10)
+	b = append(b, '\n')
+	b = appendTime(b, c.Start, ',')
+	b = append(b, " --> "...)
+	b = appendTime(b, c.End, ',')
+	b = append(b, '\n')
+	open, close := srtEmphasis.of(c.Emphasis)
+	b = append(b, open...)
+	b = appendCueText(b, c.Text, false)
+	b = append(b, close...)
+	return append(b, "\n\n"...)
+}
@@ -0,0 +1,114 @@
+package writers
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestFormatTime(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      int64
+		want       string
+		beforeTest func()
+	}{
+		{
+			name:  "zero milliseconds",
+			input: 0,
+			want:  "00:00:00,000",
+		},
+		{
+			name:  "one hour",
+			input: 3600 * 1000 * 1000,
+			want:  "01:00:00,000",
+		},
+		{
+			name:  "one minute",
+			input: 60 * 1000 * 1000,
+			want:  "00:01:00,000",
+		},
+		{
+			name:  "one second",
+			input: 1000 * 1000,
+			want:  "00:00:01,000",
+		},
+		{
+			name:  "one millisecond",
+			input: 1000,
+			want:  "00:00:00,001",
+		},
+		{
+			name:  "complex time",
+			input: 3723001000, // 1 hour, 2 minutes, 3 seconds, 1 millisecond
+			want:  "01:02:03,001",
+		},
+		{
+			name:  "negative time",
+			input: -1000,
+			want:  "00:00:00,000",
+		},
+		{
+			name:  "max single digit hours",
+			input: 9 * 3600 * 1000 * 1000,
+			want:  "09:00:00,000",
+		},
+		{
+			name:  "double digit hours",
+			input: 10 * 3600 * 1000 * 1000,
+			want:  "10:00:00,000",
+		},
+		{
+			name:  "max double digit hours",
+			input: 99*3600*1000*1000 + 59*60*1000*1000 + 59*1000*1000 + 999*1000,
+			want:  "99:59:59,999",
+		},
+		{
+			name:  "triple digit hours",
+			input: 100 * 3600 * 1000 * 1000,
+			want:  "100:00:00,000",
+		},
+		{
+			name:  "max int64",
+			input: math.MaxInt64,
+			want:  "2562047788:00:54,775",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.beforeTest != nil {
+				tt.beforeTest()
+			}
+
+			got := FormatTime(tt.input)
+			if got != tt.want {
+				t.Errorf("FormatTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("no allocations", func(t *testing.T) {
+		buf := make([]byte, 0, timeBufferSize)
+		allocs := testing.AllocsPerRun(100, func() {
+			buf = appendTime(buf[:0], math.MaxInt64, ',')
+		})
+		if allocs != 0 {
+			t.Errorf("appendTime() allocated %v times per call, want 0", allocs)
+		}
+	})
+}
+
+func TestWriteSRTFrom(t *testing.T) {
+	cues := []subtitle.Cue{{Start: 0, End: 1000000, Text: "One"}, {Start: 1000000, End: 2000000, Text: "Two"}}
+	want := "41\n00:00:00,000 --> 00:00:01,000\nOne\n\n42\n00:00:01,000 --> 00:00:02,000\nTwo\n\n"
+	var buf bytes.Buffer
+	if err := WriteSRTFrom(&buf, cues, 41); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("WriteSRTFrom() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,66 @@
+package writers
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// TemplateCue is a cue as templates see it: the cue's fields, with Number
+// counting the cues from 1.
+type TemplateCue struct {
+	Number int
+	subtitle.Cue
+}
+
+// templateFuncs are the functions templates can call besides the builtins.
+var templateFuncs = template.FuncMap{
+	// srt and vtt render microseconds as SRT and WebVTT timestamps.
+	"srt": FormatTime,
+	"vtt": FormatVTTTime,
+	// seconds renders microseconds as seconds with millisecond decimals.
+	"seconds": func(microseconds int64) string {
+		return fmt.Sprintf("%.3f", float64(microseconds)/1e6)
+	},
+	"duration": func(microseconds int64) time.Duration {
+		return time.Duration(microseconds) * time.Microsecond
+	},
+	// xml escapes text for XML and HTML.
+	"xml":   html.EscapeString,
+	"lines": func(text string) []string { return strings.Split(text, "\n") },
+	"join":  func(elems []string, sep string) string { return strings.Join(elems, sep) },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// NewTemplate returns a writer rendering cues with the text/template in
+// text, for bespoke formats such as in-house XML or teleprompter scripts.
+// The template is executed with a value whose Cues field lists the cues as
+// TemplateCue, so {{range .Cues}}{{.Number}} {{srt .Start}} {{.Text}}
+// {{end}} writes a numbered list. Besides the builtins, templates can call
+// srt and vtt to render times as timestamps, seconds and duration, xml to
+// escape text, lines to split text into its lines with join to put them
+// back together, and upper and lower.
+func NewTemplate(name, text string) (Writer, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	return WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+		data := struct{ Cues []TemplateCue }{make([]TemplateCue, len(*subs))}
+		for i, c := range *subs {
+			data.Cues[i] = TemplateCue{Number: i + 1, Cue: c}
+		}
+		bw := bufio.NewWriter(w)
+		if err := tmpl.Execute(bw, data); err != nil {
+			return fmt.Errorf("failed to execute template: %w", err)
+		}
+		return bw.Flush()
+	}), nil
+}
@@ -0,0 +1,93 @@
+package writers
+
+import (
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// emphasisTags are the tags a format opens and closes emphasized cue text
+// with, indexed by subtitle.Emphasis.
+type emphasisTags [4][2]string
+
+var (
+	srtEmphasis  = emphasisTags{{}, {"<i>", "</i>"}, {"<b>", "</b>"}, {`<font color="#FFFF00">`, "</font>"}}
+	vttEmphasis  = emphasisTags{{}, {"<i>", "</i>"}, {"<b>", "</b>"}, {"<c.yellow>", "</c>"}}
+	htmlEmphasis = emphasisTags{{}, {"<em>", "</em>"}, {"<strong>", "</strong>"}, {"<mark>", "</mark>"}}
+	ittEmphasis  = emphasisTags{{}, {`<span tts:fontStyle="italic">`, "</span>"}, {`<span tts:fontWeight="bold">`, "</span>"}, {`<span tts:color="yellow">`, "</span>"}}
+	// ASS overrides last until the end of the Dialogue line.
+	assEmphasis = emphasisTags{{}, {`{\i1}`, ""}, {`{\b1}`, ""}, {`{\c&H00FFFF&}`, ""}}
+)
+
+// of returns the tags of e, which are empty for no or unknown emphasis.
+func (t emphasisTags) of(e subtitle.Emphasis) (open, close string) {
+	if e <= 0 || int(e) >= len(t) {
+		return "", ""
+	}
+	return t[e][0], t[e][1]
+}
+
+// wrap puts the tags of e around text, unless text is empty.
+func (t emphasisTags) wrap(e subtitle.Emphasis, text string) string {
+	if text == "" {
+		return text
+	}
+	open, close := t.of(e)
+	return open + text + close
+}
+
+// appendCueText appends cue text for a subtitle file. A blank line would
+// end the cue early in both SRT and WebVTT, so empty lines are dropped.
+// With escape, &, < and > are written as character references, which
+// WebVTT needs for them to show as text rather than be parsed as tags.
+func appendCueText(b []byte, text string, escape bool) []byte {
+	first := true
+	for text != "" {
+		var line string
+		line, text, _ = strings.Cut(text, "\n")
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) == "" {
+			continue
+		}
+		if !first {
+			b = append(b, '\n')
+		}
+		first = false
+		if escape {
+			b = appendEscaped(b, line)
+		} else {
+			b = append(b, line...)
+		}
+	}
+	return b
+}
+
+// escapedLines returns the non-blank lines of text, trimmed and with &, <
+// and > escaped, for markup formats that break lines with a tag.
+func escapedLines(text string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, string(appendEscaped(nil, line)))
+		}
+	}
+	return lines
+}
+
+func appendEscaped(b []byte, s string) []byte {
+	for {
+		i := strings.IndexAny(s, "&<>")
+		if i < 0 {
+			return append(b, s...)
+		}
+		b = append(b, s[:i]...)
+		switch s[i] {
+		case '&':
+			b = append(b, "&amp;"...)
+		case '<':
+			b = append(b, "&lt;"...)
+		case '>':
+			b = append(b, "&gt;"...)
+		}
+		s = s[i+1:]
+	}
+}
@@ -0,0 +1,84 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// verifiers parse a written file back for Verify.
+var verifiers = map[string]func(r io.Reader) ([]subtitle.Cue, error){
+	"srt": subtitle.ParseSRT,
+	"vtt": subtitle.ParseVTT,
+	"json": func(r io.Reader) ([]subtitle.Cue, error) {
+		var subs subtitle.Subtitles
+		err := json.NewDecoder(r).Decode(&subs)
+		return subs, err
+	},
+}
+
+// Verifiable reports whether Verify can check files in format.
+func Verifiable(format string) bool {
+	_, ok := verifiers[format]
+	return ok
+}
+
+// Verify parses r, a file written in format from cues, back with the
+// subtitle package's own parser and reports the first cue that differs
+// from what the writer should have produced: the same number of cues,
+// times truncated to the millisecond (clamped at zero) and text without
+// blank lines or carriage returns. JSON must round-trip exactly.
+func Verify(r io.Reader, format string, cues []subtitle.Cue) error {
+	parse, ok := verifiers[format]
+	if !ok {
+		return fmt.Errorf("output format %q cannot be verified", format)
+	}
+	got, err := parse(r)
+	if err != nil {
+		return fmt.Errorf("failed to parse the written file: %w", err)
+	}
+	if len(got) != len(cues) {
+		return fmt.Errorf("wrote %d cues but read back %d", len(cues), len(got))
+	}
+
+	for i, c := range cues {
+		want := c
+		if format != "json" {
+			tags := srtEmphasis
+			if format == "vtt" {
+				tags = vttEmphasis
+			}
+			want = subtitle.Cue{Start: writtenTime(c.Start), End: writtenTime(c.End), Text: tags.wrap(c.Emphasis, writtenText(c.Text))}
+		}
+		switch g := got[i]; {
+		case g.Start != want.Start || g.End != want.End:
+			return fmt.Errorf("cue %d: timing %s --> %s read back as %s --> %s",
+				i+1, FormatTime(want.Start), FormatTime(want.End), FormatTime(g.Start), FormatTime(g.End))
+		case g.Text != want.Text:
+			return fmt.Errorf("cue %d: text %q read back as %q", i+1, want.Text, g.Text)
+		case !reflect.DeepEqual(g, want):
+			return fmt.Errorf("cue %d: source %+v read back as %+v", i+1, want.Source, g.Source)
+		}
+	}
+	return nil
+}
+
+// writtenTime is t at the millisecond precision of SRT and WebVTT.
+func writtenTime(t int64) int64 {
+	return max(t/1000, 0) * 1000
+}
+
+// writtenText is text as appendCueText writes it, before escaping.
+func writtenText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimRight(line, "\r"); strings.TrimSpace(line) != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,39 @@
+package writers
+
+import (
+	"io"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// FormatVTTTime renders microseconds as a WebVTT timestamp, which differs
+// from FormatTime only in using '.' before the milliseconds.
+func FormatVTTTime(microseconds int64) string {
+	var buf [timeBufferSize]byte
+	return string(appendTime(buf[:0], microseconds, '.'))
+}
+
+// WriteVTT writes cues as a WebVTT file. Cues are not numbered, since
+// WebVTT cue identifiers are optional. Cue text is written as plain text,
+// with &, < and > escaped.
+func WriteVTT(w io.Writer, cues []subtitle.Cue) error {
+	return writeAll(newVTTCueWriter(w), cues)
+}
+
+// appendVTTCue appends c, in a voice span when voice is not "".
+func appendVTTCue(b []byte, c subtitle.Cue, voice string) []byte {
+	b = appendTime(b, c.Start, '.')
+	b = append(b, " --> "...)
+	b = appendTime(b, c.End, '.')
+	b = append(b, '\n')
+	if voice != "" {
+		b = append(b, "<v "...)
+		b = appendEscaped(b, voice)
+		b = append(b, '>')
+	}
+	open, close := vttEmphasis.of(c.Emphasis)
+	b = append(b, open...)
+	b = appendCueText(b, c.Text, true)
+	b = append(b, close...)
+	return append(b, "\n\n"...)
+}
@@ -0,0 +1,100 @@
+package writers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+// Writer renders subtitles in one file format.
+type Writer interface {
+	Write(w io.Writer, subs *subtitle.Subtitles) error
+}
+
+// WriterFunc adapts a function to the Writer interface.
+type WriterFunc func(w io.Writer, subs *subtitle.Subtitles) error
+
+func (f WriterFunc) Write(w io.Writer, subs *subtitle.Subtitles) error {
+	return f(w, subs)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Writer{
+		"srt": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteSRT(w, *subs)
+		}),
+		"vtt": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteVTT(w, *subs)
+		}),
+		"json": WriterFunc(WriteJSON),
+		"csv": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteAuditionMarkers(w, *subs)
+		}),
+		"html": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteHTML(w, *subs)
+		}),
+		"smi": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteSAMI(w, *subs)
+		}),
+		"itt": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteITT(w, *subs, ITTFrameRate)
+		}),
+		"ass": WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+			return WriteASS(w, *subs, ASSPresets["classic"])
+		}),
+	}
+)
+
+// WriteJSON writes subs in the versioned JSON schema of subtitle.Subtitles,
+// for tools that edit cues and hand them back to the converter.
+func WriteJSON(w io.Writer, subs *subtitle.Subtitles) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(subs)
+}
+
+// Register makes a writer available under a format name, which is also the
+// file extension the command-line tool uses for it. It panics if the name is
+// already taken or w is nil, so conflicting registrations fail at startup.
+func Register(name string, w Writer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if w == nil {
+		panic("writers: Register writer is nil")
+	}
+	if _, dup := registry[name]; dup {
+		panic("writers: Register called twice for format " + name)
+	}
+	registry[name] = w
+}
+
+// Lookup returns the writer registered for a format name.
+func Lookup(name string) (Writer, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	w, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q", name)
+	}
+	return w, nil
+}
+
+// Formats returns the registered format names in sorted order.
+func Formats() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
@@ -0,0 +1,642 @@
+package writers
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"capcut-subtitle/pkg/subtitle"
+)
+
+func TestRegistry(t *testing.T) {
+	if got, want := Formats(), []string{"ass", "csv", "html", "itt", "json", "smi", "srt", "vtt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Formats() = %v, want %v", got, want)
+	}
+	if _, err := Lookup("sub"); err == nil {
+		t.Error("Lookup() expected error for unregistered format")
+	}
+
+	Register("test-count", WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error {
+		_, err := w.Write([]byte{byte('0' + len(*subs))})
+		return err
+	}))
+	writer, err := Lookup("test-count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, &subtitle.Subtitles{{Text: "a"}, {Text: "b"}}); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "2" {
+		t.Errorf("registered writer wrote %q, want %q", buf.String(), "2")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Register() expected panic for duplicate format")
+		}
+	}()
+	Register("srt", WriterFunc(nil))
+}
+
+func TestWriteVTT(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 2500000, Text: "Hello"},
+		{Start: 3600000000, End: 3601000000, Text: "Two\nlines"},
+		{Start: 3602000000, End: 3603000000, Text: "a < b & c\n\n>"},
+	}
+	want := "WEBVTT\n\n" +
+		"00:00:01.000 --> 00:00:02.500\nHello\n\n" +
+		"01:00:00.000 --> 01:00:01.000\nTwo\nlines\n\n" +
+		"01:00:02.000 --> 01:00:03.000\na &lt; b &amp; c\n&gt;\n\n"
+
+	var buf bytes.Buffer
+	if err := WriteVTT(&buf, cues); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("WriteVTT() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteAuditionMarkers(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 2500000, Text: "Hello"},
+		{Start: 83456000, End: 90000000, Text: "Two\nlines\twith a tab"},
+		{Start: 3723004000, End: 3724000000, Text: "Late"},
+	}
+	want := "Name\tStart\tDuration\tTime Format\tType\tDescription\n" +
+		"Hello\t0:01.000\t0:01.500\tdecimal\tCue\t\n" +
+		"Two lines with a tab\t1:23.456\t0:06.544\tdecimal\tCue\t\n" +
+		"Late\t1:02:03.004\t0:00.996\tdecimal\tCue\t\n"
+
+	var buf bytes.Buffer
+	if err := WriteAuditionMarkers(&buf, cues); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("WriteAuditionMarkers() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 1500000, Text: "Hello"},
+		{Start: 1500000, End: 2000000, Text: "A & <B>"},
+		{Start: 4000000, End: 5000000, Text: "After\na pause"},
+		{Start: 3725000000, End: 3726000000, Text: "Late"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, cues); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	_, body, _ := strings.Cut(got, "<h1>Transcript</h1>\n")
+	want := `<p><a class="time" href="#cue-1">0:01</a><span class="cue" id="cue-1" data-start="1.000" data-end="1.500">Hello</span> ` +
+		`<span class="cue" id="cue-2" data-start="1.500" data-end="2.000">A &amp; &lt;B&gt;</span></p>` + "\n" +
+		`<p><a class="time" href="#cue-3">0:04</a><span class="cue" id="cue-3" data-start="4.000" data-end="5.000">After<br>a pause</span></p>` + "\n" +
+		`<p><a class="time" href="#cue-4">1:02:05</a><span class="cue" id="cue-4" data-start="3725.000" data-end="3726.000">Late</span></p>` + "\n" +
+		"</body>\n</html>\n"
+	if !strings.HasPrefix(got, "<!DOCTYPE html>") || body != want {
+		t.Errorf("WriteHTML() body = %s, want %s", body, want)
+	}
+}
+
+func TestWriteSAMI(t *testing.T) {
+	en := subtitle.Source{TrackNumber: 1}
+	th := subtitle.Source{TrackNumber: 2}
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 2000000, Text: "Hello and welcome", Source: en},
+		{Start: 1000000, End: 2500000, Text: "สวัสดีครับ", Source: th},
+		{Start: 2000000, End: 3000000, Text: "This is <the> trip\nto the north", Source: en},
+		{Start: 4000000, End: 4000400, Text: "Too short to show", Source: en},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSAMI(&buf, cues); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	_, style, _ := strings.Cut(got, "background-color: black; }\n")
+	want := `.ENCC { Name: English; lang: en; SAMIType: CC; }
+.THCC { Name: Thai; lang: th; SAMIType: CC; }
+-->
+</STYLE>
+</HEAD>
+<BODY>
+<SYNC Start=1000>
+<P Class=ENCC>Hello and welcome
+<P Class=THCC>สวัสดีครับ
+<SYNC Start=2000>
+<P Class=ENCC>This is &lt;the&gt; trip<br>to the north
+<SYNC Start=2500>
+<P Class=THCC>&nbsp;
+<SYNC Start=3000>
+<P Class=ENCC>&nbsp;
+</BODY>
+</SAMI>
+`
+	if !strings.HasPrefix(got, "<SAMI>\n") || style != want {
+		t.Errorf("WriteSAMI() = %s, want %s", got, want)
+	}
+}
+
+func TestWriteITT(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 2516000, Text: "Hello and welcome"},
+		{Start: 3599960000, End: 3600000000, Text: "Rock & <roll>\nto the end"},
+		{Start: 3700000000, End: 3700000000, Text: "Kept a frame long"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteITT(&buf, cues, 25); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	header, body, _ := strings.Cut(got, "    <div>\n")
+	want := `      <p begin="00:00:01:00" end="00:00:02:13">Hello and welcome</p>
+      <p begin="00:59:59:24" end="01:00:00:00">Rock &amp; &lt;roll&gt;<br/>to the end</p>
+      <p begin="01:01:40:00" end="01:01:40:01">Kept a frame long</p>
+    </div>
+  </body>
+</tt>
+`
+	if !strings.Contains(header, `xml:lang="en" ttp:timeBase="smpte" ttp:frameRate="25"`) || body != want {
+		t.Errorf("WriteITT() = %s, want body %s", got, want)
+	}
+	if err := WriteITT(&buf, cues, 0); err == nil {
+		t.Error("WriteITT() expected error for frame rate 0")
+	}
+}
+
+func TestWriteASS(t *testing.T) {
+	cues := []subtitle.Cue{
+		{Start: 1000000, End: 1250000, Text: "Hello"},
+		{Start: 3723450000, End: 3724000000, Text: "{big}\n  world "},
+		{Start: 3725000000, End: 3726000000, Text: " "},
+	}
+
+	var buf bytes.Buffer
+	style := ASSPresets["word-pop"]
+	style.Color = "#FFD700"
+	if err := WriteASS(&buf, cues, style); err != nil {
+		t.Fatal(err)
+	}
+	got := buf.String()
+	for _, want := range []string{
+		"PlayResX: 1080\nPlayResY: 1920\n",
+		"Style: Default,Arial,120,&H0000D7FF,&H000000FF,&H00000000,&H80000000,-1,0,0,0,100,100,0,0,1,8,0,5,60,60,0,1\n",
+		"Dialogue: 0,0:00:01.00,0:00:01.25,Default,,0,0,0,,{\\fscx80\\fscy80\\t(0,80,\\fscx100\\fscy100)}Hello\n",
+		"Dialogue: 0,1:02:03.45,1:02:04.00,Default,,0,0,0,,{\\fscx80\\fscy80\\t(0,80,\\fscx100\\fscy100)}(big)\\Nworld\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteASS() = %s, want it to contain %q", got, want)
+		}
+	}
+	if n := strings.Count(got, "Dialogue:"); n != 2 {
+		t.Errorf("WriteASS() wrote %d dialogue lines, want 2", n)
+	}
+
+	for _, bad := range []ASSStyle{
+		{Size: 0, Alignment: 2, Color: "#FFFFFF", OutlineColor: "#000000"},
+		{Size: 64, Alignment: 2, Color: "white", OutlineColor: "#000000"},
+		{Size: 64, Alignment: 10, Color: "#FFFFFF", OutlineColor: "#000000"},
+	} {
+		if err := WriteASS(&buf, cues, bad); err == nil {
+			t.Errorf("WriteASS() expected error for style %+v", bad)
+		}
+	}
+}
+
+func TestWriteSpeakerColors(t *testing.T) {
+	named := subtitle.Subtitles{
+		{Start: 0, End: 1000000, Text: "Nok: Hi", Speaker: "Nok"},
+		{Start: 1000000, End: 2000000, Text: "Ben, host: Hello", Speaker: "Ben, host"},
+		{Start: 2000000, End: 3000000, Text: "again", Speaker: "Nok"},
+		{Start: 3000000, End: 4000000, Text: "Applause"},
+	}
+	tracks := subtitle.Subtitles{
+		{Start: 0, End: 1000000, Text: "Hi", Source: subtitle.Source{TrackNumber: 2}},
+		{Start: 1000000, End: 2000000, Text: "Hello", Source: subtitle.Source{TrackNumber: 1}},
+	}
+	oneTrack := subtitle.Subtitles{{Start: 0, End: 1000000, Text: "Hi", Source: subtitle.Source{TrackNumber: 1}}}
+
+	style := ASSPresets["classic"]
+	style.SpeakerColors = true
+	ass := WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error { return WriteASS(w, *subs, style) })
+	vtt, err := LookupMetadata("vtt", Metadata{SpeakerColors: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		writer Writer
+		cues   subtitle.Subtitles
+		want   []string
+	}{
+		{"ass speakers", ass, named, []string{
+			"Style: Speaker1,Arial,64,&H00FFFFFF,",
+			"Style: Speaker2,Arial,64,&H0000FFFF,",
+			"Speaker1,Nok,0,0,0,,Nok: Hi\n",
+			"Speaker2,Ben  host,0,0,0,,Ben, host: Hello\n",
+			"Speaker1,Nok,0,0,0,,again\n",
+			"Default,,0,0,0,,Applause\n",
+		}},
+		{"ass tracks", ass, tracks, []string{"Speaker1,Track 2,", "Speaker2,Track 1,"}},
+		{"ass one track", ass, oneTrack, []string{"Default,,0,0,0,,Hi\n"}},
+		{"vtt speakers", vtt, named, []string{
+			"STYLE\n::cue(v[voice=\"Nok\"]) {\n  color: #FFFFFF;\n}\n::cue(v[voice=\"Ben, host\"]) {\n  color: #FFFF00;\n}\n\n",
+			"\n<v Nok>Nok: Hi\n",
+			"\n<v Nok>again\n",
+			"\nApplause\n",
+		}},
+		{"vtt tracks", vtt, tracks, []string{"\n<v Track 2>Hi\n", "\n<v Track 1>Hello\n"}},
+		{"vtt one track", vtt, oneTrack, []string{"WEBVTT\n\n00:00:00.000 --> 00:00:01.000\nHi\n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.writer.Write(&buf, &tt.cues); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("wrote %s, want it to contain %q", buf.String(), want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteDebugCues(t *testing.T) {
+	cues := subtitle.Subtitles{
+		{Start: 0, End: 1000000, Text: "Hi there", Original: "um hi --> there\n{x}", Source: subtitle.Source{TrackNumber: 2, Segment: 4, MaterialID: "M1"}},
+		{Start: 1000000, End: 2000000, Text: "Untraced"},
+	}
+	style := ASSPresets["classic"]
+	style.DebugCues = true
+	ass := WriterFunc(func(w io.Writer, subs *subtitle.Subtitles) error { return WriteASS(w, *subs, style) })
+	vtt, err := LookupMetadata("vtt", Metadata{DebugCues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srt, err := LookupMetadata("srt", Metadata{DebugCues: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const note = `text track 2, segment 5, material M1, original "um hi -> there\n{x}"`
+	tests := []struct {
+		name   string
+		writer Writer
+		want   string
+	}{
+		{"vtt", vtt, "WEBVTT\n\nNOTE " + note + "\n\n00:00:00.000 --> 00:00:01.000\nHi there\n\n00:00:01.000 --> 00:00:02.000\nUntraced\n"},
+		{"srt", srt, "1\n00:00:00,000 --> 00:00:01,000\nHi there\n[debug: " + note + "]\n\n2\n00:00:01,000 --> 00:00:02,000\nUntraced\n\n"},
+		{"ass", ass, "Comment: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,text track 2, segment 5, material M1, original \"um hi -> there\\n(x)\"\nDialogue: 0,0:00:00.00,0:00:01.00,Default,,0,0,0,,Hi there\nDialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,Untraced\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := tt.writer.Write(&buf, &cues); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("wrote %q, want it to contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEmphasis(t *testing.T) {
+	cues := subtitle.Subtitles{
+		{Start: 0, End: 500000, Text: "a"},
+		{Start: 500000, End: 1000000, Text: "big", Emphasis: subtitle.EmphasisBold},
+		{Start: 1000000, End: 1500000, Text: "deal", Emphasis: subtitle.EmphasisColor},
+	}
+	tests := []struct {
+		format string
+		want   []string
+	}{
+		{"srt", []string{"\n<b>big</b>\n", "\n<font color=\"#FFFF00\">deal</font>\n"}},
+		{"vtt", []string{"\n<b>big</b>\n", "\n<c.yellow>deal</c>\n"}},
+		{"html", []string{"><strong>big</strong></span>", "><mark>deal</mark></span>"}},
+		{"smi", []string{">a\n", "><b>big</b>\n", `><font color="#FFFF00">deal</font>` + "\n"}},
+		{"itt", []string{`<span tts:fontWeight="bold">big</span>`, `<span tts:color="yellow">deal</span>`}},
+		{"ass", []string{`,,{\b1}big` + "\n", `,,{\c&H00FFFF&}deal` + "\n"}},
+		{"json", []string{`"emphasis": "bold"`, `"emphasis": "color"`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			writer, err := Lookup(tt.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writer.Write(&buf, &cues); err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(buf.String(), want) {
+					t.Errorf("wrote %s, want it to contain %q", buf.String(), want)
+				}
+			}
+			if Verifiable(tt.format) {
+				if err := Verify(&buf, tt.format, cues); err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLookupMetadata(t *testing.T) {
+	cues := subtitle.Subtitles{{Start: 0, End: 1000000, Text: "Hello there, how are you?"}}
+	tests := []struct {
+		format, want string
+	}{
+		{"vtt", "WEBVTT\nLanguage: en-GB\n\n00:00:00.000"},
+		{"html", `<html lang="en-GB">`},
+		{"smi", ".EN-GBCC { Name: English; lang: en-GB; SAMIType: CC; }"},
+		{"itt", `xml:lang="en-GB"`},
+		{"srt", "1\n00:00:00,000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			writer, err := LookupMetadata(tt.format, Metadata{Language: "en-GB"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writer.Write(&buf, &cues); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(buf.String(), tt.want) {
+				t.Errorf("wrote %s, want it to contain %q", buf.String(), tt.want)
+			}
+		})
+	}
+	if _, err := LookupMetadata("sub", Metadata{Language: "en"}); err == nil {
+		t.Error("LookupMetadata() expected error for unregistered format")
+	}
+}
+
+func TestWriteVTTNote(t *testing.T) {
+	cues := subtitle.Subtitles{{Start: 0, End: 1000000, Text: "Hello"}}
+	meta := Metadata{
+		Language:  "th",
+		Source:    "Trip -->\nday 2",
+		Generator: "capcut-subtitle v1.4.0",
+		Generated: time.Date(2026, 3, 1, 16, 30, 0, 0, time.FixedZone("ICT", 7*3600)),
+	}
+	writer, err := LookupMetadata("vtt", meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := writer.Write(&buf, &cues); err != nil {
+		t.Fatal(err)
+	}
+	want := "WEBVTT\nLanguage: th\n\nNOTE\nSource: Trip -> day 2\nGenerator: capcut-subtitle v1.4.0\nGenerated: 2026-03-01T09:30:00Z\n\n00:00:00.000 --> 00:00:01.000\nHello\n\n"
+	if buf.String() != want {
+		t.Errorf("wrote %q, want %q", buf.String(), want)
+	}
+	parsed, err := subtitle.ParseVTT(strings.NewReader(buf.String()))
+	if err != nil || len(parsed) != 1 {
+		t.Errorf("ParseVTT() = %v, %v, want the one cue", parsed, err)
+	}
+}
+
+func TestWriteVTTStyle(t *testing.T) {
+	cues := subtitle.Subtitles{{Start: 0, End: 1000000, Text: "Hello"}}
+	tests := []struct {
+		name  string
+		style VTTStyle
+		want  string
+	}{
+		{
+			"full",
+			VTTStyle{Color: "#ffcc00", Background: "#000000", BackgroundAlpha: 0.5, Outline: "#202020", Font: "Noto Sans Thai"},
+			"WEBVTT\n\nSTYLE\n::cue {\n  color: #FFCC00;\n  background-color: rgba(0, 0, 0, 0.50);\n  text-shadow: #202020 1px 1px 2px, #202020 -1px -1px 2px;\n  font-family: \"Noto Sans Thai\", sans-serif;\n}\n\n00:00:00.000",
+		},
+		{
+			"invalid values left out",
+			VTTStyle{Color: "white", Background: "#12345", Font: `Evil"}-->`},
+			"WEBVTT\n\nSTYLE\n::cue {\n  font-family: \"Evil--\", sans-serif;\n}\n\n00:00:00.000",
+		},
+		{"nothing to style", VTTStyle{Color: "red"}, "WEBVTT\n\n00:00:00.000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := LookupMetadata("vtt", Metadata{Style: tt.style})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writer.Write(&buf, &cues); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), tt.want) {
+				t.Errorf("wrote %q, want it to start with %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplate(t *testing.T) {
+	cues := subtitle.Subtitles{
+		{Start: 1500000, End: 3000000, Text: "Fish & chips\nplease", Source: subtitle.Source{TrackNumber: 2}},
+		{Start: 3600000000, End: 3601000000, Text: "Bye"},
+	}
+	tests := []struct {
+		name, template, want, wantErr string
+	}{
+		{
+			"in-house xml",
+			`<script>{{range .Cues}}<line n="{{.Number}}" track="{{.Source.TrackNumber}}" in="{{seconds .Start}}">{{xml (join (lines .Text) " ")}}</line>{{end}}</script>`,
+			`<script><line n="1" track="2" in="1.500">Fish &amp; chips please</line><line n="2" track="0" in="3600.000">Bye</line></script>`,
+			"",
+		},
+		{
+			"unknown function",
+			"{{range .Cues}}[{{vtt .Start}}] {{upper .Text}} ({{duration (sub .End .Start)}})\n{{end}}",
+			"", "failed to parse template",
+		},
+		{
+			"timestamps",
+			"{{range .Cues}}{{srt .Start}} {{vtt .End}}\n{{end}}",
+			"00:00:01,500 00:00:03.000\n01:00:00,000 01:00:01.000\n",
+			"",
+		},
+		{"missing field", "{{range .Cues}}{{.Actor}}{{end}}", "", "failed to execute template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := NewTemplate(tt.name, tt.template)
+			var buf bytes.Buffer
+			if err == nil {
+				err = writer.Write(&buf, &cues)
+			}
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || buf.String() != tt.want {
+				t.Errorf("wrote %q, %v, want %q", buf.String(), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteYouTubeChapters(t *testing.T) {
+	tests := []struct {
+		name     string
+		chapters []subtitle.Chapter
+		want     string
+	}{
+		{
+			name:     "short video",
+			chapters: []subtitle.Chapter{{Start: 1500000, Title: "Intro"}, {Start: 133700000, Title: "Topic"}},
+			want:     "00:00 Intro\n02:13 Topic\n",
+		},
+		{
+			name:     "past the first hour",
+			chapters: []subtitle.Chapter{{Start: 0, Title: "Intro"}, {Start: 3723000000, Title: "Q&A"}},
+			want:     "0:00:00 Intro\n1:02:03 Q&A\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteYouTubeChapters(&buf, tt.chapters); err != nil {
+				t.Fatal(err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("WriteYouTubeChapters() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCueWriter(t *testing.T) {
+	subs := subtitle.Subtitles{
+		{Start: 0, End: 1000000, Text: "First"},
+		{Start: 1000000, End: 2000000, Text: "Blank\n \nline & <tag>"},
+	}
+	for _, format := range []string{"srt", "vtt"} {
+		t.Run(format, func(t *testing.T) {
+			writer, err := Lookup(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want bytes.Buffer
+			if err := writer.Write(&want, &subs); err != nil {
+				t.Fatal(err)
+			}
+
+			var got bytes.Buffer
+			cueWriter, err := NewCueWriter(&got, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range subs {
+				if err := cueWriter.WriteCue(c); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := cueWriter.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != want.String() {
+				t.Errorf("CueWriter wrote %q, want %q", got.String(), want.String())
+			}
+			if strings.Contains(got.String(), "Blank\n\n") {
+				t.Errorf("CueWriter kept a blank line inside a cue: %q", got.String())
+			}
+		})
+	}
+
+	for _, format := range []string{"srt", "vtt"} {
+		cueWriter, err := NewCueWriter(io.Discard, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		allocs := testing.AllocsPerRun(1000, func() {
+			cueWriter.WriteCue(subs[1])
+		})
+		if allocs != 0 {
+			t.Errorf("%s WriteCue() allocated %v times per cue, want 0", format, allocs)
+		}
+	}
+
+	if _, err := NewCueWriter(io.Discard, "json"); err == nil {
+		t.Error("NewCueWriter() expected error for json")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	cues := subtitle.Subtitles{
+		{Start: -500, End: 1000999, Text: "Crlf\r\n\nand a & <b>"},
+		{Start: 2000000, End: 3000000, Text: "", Source: subtitle.Source{MaterialID: "m", Segment: 1}},
+	}
+	for _, format := range []string{"srt", "vtt", "json"} {
+		t.Run(format, func(t *testing.T) {
+			writer, err := Lookup(format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writer.Write(&buf, &cues); err != nil {
+				t.Fatal(err)
+			}
+			if err := Verify(&buf, format, cues); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+
+	corrupt := []struct {
+		name, format, data string
+	}{
+		{"cue lost", "srt", "1\n00:00:00,000 --> 00:00:01,000\nCrlf\nand a & <b>\n\n"},
+		{"timing", "vtt", "WEBVTT\n\n00:00:00.000 --> 00:00:01.001\nCrlf\nand a &amp; &lt;b&gt;\n\n00:00:02.000 --> 00:00:03.000\n\n"},
+		{"text", "srt", "1\n00:00:00,000 --> 00:00:01,000\nCrlf\n\n2\n00:00:02,000 --> 00:00:03,000\n\n"},
+		{"unparsable", "vtt", "1\n00:00:00.000 --> 00:00:01.000\n"},
+	}
+	for _, tt := range corrupt {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Verify(strings.NewReader(tt.data), tt.format, cues); err == nil {
+				t.Error("Verify() expected error")
+			}
+		})
+	}
+
+	if Verifiable("html") {
+		t.Error("Verifiable(html) = true")
+	}
+}
+
+func TestWriteFileError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "subtitles.srt")
+	err := WriteFile(path, "srt", subtitle.Subtitles{{Text: "Hi"}})
+
+	var writeErr *WriteError
+	if !errors.As(err, &writeErr) {
+		t.Fatalf("WriteFile() error = %v, want a *WriteError", err)
+	}
+	if writeErr.Path != path || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("WriteFile() error = %+v", writeErr)
+	}
+}
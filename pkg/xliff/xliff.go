@@ -0,0 +1,231 @@
+// Package xliff reads and writes subtitles as XLIFF 1.2 and 2.0
+// documents, the format computer-assisted translation tools work on, so
+// cues can be translated with them and put back in time.
+package xliff
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"capcut-subtitle/pkg/subtitle"
+	"capcut-subtitle/pkg/writers"
+)
+
+// The XLIFF versions Write writes.
+const (
+	Version12 = "1.2"
+	Version20 = "2.0"
+)
+
+// Unit is a cue as a translation unit: its source text, its translation,
+// "" until there is one, and its times in microseconds.
+type Unit struct {
+	Start, End     int64
+	Source, Target string
+}
+
+// Document is the units of one subtitle file in a source language and
+// their translation into a target language.
+type Document struct {
+	// Original names the file the units were extracted from.
+	Original string
+	// SourceLang and TargetLang are BCP-47 language tags, such as th and
+	// en-US. TargetLang may be "" before the document is translated.
+	SourceLang, TargetLang string
+	Units                  []Unit
+}
+
+// Write writes d as an XLIFF document of version, Version12 or Version20,
+// with a trans-unit or unit per unit numbered from 1. The times of each
+// unit are in a note as an SRT timing line, such as
+// "00:00:01,000 --> 00:00:02,500", for translators to see and Read to
+// parse.
+func Write(w io.Writer, d Document, version string) error {
+	if version != Version12 && version != Version20 {
+		return fmt.Errorf("unsupported XLIFF version %q, want %s or %s", version, Version12, Version20)
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	target := ""
+	if version == Version12 {
+		if d.TargetLang != "" {
+			target = fmt.Sprintf(` target-language="%s"`, html.EscapeString(d.TargetLang))
+		}
+		fmt.Fprintf(bw, `<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2">
+  <file original="%s" source-language="%s"%s datatype="plaintext">
+    <body>
+`, html.EscapeString(d.Original), html.EscapeString(d.SourceLang), target)
+		for i, u := range d.Units {
+			fmt.Fprintf(bw, "      <trans-unit id=\"%d\" xml:space=\"preserve\">\n        <source>%s</source>\n", i+1, html.EscapeString(u.Source))
+			if u.Target != "" {
+				fmt.Fprintf(bw, "        <target>%s</target>\n", html.EscapeString(u.Target))
+			}
+			fmt.Fprintf(bw, "        <note>%s</note>\n      </trans-unit>\n", timing(u))
+		}
+		bw.WriteString("    </body>\n  </file>\n</xliff>\n")
+		return bw.Flush()
+	}
+
+	if d.TargetLang != "" {
+		target = fmt.Sprintf(` trgLang="%s"`, html.EscapeString(d.TargetLang))
+	}
+	fmt.Fprintf(bw, `<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="%s"%s>
+  <file id="f1" original="%s">
+`, html.EscapeString(d.SourceLang), target, html.EscapeString(d.Original))
+	for i, u := range d.Units {
+		fmt.Fprintf(bw, "    <unit id=\"%d\" xml:space=\"preserve\">\n      <notes>\n        <note category=\"timing\">%s</note>\n      </notes>\n      <segment>\n        <source>%s</source>\n",
+			i+1, timing(u), html.EscapeString(u.Source))
+		if u.Target != "" {
+			fmt.Fprintf(bw, "        <target>%s</target>\n", html.EscapeString(u.Target))
+		}
+		bw.WriteString("      </segment>\n    </unit>\n")
+	}
+	bw.WriteString("  </file>\n</xliff>\n")
+	return bw.Flush()
+}
+
+// timing renders the times of u as an SRT timing line.
+func timing(u Unit) string {
+	return writers.FormatTime(u.Start) + " --> " + writers.FormatTime(u.End)
+}
+
+// document is the part of an XLIFF 1.2 or 2.0 document Read looks at.
+type document struct {
+	XMLName xml.Name `xml:"xliff"`
+	Version string   `xml:"version,attr"`
+	// SourceLang and TargetLang are the languages of XLIFF 2.0.
+	SourceLang string `xml:"srcLang,attr"`
+	TargetLang string `xml:"trgLang,attr"`
+	Files      []struct {
+		Original string `xml:"original,attr"`
+		// SourceLang and TargetLang are the languages of XLIFF 1.2.
+		SourceLang string `xml:"source-language,attr"`
+		TargetLang string `xml:"target-language,attr"`
+		TransUnits []struct {
+			Source text     `xml:"source"`
+			Target text     `xml:"target"`
+			Notes  []string `xml:"note"`
+		} `xml:"body>trans-unit"`
+		Units []struct {
+			Notes []string `xml:"notes>note"`
+			// Parts are the segments and ignorables of the unit in order;
+			// tools may split a unit into sentences with the spaces
+			// between them in ignorables.
+			Parts []struct {
+				Source text `xml:"source"`
+				Target text `xml:"target"`
+			} `xml:",any"`
+		} `xml:"unit"`
+	} `xml:"file"`
+}
+
+// text is the text of a source or target element without its inline
+// markup: the native codes of bpt, ept, it and ph are dropped, and the
+// text of elements such as g, mrk and pc is kept.
+type text string
+
+func (s *text) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var b strings.Builder
+	codes := 0
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "bpt", "ept", "it", "ph":
+				codes++
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "bpt", "ept", "it", "ph":
+				codes--
+			case start.Name.Local:
+				if codes == 0 {
+					*s = text(b.String())
+					return nil
+				}
+			}
+		case xml.CharData:
+			if codes == 0 {
+				b.Write(t)
+			}
+		}
+	}
+}
+
+// Read reads an XLIFF 1.2 or 2.0 document, such as one Write wrote and a
+// translator filled in, taking the times of each unit from its first note
+// holding an SRT timing line. The units of every file in the document are
+// read in order; Original and the languages come from the first.
+func Read(r io.Reader) (Document, error) {
+	var doc document
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Document{}, fmt.Errorf("failed to parse XLIFF: %w", err)
+	}
+	v2 := strings.HasPrefix(doc.Version, "2.")
+	if !v2 && !strings.HasPrefix(doc.Version, "1.") {
+		return Document{}, fmt.Errorf("unsupported XLIFF version %q", doc.Version)
+	}
+
+	var d Document
+	if v2 {
+		d.SourceLang, d.TargetLang = doc.SourceLang, doc.TargetLang
+	}
+	for i, f := range doc.Files {
+		if i == 0 {
+			d.Original = f.Original
+			if !v2 {
+				d.SourceLang, d.TargetLang = f.SourceLang, f.TargetLang
+			}
+		}
+		for _, tu := range f.TransUnits {
+			u := Unit{Source: string(tu.Source), Target: string(tu.Target)}
+			if err := parseTiming(&u, tu.Notes, len(d.Units)+1); err != nil {
+				return Document{}, err
+			}
+			d.Units = append(d.Units, u)
+		}
+		for _, unit := range f.Units {
+			var source, target strings.Builder
+			for _, part := range unit.Parts {
+				source.WriteString(string(part.Source))
+				target.WriteString(string(part.Target))
+			}
+			u := Unit{Source: source.String(), Target: target.String()}
+			if err := parseTiming(&u, unit.Notes, len(d.Units)+1); err != nil {
+				return Document{}, err
+			}
+			d.Units = append(d.Units, u)
+		}
+	}
+	return d, nil
+}
+
+// parseTiming sets the times of u, the unit numbered n in the document,
+// from the first of notes that is an SRT timing line.
+func parseTiming(u *Unit, notes []string, n int) error {
+	for _, note := range notes {
+		start, end, ok := strings.Cut(strings.TrimSpace(note), "-->")
+		if !ok {
+			continue
+		}
+		s, err := subtitle.ParseTimestamp(strings.TrimSpace(start))
+		if err != nil {
+			continue
+		}
+		e, err := subtitle.ParseTimestamp(strings.TrimSpace(end))
+		if err != nil {
+			continue
+		}
+		u.Start, u.End = s, e
+		return nil
+	}
+	return fmt.Errorf("unit %d of the XLIFF has no timing note", n)
+}
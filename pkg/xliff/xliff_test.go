@@ -0,0 +1,81 @@
+package xliff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWriteRead(t *testing.T) {
+	d := Document{Original: "movie.srt", SourceLang: "th", TargetLang: "en", Units: []Unit{
+		{Start: 1_000_000, End: 2_500_000, Source: "สวัสดี", Target: "Hello"},
+		{Start: 2_500_000, End: 4_000_000, Source: "ราคา <10 บาท\nเท่านั้น"},
+	}}
+	for _, version := range []string{Version12, Version20} {
+		var b strings.Builder
+		if err := Write(&b, d, version); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(b.String(), "00:00:01,000 --> 00:00:02,500") {
+			t.Errorf("Write(%s) has no timing note:\n%s", version, b.String())
+		}
+		got, err := Read(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, d) {
+			t.Errorf("Read(Write(%s)) = %+v, want %+v", version, got, d)
+		}
+	}
+	if err := Write(&strings.Builder{}, d, "1.1"); err == nil {
+		t.Error("Write() succeeded with XLIFF 1.1")
+	}
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want []Unit
+	}{
+		{
+			name: "1.2 with inline codes",
+			doc: `<xliff version="1.2" xmlns="urn:oasis:names:tc:xliff:document:1.2"><file source-language="en" target-language="th" datatype="plaintext" original="a.srt"><body>
+  <trans-unit id="1"><source>Click <bpt id="1">&lt;b&gt;</bpt>Save<ept id="1">&lt;/b&gt;</ept></source><target><g id="1">คลิกบันทึก</g></target>
+    <note from="reviewer">Keep it short</note><note>00:00:00,000 --> 00:00:01,000</note></trans-unit>
+</body></file></xliff>`,
+			want: []Unit{{Start: 0, End: 1_000_000, Source: "Click Save", Target: "คลิกบันทึก"}},
+		},
+		{
+			name: "2.0 split into segments",
+			doc: `<xliff version="2.0" xmlns="urn:oasis:names:tc:xliff:document:2.0" srcLang="en" trgLang="th"><file id="f1">
+  <unit id="1"><notes><note category="timing">00:00:01,000 --> 00:00:03,000</note></notes>
+    <segment><source>Hi.</source><target>หวัดดี</target></segment><ignorable><source> </source><target> </target></ignorable><segment><source>Bye.</source><target>บาย</target></segment></unit>
+</file></xliff>`,
+			want: []Unit{{Start: 1_000_000, End: 3_000_000, Source: "Hi. Bye.", Target: "หวัดดี บาย"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Units, tt.want) {
+				t.Errorf("Read() = %+v, want %+v", got.Units, tt.want)
+			}
+			if got.SourceLang != "en" || got.TargetLang != "th" {
+				t.Errorf("Read() languages = %q, %q, want en, th", got.SourceLang, got.TargetLang)
+			}
+		})
+	}
+
+	for _, doc := range []string{
+		`<xliff version="1.2"><file><body><trans-unit id="1"><source>x</source></trans-unit></body></file></xliff>`,
+		`<xliff version="3.0"/>`,
+	} {
+		if _, err := Read(strings.NewReader(doc)); err == nil {
+			t.Errorf("Read(%s) succeeded", doc)
+		}
+	}
+}
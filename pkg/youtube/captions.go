@@ -0,0 +1,194 @@
+// Package youtube uploads caption tracks to videos through the YouTube Data
+// API. Authorization is left to the caller, who supplies an OAuth 2.0 access
+// token with the youtube.force-ssl scope.
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+)
+
+// DefaultBaseURL is the root of the YouTube Data API.
+const DefaultBaseURL = "https://www.googleapis.com"
+
+// Client calls the captions endpoints of the YouTube Data API.
+type Client struct {
+	// Token is the OAuth 2.0 access token sent with every request.
+	Token string
+	// BaseURL replaces DefaultBaseURL when set, for tests.
+	BaseURL string
+	// HTTPClient replaces http.DefaultClient when set.
+	HTTPClient *http.Client
+}
+
+// Caption describes a caption track of a video.
+type Caption struct {
+	ID       string
+	VideoID  string
+	Language string
+	Name     string
+	// Draft tracks are uploaded but not shown to viewers.
+	Draft bool
+}
+
+type captionResource struct {
+	ID      string         `json:"id,omitempty"`
+	Snippet captionSnippet `json:"snippet"`
+}
+
+type captionSnippet struct {
+	VideoID  string `json:"videoId"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	IsDraft  bool   `json:"isDraft"`
+}
+
+func (r captionResource) caption() Caption {
+	return Caption{ID: r.ID, VideoID: r.Snippet.VideoID, Language: r.Snippet.Language, Name: r.Snippet.Name, Draft: r.Snippet.IsDraft}
+}
+
+// List returns the caption tracks of a video.
+func (c *Client) List(ctx context.Context, videoID string) ([]Caption, error) {
+	query := url.Values{"part": {"snippet"}, "videoId": {videoID}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL()+"/youtube/v3/captions?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []captionResource `json:"items"`
+	}
+	if err := c.do(req, &list); err != nil {
+		return nil, fmt.Errorf("failed to list captions of video %s: %w", videoID, err)
+	}
+	captions := make([]Caption, len(list.Items))
+	for i, item := range list.Items {
+		captions[i] = item.caption()
+	}
+	return captions, nil
+}
+
+// Insert adds a caption track to caption.VideoID with the contents of body,
+// an SRT or WebVTT file, and returns the new track.
+func (c *Client) Insert(ctx context.Context, caption Caption, body []byte) (Caption, error) {
+	caption.ID = ""
+	uploaded, err := c.upload(ctx, http.MethodPost, caption, body)
+	if err != nil {
+		return Caption{}, fmt.Errorf("failed to add captions to video %s: %w", caption.VideoID, err)
+	}
+	return uploaded, nil
+}
+
+// Update replaces the contents of the existing track caption.ID with body
+// and its draft status with caption.Draft.
+func (c *Client) Update(ctx context.Context, caption Caption, body []byte) (Caption, error) {
+	uploaded, err := c.upload(ctx, http.MethodPut, caption, body)
+	if err != nil {
+		return Caption{}, fmt.Errorf("failed to update captions %s: %w", caption.ID, err)
+	}
+	return uploaded, nil
+}
+
+// upload sends the track metadata and file as a multipart upload.
+func (c *Client) upload(ctx context.Context, method string, caption Caption, body []byte) (Caption, error) {
+	metadata, err := json.Marshal(captionResource{
+		ID: caption.ID,
+		Snippet: captionSnippet{
+			VideoID:  caption.VideoID,
+			Language: caption.Language,
+			Name:     caption.Name,
+			IsDraft:  caption.Draft,
+		},
+	})
+	if err != nil {
+		return Caption{}, err
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+	for _, part := range []struct {
+		contentType string
+		data        []byte
+	}{
+		{"application/json; charset=UTF-8", metadata},
+		{"application/octet-stream", body},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{"Content-Type": {part.contentType}})
+		if err != nil {
+			return Caption{}, err
+		}
+		w.Write(part.data)
+	}
+	if err := parts.Close(); err != nil {
+		return Caption{}, err
+	}
+
+	query := url.Values{"part": {"snippet"}, "uploadType": {"multipart"}}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL()+"/upload/youtube/v3/captions?"+query.Encode(), &buf)
+	if err != nil {
+		return Caption{}, err
+	}
+	req.Header.Set("Content-Type", "multipart/related; boundary="+parts.Boundary())
+
+	var uploaded captionResource
+	if err := c.do(req, &uploaded); err != nil {
+		return Caption{}, err
+	}
+	return uploaded.caption(), nil
+}
+
+// Error is an error response of the API.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("YouTube API: %s (HTTP %d)", e.Message, e.StatusCode)
+}
+
+// do sends req and decodes the JSON response into v.
+func (c *Client) do(req *http.Request, v any) error {
+	req.Header.Set("Authorization", "Bearer "+c.Token)
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode/100 != 2 {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		apiErr := &Error{StatusCode: resp.StatusCode, Message: http.StatusText(resp.StatusCode)}
+		if json.Unmarshal(data, &body) == nil && body.Error.Message != "" {
+			apiErr.Message = body.Error.Message
+		}
+		return apiErr
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (c *Client) baseURL() string {
+	if c.BaseURL != "" {
+		return c.BaseURL
+	}
+	return DefaultBaseURL
+}
@@ -0,0 +1,95 @@
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestClient(t *testing.T) {
+	var uploads []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			io.WriteString(w, `{"error":{"code":401,"message":"Invalid Credentials"}}`)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/youtube/v3/captions":
+			if r.URL.Query().Get("videoId") != "abc" {
+				t.Errorf("videoId = %q", r.URL.Query().Get("videoId"))
+			}
+			io.WriteString(w, `{"items":[{"id":"c1","snippet":{"videoId":"abc","language":"en","name":"English"}}]}`)
+		case r.URL.Path == "/upload/youtube/v3/captions":
+			_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts := multipart.NewReader(r.Body, params["boundary"])
+			metadata, err := parts.NextPart()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var resource captionResource
+			if err := json.NewDecoder(metadata).Decode(&resource); err != nil {
+				t.Fatal(err)
+			}
+			media, err := parts.NextPart()
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(media)
+			uploads = append(uploads, r.Method+" "+string(body))
+			if resource.ID == "" {
+				resource.ID = "c2"
+			}
+			json.NewEncoder(w).Encode(resource)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	client := &Client{Token: "token", BaseURL: server.URL}
+
+	captions, err := client.List(ctx, "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Caption{{ID: "c1", VideoID: "abc", Language: "en", Name: "English"}}; !reflect.DeepEqual(captions, want) {
+		t.Errorf("List() = %+v, want %+v", captions, want)
+	}
+
+	added, err := client.Insert(ctx, Caption{VideoID: "abc", Language: "th", Name: "Thai", Draft: true}, []byte("new"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Caption{ID: "c2", VideoID: "abc", Language: "th", Name: "Thai", Draft: true}); added != want {
+		t.Errorf("Insert() = %+v, want %+v", added, want)
+	}
+
+	updated, err := client.Update(ctx, captions[0], []byte("replaced"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated != captions[0] {
+		t.Errorf("Update() = %+v, want %+v", updated, captions[0])
+	}
+	if want := []string{"POST new", "PUT replaced"}; !reflect.DeepEqual(uploads, want) {
+		t.Errorf("uploads = %q, want %q", uploads, want)
+	}
+
+	_, err = (&Client{Token: "expired", BaseURL: server.URL}).List(ctx, "abc")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized || apiErr.Message != "Invalid Credentials" {
+		t.Errorf("List() with a bad token error = %v", err)
+	}
+}